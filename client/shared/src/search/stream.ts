@@ -165,6 +165,7 @@ export interface Skipped {
      * - repository-missing :: we could not search a repository because it is not cloned and we failed to find it on the remote code host.
      * - excluded-fork :: we did not search a repository because it is a fork.
      * - excluded-archive :: we did not search a repository because it is archived.
+     * - partial-clone :: we only searched the names of files whose content has not been fetched from a partial clone.
      * - display :: we hit the display limit, so we stopped sending results from the backend.
      */
     reason:
@@ -176,6 +177,7 @@ export interface Skipped {
         | 'repository-missing'
         | 'excluded-fork'
         | 'excluded-archive'
+        | 'partial-clone'
         | 'display'
         | 'error'
     /**
@@ -6,6 +6,7 @@ import GithubIcon from 'mdi-react/GithubIcon'
 import GitIcon from 'mdi-react/GitIcon'
 import GitLabIcon from 'mdi-react/GitlabIcon'
 import LanguageJavaIcon from 'mdi-react/LanguageJavaIcon'
+import SourceRepositoryIcon from 'mdi-react/SourceRepositoryIcon'
 import React from 'react'
 
 import { PhabricatorIcon } from '@sourcegraph/shared/src/components/icons'
@@ -20,6 +21,7 @@ import jvmPackagesSchemaJSON from '../../../../../schema/jvm-packages.schema.jso
 import otherExternalServiceSchemaJSON from '../../../../../schema/other_external_service.schema.json'
 import perforceSchemaJSON from '../../../../../schema/perforce.schema.json'
 import phabricatorSchemaJSON from '../../../../../schema/phabricator.schema.json'
+import subversionSchemaJSON from '../../../../../schema/subversion.schema.json'
 import { ExternalServiceKind } from '../../graphql-operations'
 import { EditorAction } from '../../site-admin/configHelpers'
 import { PerforceIcon } from '../PerforceIcon'
@@ -1210,6 +1212,61 @@ const JVM_PACKAGES: AddExternalServiceOptions = {
     ),
     editorActions: [],
 }
+const SUBVERSION: AddExternalServiceOptions = {
+    kind: ExternalServiceKind.SUBVERSION,
+    title: 'Subversion',
+    icon: SourceRepositoryIcon,
+    jsonSchema: subversionSchemaJSON,
+    defaultDisplayName: 'Subversion',
+    defaultConfig: `{
+  "url": "https://svn.example.com/repos",
+  "username": "<username>",
+  "password": "<password>"
+}`,
+    instructions: (
+        <div>
+            <ol>
+                <li>
+                    In the configuration below, set <Field>url</Field> to the URL of the Subversion root, for example{' '}
+                    <code>"https://svn.example.com/repos"</code> or <code>"svn://svn.example.com"</code>.
+                </li>
+                <li>
+                    Set <Field>username</Field> and <Field>password</Field> to the credentials of a user with read
+                    access to the repositories, or remove them for anonymous access.
+                </li>
+                <li>
+                    By default, every directory directly under the root is synced as a repository. Set{' '}
+                    <Field>repos</Field> to sync only the listed paths, and <Field>layout</Field> if the repositories
+                    do not use the standard <code>trunk</code>, <code>branches</code> and <code>tags</code> layout.
+                </li>
+                <li>
+                    Optionally, set <Field>authors</Field> to map Subversion users to Git authors, for example{' '}
+                    <code>{'{"jdoe": "Jane Doe <jane@example.com>"}'}</code>.
+                </li>
+            </ol>
+        </div>
+    ),
+    editorActions: [
+        {
+            id: 'setRepos',
+            label: 'Set repositories',
+            run: (config: string) => {
+                const value = '<path>'
+                const edits = setProperty(config, ['repos'], [value], defaultFormattingOptions)
+                return { edits, selectText: value }
+            },
+        },
+        {
+            id: 'mapAuthors',
+            label: 'Map authors',
+            run: (config: string) => {
+                const value = 'Name <email>'
+                const edits = setProperty(config, ['authors'], { '<username>': value }, defaultFormattingOptions)
+                return { edits, selectText: value }
+            },
+        },
+    ],
+}
 
 export const codeHostExternalServices: Record<string, AddExternalServiceOptions> = {
     github: GITHUB_DOTCOM,
@@ -1224,6 +1281,7 @@ export const codeHostExternalServices: Record<string, AddExternalServiceOptions>
     git: GENERIC_GIT,
     ...(window.context?.experimentalFeatures?.perforce === 'enabled' ? { perforce: PERFORCE } : {}),
     ...(window.context?.experimentalFeatures?.jvmPackages === 'enabled' ? { jvmPackages: JVM_PACKAGES } : {}),
+    ...(window.context?.experimentalFeatures?.subversion === 'enabled' ? { subversion: SUBVERSION } : {}),
 }
 
 export const nonCodeHostExternalServices: Record<string, AddExternalServiceOptions> = {
@@ -1246,4 +1304,5 @@ export const defaultExternalServices: Record<ExternalServiceKind, AddExternalSer
     [ExternalServiceKind.AWSCODECOMMIT]: AWS_CODE_COMMIT,
     [ExternalServiceKind.PERFORCE]: PERFORCE,
     [ExternalServiceKind.JVMPACKAGES]: JVM_PACKAGES,
+    [ExternalServiceKind.SUBVERSION]: SUBVERSION,
 }
@@ -20,6 +20,7 @@ import perforceSchemaJSON from '../../../../schema/perforce.schema.json'
 import phabricatorSchemaJSON from '../../../../schema/phabricator.schema.json'
 import settingsSchemaJSON from '../../../../schema/settings.schema.json'
 import siteSchemaJSON from '../../../../schema/site.schema.json'
+import subversionSchemaJSON from '../../../../schema/subversion.schema.json'
 import { PageTitle } from '../components/PageTitle'
 import { DynamicallyImportedMonacoSettingsEditor } from '../settings/DynamicallyImportedMonacoSettingsEditor'
 
@@ -45,6 +46,7 @@ const externalServices: Record<ExternalServiceKind, JSONSchema> = {
     OTHER: otherExternalServiceSchemaJSON,
     PERFORCE: perforceSchemaJSON,
     PHABRICATOR: phabricatorSchemaJSON,
+    SUBVERSION: subversionSchemaJSON,
 }
 
 const allConfigSchema = {
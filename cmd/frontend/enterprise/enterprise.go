@@ -22,6 +22,7 @@ type Services struct {
 	BitbucketServerWebhook    http.Handler
 	NewCodeIntelUploadHandler NewCodeIntelUploadHandler
 	NewExecutorProxyHandler   NewExecutorProxyHandler
+	SearchJobResultsHandler   http.Handler
 	AuthzResolver             graphqlbackend.AuthzResolver
 	BatchChangesResolver      graphqlbackend.BatchChangesResolver
 	CodeIntelResolver         graphqlbackend.CodeIntelResolver
@@ -30,6 +31,7 @@ type Services struct {
 	LicenseResolver           graphqlbackend.LicenseResolver
 	DotcomResolver            graphqlbackend.DotcomRootResolver
 	SearchContextsResolver    graphqlbackend.SearchContextsResolver
+	SearchJobsResolver        graphqlbackend.SearchJobsResolver
 }
 
 // NewCodeIntelUploadHandler creates a new handler for the LSIF upload endpoint. The
@@ -49,6 +51,7 @@ func DefaultServices() Services {
 		BitbucketServerWebhook:    makeNotFoundHandler("bitbucket server webhook"),
 		NewCodeIntelUploadHandler: func(_ bool) http.Handler { return makeNotFoundHandler("code intel upload") },
 		NewExecutorProxyHandler:   func() http.Handler { return makeNotFoundHandler("executor proxy") },
+		SearchJobResultsHandler:   makeNotFoundHandler("search job results"),
 	}
 }
 
@@ -0,0 +1,33 @@
+package graphqlbackend
+
+import (
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// codeOwnerSearchResultResolver is a resolver for the GraphQL type `CodeOwnerSearchResult`
+type codeOwnerSearchResultResolver struct {
+	result.OwnerMatch
+
+	RepoResolver *RepositoryResolver
+}
+
+func (r *codeOwnerSearchResultResolver) Owner() string {
+	return r.OwnerMatch.Owner
+}
+
+func (r *codeOwnerSearchResultResolver) Repository() *RepositoryResolver {
+	return r.RepoResolver
+}
+
+func (r *codeOwnerSearchResultResolver) ToRepository() (*RepositoryResolver, bool) { return nil, false }
+func (r *codeOwnerSearchResultResolver) ToFileMatch() (*FileMatchResolver, bool)   { return nil, false }
+func (r *codeOwnerSearchResultResolver) ToCommitSearchResult() (*CommitSearchResultResolver, bool) {
+	return nil, false
+}
+func (r *codeOwnerSearchResultResolver) ToCodeOwnerSearchResult() (*codeOwnerSearchResultResolver, bool) {
+	return r, true
+}
+
+func (r *codeOwnerSearchResultResolver) ResultCount() int32 {
+	return 1
+}
@@ -29,6 +29,10 @@ type CodeIntelResolver interface {
 	IndexConfiguration(ctx context.Context, id graphql.ID) (IndexConfigurationResolver, error) // TODO - rename ...ForRepo
 	UpdateRepositoryIndexConfiguration(ctx context.Context, args *UpdateRepositoryIndexConfigurationArgs) (*EmptyResponse, error)
 	PreviewGitObjectFilter(ctx context.Context, id graphql.ID, args *PreviewGitObjectFilterArgs) ([]GitObjectFilterPreviewResolver, error)
+	PackageDependents(ctx context.Context, args *PackageDependencyQueryArgs) (PackageDependencyConnectionResolver, error)
+	PackageDependencies(ctx context.Context, args *PackageDependencyQueryArgs) (PackageDependencyConnectionResolver, error)
+	RepositoryPackageDependents(ctx context.Context, id graphql.ID, args *RepositoryPackageDependencyQueryArgs) (PackageDependencyConnectionResolver, error)
+	RepositoryPackageDependencies(ctx context.Context, id graphql.ID, args *RepositoryPackageDependencyQueryArgs) (PackageDependencyConnectionResolver, error)
 	NodeResolvers() map[string]NodeByIDFunc
 }
 
@@ -152,6 +156,8 @@ type GitBlobLSIFDataResolver interface {
 	Ranges(ctx context.Context, args *LSIFRangesArgs) (CodeIntelligenceRangeConnectionResolver, error)
 	Definitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error)
 	References(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error)
+	IncomingCalls(ctx context.Context, args *LSIFPagedQueryPositionArgs) (CallHierarchyConnectionResolver, error)
+	OutgoingCalls(ctx context.Context, args *LSIFPagedQueryPositionArgs) (CallHierarchyConnectionResolver, error)
 	Hover(ctx context.Context, args *LSIFQueryPositionArgs) (HoverResolver, error)
 	Documentation(ctx context.Context, args *LSIFQueryPositionArgs) (DocumentationResolver, error)
 }
@@ -211,6 +217,16 @@ type LocationConnectionResolver interface {
 	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
 }
 
+type CallHierarchyConnectionResolver interface {
+	Nodes(ctx context.Context) ([]CallHierarchyItemResolver, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type CallHierarchyItemResolver interface {
+	Symbol(ctx context.Context) (LocationResolver, error)
+	CallSites(ctx context.Context) ([]LocationResolver, error)
+}
+
 type HoverResolver interface {
 	Markdown() Markdown
 	Range() RangeResolver
@@ -297,3 +313,29 @@ type CodeIntelligenceConfigurationPolicyResolver interface {
 	IndexCommitMaxAgeHours() *int32
 	IndexIntermediateCommits() bool
 }
+
+type PackageDependencyQueryArgs struct {
+	graphqlutil.ConnectionArgs
+	Scheme  string
+	Name    string
+	Version *string
+	After   *string
+}
+
+type RepositoryPackageDependencyQueryArgs struct {
+	graphqlutil.ConnectionArgs
+	After *string
+}
+
+type PackageDependencyConnectionResolver interface {
+	Nodes(ctx context.Context) ([]PackageDependencyResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type PackageDependencyResolver interface {
+	Scheme() string
+	Name() string
+	Version() string
+	Repository(ctx context.Context) (*RepositoryResolver, error)
+}
@@ -170,6 +170,25 @@ func (r *externalServiceResolver) NextSyncAt() *DateTime {
 	return &DateTime{Time: r.externalService.NextSyncAt}
 }
 
+func (r *externalServiceResolver) RepoDiskUsage(ctx context.Context) (BigInt, error) {
+	usage, err := database.GitserverRepos(r.db).ExternalServiceDiskUsage(ctx, r.externalService.ID)
+	if err != nil {
+		return BigInt{}, err
+	}
+	return BigInt{Int: usage[r.externalService.ID]}, nil
+}
+
+func (r *externalServiceResolver) RepoDiskQuota() *BigInt {
+	if c := conf.Get().ExperimentalFeatures; c != nil {
+		for _, q := range c.GitServerDiskQuotas {
+			if int64(q.ExternalService) == r.externalService.ID {
+				return &BigInt{Int: int64(q.MaxBytes)}
+			}
+		}
+	}
+	return nil
+}
+
 var scopeCache = rcache.New("extsvc_token_scope")
 
 func (r *externalServiceResolver) GrantedScopes(ctx context.Context) (*[]string, error) {
@@ -0,0 +1,130 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/fuzzyfinder"
+	"github.com/sourcegraph/sourcegraph/internal/search/searchcontexts"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// maxFuzzyFileMatches is the maximum number of matches returned by the
+// fuzzyFiles fields.
+const maxFuzzyFileMatches = 1000
+
+type fuzzyFilesArgs struct {
+	Query string
+	First int32
+}
+
+func (a *fuzzyFilesArgs) limit() int {
+	if a.First <= 0 || a.First > maxFuzzyFileMatches {
+		return maxFuzzyFileMatches
+	}
+	return int(a.First)
+}
+
+func (r *GitCommitResolver) FuzzyFiles(ctx context.Context, args *fuzzyFilesArgs) ([]*fuzzyFileMatchResolver, error) {
+	ix, err := fuzzyfinder.DefaultStore.Index(ctx, r.gitRepo, api.CommitID(r.oid))
+	if err != nil {
+		return nil, err
+	}
+
+	matches := ix.Search(args.Query, args.limit())
+	resolvers := make([]*fuzzyFileMatchResolver, 0, len(matches))
+	for _, m := range matches {
+		resolvers = append(resolvers, &fuzzyFileMatchResolver{
+			match: m,
+			file: &GitTreeEntryResolver{
+				db:     r.db,
+				commit: r,
+				stat:   CreateFileInfo(m.Path, false),
+			},
+		})
+	}
+	return resolvers, nil
+}
+
+func (r *schemaResolver) FuzzyFiles(ctx context.Context, args *struct {
+	Query         string
+	SearchContext string
+	First         int32
+}) ([]*fuzzyFileMatchResolver, error) {
+	sc, err := searchcontexts.ResolveSearchContextSpec(ctx, r.db, args.SearchContext)
+	if err != nil {
+		return nil, err
+	}
+	if searchcontexts.IsAutoDefinedSearchContext(sc) || searchcontexts.IsQueryDefinedSearchContext(sc) {
+		return nil, errors.Errorf("search context %q does not list repositories to find files in", args.SearchContext)
+	}
+
+	// 🚨 SECURITY: GetRepositoryRevisions only returns the repositories the
+	// current user has access to.
+	repoRevs, err := searchcontexts.GetRepositoryRevisions(ctx, r.db, sc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []fuzzyfinder.RepoCommit
+	commitResolvers := map[fuzzyfinder.RepoCommit]*GitCommitResolver{}
+	for _, rr := range repoRevs {
+		repoResolver := NewRepositoryResolver(r.db, rr.Repo.ToRepo())
+		revs := rr.RevSpecs()
+		if len(revs) == 0 {
+			revs = []string{""}
+		}
+		for _, rev := range revs {
+			if rev == "" {
+				rev = "HEAD"
+			}
+			commitID, err := git.ResolveRevision(ctx, rr.Repo.Name, rev, git.ResolveRevisionOptions{})
+			if err != nil {
+				return nil, err
+			}
+			rc := fuzzyfinder.RepoCommit{Repo: rr.Repo.Name, Commit: commitID}
+			if _, ok := commitResolvers[rc]; ok {
+				continue
+			}
+			commits = append(commits, rc)
+			commitResolvers[rc] = toGitCommitResolver(repoResolver, r.db, commitID, nil)
+		}
+	}
+
+	limit := (&fuzzyFilesArgs{First: args.First}).limit()
+	matches, err := fuzzyfinder.DefaultStore.SearchCommits(ctx, commits, args.Query, limit)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*fuzzyFileMatchResolver, 0, len(matches))
+	for _, m := range matches {
+		resolvers = append(resolvers, &fuzzyFileMatchResolver{
+			match: m.Match,
+			file: &GitTreeEntryResolver{
+				db:     r.db,
+				commit: commitResolvers[m.RepoCommit],
+				stat:   CreateFileInfo(m.Path, false),
+			},
+		})
+	}
+	return resolvers, nil
+}
+
+type fuzzyFileMatchResolver struct {
+	match fuzzyfinder.Match
+	file  *GitTreeEntryResolver
+}
+
+func (r *fuzzyFileMatchResolver) File() *GitTreeEntryResolver { return r.file }
+func (r *fuzzyFileMatchResolver) Score() int32                { return int32(r.match.Score) }
+func (r *fuzzyFileMatchResolver) Typos() int32                { return int32(r.match.Typos) }
+
+func (r *fuzzyFileMatchResolver) Positions() []int32 {
+	positions := make([]int32, 0, len(r.match.Positions))
+	for _, p := range r.match.Positions {
+		positions = append(positions, int32(p))
+	}
+	return positions
+}
@@ -0,0 +1,74 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/fuzzyfinder"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
+)
+
+func TestGitCommitFuzzyFiles(t *testing.T) {
+	resetMocks()
+	database.Mocks.ExternalServices.List = func(opt database.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+		return nil, nil
+	}
+	database.Mocks.Repos.MockGetByName(t, "github.com/gorilla/mux", 2)
+	backend.Mocks.Repos.ResolveRev = func(ctx context.Context, repo *types.Repo, rev string) (api.CommitID, error) {
+		return exampleCommitSHA1, nil
+	}
+	backend.Mocks.Repos.MockGetCommit_Return_NoCheck(t, &gitapi.Commit{ID: exampleCommitSHA1})
+	defer git.ResetMocks()
+
+	orig := fuzzyfinder.DefaultStore
+	fuzzyfinder.DefaultStore = &fuzzyfinder.Store{
+		Dir: t.TempDir(),
+		FetchPaths: func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]byte, error) {
+			return []byte("mux.go\x00route.go\x00regexp.go\x00"), nil
+		},
+	}
+	defer func() { fuzzyfinder.DefaultStore = orig }()
+
+	RunTests(t, []*Test{
+		{
+			Schema: mustParseGraphQLSchema(t),
+			Query: `
+				{
+					repository(name: "github.com/gorilla/mux") {
+						commit(rev: "` + exampleCommitSHA1 + `") {
+							fuzzyFiles(query: "rte", first: 1) {
+								file {
+									path
+								}
+								typos
+								positions
+							}
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+{
+  "repository": {
+    "commit": {
+      "fuzzyFiles": [
+        {
+          "file": {
+            "path": "route.go"
+          },
+          "typos": 0,
+          "positions": [0, 3, 4]
+        }
+      ]
+    }
+  }
+}
+			`,
+		},
+	})
+}
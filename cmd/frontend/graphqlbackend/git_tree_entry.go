@@ -7,18 +7,15 @@ import (
 	neturl "net/url"
 	"os"
 	"path"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/cockroachdb/errors"
 	"github.com/inconshreveable/log15"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/externallink"
-	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cloneurls"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/highlight"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -127,16 +124,16 @@ func (r *GitTreeEntryResolver) URL(ctx context.Context) (string, error) {
 
 	if submodule := r.Submodule(); submodule != nil {
 		span.SetTag("Submodule", "true")
-		url := submodule.URL()
-		if strings.HasPrefix(url, "../") {
-			url = path.Join(r.Repository().Name(), url)
-		}
-		repoName, err := cloneURLToRepoName(ctx, r.db, url)
+		repo, err := submodule.Repository(ctx)
 		if err != nil {
-			log15.Error("Failed to resolve submodule repository name from clone URL", "cloneURL", submodule.URL(), "err", err)
+			log15.Error("Failed to resolve submodule repository from clone URL", "cloneURL", submodule.URL(), "err", err)
+			return "", nil
+		}
+		if repo == nil {
 			return "", nil
 		}
-		return "/" + repoName + "@" + submodule.Commit(), nil
+		// Link to the pinned commit of the submodule.
+		return "/" + repo.Name() + "@" + submodule.Commit(), nil
 	}
 	url := r.commit.repoRevURL()
 	return r.urlPath(url).String(), nil
@@ -182,25 +179,11 @@ func (r *GitTreeEntryResolver) RawZipArchiveURL() string {
 
 func (r *GitTreeEntryResolver) Submodule() *gitSubmoduleResolver {
 	if submoduleInfo, ok := r.stat.Sys().(git.Submodule); ok {
-		return &gitSubmoduleResolver{submodule: submoduleInfo}
+		return &gitSubmoduleResolver{db: r.db, parent: r.commit.repoResolver, submodule: submoduleInfo}
 	}
 	return nil
 }
 
-func cloneURLToRepoName(ctx context.Context, db dbutil.DB, cloneURL string) (string, error) {
-	span, ctx := ot.StartSpanFromContext(ctx, "cloneURLToRepoName")
-	defer span.Finish()
-
-	repoName, err := cloneurls.ReposourceCloneURLToRepoName(ctx, db, cloneURL)
-	if err != nil {
-		return "", err
-	}
-	if repoName == "" {
-		return "", errors.Errorf("no matching code host found for %s", cloneURL)
-	}
-	return string(repoName), nil
-}
-
 func CreateFileInfo(path string, isDir bool) fs.FileInfo {
 	return fileInfo{path: path, isDir: isDir}
 }
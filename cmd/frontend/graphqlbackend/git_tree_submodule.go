@@ -1,8 +1,19 @@
 package graphqlbackend
 
-import "github.com/sourcegraph/sourcegraph/internal/vcs/git"
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cloneurls"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
 
 type gitSubmoduleResolver struct {
+	db        dbutil.DB
+	parent    *RepositoryResolver
 	submodule git.Submodule
 }
 
@@ -17,3 +28,35 @@ func (r *gitSubmoduleResolver) Commit() string {
 func (r *gitSubmoduleResolver) Path() string {
 	return r.submodule.Path
 }
+
+func (r *gitSubmoduleResolver) Repository(ctx context.Context) (*RepositoryResolver, error) {
+	if r.submodule.URL == "" {
+		return nil, nil
+	}
+	parent, err := r.parent.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := cloneurls.SubmoduleRepo(ctx, r.db, parent, r.submodule.URL)
+	if err != nil || repo == nil {
+		return nil, err
+	}
+	return NewRepositoryResolver(r.db, repo), nil
+}
+
+// submoduleResolver returns a search.SubmoduleResolver which resolves
+// submodules to the repositories on this instance that the current user can
+// access.
+func submoduleResolver(db dbutil.DB) search.SubmoduleResolver {
+	return func(ctx context.Context, repo types.RepoName, submoduleURL string) (*types.RepoName, error) {
+		parent, err := database.Repos(db).Get(ctx, repo.ID)
+		if err != nil {
+			return nil, err
+		}
+		submoduleRepo, err := cloneurls.SubmoduleRepo(ctx, db, parent, submoduleURL)
+		if err != nil || submoduleRepo == nil {
+			return nil, err
+		}
+		return &types.RepoName{ID: submoduleRepo.ID, Name: submoduleRepo.Name}, nil
+	}
+}
@@ -330,7 +330,7 @@ func prometheusGraphQLRequestName(requestName string) string {
 	return "other"
 }
 
-func NewSchema(db dbutil.DB, batchChanges BatchChangesResolver, codeIntel CodeIntelResolver, insights InsightsResolver, authz AuthzResolver, codeMonitors CodeMonitorsResolver, license LicenseResolver, dotcom DotcomRootResolver, searchContexts SearchContextsResolver) (*graphql.Schema, error) {
+func NewSchema(db dbutil.DB, batchChanges BatchChangesResolver, codeIntel CodeIntelResolver, insights InsightsResolver, authz AuthzResolver, codeMonitors CodeMonitorsResolver, license LicenseResolver, dotcom DotcomRootResolver, searchContexts SearchContextsResolver, searchJobs SearchJobsResolver) (*graphql.Schema, error) {
 	resolver := newSchemaResolver(db)
 	schemas := []string{mainSchema}
 
@@ -403,6 +403,16 @@ func NewSchema(db dbutil.DB, batchChanges BatchChangesResolver, codeIntel CodeIn
 		}
 	}
 
+	if searchJobs != nil {
+		EnterpriseResolvers.searchJobsResolver = searchJobs
+		resolver.SearchJobsResolver = searchJobs
+		schemas = append(schemas, searchJobsSchema)
+		// Register NodeByID handlers.
+		for kind, res := range searchJobs.NodeResolvers() {
+			resolver.nodeByIDFns[kind] = res
+		}
+	}
+
 	schemas = append(schemas, computeSchema)
 
 	return graphql.ParseSchema(
@@ -426,6 +436,7 @@ type schemaResolver struct {
 	LicenseResolver
 	DotcomRootResolver
 	SearchContextsResolver
+	SearchJobsResolver
 
 	db                dbutil.DB
 	repoupdaterClient *repoupdater.Client
@@ -496,6 +507,7 @@ var EnterpriseResolvers = struct {
 	licenseResolver        LicenseResolver
 	dotcomResolver         DotcomRootResolver
 	searchContextsResolver SearchContextsResolver
+	searchJobsResolver     SearchJobsResolver
 }{}
 
 // DEPRECATED
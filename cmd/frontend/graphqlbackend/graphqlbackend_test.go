@@ -95,7 +95,8 @@ func TestResolverTo(t *testing.T) {
 	for _, r := range resolvers {
 		typ := reflect.TypeOf(r)
 		for i := 0; i < typ.NumMethod(); i++ {
-			if name := typ.Method(i).Name; strings.HasPrefix(name, "To") {
+			// Skip methods like Topics that only happen to start with "To".
+			if name := typ.Method(i).Name; strings.HasPrefix(name, "To") && typ.Method(i).Type.NumIn() == 1 {
 				reflect.ValueOf(r).MethodByName(name).Call(nil)
 			}
 		}
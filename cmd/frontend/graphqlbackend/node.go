@@ -196,6 +196,11 @@ func (r *NodeResolver) ToSearchContext() (SearchContextResolver, bool) {
 	return n, ok
 }
 
+func (r *NodeResolver) ToSearchJob() (SearchJobResolver, bool) {
+	n, ok := r.Node.(SearchJobResolver)
+	return n, ok
+}
+
 func (r *NodeResolver) ToSite() (*siteResolver, bool) {
 	n, ok := r.Node.(*siteResolver)
 	return n, ok
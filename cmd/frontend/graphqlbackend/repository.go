@@ -290,6 +290,9 @@ func (r *RepositoryResolver) ToFileMatch() (*FileMatchResolver, bool)   { return
 func (r *RepositoryResolver) ToCommitSearchResult() (*CommitSearchResultResolver, bool) {
 	return nil, false
 }
+func (r *RepositoryResolver) ToCodeOwnerSearchResult() (*codeOwnerSearchResultResolver, bool) {
+	return nil, false
+}
 
 func (r *RepositoryResolver) ResultCount() int32 {
 	return 1
@@ -353,6 +356,14 @@ func (r *RepositoryResolver) PreviewGitObjectFilter(ctx context.Context, args *P
 	return EnterpriseResolvers.codeIntelResolver.PreviewGitObjectFilter(ctx, r.ID(), args)
 }
 
+func (r *RepositoryResolver) PackageDependents(ctx context.Context, args *RepositoryPackageDependencyQueryArgs) (PackageDependencyConnectionResolver, error) {
+	return EnterpriseResolvers.codeIntelResolver.RepositoryPackageDependents(ctx, r.ID(), args)
+}
+
+func (r *RepositoryResolver) PackageDependencies(ctx context.Context, args *RepositoryPackageDependencyQueryArgs) (PackageDependencyConnectionResolver, error) {
+	return EnterpriseResolvers.codeIntelResolver.RepositoryPackageDependencies(ctx, r.ID(), args)
+}
+
 type AuthorizedUserArgs struct {
 	RepositoryID graphql.ID
 	Permission   string
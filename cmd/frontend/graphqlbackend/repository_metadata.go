@@ -0,0 +1,111 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
+)
+
+func (r *RepositoryResolver) Topics(ctx context.Context) ([]string, error) {
+	repo, err := r.repo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var topics []string
+	switch m := repo.Metadata.(type) {
+	case *github.Repository:
+		topics = m.Topics
+	case *gitlab.Project:
+		topics = m.Topics
+	}
+	if topics == nil {
+		topics = []string{}
+	}
+	return topics, nil
+}
+
+func (r *RepositoryResolver) KeyValuePairs(ctx context.Context) ([]*keyValuePairResolver, error) {
+	kvps, err := database.RepoKVPs(r.db).List(ctx, r.IDInt32())
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*keyValuePairResolver, 0, len(kvps))
+	for _, kvp := range kvps {
+		resolvers = append(resolvers, &keyValuePairResolver{kvp: kvp})
+	}
+	return resolvers, nil
+}
+
+type keyValuePairResolver struct {
+	kvp database.KeyValuePair
+}
+
+func (r *keyValuePairResolver) Key() string    { return r.kvp.Key }
+func (r *keyValuePairResolver) Value() *string { return r.kvp.Value }
+
+type repoKeyValuePairArgs struct {
+	Repo  graphql.ID
+	Key   string
+	Value *string
+}
+
+func (r *schemaResolver) AddRepoKeyValuePair(ctx context.Context, args *repoKeyValuePairArgs) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may change the metadata of repositories.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	repoID, err := UnmarshalRepositoryID(args.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.RepoKVPs(r.db).Create(ctx, repoID, database.KeyValuePair{Key: args.Key, Value: args.Value}); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (r *schemaResolver) UpdateRepoKeyValuePair(ctx context.Context, args *repoKeyValuePairArgs) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may change the metadata of repositories.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	repoID, err := UnmarshalRepositoryID(args.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.RepoKVPs(r.db).Update(ctx, repoID, database.KeyValuePair{Key: args.Key, Value: args.Value}); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (r *schemaResolver) DeleteRepoKeyValuePair(ctx context.Context, args *struct {
+	Repo graphql.ID
+	Key  string
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may change the metadata of repositories.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	repoID, err := UnmarshalRepositoryID(args.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.RepoKVPs(r.db).Delete(ctx, repoID, args.Key); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
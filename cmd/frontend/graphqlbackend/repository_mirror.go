@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"database/sql"
 	"net/url"
 	"strings"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
@@ -142,6 +144,21 @@ func (r *repositoryMirrorInfoResolver) UpdatedAt(ctx context.Context) (*DateTime
 	return DateTimeOrNil(info.LastFetched), nil
 }
 
+func (r *repositoryMirrorInfoResolver) ByteSize(ctx context.Context) (BigInt, error) {
+	repo, err := database.GitserverRepos(r.db).GetByID(ctx, r.repository.IDInt32())
+	if errors.Is(err, sql.ErrNoRows) {
+		return BigInt{}, nil
+	}
+	if err != nil {
+		return BigInt{}, err
+	}
+	// The size of a repository that was removed from disk is stale.
+	if repo.CloneStatus != types.CloneStatusCloned {
+		return BigInt{}, nil
+	}
+	return BigInt{Int: repo.RepoSizeBytes}, nil
+}
+
 func (r *repositoryMirrorInfoResolver) UpdateSchedule(ctx context.Context) (*updateScheduleResolver, error) {
 	info, err := r.repoUpdateSchedulerInfo(ctx)
 	if err != nil {
@@ -43,3 +43,7 @@ var computeSchema string
 // searchContextsSchema is the Search Contexts raw graqhql schema.
 //go:embed search_contexts.graphql
 var searchContextsSchema string
+
+// searchJobsSchema is the Search Jobs raw graphql schema.
+//go:embed search_jobs.graphql
+var searchJobsSchema string
@@ -17,6 +17,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"github.com/sourcegraph/sourcegraph/internal/search/run"
+	"github.com/sourcegraph/sourcegraph/internal/search/searchcontexts"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
 	"github.com/sourcegraph/sourcegraph/internal/search/unindexed"
 	zoektutil "github.com/sourcegraph/sourcegraph/internal/search/zoekt"
@@ -44,6 +45,10 @@ type SearchArgs struct {
 	// to make it visible in the browser.
 	Stream streaming.Sender
 
+	// DefaultLimit, if non-zero, overrides the result limit of queries that
+	// don't specify count:.
+	DefaultLimit int
+
 	// For tests
 	Settings *schema.Settings
 }
@@ -95,11 +100,15 @@ func NewSearchImplementer(ctx context.Context, db dbutil.DB, args *SearchArgs) (
 		return alertForQuery(args.Query, err).wrapSearchImplementer(db), nil
 	}
 	tr.LazyPrintf("parsing done")
+	plan = searchcontexts.ApplySearchContextQueries(ctx, db, plan)
 
 	defaultLimit := defaultMaxSearchResults
 	if args.Stream != nil {
 		defaultLimit = defaultMaxSearchResultsStreaming
 	}
+	if args.DefaultLimit != 0 {
+		defaultLimit = args.DefaultLimit
+	}
 	if searchType == query.SearchTypeStructural {
 		// Set a lower max result count until structural search supports true streaming.
 		defaultLimit = defaultMaxSearchResults
@@ -23,6 +23,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/gitdomain"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/commit"
+	"github.com/sourcegraph/sourcegraph/internal/search/cost"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"github.com/sourcegraph/sourcegraph/internal/search/run"
@@ -578,9 +579,24 @@ func errorToAlert(err error) (*searchAlert, error) {
 		}
 	}
 
+	{
+		var e *cost.OverBudgetError
+		if errors.As(err, &e) {
+			return alertForOverSearchBudget(e), nil
+		}
+	}
+
 	return nil, err
 }
 
+func alertForOverSearchBudget(e *cost.OverBudgetError) *searchAlert {
+	return &searchAlert{
+		prometheusType: "over_search_cost_budget",
+		title:          "Search cost budget exceeded",
+		description:    fmt.Sprintf("This search has an estimated cost of %d, which exceeds the remaining %s search cost budget (%d per window). Try again in %s, or narrow down the repositories searched with the \"repo:\" filter. Site admins can raise the budget with the \"search.costBudgets\" site configuration.", e.Cost, e.Scope, e.Budget, e.RetryAfter.Round(time.Second)),
+	}
+}
+
 func maxAlertByPriority(a, b *searchAlert) *searchAlert {
 	if a == nil {
 		return b
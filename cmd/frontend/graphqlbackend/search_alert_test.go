@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/google/go-cmp/cmp"
@@ -16,6 +17,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/commit"
+	"github.com/sourcegraph/sourcegraph/internal/search/cost"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"github.com/sourcegraph/sourcegraph/internal/search/run"
@@ -191,6 +193,23 @@ func TestAlertForDiffCommitSearchLimits(t *testing.T) {
 	}
 }
 
+func TestErrorToAlertOverSearchBudget(t *testing.T) {
+	err := multierror.Append(&multierror.Error{}, &cost.OverBudgetError{
+		Scope:      "access token",
+		Cost:       500,
+		Budget:     1000,
+		RetryAfter: 90 * time.Second,
+	})
+	alert, _ := errorToAlert(err)
+	if alert == nil {
+		t.Fatal("expected alert")
+	}
+	want := `This search has an estimated cost of 500, which exceeds the remaining access token search cost budget (1000 per window). Try again in 1m30s, or narrow down the repositories searched with the "repo:" filter. Site admins can raise the budget with the "search.costBudgets" site configuration.`
+	if diff := cmp.Diff(want, alert.description); diff != "" {
+		t.Fatalf("mismatched alert (-want, +got):\n%s", diff)
+	}
+}
+
 func TestErrorToAlertStructuralSearch(t *testing.T) {
 	cases := []struct {
 		name           string
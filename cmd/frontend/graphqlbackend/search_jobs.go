@@ -0,0 +1,56 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+)
+
+type SearchJobsResolver interface {
+	// Query
+	SearchJobs(ctx context.Context, args *ListSearchJobsArgs) (SearchJobConnectionResolver, error)
+
+	// Mutations
+	CreateSearchJob(ctx context.Context, args *CreateSearchJobArgs) (SearchJobResolver, error)
+
+	NodeResolvers() map[string]NodeByIDFunc
+}
+
+type ListSearchJobsArgs struct {
+	First int32
+	After *string
+}
+
+type CreateSearchJobArgs struct {
+	Query string
+}
+
+type SearchJobConnectionResolver interface {
+	Nodes(ctx context.Context) ([]SearchJobResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type SearchJobResolver interface {
+	ID() graphql.ID
+	Query() string
+	State(ctx context.Context) (string, error)
+	Creator(ctx context.Context) (*UserResolver, error)
+	CreatedAt() DateTime
+	StartedAt() *DateTime
+	FinishedAt() *DateTime
+	FailureMessage() *string
+	RepoStats(ctx context.Context) (SearchJobRepoStatsResolver, error)
+	CSVURL() string
+	JSONLinesURL() string
+}
+
+type SearchJobRepoStatsResolver interface {
+	Total() int32
+	Completed() int32
+	Failed() int32
+	InProgress() int32
+	MatchCount() int32
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	searchlogs "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/search/logs"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
@@ -35,7 +36,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/commit"
+	"github.com/sourcegraph/sourcegraph/internal/search/cost"
+	"github.com/sourcegraph/sourcegraph/internal/search/dependencies"
 	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/search/ownership"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
@@ -166,6 +170,11 @@ func matchesToResolvers(db dbutil.DB, matches []result.Match) []SearchResultReso
 				db:          db,
 				CommitMatch: *v,
 			})
+		case *result.OwnerMatch:
+			resolvers = append(resolvers, &codeOwnerSearchResultResolver{
+				OwnerMatch:   *v,
+				RepoResolver: getRepoResolver(v.Repo, ""),
+			})
 		}
 	}
 	return resolvers
@@ -324,8 +333,8 @@ loop:
 	for _, r := range sr.Matches {
 		r := r // shadow so it doesn't change in the goroutine
 		switch m := r.(type) {
-		case *result.RepoMatch:
-			// We don't care about repo results here.
+		case *result.RepoMatch, *result.OwnerMatch:
+			// We don't care about repo or owner results here.
 			continue
 		case *result.CommitMatch:
 			// Diff searches are cheap, because we implicitly have author date info.
@@ -503,6 +512,17 @@ func (r *searchResolver) toRepoOptions(q query.Q, opts resolveRepositoriesOpts)
 	commitAfter, _ := q.StringValue(query.FieldRepoHasCommitAfter)
 	searchContextSpec, _ := q.StringValue(query.FieldContext)
 
+	var hasTopics []string
+	var hasKVPs []query.RepoHasKVPPredicate
+	query.VisitPredicate(q, query.FieldRepo, func(pred query.Predicate) {
+		switch p := pred.(type) {
+		case *query.RepoHasTopicPredicate:
+			hasTopics = append(hasTopics, p.Topic)
+		case *query.RepoHasKVPPredicate:
+			hasKVPs = append(hasKVPs, *p)
+		}
+	})
+
 	var CacheLookup bool
 	if len(opts.effectiveRepoFieldValues) == 0 && opts.limit == 0 {
 		// indicates resolving repositories should cache DB lookups
@@ -521,6 +541,8 @@ func (r *searchResolver) toRepoOptions(q query.Q, opts resolveRepositoriesOpts)
 		NoArchived:        archived == query.No,
 		Visibility:        visibility,
 		CommitAfter:       commitAfter,
+		HasTopics:         hasTopics,
+		HasKVPs:           hasKVPs,
 		Query:             q,
 		Ranked:            true,
 		Limit:             opts.limit,
@@ -557,7 +579,7 @@ func withMode(args search.TextParameters, st query.SearchType) search.TextParame
 
 	hasGlobalSearchResultType := args.ResultTypes.Has(result.TypeFile | result.TypePath | result.TypeSymbol)
 	isIndexedSearch := args.PatternInfo.Index != query.No
-	isEmpty := args.PatternInfo.Pattern == "" && args.PatternInfo.ExcludePattern == "" && len(args.PatternInfo.IncludePatterns) == 0
+	isEmpty := args.PatternInfo.Pattern == "" && args.PatternInfo.PatternExpression == nil && args.PatternInfo.ExcludePattern == "" && len(args.PatternInfo.IncludePatterns) == 0
 	if isGlobalSearch() && isIndexedSearch && hasGlobalSearchResultType && !isEmpty {
 		args.Mode = search.ZoektGlobalSearch
 	}
@@ -621,6 +643,12 @@ func (r *searchResolver) toSearchInputs(q query.Q) (*search.TextParameters, []ru
 	args = withResultTypes(args, forceResultTypes)
 	args = withMode(args, r.PatternType)
 
+	if p.PatternExpression != nil && args.ResultTypes.Without(patternExpressionResultTypes) != 0 {
+		// Pattern expressions are evaluated per file and per repository
+		// name by the backends. Other backends would ignore the pattern.
+		return nil, nil, errors.Errorf("and/or pattern expressions are not supported for type:%s", args.ResultTypes.Without(patternExpressionResultTypes))
+	}
+
 	var jobs []run.Job
 	{
 		// This code block creates search jobs under specific
@@ -629,13 +657,16 @@ func (r *searchResolver) toSearchInputs(q query.Q) (*search.TextParameters, []ru
 		// of the above logic should be used to create search jobs
 		// across all of Sourcegraph.
 		if r.PatternType == query.SearchTypeStructural && p.Pattern != "" {
+			_, minusRepoFilters := q.Repositories()
 			jobs = append(jobs, &unindexed.StructuralSearch{
 				RepoFetcher: unindexed.NewRepoFetcher(r.stream, &args),
 				Mode:        args.Mode,
 				SearcherArgs: search.SearcherParameters{
-					SearcherURLs:    args.SearcherURLs,
-					PatternInfo:     args.PatternInfo,
-					UseFullDeadline: args.UseFullDeadline,
+					SearcherURLs:     args.SearcherURLs,
+					PatternInfo:      args.PatternInfo,
+					UseFullDeadline:  args.UseFullDeadline,
+					ResolveSubmodule: submoduleResolver(r.db),
+					MinusRepoFilters: minusRepoFilters,
 				},
 			})
 		}
@@ -832,6 +863,29 @@ func (r *searchResolver) invalidateCache() {
 	}
 }
 
+// patternExpressionResultTypes are the result types whose backends evaluate
+// and/or pattern expressions natively.
+const patternExpressionResultTypes = result.TypeFile | result.TypePath | result.TypeRepo
+
+// isNativePatternExpression returns true if the and/or pattern expression of q
+// can be evaluated per file and per repository name by the search backends in
+// a single search, rather than by searching for each operand and intersecting
+// or merging the results. This is the case for text searches that only return
+// file content, path and repository results.
+func isNativePatternExpression(q query.Basic) bool {
+	if _, ok := search.ToPatternExpression(q); !ok {
+		return false
+	}
+
+	native := true
+	q.VisitParameter(query.FieldType, func(value string, negated bool, _ query.Annotation) {
+		if rt := result.TypeFromString[value]; negated || !patternExpressionResultTypes.Has(rt) {
+			native = false
+		}
+	})
+	return native
+}
+
 // evaluatePatternExpression evaluates a search pattern containing and/or expressions.
 func (r *searchResolver) evaluatePatternExpression(ctx context.Context, q query.Basic) (*SearchResults, error) {
 	switch term := q.Pattern.(type) {
@@ -841,9 +895,18 @@ func (r *searchResolver) evaluatePatternExpression(ctx context.Context, q query.
 		}
 
 		switch term.Kind {
-		case query.And:
-			return r.evaluateAnd(ctx, q)
-		case query.Or:
+		case query.And, query.Or:
+			if isNativePatternExpression(q) {
+				r.invalidateCache()
+				args, jobs, err := r.toSearchInputs(q.ToParseTree())
+				if err != nil {
+					return &SearchResults{}, err
+				}
+				return r.evaluateLeaf(ctx, args, jobs)
+			}
+			if term.Kind == query.And {
+				return r.evaluateAnd(ctx, q)
+			}
 			return r.evaluateOr(ctx, q)
 		case query.Concat:
 			r.invalidateCache()
@@ -1063,7 +1126,19 @@ func (r *searchResolver) resultsRecursive(ctx context.Context, plan query.Plan)
 			if err != nil {
 				return nil, err
 			}
-			return r.resultsRecursive(ctx, plan)
+			srr, err := r.resultsRecursive(ctx, plan)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := pred.(*query.RepoDependenciesPredicate); ok && srr != nil {
+				// The plan finds the dependency files of the repos, which
+				// we map to the repos of their dependencies.
+				srr.Matches, err = dependencies.Resolve(ctx, r.db, srr.Matches)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return srr, nil
 		})
 		if errors.Is(err, ErrPredicateNoResults) {
 			continue
@@ -1077,13 +1152,20 @@ func (r *searchResolver) resultsRecursive(ctx context.Context, plan query.Plan)
 			return r.resultsRecursive(ctx, predicatePlan)
 		}
 
-		newResult, err := r.evaluate(ctx, q)
+		q, ownerFilter, err := ownership.FromQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		newResult, err := r.evaluateWithOwnership(ctx, q, ownerFilter)
 		if err != nil {
 			// Fail if any subexpression fails.
 			return nil, err
 		}
 
 		if newResult != nil {
+			if ownerFilter != nil {
+				newResult.Matches = ownerFilter.Apply(ctx, newResult.Matches)
+			}
 			newResult.Matches = result.Select(newResult.Matches, q)
 			sr = union(sr, newResult)
 			if len(sr.Matches) > wantCount {
@@ -1099,6 +1181,17 @@ func (r *searchResolver) resultsRecursive(ctx context.Context, plan query.Plan)
 	return sr, err
 }
 
+// evaluateWithOwnership evaluates q, filtering the streamed results by the
+// code owners of their files if ownerFilter is non-nil.
+func (r *searchResolver) evaluateWithOwnership(ctx context.Context, q query.Basic, ownerFilter *ownership.Filter) (*SearchResults, error) {
+	if ownerFilter != nil && r.stream != nil {
+		orig := r.stream
+		r.stream = ownerFilter.Stream(ctx, orig)
+		defer func() { r.stream = orig }()
+	}
+	return r.evaluate(ctx, q)
+}
+
 // searchResultsToRepoNodes converts a set of search results into repository nodes
 // such that they can be used to replace a repository predicate
 func searchResultsToRepoNodes(matches []result.Match) ([]query.Node, error) {
@@ -1197,6 +1290,14 @@ func substitutePredicates(q query.Basic, evaluate func(query.Predicate) (*Search
 		name, params := query.ParseAsPredicate(value)
 		predicate := query.DefaultPredicateRegistry.Get(field, name)
 		predicate.ParseParams(params)
+		if query.IsRepoMetadataPredicate(predicate) {
+			// Resolved together with the other repo: filters.
+			return orig
+		}
+		if _, ok := predicate.(*query.FileHasOwnerPredicate); ok {
+			// Resolved while filtering the results, see package ownership.
+			return orig
+		}
 		srr, err := evaluate(predicate)
 		if err != nil {
 			topErr = err
@@ -1482,9 +1583,13 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 
 	args.RepoOptions = r.toRepoOptions(args.Query, resolveRepositoriesOpts{})
 
-	// performance optimization: call zoekt early, resolve repos concurrently, filter
-	// search results with resolved repos.
-	if args.Mode == search.ZoektGlobalSearch {
+	// The search cost is estimated from the resolved repositories, so searches
+	// charged to a budget resolve repositories and are charged before any
+	// backend is started. Otherwise an over budget search could still send
+	// matches.
+	charged := r.chargesSearchCost(ctx)
+
+	startGlobalSearch := func() {
 		argsIndexed := *args
 
 		userID := int32(0)
@@ -1529,9 +1634,11 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 				}
 
 				searcherArgs := &search.SearcherParameters{
-					SearcherURLs:    argsIndexed.SearcherURLs,
-					PatternInfo:     argsIndexed.PatternInfo,
-					UseFullDeadline: argsIndexed.UseFullDeadline,
+					SearcherURLs:     argsIndexed.SearcherURLs,
+					PatternInfo:      argsIndexed.PatternInfo,
+					UseFullDeadline:  argsIndexed.UseFullDeadline,
+					ResolveSubmodule: submoduleResolver(r.db),
+					MinusRepoFilters: argsIndexed.RepoOptions.MinusRepoFilters,
 				}
 
 				notSearcherOnly := argsIndexed.Mode != search.SearcherOnly
@@ -1558,6 +1665,12 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 		}
 	}
 
+	// performance optimization: call zoekt early, resolve repos concurrently, filter
+	// search results with resolved repos.
+	if args.Mode == search.ZoektGlobalSearch && !charged {
+		startGlobalSearch()
+	}
+
 	resolved, err := r.resolveRepositories(ctx, args.RepoOptions)
 	if err != nil {
 		if alert, err := errorToAlert(err); alert != nil {
@@ -1579,6 +1692,18 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 		return r.alertForNoResolvedRepos(ctx, args.Query).wrapResults(), nil
 	}
 
+	if charged {
+		if err := r.chargeSearchCost(ctx, args); err != nil {
+			if alert, err := errorToAlert(err); alert != nil {
+				return alert.wrapResults(), err
+			}
+			return nil, err
+		}
+		if args.Mode == search.ZoektGlobalSearch {
+			startGlobalSearch()
+		}
+	}
+
 	if len(resolved.MissingRepoRevs) > 0 {
 		agg.Error(&missingRepoRevsError{Missing: resolved.MissingRepoRevs})
 		tr.LazyPrintf("adding error for missing repo revs - done")
@@ -1631,9 +1756,11 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 				}
 
 				searcherArgs := &search.SearcherParameters{
-					SearcherURLs:    args.SearcherURLs,
-					PatternInfo:     args.PatternInfo,
-					UseFullDeadline: args.UseFullDeadline,
+					SearcherURLs:     args.SearcherURLs,
+					PatternInfo:      args.PatternInfo,
+					UseFullDeadline:  args.UseFullDeadline,
+					ResolveSubmodule: submoduleResolver(r.db),
+					MinusRepoFilters: args.RepoOptions.MinusRepoFilters,
 				}
 
 				notSearcherOnly := args.Mode != search.SearcherOnly
@@ -1729,6 +1856,22 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 	return r.toSearchResults(ctx, agg)
 }
 
+// chargesSearchCost returns true if the search is charged to the search cost
+// budgets of the current user. Site admins are not charged.
+func (r *searchResolver) chargesSearchCost(ctx context.Context) bool {
+	return cost.Charged(ctx) && backend.CheckCurrentUserIsSiteAdmin(ctx, r.db) != nil
+}
+
+// chargeSearchCost charges the estimated cost of searching args.Repos to the
+// search cost budgets of the current user.
+func (r *searchResolver) chargeSearchCost(ctx context.Context, args *search.TextParameters) error {
+	b, err := query.ToBasicQuery(args.Query)
+	if err != nil {
+		return err
+	}
+	return cost.Charge(ctx, cost.Estimate(query.Plan{b}, len(args.Repos)))
+}
+
 // toSearchResults converts an Aggregator to SearchResults.
 //
 // toSearchResults relies on all WaitGroups being done since it relies on
@@ -1777,6 +1920,7 @@ type SearchResultResolver interface {
 	ToRepository() (*RepositoryResolver, bool)
 	ToFileMatch() (*FileMatchResolver, bool)
 	ToCommitSearchResult() (*CommitSearchResultResolver, bool)
+	ToCodeOwnerSearchResult() (*codeOwnerSearchResultResolver, bool)
 
 	ResultCount() int32
 }
@@ -1821,6 +1965,8 @@ func compareSearchResults(left, right result.Match, exactFilePatterns map[string
 			return string(r.Name), "", nil
 		case *result.FileMatch:
 			return string(r.Repo.Name), r.Path, nil
+		case *result.OwnerMatch:
+			return string(r.Repo.Name), r.Owner, nil
 		case *result.CommitMatch:
 			// Commits are relatively sorted by date, and after repo
 			// or path names. We use ~ as the key for repo and
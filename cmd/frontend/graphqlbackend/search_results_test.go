@@ -13,10 +13,12 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/zoekt"
 	"go.uber.org/atomic"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
@@ -25,6 +27,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	searchbackend "github.com/sourcegraph/sourcegraph/internal/search/backend"
+	"github.com/sourcegraph/sourcegraph/internal/search/cost"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
@@ -875,10 +878,19 @@ func TestEvaluateAnd(t *testing.T) {
 			wantAlert:    false,
 		},
 		{
+			// The expression is evaluated by zoekt in a single search, so
+			// there is no intersection that could have missed results.
 			name:         "zoekt does not return enough matches, not exhausted",
 			query:        "foo and bar index:only count:50",
 			zoektMatches: 10,
 			filesSkipped: 1,
+			wantAlert:    false,
+		},
+		{
+			name:         "expression with unsupported result type, not exhausted",
+			query:        "foo and bar index:only count:50 type:symbol",
+			zoektMatches: 10,
+			filesSkipped: 1,
 			wantAlert:    true,
 		},
 		{
@@ -944,6 +956,78 @@ func TestEvaluateAnd(t *testing.T) {
 	}
 }
 
+func TestSearchResultsOverSearchCostBudget(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		SearchCostBudgets: &schema.SearchCostBudgets{PerUser: 1},
+	}})
+	defer conf.Mock(nil)
+
+	var charged int64
+	cost.MockCharge = func(_ context.Context, c int64) error {
+		charged = c
+		return &cost.OverBudgetError{Scope: "user", Cost: c, Budget: 1, RetryAfter: time.Minute}
+	}
+	defer func() { cost.MockCharge = nil }()
+
+	minimalRepos, zoektRepos := generateRepos(5)
+	database.Mocks.Repos.ListRepoNames = func(_ context.Context, op database.ReposListOptions) ([]types.RepoName, error) {
+		return minimalRepos, nil
+	}
+	database.Mocks.Repos.Count = mockCount
+	database.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	defer func() { database.Mocks = database.MockStores{} }()
+
+	z := &searchbackend.FakeSearcher{
+		Repos:  zoektRepos,
+		Result: &zoekt.SearchResult{Files: generateZoektMatches(5)},
+	}
+
+	// A global search starts zoekt before resolving repositories, unless it
+	// is charged to a budget.
+	p, err := query.Pipeline(query.InitLiteral("foo index:only"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var sent []result.Match
+	resolver := &searchResolver{
+		db: db,
+		SearchInputs: &run.SearchInputs{
+			Plan:         p,
+			Query:        p.ToParseTree(),
+			UserSettings: &schema.Settings{},
+		},
+		zoekt:    z,
+		reposMu:  &sync.Mutex{},
+		resolved: &searchrepos.Resolved{},
+		stream: streaming.StreamFunc(func(e streaming.SearchEvent) {
+			mu.Lock()
+			sent = append(sent, e.Results...)
+			mu.Unlock()
+		}),
+	}
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	results, err := resolver.Results(ctx)
+	if err != nil {
+		t.Fatal("Results:", err)
+	}
+	if charged == 0 {
+		t.Fatal("expected search to be charged")
+	}
+	if results.SearchResults.Alert == nil || results.SearchResults.Alert.prometheusType != "over_search_cost_budget" {
+		t.Fatalf("expected over budget alert, got %+v", results.SearchResults.Alert)
+	}
+	if len(sent) != 0 || results.MatchCount() != 0 {
+		t.Fatalf("expected no matches, got %d sent and %d in results", len(sent), results.MatchCount())
+	}
+}
+
 func TestSearchContext(t *testing.T) {
 	orig := envvar.SourcegraphDotComMode()
 	envvar.MockSourcegraphDotComMode(true)
@@ -1060,6 +1144,53 @@ func TestIsGlobalSearch(t *testing.T) {
 
 }
 
+func TestPatternExpressionResultTypes(t *testing.T) {
+	tts := []struct {
+		searchQuery string
+		wantTypes   result.Types
+		wantErr     bool
+	}{
+		{searchQuery: "foo and bar", wantTypes: result.TypeFile | result.TypePath | result.TypeRepo},
+		{searchQuery: "type:repo foo and bar", wantTypes: result.TypeRepo},
+		{searchQuery: "type:file foo or bar", wantTypes: result.TypeFile},
+		{searchQuery: "type:commit foo and bar", wantErr: true},
+	}
+
+	for _, tt := range tts {
+		t.Run(tt.searchQuery, func(t *testing.T) {
+			qinfo, err := query.ParseLiteral(tt.searchQuery)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resolver := searchResolver{
+				SearchInputs: &run.SearchInputs{
+					Query:        qinfo,
+					UserSettings: &schema.Settings{},
+					PatternType:  query.SearchTypeLiteral,
+				},
+			}
+
+			args, _, err := resolver.toSearchInputs(resolver.Query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error for unsupported result type")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if args.PatternInfo.PatternExpression == nil {
+				t.Fatal("expected pattern expression")
+			}
+			if args.ResultTypes != tt.wantTypes {
+				t.Errorf("unexpected result types. want=%s have=%s", tt.wantTypes, args.ResultTypes)
+			}
+		})
+	}
+}
+
 func TestZeroElapsedMilliseconds(t *testing.T) {
 	r := &SearchResultsResolver{}
 	if got := r.ElapsedMilliseconds(); got != 0 {
@@ -1098,3 +1229,33 @@ func TestIsContextError(t *testing.T) {
 		})
 	}
 }
+
+func TestSubstitutePredicates_RepoMetadata(t *testing.T) {
+	plan, err := query.Pipeline(query.InitRegexp(`repo:has.topic(payments) repo:has(owner:team-x) foo`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	predicatePlan, err := substitutePredicates(plan[0], func(query.Predicate) (*SearchResults, error) {
+		t.Fatal("repository metadata predicates should not be evaluated as subqueries")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if predicatePlan != nil {
+		t.Fatalf("expected no new plan, got %s", predicatePlan.ToParseTree().String())
+	}
+
+	sr := &searchResolver{SearchInputs: &run.SearchInputs{UserSettings: &schema.Settings{}}}
+	opts := sr.toRepoOptions(plan[0].ToParseTree(), resolveRepositoriesOpts{})
+	value := "team-x"
+	want := search.RepoOptions{
+		RepoFilters: nil,
+		HasTopics:   []string{"payments"},
+		HasKVPs:     []query.RepoHasKVPPredicate{{Key: "owner", Value: &value}},
+	}
+	if diff := cmp.Diff(want, opts, cmpopts.IgnoreFields(search.RepoOptions{}, "UserSettings", "NoForks", "NoArchived", "Visibility", "Query", "Ranked", "CacheLookup")); diff != "" {
+		t.Fatalf("unexpected repo options (-want +got):\n%s", diff)
+	}
+}
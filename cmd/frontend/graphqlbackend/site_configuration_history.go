@@ -0,0 +1,261 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+const defaultSiteConfigurationHistoryPageSize = 20
+
+type siteConfigurationHistoryArgs struct {
+	graphqlutil.ConnectionArgs
+	After *string
+}
+
+func (r *siteConfigurationResolver) History(ctx context.Context, args *siteConfigurationHistoryArgs) (*siteConfigurationChangeConnectionResolver, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	opt := confdb.SiteListOptions{Limit: defaultSiteConfigurationHistoryPageSize}
+	if args.First != nil {
+		opt.Limit = int(*args.First)
+	}
+	if args.After != nil {
+		beforeID, err := strconv.ParseInt(*args.After, 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cursor")
+		}
+		opt.BeforeID = int32(beforeID)
+	}
+
+	return &siteConfigurationChangeConnectionResolver{db: r.db, opt: opt}, nil
+}
+
+func (r *siteConfigurationResolver) Diff(ctx context.Context, args *struct {
+	From int32
+	To   int32
+}) (string, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return "", err
+	}
+
+	from, err := redactedSiteConfigurationRevision(ctx, args.From)
+	if err != nil {
+		return "", err
+	}
+	to, err := redactedSiteConfigurationRevision(ctx, args.To)
+	if err != nil {
+		return "", err
+	}
+
+	return siteConfigurationDiff(args.From, from, args.To, to), nil
+}
+
+// redactedSiteConfigurationRevision returns the contents of the given site configuration revision
+// with all secrets redacted.
+func redactedSiteConfigurationRevision(ctx context.Context, id int32) (string, error) {
+	config, err := confdb.SiteGetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if config == nil {
+		return "", errors.Errorf("site configuration revision %d not found", id)
+	}
+
+	return conf.RedactSecrets(config.Contents)
+}
+
+// siteConfigurationDiff returns a line-oriented diff between the given site configuration revisions.
+// Every line of both revisions is included: lines prefixed with "-" and "+" were removed and added,
+// respectively, and unchanged lines are prefixed with a space.
+func siteConfigurationDiff(fromID int32, from string, toID int32, to string) string {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- revision %d\n+++ revision %d\n", fromID, toID)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&buf, " %s\n", a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&buf, "-%s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&buf, "+%s\n", b[j])
+			j++
+		}
+	}
+
+	return buf.String()
+}
+
+type siteConfigurationChangeConnectionResolver struct {
+	db  dbutil.DB
+	opt confdb.SiteListOptions
+}
+
+func (r *siteConfigurationChangeConnectionResolver) compute(ctx context.Context) ([]*confdb.SiteConfig, error) {
+	// Request one extra revision to determine if there is a next page.
+	opt := r.opt
+	if opt.Limit > 0 {
+		opt.Limit++
+	}
+
+	return confdb.SiteList(ctx, opt)
+}
+
+func (r *siteConfigurationChangeConnectionResolver) Nodes(ctx context.Context) ([]*siteConfigurationChangeResolver, error) {
+	configs, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.Limit > 0 && len(configs) > r.opt.Limit {
+		configs = configs[:r.opt.Limit]
+	}
+
+	resolvers := make([]*siteConfigurationChangeResolver, 0, len(configs))
+	for _, config := range configs {
+		resolvers = append(resolvers, &siteConfigurationChangeResolver{db: r.db, config: config})
+	}
+	return resolvers, nil
+}
+
+func (r *siteConfigurationChangeConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := confdb.SiteCount(ctx)
+	return int32(count), err
+}
+
+func (r *siteConfigurationChangeConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	configs, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.Limit > 0 && len(configs) > r.opt.Limit {
+		return graphqlutil.NextPageCursor(strconv.Itoa(int(configs[r.opt.Limit-1].ID))), nil
+	}
+	return graphqlutil.HasNextPage(false), nil
+}
+
+type siteConfigurationChangeResolver struct {
+	db     dbutil.DB
+	config *confdb.SiteConfig
+}
+
+func (r *siteConfigurationChangeResolver) ID() int32 { return r.config.ID }
+
+func (r *siteConfigurationChangeResolver) Author(ctx context.Context) (*UserResolver, error) {
+	if r.config.AuthorUserID == 0 {
+		return nil, nil
+	}
+
+	user, err := UserByIDInt32(ctx, r.db, r.config.AuthorUserID)
+	if errcode.IsNotFound(err) {
+		// The author has since been deleted
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *siteConfigurationChangeResolver) RedactedContents() (JSONCString, error) {
+	contents, err := conf.RedactSecrets(r.config.Contents)
+	return JSONCString(contents), err
+}
+
+func (r *siteConfigurationChangeResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.config.CreatedAt}
+}
+
+func (r *schemaResolver) RollbackSiteConfiguration(ctx context.Context, args *struct {
+	LastID   int32
+	Revision int32
+}) (bool, error) {
+	// 🚨 SECURITY: Rolling back replaces the site configuration, which controls
+	// authentication and contains secrets, so only admins may do it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return false, err
+	}
+	if !canUpdateSiteConfiguration() {
+		return false, errors.New("updating site configuration not allowed when using SITE_CONFIG_FILE")
+	}
+
+	revision, err := confdb.SiteGetByID(ctx, args.Revision)
+	if err != nil {
+		return false, err
+	}
+	if revision == nil {
+		return false, errors.Errorf("site configuration revision %d not found", args.Revision)
+	}
+
+	// The revision is written as a new revision of the site configuration (rather than deleting
+	// the revisions that followed it) so that the rollback itself is preserved in the history.
+	// The write is rejected with confdb.ErrNewerEdit if args.LastID is no longer the latest
+	// revision.
+	prev := globals.ConfigurationServerFrontendOnly.Raw()
+	prev.Site = revision.Contents
+	if err := globals.ConfigurationServerFrontendOnly.WriteIfUpToDate(ctx, prev, args.LastID); err != nil {
+		if errors.Is(err, confdb.ErrNewerEdit) {
+			return false, confdb.ErrNewerEdit
+		}
+		return false, err
+	}
+
+	logSiteConfigurationRollback(ctx, r.db, args.LastID, revision.ID)
+	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
+}
+
+func logSiteConfigurationRollback(ctx context.Context, db dbutil.DB, fromRevision, toRevision int32) {
+	args, _ := json.Marshal(struct {
+		FromRevision int32 `json:"fromRevision"`
+		ToRevision   int32 `json:"toRevision"`
+	}{fromRevision, toRevision})
+
+	database.SecurityEventLogs(db).LogEvent(ctx, &database.SecurityEvent{
+		Name:      database.SecurityEventNameSiteConfigurationRolledBack,
+		UserID:    uint32(actor.FromContext(ctx).UID),
+		Argument:  args,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	})
+}
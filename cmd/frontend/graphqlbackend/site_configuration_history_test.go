@@ -0,0 +1,28 @@
+package graphqlbackend
+
+import "testing"
+
+func TestSiteConfigurationDiff(t *testing.T) {
+	from := `{
+  "externalURL": "https://a.example.com",
+  "auth.providers": [{"type": "builtin"}],
+}`
+	to := `{
+  "externalURL": "https://b.example.com",
+  "auth.providers": [{"type": "builtin"}],
+  "email.address": "admin@example.com",
+}`
+
+	want := `--- revision 1
++++ revision 2
+ {
+-  "externalURL": "https://a.example.com",
++  "externalURL": "https://b.example.com",
+   "auth.providers": [{"type": "builtin"}],
++  "email.address": "admin@example.com",
+ }
+`
+	if got := siteConfigurationDiff(1, from, 2, to); got != want {
+		t.Errorf("unexpected diff.\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
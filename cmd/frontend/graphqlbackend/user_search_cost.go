@@ -0,0 +1,32 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/search/cost"
+)
+
+func (r *UserResolver) SearchCostUsage(ctx context.Context) (*searchCostUsageResolver, error) {
+	// 🚨 SECURITY: Only the user and site admins can see how much the user
+	// searched.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.db, r.user.ID); err != nil {
+		return nil, err
+	}
+
+	usage, err := cost.GetUserUsage(r.user.ID)
+	if err != nil || usage == nil {
+		return nil, err
+	}
+	return &searchCostUsageResolver{usage: usage}, nil
+}
+
+type searchCostUsageResolver struct {
+	usage *cost.UserUsage
+}
+
+func (r *searchCostUsageResolver) Spent() int32 { return int32(r.usage.Spent) }
+
+func (r *searchCostUsageResolver) Budget() int32 { return int32(r.usage.Budget) }
+
+func (r *searchCostUsageResolver) WindowEndsAt() DateTime { return DateTime{Time: r.usage.WindowEnd} }
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gorilla/mux"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/handlerutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// Examples:
+//
+// Stream the blame of a file:
+//     curl http://localhost:3080/github.com/gorilla/mux/-/stream/blame/mux.go
+//
+// Stream the blame of lines 10 to 20 of a file:
+//     curl 'http://localhost:3080/github.com/gorilla/mux/-/stream/blame/mux.go?startLine=10&endLine=20'
+//
+// The response is a stream of server-sent events. Each "hunk" event describes
+// the lines of the file last changed by a commit, and hunks are not sent in
+// line order. The stream ends with a "done" event. Errors that occur after the
+// stream has started are sent as an "error" event.
+
+// blameHunk is the data of a "hunk" event.
+type blameHunk struct {
+	StartLine int
+	EndLine   int
+	StartByte int
+	EndByte   int
+	Commit    string
+	Author    blameAuthor
+	Message   string
+	Filename  string
+}
+
+type blameAuthor struct {
+	Name  string
+	Email string
+	Date  time.Time
+}
+
+func serveBlameStream(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	var opt git.BlameOptions
+	for name, v := range map[string]*int{"startLine": &opt.StartLine, "endLine": &opt.EndLine} {
+		if s := r.URL.Query().Get(name); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				serveError(w, r, errors.Errorf("invalid %s: %s", name, s), http.StatusBadRequest)
+				return nil
+			}
+			*v = n
+		}
+	}
+
+	repo, commitID, err := handlerutil.GetRepoAndRev(ctx, mux.Vars(r))
+	if err != nil {
+		var urlMovedError *handlerutil.URLMovedError
+		if errors.As(err, &urlMovedError) {
+			return handlerutil.RedirectToNewRepoName(w, r, urlMovedError.NewRepo)
+		}
+		if status := errcode.HTTP(err); status != http.StatusInternalServerError {
+			serveError(w, r, err, status)
+			return nil
+		}
+		return err
+	}
+	opt.NewestCommit = commitID
+	path := strings.TrimPrefix(mux.Vars(r)["Path"], "/")
+
+	eventWriter, err := streamhttp.NewWriter(w)
+	if err != nil {
+		return err
+	}
+
+	// Always send a final done event so clients know the stream is shutting
+	// down.
+	defer eventWriter.Event("done", map[string]interface{}{})
+
+	err = git.StreamBlameFile(ctx, repo.Name, path, &opt, func(h *git.Hunk) error {
+		return eventWriter.Event("hunk", blameHunk{
+			StartLine: h.StartLine,
+			EndLine:   h.EndLine,
+			StartByte: h.StartByte,
+			EndByte:   h.EndByte,
+			Commit:    string(h.CommitID),
+			Author: blameAuthor{
+				Name:  h.Author.Name,
+				Email: h.Author.Email,
+				Date:  h.Author.Date,
+			},
+			Message:  h.Message,
+			Filename: h.Filename,
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		// The stream has started, so the error can only be sent as an event.
+		_ = eventWriter.Event("error", streamhttp.EventError{Message: err.Error()})
+	}
+	return nil
+}
@@ -54,6 +54,7 @@ const (
 	routeTree                    = "tree"
 	routeBlob                    = "blob"
 	routeRaw                     = "raw"
+	routeBlameStream             = "blame.stream"
 	routeOrganizations           = "org"
 	routeSettings                = "settings"
 	routeSiteAdmin               = "site-admin"
@@ -192,6 +193,9 @@ func newRouter() *mux.Router {
 	// raw
 	repoRev.Path("/raw{Path:.*}").Methods("GET", "HEAD").Name(routeRaw)
 
+	// streaming blame
+	repoRev.Path("/stream/blame{Path:.*}").Methods("GET").Name(routeBlameStream)
+
 	repo := r.PathPrefix(repoRevPath + "/" + routevar.RepoPathDelim).Subrouter()
 	repo.PathPrefix("/settings").Methods("GET").Name(routeRepoSettings)
 	repo.PathPrefix("/code-intelligence").Methods("GET").Name(routeRepoCodeIntelligence)
@@ -341,6 +345,9 @@ func initRouter(db dbutil.DB, router *mux.Router, codeIntelResolver graphqlbacke
 	// raw
 	router.Get(routeRaw).Handler(handler(serveRaw))
 
+	// streaming blame
+	router.Get(routeBlameStream).Handler(handler(serveBlameStream))
+
 	// All other routes that are not found.
 	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		serveError(w, r, errors.New("route not found"), http.StatusNotFound)
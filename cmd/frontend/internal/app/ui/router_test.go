@@ -123,6 +123,13 @@ func TestRouter(t *testing.T) {
 			wantVars:  map[string]string{"Repo": "r", "Rev": "@v", "Path": "/d/f"},
 		},
 
+		// streaming blame
+		{
+			path:      "/r@v/-/stream/blame/d/f",
+			wantRoute: routeBlameStream,
+			wantVars:  map[string]string{"Repo": "r", "Rev": "@v", "Path": "/d/f"},
+		},
+
 		// about.sourcegraph.com redirects
 		{
 			path:      "/about",
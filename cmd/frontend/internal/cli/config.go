@@ -23,6 +23,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
@@ -411,7 +412,11 @@ func (c configurationSource) Write(ctx context.Context, input conftypes.RawUnifi
 	if err != nil {
 		return errors.Wrap(err, "confdb.SiteGetLatest")
 	}
-	_, err = confdb.SiteCreateIfUpToDate(ctx, &site.ID, input.Site)
+	return c.WriteIfUpToDate(ctx, input, site.ID)
+}
+
+func (c configurationSource) WriteIfUpToDate(ctx context.Context, input conftypes.RawUnified, lastID int32) error {
+	_, err := confdb.SiteCreateIfUpToDate(ctx, &lastID, actor.FromContext(ctx).UID, input.Site)
 	if err != nil {
 		return errors.Wrap(err, "confdb.SiteCreateIfUpToDate")
 	}
@@ -0,0 +1,75 @@
+package cloneurls
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// SubmoduleRepo returns the repository on this instance that the URL of a
+// submodule of repo refers to, or nil if there is none. Like git, we resolve
+// relative submodule URLs against the clone URL of repo.
+func SubmoduleRepo(ctx context.Context, db dbutil.DB, repo *types.Repo, submoduleURL string) (_ *types.Repo, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "SubmoduleRepo")
+	defer span.Finish()
+
+	cloneURL := submoduleURL
+	if strings.HasPrefix(submoduleURL, "./") || strings.HasPrefix(submoduleURL, "../") {
+		if base := repoCloneURL(repo); base != "" {
+			cloneURL, err = resolveSubmoduleURL(base, submoduleURL)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	name, err := ReposourceCloneURLToRepoName(ctx, db, cloneURL)
+	if err != nil || name == "" {
+		return nil, err
+	}
+
+	submoduleRepo, err := database.Repos(db).GetByName(ctx, name)
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	return submoduleRepo, err
+}
+
+// repoCloneURL returns a clone URL of repo, or the empty string if it has no
+// sources.
+func repoCloneURL(repo *types.Repo) string {
+	ids := make([]string, 0, len(repo.Sources))
+	for id := range repo.Sources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if cloneURL := repo.Sources[id].CloneURL; cloneURL != "" {
+			return cloneURL
+		}
+	}
+	return ""
+}
+
+// resolveSubmoduleURL resolves the relative submodule URL rel against the
+// clone URL base of the superproject. The last path component of base is
+// treated as a directory, so "../b.git" relative to "https://host/org/a.git"
+// is "https://host/org/b.git". Credentials in base are dropped.
+func resolveSubmoduleURL(base, rel string) (string, error) {
+	u, err := vcs.ParseURL(base)
+	if err != nil {
+		return "", err
+	}
+	u.User = nil
+	u.Path = path.Join(u.Path, rel)
+	u.RawPath = ""
+	return u.String(), nil
+}
@@ -12,14 +12,16 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/internal/conf/confdefaults"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 )
 
 // SiteConfig contains the contents of a site config along with associated metadata.
 type SiteConfig struct {
-	ID        int32     // the unique ID of this config
-	Contents  string    // the raw JSON content (with comments and trailing commas allowed)
-	CreatedAt time.Time // the date when this config was created
-	UpdatedAt time.Time // the date when this config was updated
+	ID           int32     // the unique ID of this config
+	AuthorUserID int32     // the ID of the user who created this config, or 0 if unknown
+	Contents     string    // the raw JSON content (with comments and trailing commas allowed)
+	CreatedAt    time.Time // the date when this config was created
+	UpdatedAt    time.Time // the date when this config was updated
 }
 
 // ErrNewerEdit is returned by SiteCreateIfUpToDate when a newer edit has already been applied and
@@ -28,13 +30,15 @@ var ErrNewerEdit = errors.New("someone else has already applied a newer edit")
 
 // SiteCreateIfUpToDate saves the given site config "contents" to the database iff the
 // supplied "lastID" is equal to the one that was most recently saved to the database.
+// The new revision is attributed to the given author, which may be 0 if the change was
+// not made by a user.
 //
 // The site config that was most recently saved to the database is returned.
 // An error is returned if "contents" is invalid JSON.
 //
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
-func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (latest *SiteConfig, err error) {
+func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, authorUserID int32, contents string) (latest *SiteConfig, err error) {
 	tx, done, err := newTransaction(ctx)
 	if err != nil {
 		return nil, err
@@ -49,7 +53,7 @@ func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (
 		lastID = newLastID
 	}
 
-	return createIfUpToDate(ctx, tx, lastID, contents)
+	return createIfUpToDate(ctx, tx, lastID, authorUserID, contents)
 }
 
 // SiteGetLatest returns the site config that was most recently saved to the database.
@@ -72,6 +76,66 @@ func SiteGetLatest(ctx context.Context) (latest *SiteConfig, err error) {
 	return getLatest(ctx, tx)
 }
 
+// SiteGetByID returns the site config with the given ID. This returns nil, nil if
+// no such site config exists.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteGetByID(ctx context.Context, id int32) (*SiteConfig, error) {
+	q := sqlf.Sprintf("SELECT s.id, s.author_user_id, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s AND id=%s", "site", id)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseQueryRows(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) != 1 {
+		return nil, nil
+	}
+	return versions[0], nil
+}
+
+// SiteListOptions specifies the options for listing site config revisions.
+type SiteListOptions struct {
+	// Limit is the maximum number of revisions to return. A zero value returns all revisions.
+	Limit int
+
+	// BeforeID, if non-zero, restricts the result to revisions created before the
+	// revision with this ID.
+	BeforeID int32
+}
+
+// SiteList returns the site config revisions saved to the database, newest first.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteList(ctx context.Context, opt SiteListOptions) ([]*SiteConfig, error) {
+	conds := []*sqlf.Query{sqlf.Sprintf("type=%s", "site")}
+	if opt.BeforeID != 0 {
+		conds = append(conds, sqlf.Sprintf("id < %s", opt.BeforeID))
+	}
+	limit := sqlf.Sprintf("")
+	if opt.Limit > 0 {
+		limit = sqlf.Sprintf("LIMIT %s", opt.Limit)
+	}
+
+	q := sqlf.Sprintf("SELECT s.id, s.author_user_id, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE %s ORDER BY id DESC %s", sqlf.Join(conds, "AND"), limit)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	return parseQueryRows(ctx, rows)
+}
+
+// SiteCount returns the number of site config revisions saved to the database.
+func SiteCount(ctx context.Context) (count int, err error) {
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM critical_and_site_config WHERE type=%s", "site")
+	err = dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count)
+	return count, err
+}
+
 func newTransaction(ctx context.Context) (tx queryable, done func(), err error) {
 	rtx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
@@ -101,20 +165,20 @@ func addDefault(ctx context.Context, tx queryable, contents string) (newLastID *
 	}
 
 	// Create the default.
-	latest, err = createIfUpToDate(ctx, tx, nil, contents)
+	latest, err = createIfUpToDate(ctx, tx, nil, 0, contents)
 	if err != nil {
 		return nil, err
 	}
 	return &latest.ID, nil
 }
 
-func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, contents string) (latest *SiteConfig, err error) {
+func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, authorUserID int32, contents string) (latest *SiteConfig, err error) {
 	// Validate JSON syntax before saving.
 	if _, errs := jsonx.Parse(contents, jsonx.ParseOptions{Comments: true, TrailingCommas: true}); len(errs) > 0 {
 		return nil, errors.Errorf("invalid settings JSON: %v", errs)
 	}
 
-	new := SiteConfig{Contents: contents}
+	new := SiteConfig{AuthorUserID: authorUserID, Contents: contents}
 
 	latest, err = getLatest(ctx, tx)
 	if err != nil {
@@ -126,8 +190,8 @@ func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, contents
 
 	err = tx.QueryRowContext(
 		ctx,
-		"INSERT INTO critical_and_site_config(type, contents) VALUES($1, $2) RETURNING id, created_at, updated_at",
-		"site", new.Contents,
+		"INSERT INTO critical_and_site_config(type, contents, author_user_id) VALUES($1, $2, $3) RETURNING id, created_at, updated_at",
+		"site", new.Contents, nullInt32Column(new.AuthorUserID),
 	).Scan(&new.ID, &new.CreatedAt, &new.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -136,7 +200,7 @@ func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, contents
 }
 
 func getLatest(ctx context.Context, tx queryable) (*SiteConfig, error) {
-	q := sqlf.Sprintf("SELECT s.id, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", "site")
+	q := sqlf.Sprintf("SELECT s.id, s.author_user_id, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", "site")
 	rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return nil, err
@@ -157,7 +221,7 @@ func parseQueryRows(ctx context.Context, rows *sql.Rows) ([]*SiteConfig, error)
 	defer rows.Close()
 	for rows.Next() {
 		f := SiteConfig{}
-		err := rows.Scan(&f.ID, &f.Contents, &f.CreatedAt, &f.UpdatedAt)
+		err := rows.Scan(&f.ID, &dbutil.NullInt32{N: &f.AuthorUserID}, &f.Contents, &f.CreatedAt, &f.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -169,6 +233,14 @@ func parseQueryRows(ctx context.Context, rows *sql.Rows) ([]*SiteConfig, error)
 	return versions, nil
 }
 
+// nullInt32Column returns nil for zero values so that unknown authors are stored as NULL.
+func nullInt32Column(n int32) *int32 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
 // queryable allows us to reuse the same logic for certain operations both
 // inside and outside an explicit transaction.
 type queryable interface {
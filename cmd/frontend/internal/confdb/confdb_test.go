@@ -36,7 +36,7 @@ func TestSiteCreate_RejectInvalidJSON(t *testing.T) {
 
 	malformedJSON := "[This is malformed.}"
 
-	_, err := SiteCreateIfUpToDate(ctx, nil, malformedJSON)
+	_, err := SiteCreateIfUpToDate(ctx, nil, 0, malformedJSON)
 
 	if err == nil || !strings.Contains(err.Error(), "invalid settings JSON") {
 		t.Fatalf("expected parse error after creating configuration with malformed JSON, got: %+v", err)
@@ -160,7 +160,7 @@ func TestSiteCreateIfUpToDate(t *testing.T) {
 			dbtesting.SetupGlobalTestDB(t)
 			ctx := context.Background()
 			for _, p := range test.sequence {
-				output, err := SiteCreateIfUpToDate(ctx, &p.input.lastID, p.input.contents)
+				output, err := SiteCreateIfUpToDate(ctx, &p.input.lastID, 0, p.input.contents)
 				if err != nil {
 					if err == p.expected.err {
 						continue
@@ -198,3 +198,62 @@ func TestSiteCreateIfUpToDate(t *testing.T) {
 		})
 	}
 }
+
+func TestSiteHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	latest, err := SiteGetLatest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, contents := range []string{`{"a": 1}`, `{"a": 2}`, `{"a": 3}`} {
+		// The user referenced by the author ID need not exist
+		if latest, err = SiteCreateIfUpToDate(ctx, &latest.ID, int32(i+1), contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := SiteCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("unexpected count. want=%d have=%d", 4, count)
+	}
+
+	versions, err := SiteList(ctx, SiteListOptions{Limit: 2, BeforeID: latest.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("unexpected number of versions. want=%d have=%d", 2, len(versions))
+	}
+	if versions[0].Contents != `{"a": 2}` || versions[0].AuthorUserID != 2 {
+		t.Errorf("unexpected version: %+v", versions[0])
+	}
+	if versions[1].Contents != `{"a": 1}` || versions[1].AuthorUserID != 1 {
+		t.Errorf("unexpected version: %+v", versions[1])
+	}
+
+	// The default configuration has no author
+	defaultConfig, err := SiteGetByID(ctx, latest.ID-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaultConfig == nil || defaultConfig.AuthorUserID != 0 {
+		t.Errorf("unexpected default version: %+v", defaultConfig)
+	}
+
+	missing, err := SiteGetByID(ctx, latest.ID+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != nil {
+		t.Errorf("unexpected version: %+v", missing)
+	}
+}
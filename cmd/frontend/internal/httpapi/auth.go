@@ -1,6 +1,8 @@
 package httpapi
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 
 	"github.com/inconshreveable/log15"
@@ -107,7 +109,11 @@ func AccessTokenAuthMiddleware(db dbutil.DB, next http.Handler) http.Handler {
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			fingerprint := sha256.Sum256([]byte(token))
+			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{
+				UID:                    actorUserID,
+				AccessTokenFingerprint: hex.EncodeToString(fingerprint[:]),
+			}))
 		}
 
 		next.ServeHTTP(w, r)
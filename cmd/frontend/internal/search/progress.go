@@ -48,6 +48,7 @@ func (p *progressAggregator) currentStats() api.ProgressStats {
 		ElapsedMilliseconds: int(time.Since(p.Start).Milliseconds()),
 		ExcludedArchived:    p.Stats.ExcludedArchived,
 		ExcludedForks:       p.Stats.ExcludedForks,
+		MissingBlobs:        p.Stats.MissingBlobs,
 		Timedout:            getNames(p.Stats, searchshared.RepoStatusTimedout),
 		Missing:             getNames(p.Stats, searchshared.RepoStatusMissing),
 		Cloning:             getNames(p.Stats, searchshared.RepoStatusCloning),
@@ -58,6 +59,12 @@ func (p *progressAggregator) currentStats() api.ProgressStats {
 	}
 }
 
+// Partial returns true if the search has not found all results, e.g. because
+// it hit the limit or some repositories timed out.
+func (p *progressAggregator) Partial() bool {
+	return p.Stats.IsLimitHit || p.Stats.Status.Any(searchshared.RepoStatusTimedout|searchshared.RepoStatusMissing|searchshared.RepoStatusCloning)
+}
+
 // Current returns the current progress event.
 func (p *progressAggregator) Current() api.Progress {
 	p.Dirty = false
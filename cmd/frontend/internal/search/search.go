@@ -25,6 +25,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/honey"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	searchshared "github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 	"github.com/sourcegraph/sourcegraph/internal/search/run"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
@@ -43,6 +44,14 @@ func StreamHandler(db dbutil.DB) http.Handler {
 	}
 }
 
+// maxAggregationGroups is the number of largest groups sent in aggregation
+// events.
+const maxAggregationGroups = 100
+
+// maxAggregationResults is the result limit of searches with an aggregation
+// that don't specify count:. It is the same as count:all.
+const maxAggregationResults = 99999999
+
 type streamHandler struct {
 	db                  dbutil.DB
 	newSearchResolver   func(context.Context, dbutil.DB, *graphqlbackend.SearchArgs) (searchResolver, error)
@@ -82,7 +91,15 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Log events to trace
 	eventWriter.StatHook = eventStreamOTHook(tr.LogFields)
 
-	events, inputs, results := h.startSearch(ctx, args)
+	// The aggregation is validated before the search starts, so that an
+	// invalid aggregation doesn't search anything.
+	var aggregation *streaming.SearchAggregation
+	events, inputs, results := h.startSearch(ctx, args, func(inputs run.SearchInputs) (err error) {
+		if args.Aggregation != "" {
+			aggregation, err = streaming.NewSearchAggregation(args.Aggregation, inputs.Plan)
+		}
+		return err
+	})
 	events = batchEvents(events, 50*time.Millisecond)
 
 	// Display is the number of results we send down. If display is < 0 we
@@ -90,6 +107,11 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// can only send up to limit results.
 	display := args.Display
 	limit := inputs.MaxResults()
+	if aggregation != nil && inputs.Query.Count() == nil {
+		// Aggregations count all results, but we don't send down more of them
+		// than a search without an aggregation would.
+		limit = searchshared.DefaultMaxSearchResultsStreaming
+	}
 	if display < 0 || display > limit {
 		display = limit
 	}
@@ -111,6 +133,21 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		_ = eventWriter.Event("progress", progress.Current())
 	}
 
+	sendAggregation := func() {
+		groups, otherGroups, otherCount := aggregation.Compute(maxAggregationGroups)
+		buf := make([]streamhttp.EventAggregationGroup, 0, len(groups))
+		for _, g := range groups {
+			buf = append(buf, streamhttp.EventAggregationGroup{Label: g.Label, Count: g.Count})
+		}
+		_ = eventWriter.Event("aggregation", streamhttp.EventAggregation{
+			Mode:            string(args.Aggregation),
+			Groups:          buf,
+			OtherGroupCount: otherGroups,
+			OtherMatchCount: otherCount,
+			Partial:         progress.Partial(),
+		})
+	}
+
 	filters := &streaming.SearchFilters{
 		Globbing: false, // TODO
 	}
@@ -130,6 +167,10 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if progress.Dirty {
 			sendProgress()
 		}
+
+		if aggregation != nil && aggregation.Dirty {
+			sendAggregation()
+		}
 	}
 	flushTicker := time.NewTicker(h.flushTickerInternal)
 	defer flushTicker.Stop()
@@ -142,6 +183,11 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		progress.Update(event)
 		filters.Update(event)
 
+		// Aggregate all results, not only those within the display limit.
+		if aggregation != nil {
+			aggregation.Update(event)
+		}
+
 		// Truncate the event to the match limit before fetching repo metadata
 		for i, match := range event.Results {
 			if display <= 0 {
@@ -220,6 +266,11 @@ LOOP:
 		}
 	}
 
+	// Send the final counts of the aggregation.
+	if aggregation != nil {
+		sendAggregation()
+	}
+
 	resultsResolver, err := results()
 	if err != nil {
 		_ = eventWriter.Event("error", streamhttp.EventError{Message: err.Error()})
@@ -278,10 +329,13 @@ LOOP:
 // startSearch will start a search. It returns the events channel which
 // streams out search events. Once events is closed you can call results which
 // will return the results resolver and error.
-func (h *streamHandler) startSearch(ctx context.Context, a *args) (events <-chan streaming.SearchEvent, inputs run.SearchInputs, results func() (*graphqlbackend.SearchResultsResolver, error)) {
+//
+// prepare is called with the inputs of the search before it starts. If it
+// returns an error, the search is not started and results returns the error.
+func (h *streamHandler) startSearch(ctx context.Context, a *args, prepare func(run.SearchInputs) error) (events <-chan streaming.SearchEvent, inputs run.SearchInputs, results func() (*graphqlbackend.SearchResultsResolver, error)) {
 	eventsC := make(chan streaming.SearchEvent)
 
-	search, err := h.newSearchResolver(ctx, h.db, &graphqlbackend.SearchArgs{
+	searchArgs := &graphqlbackend.SearchArgs{
 		Query:       a.Query,
 		Version:     a.Version,
 		PatternType: strPtr(a.PatternType),
@@ -289,7 +343,17 @@ func (h *streamHandler) startSearch(ctx context.Context, a *args) (events <-chan
 		Stream: streaming.StreamFunc(func(event streaming.SearchEvent) {
 			eventsC <- event
 		}),
-	})
+	}
+	if a.Aggregation != "" {
+		// Aggregations are computed over all results, not only the ones we
+		// display.
+		searchArgs.DefaultLimit = maxAggregationResults
+	}
+
+	search, err := h.newSearchResolver(ctx, h.db, searchArgs)
+	if err == nil {
+		err = prepare(search.Inputs())
+	}
 	if err != nil {
 		close(eventsC)
 		return eventsC, run.SearchInputs{}, func() (*graphqlbackend.SearchResultsResolver, error) {
@@ -337,6 +401,10 @@ type args struct {
 	DecorationLimit        int    // The initial number of files to decorate in the result set.
 	DecorationKind         string // The kind of decoration to apply (HTML highlighting, plaintext, etc.)
 	DecorationContextLines int    // The number of lines of context to include around lines with matches.
+
+	// Aggregation is the optional mode by which to group and count all
+	// matches of the search, sent as aggregation events.
+	Aggregation streaming.AggregationMode
 }
 
 func parseURLQuery(q url.Values) (*args, error) {
@@ -370,6 +438,12 @@ func parseURLQuery(q url.Values) (*args, error) {
 		return nil, errors.Errorf("decorationLimit must be an integer, got %q: %w", decorationLimit, err)
 	}
 
+	if aggregation := get("aggregation", ""); aggregation != "" {
+		if a.Aggregation, err = streaming.ParseAggregationMode(aggregation); err != nil {
+			return nil, err
+		}
+	}
+
 	decorationContextLines := get("dc", "1")
 	if a.DecorationContextLines, err = strconv.Atoi(decorationContextLines); err != nil {
 		return nil, errors.Errorf("decorationContextLines must be an integer, got %q: %w", decorationContextLines, err)
@@ -419,6 +493,8 @@ func fromMatch(match result.Match, repoCache map[api.RepoID]*types.SearchedRepo)
 		return fromRepository(v, repoCache)
 	case *result.CommitMatch:
 		return fromCommit(v, repoCache)
+	case *result.OwnerMatch:
+		return fromOwner(v)
 	default:
 		panic(fmt.Sprintf("unknown match type %T", v))
 	}
@@ -548,6 +624,15 @@ func fromRepository(rm *result.RepoMatch, repoCache map[api.RepoID]*types.Search
 	return repoEvent
 }
 
+func fromOwner(om *result.OwnerMatch) *streamhttp.EventOwnerMatch {
+	return &streamhttp.EventOwnerMatch{
+		Type:         streamhttp.OwnerMatchType,
+		Owner:        om.Owner,
+		RepositoryID: int32(om.Repo.ID),
+		Repository:   string(om.Repo.Name),
+	}
+}
+
 func fromCommit(commit *result.CommitMatch, repoCache map[api.RepoID]*types.SearchedRepo) *streamhttp.EventCommitMatch {
 	content := commit.Body.Value
 
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
@@ -212,6 +213,142 @@ func TestDisplayLimit(t *testing.T) {
 	}
 }
 
+func TestAggregation(t *testing.T) {
+	mock := &mockSearchResolver{
+		done: make(chan struct{}),
+	}
+
+	database.Mocks.Repos.Metadata = func(ctx context.Context, ids ...api2.RepoID) (_ []*types.SearchedRepo, err error) {
+		res := make([]*types.SearchedRepo, 0, len(ids))
+		for _, id := range ids {
+			res = append(res, &types.SearchedRepo{
+				ID: id,
+			})
+		}
+		return res, nil
+	}
+	t.Cleanup(func() { database.Mocks.Repos.Metadata = nil })
+
+	ts := httptest.NewServer(&streamHandler{
+		flushTickerInternal: 1 * time.Millisecond,
+		pingTickerInterval:  1 * time.Millisecond,
+		newSearchResolver: func(_ context.Context, _ dbutil.DB, args *graphqlbackend.SearchArgs) (searchResolver, error) {
+			if args.DefaultLimit != maxAggregationResults {
+				t.Errorf("unexpected default limit. want=%d have=%d", maxAggregationResults, args.DefaultLimit)
+			}
+			mock.c = args.Stream
+			q, err := query.Parse("foo", query.Literal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mock.inputs = &run.SearchInputs{
+				Query: q,
+			}
+			return mock, nil
+		}})
+	defer ts.Close()
+
+	req, _ := streamhttp.NewRequest(ts.URL, "foo")
+	q := req.URL.Query()
+	q.Add("display", "1")
+	q.Add("aggregation", "repo")
+	req.URL.RawQuery = q.Encode()
+
+	var aggregation *streamhttp.EventAggregation
+	decoder := streamhttp.FrontendStreamDecoder{
+		OnAggregation: func(a *streamhttp.EventAggregation) {
+			aggregation = a
+		},
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		return decoder.ReadAll(resp.Body)
+	})
+
+	// Only the first match is displayed, but all are aggregated.
+	mock.c.Send(streaming.SearchEvent{
+		Results: []result.Match{mkRepoMatch(1), mkRepoMatch(2)},
+	})
+	mock.c.Send(streaming.SearchEvent{
+		Results: []result.Match{mkRepoMatch(2)},
+		Stats:   streaming.Stats{IsLimitHit: true},
+	})
+	mock.Close()
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &streamhttp.EventAggregation{
+		Mode: "repo",
+		Groups: []streamhttp.EventAggregationGroup{
+			{Label: "repo2", Count: 2},
+			{Label: "repo1", Count: 1},
+		},
+		Partial: true,
+	}
+	if diff := cmp.Diff(want, aggregation); diff != "" {
+		t.Fatalf("unexpected aggregation (-want +got):\n%s", diff)
+	}
+}
+
+func TestAggregation_invalid(t *testing.T) {
+	mock := &mockSearchResolver{
+		done: make(chan struct{}),
+	}
+
+	ts := httptest.NewServer(&streamHandler{
+		flushTickerInternal: 1 * time.Millisecond,
+		pingTickerInterval:  1 * time.Millisecond,
+		newSearchResolver: func(context.Context, dbutil.DB, *graphqlbackend.SearchArgs) (searchResolver, error) {
+			q, err := query.Parse("foo", query.Literal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mock.inputs = &run.SearchInputs{
+				Plan:  query.Plan{query.Basic{Pattern: query.Pattern{Value: "foo"}}},
+				Query: q,
+			}
+			return mock, nil
+		}})
+	defer ts.Close()
+
+	req, _ := streamhttp.NewRequest(ts.URL, "foo")
+	q := req.URL.Query()
+	q.Add("aggregation", "capture_group")
+	req.URL.RawQuery = q.Encode()
+
+	var errorMessage string
+	decoder := streamhttp.FrontendStreamDecoder{
+		OnError: func(e *streamhttp.EventError) {
+			errorMessage = e.Message
+		},
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := decoder.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+
+	// The search is never started, so the mock doesn't need to be closed.
+	if want := "capture group aggregation requires a single regular expression pattern"; errorMessage != want {
+		t.Fatalf("unexpected error. want=%q have=%q", want, errorMessage)
+	}
+	if mock.started {
+		t.Fatal("search was started")
+	}
+}
+
 func mkRepoMatch(id int) *result.RepoMatch {
 	return &result.RepoMatch{
 		ID:   api2.RepoID(id),
@@ -223,9 +360,12 @@ type mockSearchResolver struct {
 	done   chan struct{}
 	c      streaming.Sender
 	inputs *run.SearchInputs
+
+	started bool
 }
 
 func (h *mockSearchResolver) Results(ctx context.Context) (*graphqlbackend.SearchResultsResolver, error) {
+	h.started = true
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
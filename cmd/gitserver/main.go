@@ -175,9 +175,61 @@ func main() {
 				}
 
 				return &server.JVMPackagesSyncer{Config: &c, DBStore: codeintelDB}, nil
+			case extsvc.TypeSubversion:
+				var c schema.SubversionConnection
+				for _, info := range r.Sources {
+					es, err := externalServiceStore.GetByID(ctx, info.ExternalServiceID())
+					if err != nil {
+						return nil, errors.Wrap(err, "get external service")
+					}
+
+					normalized, err := jsonc.Parse(es.Config)
+					if err != nil {
+						return nil, errors.Wrap(err, "normalize JSON")
+					}
+
+					if err = jsoniter.Unmarshal(normalized, &c); err != nil {
+						return nil, errors.Wrap(err, "unmarshal JSON")
+					}
+					break
+				}
+
+				return &server.SubversionRepoSyncer{Config: &c}, nil
 			}
 			return &server.GitRepoSyncer{}, nil
 		},
+		GetCodeHostLimitsFunc: func(ctx context.Context, repo api.RepoName) (server.CodeHostLimits, error) {
+			r, err := repoStore.GetByName(ctx, repo)
+			if err != nil {
+				return server.CodeHostLimits{}, errors.Wrap(err, "get repository")
+			}
+
+			// Use the external service with the lowest ID, so that a repository
+			// synced by several always shares the limits of the same one.
+			var id int64
+			for _, eid := range r.ExternalServiceIDs() {
+				if id == 0 || eid < id {
+					id = eid
+				}
+			}
+			if id == 0 {
+				return server.CodeHostLimits{}, nil
+			}
+
+			svc, err := externalServiceStore.GetByID(ctx, id)
+			if err != nil {
+				return server.CodeHostLimits{}, errors.Wrap(err, "get external service")
+			}
+			limits, err := extsvc.ExtractGitLimits(svc.Config, svc.Kind)
+			if err != nil {
+				return server.CodeHostLimits{}, err
+			}
+			return server.CodeHostLimits{
+				Key:                  svc.URN(),
+				MaxConcurrentClones:  limits.MaxConcurrentClones,
+				MaxRequestsPerSecond: limits.MaxRequestsPerSecond,
+			}, nil
+		},
 		Hostname:   hostname.Get(),
 		DB:         db,
 		CloneQueue: server.NewCloneQueue(list.New()),
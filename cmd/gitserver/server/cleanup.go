@@ -11,7 +11,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -80,9 +79,10 @@ const reposStatsName = "repos-stats.json"
 // 3. Remove stale lock files.
 // 4. Ensure correct git attributes
 // 5. Scrub remote URLs
-// 6. Perform garbage collection
-// 7. Re-clone repos after a while. (simulate git gc)
-// 8. Remove repos based on disk pressure.
+// 6. Repack and write commit-graphs and multi-pack-indexes
+// 7. Perform garbage collection
+// 8. Re-clone repos after a while. (simulate git gc)
+// 9. Remove repos based on disk pressure.
 func (s *Server) cleanupRepos() {
 	janitorRunning.Set(1)
 	defer janitorRunning.Set(0)
@@ -94,9 +94,17 @@ func (s *Server) cleanupRepos() {
 		UpdatedAt: time.Now(),
 	}
 
+	// repoSizes are the sizes computed by computeStats, which are reused to
+	// free up disk space without walking all repositories again.
+	repoSizes := make(map[GitDir]int64)
+
 	computeStats := func(dir GitDir) (done bool, err error) {
-		stats.GitDirBytes += dirSize(dir.Path("."))
-		return false, nil
+		size := dirSize(dir.Path("."))
+		repoSizes[dir] = size
+		stats.GitDirBytes += size
+		repo := s.name(dir)
+		addLargestRepo(&stats, protocol.RepoSize{Name: repo, Bytes: size})
+		return false, s.setRepoSize(bCtx, repo, size)
 	}
 
 	maybeRemoveCorrupt := func(dir GitDir) (done bool, err error) {
@@ -115,6 +123,33 @@ func (s *Server) cleanupRepos() {
 		return true, nil
 	}
 
+	removeStaleReplica := func(dir GitDir) (done bool, err error) {
+		if !isReplicaDir(dir) {
+			return false, nil
+		}
+
+		if s.replicaPrimary(s.name(dir)) != "" {
+			// The repository is still replicated to this gitserver, but
+			// only while it is hot.
+			idle, err := s.idleReplica(dir, time.Now())
+			if err != nil || !idle {
+				return false, err
+			}
+			log15.Info("removing idle replica", "repo", dir)
+		} else {
+			// The repository is no longer replicated to this gitserver,
+			// because replicas have been reconfigured or gitservers added
+			// or removed.
+			log15.Info("removing stale replica", "repo", dir)
+		}
+
+		if err := s.removeRepoDirectory(dir); err != nil {
+			return true, err
+		}
+		reposRemoved.Inc()
+		return true, nil
+	}
+
 	ensureGitAttributes := func(dir GitDir) (done bool, err error) {
 		return false, setGitAttributes(dir)
 	}
@@ -220,6 +255,33 @@ func (s *Server) cleanupRepos() {
 		return false, multi
 	}
 
+	performMaintenance := func(dir GitDir) (done bool, err error) {
+		objects, err := getObjectStats(dir)
+		if err != nil {
+			return false, err
+		}
+		stats.LooseObjects += objects.LooseObjects
+		stats.Packfiles += int64(len(objects.PackSizes))
+
+		if !enableMaintenance {
+			return false, nil
+		}
+		results, err := maintainRepo(bCtx, dir, objects)
+		for _, r := range results {
+			if stats.Maintenance == nil {
+				stats.Maintenance = map[string]protocol.MaintenanceTaskStats{}
+			}
+			taskStats := stats.Maintenance[r.Task]
+			if r.Err != nil {
+				taskStats.Failed++
+			} else {
+				taskStats.Succeeded++
+			}
+			stats.Maintenance[r.Task] = taskStats
+		}
+		return false, err
+	}
+
 	performGC := func(dir GitDir) (done bool, err error) {
 		if !enableGCAuto {
 			return false, nil
@@ -236,6 +298,9 @@ func (s *Server) cleanupRepos() {
 		{"compute statistics", computeStats},
 		// Do some sanity checks on the repository.
 		{"maybe remove corrupt", maybeRemoveCorrupt},
+		// Replicas of repositories which are no longer replicated to this
+		// gitserver or no longer hot are not fetched anymore.
+		{"remove stale replica", removeStaleReplica},
 		// If git is interrupted it can leave lock files lying around. It does not clean
 		// these up, and instead fails commands.
 		{"remove stale locks", removeStaleLocks},
@@ -244,6 +309,9 @@ func (s *Server) cleanupRepos() {
 		// 2021-03-01 (tomas,keegan) we used to store an authenticated remote URL on
 		// disk. We no longer need it so we can scrub it.
 		{"scrub remote URL", scrubRemoteURL},
+		// Keeps object lookups and commit walks fast by packing objects and writing
+		// multi-pack-indexes, bitmaps and commit-graphs as needed.
+		{"maintenance", performMaintenance},
 		// Runs a number of housekeeping tasks within the current repository, such as
 		// compressing file revisions (to reduce disk space and increase performance),
 		// removing unreachable objects which may have been created from prior
@@ -310,7 +378,7 @@ func (s *Server) cleanupRepos() {
 	if err != nil {
 		log15.Error("cleanup: ensuring free disk space", "error", err)
 	}
-	if err := s.freeUpSpace(b); err != nil {
+	if err := s.freeUpSpace(b, repoSizes); err != nil {
 		log15.Error("cleanup: error freeing up space", "error", err)
 	}
 }
@@ -367,31 +435,36 @@ func (s *StatDiskSizer) DiskSizeBytes(mountPoint string) (uint64, error) {
 	return free, nil
 }
 
-// freeUpSpace removes git directories under ReposDir, in order from least
-// recently to most recently used, until it has freed howManyBytesToFree.
-func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
+// freeUpSpace removes git directories under ReposDir until it has freed
+// howManyBytesToFree. Large repositories which haven't been accessed for a long
+// time are removed first, see evictionScore. repoSizes are the known sizes of
+// git directories, only the sizes of the others are computed.
+func (s *Server) freeUpSpace(howManyBytesToFree int64, repoSizes map[GitDir]int64) error {
 	if howManyBytesToFree <= 0 {
 		return nil
 	}
 
-	// Get the git directories and their mod times.
+	// Get the git directories, their sizes and last access times.
 	gitDirs, err := s.findGitDirs()
 	if err != nil {
 		return errors.Wrap(err, "finding git dirs")
 	}
-	dirModTimes := make(map[GitDir]time.Time, len(gitDirs))
+	dirSizes := make(map[GitDir]int64, len(gitDirs))
+	dirAccessTimes := make(map[GitDir]time.Time, len(gitDirs))
 	for _, d := range gitDirs {
-		mt, err := gitDirModTime(d)
+		at, err := repoLastAccessed(d)
 		if err != nil {
-			return errors.Wrap(err, "computing mod time of git dir")
+			return errors.Wrap(err, "computing last access time of git dir")
+		}
+		dirAccessTimes[d] = at
+		if size, ok := repoSizes[d]; ok {
+			dirSizes[d] = size
+		} else {
+			dirSizes[d] = dirSize(d.Path("."))
 		}
-		dirModTimes[d] = mt
 	}
 
-	// Sort the repos from least to most recently used.
-	sort.Slice(gitDirs, func(i, j int) bool {
-		return dirModTimes[gitDirs[i]].Before(dirModTimes[gitDirs[j]])
-	})
+	sortForEviction(gitDirs, dirSizes, dirAccessTimes, time.Now())
 
 	// Remove repos until howManyBytesToFree is met or exceeded.
 	var spaceFreed int64
@@ -403,7 +476,7 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 		if spaceFreed >= howManyBytesToFree {
 			return nil
 		}
-		delta := dirSize(d.Path("."))
+		delta := dirSizes[d]
 		if err := s.removeRepoDirectory(d); err != nil {
 			return errors.Wrap(err, "removing repo directory")
 		}
@@ -416,9 +489,10 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 			return errors.Wrap(err, "finding the amount of space free on disk")
 		}
 		G := float64(1024 * 1024 * 1024)
-		log15.Warn("cleanup: removed least recently used repo",
+		log15.Warn("cleanup: removed repo to free up space",
 			"repo", d,
-			"how old", time.Since(dirModTimes[d]),
+			"size in GiB", float64(delta)/G,
+			"last accessed", time.Since(dirAccessTimes[d]),
 			"free space in GiB", float64(actualFreeBytes)/G,
 			"actual percent of disk space free", float64(actualFreeBytes)/float64(diskSizeBytes)*100.0,
 			"desired percent of disk space free", float64(s.DesiredPercentFree),
@@ -487,6 +561,7 @@ func dirSize(d string) int64 {
 func (s *Server) removeRepoDirectory(gitDir GitDir) error {
 	ctx := context.Background()
 	dir := string(gitDir)
+	replica := s.isReplica(s.name(gitDir))
 
 	// Rename out of the location so we can atomically stop using the repo.
 	tmp, err := s.tempDir("delete-repo")
@@ -501,8 +576,11 @@ func (s *Server) removeRepoDirectory(gitDir GitDir) error {
 	// Everything after this point is just cleanup, so any error that occurs
 	// should not be returned, just logged.
 
-	// Set as not_cloned in the database
-	s.setCloneStatusNonFatal(ctx, s.name(gitDir), types.CloneStatusNotCloned)
+	// Set as not_cloned in the database, unless the repository was a replica,
+	// whose state is recorded by its primary gitserver.
+	if !replica {
+		s.setCloneStatusNonFatal(ctx, s.name(gitDir), types.CloneStatusNotCloned)
+	}
 
 	// Cleanup empty parent directories. We just attempt to remove and if we
 	// have a failure we assume it's due to the directory having other
@@ -712,7 +790,13 @@ func checkMaybeCorruptRepo(repo api.RepoName, dir GitDir, stderr string) {
 // operate synchronously and be aggressive with its internal heurisitcs when
 // deciding to act (meaning it will act now at lower thresholds).
 func gitGC(dir GitDir) error {
-	cmd := exec.Command("git", "-c", "gc.auto=1", "-c", "gc.autoDetach=false", "gc", "--auto")
+	args := []string{"-c", "gc.auto=1", "-c", "gc.autoDetach=false"}
+	if enableMaintenance {
+		// The maintenance tasks write a split commit-graph with bloom filters,
+		// which we don't want git gc to replace.
+		args = append(args, "-c", "gc.writeCommitGraph=false")
+	}
+	cmd := exec.Command("git", append(args, "gc", "--auto")...)
 	dir.Set(cmd)
 	err := cmd.Run()
 	if err != nil {
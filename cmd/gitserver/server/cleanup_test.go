@@ -56,6 +56,14 @@ func TestCleanup_computeStats(t *testing.T) {
 		// we only have .git dirs to measure so this is correct.
 		GitDirBytes: dirSize(root),
 	}
+	for _, name := range []string{"a", "b/d", "c"} {
+		// All repositories have the same size, so they are listed in the
+		// order they are walked in.
+		want.LargestRepos = append(want.LargestRepos, protocol.RepoSize{
+			Name:  api.RepoName(name),
+			Bytes: dirSize(path.Join(root, name, ".git")),
+		})
+	}
 
 	// We run cleanupRepos because we want to test as a side-effect it creates
 	// the correct file in the correct place.
@@ -718,13 +726,13 @@ func isEmptyDir(path string) (bool, error) {
 func TestFreeUpSpace(t *testing.T) {
 	t.Run("no error if no space requested and no repos", func(t *testing.T) {
 		s := &Server{DiskSizer: &fakeDiskSizer{}}
-		if err := s.freeUpSpace(0); err != nil {
+		if err := s.freeUpSpace(0, nil); err != nil {
 			t.Fatal(err)
 		}
 	})
 	t.Run("error if space requested and no repos", func(t *testing.T) {
 		s := &Server{DiskSizer: &fakeDiskSizer{}}
-		if err := s.freeUpSpace(1); err == nil {
+		if err := s.freeUpSpace(1, nil); err == nil {
 			t.Fatal("want error")
 		}
 	})
@@ -757,7 +765,7 @@ func TestFreeUpSpace(t *testing.T) {
 			ReposDir:  rd,
 			DiskSizer: &fakeDiskSizer{},
 		}
-		if err := s.freeUpSpace(1000); err != nil {
+		if err := s.freeUpSpace(1000, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -772,6 +780,33 @@ func TestFreeUpSpace(t *testing.T) {
 			t.Errorf("repo dir size is %d, want no more than %d", rds, wantSize)
 		}
 	})
+	t.Run("known repo sizes are used", func(t *testing.T) {
+		rd := t.TempDir()
+		r1 := filepath.Join(rd, "repo1")
+		r2 := filepath.Join(rd, "repo2")
+		if err := makeFakeRepo(r1, 1000); err != nil {
+			t.Fatal(err)
+		}
+		if err := makeFakeRepo(r2, 1000); err != nil {
+			t.Fatal(err)
+		}
+
+		// Record a larger size for repo1 than it has on disk, so that removing
+		// it frees up enough space only if the recorded size is used.
+		s := Server{
+			ReposDir:  rd,
+			DiskSizer: &fakeDiskSizer{},
+		}
+		repoSizes := map[GitDir]int64{GitDir(filepath.Join(r1, ".git")): 3000}
+		if err := s.freeUpSpace(2000, repoSizes); err != nil {
+			t.Fatal(err)
+		}
+
+		assertPaths(t, rd,
+			".tmp",
+			"repo2/.git/HEAD",
+			"repo2/.git/space_eater")
+	})
 }
 
 func makeFakeRepo(d string, sizeBytes int) error {
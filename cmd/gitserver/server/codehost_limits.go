@@ -0,0 +1,299 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// CodeHostLimits are the limits of the remote git operations run for the
+// repositories of a code host connection.
+type CodeHostLimits struct {
+	// Key identifies the code host connection. Repositories with the same key
+	// share their limits.
+	Key string
+
+	// MaxConcurrentClones is the maximum number of clones and fetches run at
+	// the same time. Zero means that only gitMaxConcurrentClones applies.
+	MaxConcurrentClones int
+
+	// MaxRequestsPerSecond overrides gitMaxCodehostRequestsPerSecond if
+	// non-nil.
+	MaxRequestsPerSecond *int
+}
+
+// codeHostLimitsTTL is how long the limits of a repository are cached. Looking
+// them up reads the repository and the config of its external service from
+// the database, so changes to the config take effect after at most
+// codeHostLimitsTTL.
+const codeHostLimitsTTL = time.Minute
+
+// maxCachedCodeHostLimits is the number of repositories whose limits are
+// cached.
+const maxCachedCodeHostLimits = 10000
+
+type cachedCodeHostLimits struct {
+	limits  CodeHostLimits
+	expires time.Time
+}
+
+// codeHostLimits returns the limits of the code host connection repo is
+// synced from. All repositories share the same limits if they cannot be
+// determined.
+func (s *Server) codeHostLimits(ctx context.Context, repo api.RepoName) CodeHostLimits {
+	if s.GetCodeHostLimitsFunc == nil {
+		return CodeHostLimits{}
+	}
+	if s.codeHostLimitsCache != nil {
+		if v, ok := s.codeHostLimitsCache.Get(repo); ok {
+			if cached := v.(cachedCodeHostLimits); time.Now().Before(cached.expires) {
+				return cached.limits
+			}
+		}
+	}
+
+	// We may be looking up a private repo so we need an internal actor.
+	limits, err := s.GetCodeHostLimitsFunc(actor.WithInternalActor(ctx), repo)
+	if err != nil {
+		log15.Warn("failed to get code host limits", "repo", repo, "error", err)
+		return CodeHostLimits{}
+	}
+	if s.codeHostLimitsCache != nil {
+		s.codeHostLimitsCache.Add(repo, cachedCodeHostLimits{limits: limits, expires: time.Now().Add(codeHostLimitsTTL)})
+	}
+	return limits
+}
+
+// codeHostLimiter is a semaphore which queues the operations of each code
+// host separately, and optionally limits them further per code host. Free
+// slots are handed to the queues of the code hosts in turn, so that a code
+// host with many queued or slow operations cannot starve the others.
+type codeHostLimiter struct {
+	// op labels the metrics of the limiter.
+	op string
+
+	mu      sync.Mutex
+	limit   int
+	running int
+	hosts   map[string]*codeHostQueue
+	// order contains the hosts with running or queued operations, in the
+	// order they were added.
+	order []*codeHostQueue
+	// served counts the slots handed out.
+	served uint64
+}
+
+type codeHostQueue struct {
+	key     string
+	limit   int
+	running int
+	waiters list.List // of chan struct{}
+	// lastServed is the value of served when the host was last handed a
+	// slot.
+	lastServed uint64
+}
+
+func newCodeHostLimiter(op string, limit int) *codeHostLimiter {
+	return &codeHostLimiter{
+		op:    op,
+		limit: limit,
+		hosts: make(map[string]*codeHostQueue),
+	}
+}
+
+// SetLimit adjusts the limit over all code hosts. Running operations are not
+// canceled if we are over the new limit.
+func (l *codeHostLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.dispatch()
+}
+
+// GetLimit reports the limit over all code hosts and the number of running
+// operations.
+func (l *codeHostLimiter) GetLimit() (cap, len int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.running
+}
+
+// Acquire waits for a free slot for an operation against the code host
+// limits.Key. On success a child context of ctx is returned. The cancel
+// function must be called to release the slot, and is safe to call more than
+// once.
+//
+// If ctx is Done before we can acquire, then the context error is returned.
+func (l *codeHostLimiter) Acquire(ctx context.Context, limits CodeHostLimits) (context.Context, context.CancelFunc, error) {
+	l.mu.Lock()
+	h, ok := l.hosts[limits.Key]
+	if !ok {
+		h = &codeHostQueue{key: limits.Key}
+		l.hosts[limits.Key] = h
+		l.order = append(l.order, h)
+	}
+	h.limit = limits.MaxConcurrentClones
+	ready := make(chan struct{})
+	el := h.waiters.PushBack(ready)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-ready:
+			// We were handed a slot concurrently.
+			l.release(h)
+		default:
+			h.waiters.Remove(el)
+			l.removeIfIdle(h)
+			l.observe(h)
+		}
+		l.mu.Unlock()
+		return nil, nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel()
+			l.mu.Lock()
+			l.release(h)
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// release frees the slot of an operation against h. l.mu must be held.
+func (l *codeHostLimiter) release(h *codeHostQueue) {
+	h.running--
+	l.running--
+	l.dispatch()
+	l.removeIfIdle(h)
+	l.observe(h)
+}
+
+// dispatch hands free slots to the queued operations, of the code host served
+// least recently first. l.mu must be held.
+func (l *codeHostLimiter) dispatch() {
+	for l.running < l.limit {
+		var h *codeHostQueue
+		for _, candidate := range l.order {
+			if candidate.waiters.Len() == 0 || (candidate.limit > 0 && candidate.running >= candidate.limit) {
+				continue
+			}
+			if h == nil || candidate.lastServed < h.lastServed {
+				h = candidate
+			}
+		}
+		if h == nil {
+			return
+		}
+
+		close(h.waiters.Remove(h.waiters.Front()).(chan struct{}))
+		l.served++
+		h.lastServed = l.served
+		h.running++
+		l.running++
+		l.observe(h)
+	}
+}
+
+// removeIfIdle forgets about h if it has no running or queued operations, so
+// that we only keep track of the code hosts in use. l.mu must be held.
+func (l *codeHostLimiter) removeIfIdle(h *codeHostQueue) {
+	if h.running > 0 || h.waiters.Len() > 0 || l.hosts[h.key] != h {
+		return
+	}
+	delete(l.hosts, h.key)
+	for i, o := range l.order {
+		if o != h {
+			continue
+		}
+		l.order = append(l.order[:i], l.order[i+1:]...)
+		break
+	}
+}
+
+// observe reports the queued and running operations of h. l.mu must be held.
+func (l *codeHostLimiter) observe(h *codeHostQueue) {
+	if l.hosts[h.key] != h {
+		codeHostQueued.DeleteLabelValues(l.op, h.key)
+		codeHostRunning.DeleteLabelValues(l.op, h.key)
+		return
+	}
+	codeHostQueued.WithLabelValues(l.op, h.key).Set(float64(h.waiters.Len()))
+	codeHostRunning.WithLabelValues(l.op, h.key).Set(float64(h.running))
+}
+
+// codeHostRateLimiters limits the remote git operations done per second per
+// code host.
+type codeHostRateLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*codeHostRateLimiter
+}
+
+type codeHostRateLimiter struct {
+	*rate.Limiter
+	maxRequestsPerSecond int
+}
+
+func newCodeHostRateLimiters() *codeHostRateLimiters {
+	return &codeHostRateLimiters{limiters: make(map[string]*codeHostRateLimiter)}
+}
+
+// Wait blocks until an operation against the code host limits.Key is
+// allowed.
+func (r *codeHostRateLimiters) Wait(ctx context.Context, limits CodeHostLimits) error {
+	maxRequestsPerSecond := conf.GitMaxCodehostRequestsPerSecond()
+	if limits.MaxRequestsPerSecond != nil {
+		maxRequestsPerSecond = *limits.MaxRequestsPerSecond
+	}
+
+	r.mu.Lock()
+	l, ok := r.limiters[limits.Key]
+	if !ok {
+		l = &codeHostRateLimiter{Limiter: rate.NewLimiter(rate.Inf, 10), maxRequestsPerSecond: -1}
+		r.limiters[limits.Key] = l
+	}
+	if l.maxRequestsPerSecond != maxRequestsPerSecond {
+		setRequestsPerSecond(l.Limiter, maxRequestsPerSecond)
+		l.maxRequestsPerSecond = maxRequestsPerSecond
+	}
+	r.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		codeHostRateLimitWait.WithLabelValues(limits.Key).Observe(time.Since(start).Seconds())
+	}()
+	return l.Wait(ctx)
+}
+
+func setRequestsPerSecond(l *rate.Limiter, maxRequestsPerSecond int) {
+	if maxRequestsPerSecond == -1 {
+		// As a special case, -1 means no limiting
+		l.SetLimit(rate.Inf)
+		l.SetBurst(10)
+	} else if maxRequestsPerSecond == 0 {
+		// A limiter with zero limit but a non-zero burst is not rejecting all events
+		// because the bucket is initially full with N tokens and refilled N tokens
+		// every second, where N is the burst size. See
+		// https://github.com/golang/go/issues/18763 for details.
+		l.SetLimit(0)
+		l.SetBurst(0)
+	} else {
+		l.SetLimit(rate.Limit(maxRequestsPerSecond))
+		l.SetBurst(10)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestCodeHostLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := newCodeHostLimiter("test", 2)
+
+	a := CodeHostLimits{Key: "a", MaxConcurrentClones: 1}
+	b := CodeHostLimits{Key: "b"}
+
+	_, releaseA, err := l.Acquire(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a is at its own limit, but b is not.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := l.Acquire(timeoutCtx, a); err != context.DeadlineExceeded {
+		t.Fatalf("expected a to be at its limit, have error %v", err)
+	}
+	_, releaseB, err := l.Acquire(ctx, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap, len := l.GetLimit(); cap != 2 || len != 2 {
+		t.Fatalf("have cap %d and len %d, want 2 and 2", cap, len)
+	}
+
+	releaseA()
+	releaseA() // releasing twice is fine
+	releaseB()
+	if cap, len := l.GetLimit(); cap != 2 || len != 0 {
+		t.Fatalf("have cap %d and len %d, want 2 and 0", cap, len)
+	}
+	if len(l.hosts) != 0 || len(l.order) != 0 {
+		t.Fatalf("expected idle hosts to be removed, have %d", len(l.hosts))
+	}
+}
+
+func TestCodeHostLimiter_fair(t *testing.T) {
+	ctx := context.Background()
+	l := newCodeHostLimiter("test", 1)
+
+	a := CodeHostLimits{Key: "a"}
+	b := CodeHostLimits{Key: "b"}
+
+	_, release, err := l.Acquire(ctx, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan string)
+	queue := func(limits CodeHostLimits, want int) {
+		go func() {
+			_, release, err := l.Acquire(ctx, limits)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			acquired <- limits.Key
+			release()
+		}()
+		// Wait for the acquire to be queued, so that we know the order.
+		for {
+			var queued int
+			l.mu.Lock()
+			if h, ok := l.hosts[limits.Key]; ok {
+				queued = h.waiters.Len()
+			}
+			l.mu.Unlock()
+			if queued == want {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	queue(a, 1)
+	queue(a, 2)
+	queue(a, 3)
+	queue(b, 1)
+
+	// b does not have to wait for all queued operations of a.
+	release()
+	var have []string
+	for i := 0; i < 4; i++ {
+		have = append(have, <-acquired)
+	}
+	if diff := cmp.Diff([]string{"b", "a", "a", "a"}, have); diff != "" {
+		t.Fatalf("unexpected acquire order (-want +have):\n%s", diff)
+	}
+}
+
+func TestCodeHostLimitsCache(t *testing.T) {
+	calls := 0
+	s := &Server{
+		GetCodeHostLimitsFunc: func(ctx context.Context, repo api.RepoName) (CodeHostLimits, error) {
+			calls++
+			return CodeHostLimits{Key: "a", MaxConcurrentClones: calls}, nil
+		},
+	}
+	s.codeHostLimitsCache, _ = lru.New(maxCachedCodeHostLimits)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if limits := s.codeHostLimits(ctx, "github.com/foo/bar"); limits.MaxConcurrentClones != 1 {
+			t.Fatalf("expected cached limits, have %+v", limits)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected limits to be looked up once, have %d lookups", calls)
+	}
+
+	// Expired limits are looked up again.
+	s.codeHostLimitsCache.Add(api.RepoName("github.com/foo/bar"), cachedCodeHostLimits{expires: time.Now().Add(-time.Second)})
+	if limits := s.codeHostLimits(ctx, "github.com/foo/bar"); limits.MaxConcurrentClones != 2 {
+		t.Fatalf("expected limits to be looked up again, have %+v", limits)
+	}
+}
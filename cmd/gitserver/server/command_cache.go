@@ -0,0 +1,303 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+)
+
+// commandCacheDirName is the name of the directory in ReposDir the output of
+// commands is cached in.
+const commandCacheDirName = ".command-cache"
+
+const (
+	defaultCommandCacheMaxEntrySizeKB = 10240
+	defaultCommandCacheSizeMB         = 10240
+)
+
+var (
+	commandCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_command_cache_requests_total",
+		Help: "The number of cacheable exec requests, by command and whether they were served from the cache, run and cached, or bypassed the cache.",
+	}, []string{"cmd", "result"})
+	commandCacheSizeBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "src_gitserver_command_cache_size_bytes",
+		Help: "The total size of the command output cached on disk.",
+	})
+	commandCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_gitserver_command_cache_evictions_total",
+		Help: "The number of command outputs evicted from the cache.",
+	})
+)
+
+// commandCacheConfig returns the maximum size of the output of a command to
+// cache and of the command cache, or ok=false if the output of commands should
+// not be cached.
+func commandCacheConfig() (maxEntrySize, cacheSize int64, ok bool) {
+	c := conf.Get().ExperimentalFeatures
+	if c == nil || c.GitServerCommandCache == nil || !c.GitServerCommandCache.Enabled {
+		return 0, 0, false
+	}
+	maxEntrySizeKB, cacheSizeMB := c.GitServerCommandCache.MaxEntrySizeKB, c.GitServerCommandCache.CacheSizeMB
+	if maxEntrySizeKB <= 0 {
+		maxEntrySizeKB = defaultCommandCacheMaxEntrySizeKB
+	}
+	if cacheSizeMB <= 0 {
+		cacheSizeMB = defaultCommandCacheSizeMB
+	}
+	return int64(maxEntrySizeKB) * 1024, int64(cacheSizeMB) * 1024 * 1024, true
+}
+
+func (s *Server) commandCache() *diskcache.Store {
+	return &diskcache.Store{
+		Dir:               filepath.Join(s.ReposDir, commandCacheDirName),
+		Component:         "gitserver-command-cache",
+		BackgroundTimeout: time.Minute,
+	}
+}
+
+// evictCommandCache evicts the least recently used command output until the
+// cache fits in its configured size. The whole cache is evicted if the output
+// of commands is not cached.
+func (s *Server) evictCommandCache() {
+	_, cacheSize, _ := commandCacheConfig()
+	stats, err := s.commandCache().Evict(cacheSize)
+	if err != nil {
+		log15.Error("failed to evict command cache", "error", err)
+		return
+	}
+	commandCacheSizeBytes.Set(float64(stats.CacheSize))
+	commandCacheEvictions.Add(float64(stats.Evicted))
+}
+
+// cacheableCommands are the git commands whose output may be cached. Their
+// output only depends on the objects they are run on, and the commands which
+// show author names also on the mailmap of the repository (see
+// mailmapCommands).
+var cacheableCommands = map[string]bool{
+	"blame":      true,
+	"diff":       true,
+	"log":        true,
+	"ls-tree":    true,
+	"merge-base": true,
+	"rev-list":   true,
+}
+
+// mailmapCommands are the cacheable commands which map author names and
+// emails with the .mailmap file at HEAD of the repository.
+var mailmapCommands = map[string]bool{
+	"blame": true,
+	"log":   true,
+}
+
+// cacheableFlags are the flags of cacheable commands which don't make their
+// output depend on anything but the commit IDs they are given. Flags showing
+// notes, such as --notes and --show-notes, are not cacheable since notes
+// change without the commits changing.
+var cacheableFlags = map[string]bool{
+	"--binary":             true,
+	"--count":              true,
+	"--date-order":         true,
+	"--first-parent":       true,
+	"--fixed-strings":      true,
+	"--full-history":       true,
+	"--full-index":         true,
+	"--full-name":          true,
+	"--full-tree":          true,
+	"--incremental":        true,
+	"--is-ancestor":        true,
+	"--left-right":         true,
+	"--line-porcelain":     true,
+	"--long":               true,
+	"--merges":             true,
+	"--name-only":          true,
+	"--name-status":        true,
+	"--no-color":           true,
+	"--no-merges":          true,
+	"--no-patch":           true,
+	"--no-prefix":          true,
+	"--numstat":            true,
+	"--patch":              true,
+	"--porcelain":          true,
+	"--raw":                true,
+	"--regexp-ignore-case": true,
+	"--reverse":            true,
+	"--root":               true,
+	"--shortstat":          true,
+	"--topo-order":         true,
+	"-p":                   true,
+	"-r":                   true,
+	"-t":                   true,
+	"-w":                   true,
+	"-z":                   true,
+}
+
+var (
+	// cacheableFlagValues are the flags of cacheable commands with a value,
+	// and the values they may have.
+	cacheableFlagValues = map[string]*regexp.Regexp{
+		"--after":       absoluteDateRe,
+		"--author":      anyValueRe,
+		"--before":      absoluteDateRe,
+		"--committer":   anyValueRe,
+		"--format":      anyValueRe, // see isCacheableFormat
+		"--grep":        anyValueRe,
+		"--max-count":   numberRe,
+		"--max-parents": numberRe,
+		"--min-parents": numberRe,
+		"--pretty":      anyValueRe, // see isCacheableFormat
+		"--skip":        numberRe,
+		"--since":       absoluteDateRe,
+		"--until":       absoluteDateRe,
+		"-L":            lineRangeRe,
+		"-n":            numberRe,
+	}
+
+	anyValueRe = regexp.MustCompile(``)
+	numberRe   = regexp.MustCompile(`^[0-9]+$`)
+	// lineRangeRe matches the line ranges of git blame -L.
+	lineRangeRe = regexp.MustCompile(`^[0-9]+,[0-9]+$`)
+	// absoluteDateRe matches dates which do not depend on the current time.
+	// Git takes the time of day of dates without one from the current time.
+	absoluteDateRe = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}[T ][0-9]{2}:[0-9]{2}:[0-9]{2}(Z|[+-][0-9]{2}:?[0-9]{2})?$`)
+
+	// immutableRevisionRe matches revisions which only reference commit IDs,
+	// such as "c1^..c2~2", and objects at commit IDs, such as "c1:dir".
+	immutableRevisionRe = regexp.MustCompile(`^(\^?[0-9a-f]{40}([~^][0-9]*)*(\.\.\.?[0-9a-f]{40}([~^][0-9]*)*)?|[0-9a-f]{40}([~^][0-9]*)*(\^\{tree\}|:.*))$`)
+
+	// mutableFormatRe matches the placeholders of git log formats whose
+	// expansion changes over time, such as ref names, notes and relative
+	// dates.
+	mutableFormatRe = regexp.MustCompile(`%(d|D|N|S|g|G|ar|cr|ah|ch|\(describe)`)
+)
+
+// isCacheableFormat reports whether the output of git log with the format
+// (the value of --format or --pretty) only depends on the commits shown.
+func isCacheableFormat(format string) bool {
+	if !strings.HasPrefix(format, "format:") && !strings.HasPrefix(format, "tformat:") && !strings.Contains(format, "%") {
+		// Only custom formats are allowed, since the predefined formats may
+		// depend on the configuration.
+		return false
+	}
+	return !mutableFormatRe.MatchString(strings.ReplaceAll(format, "%%", ""))
+}
+
+// isCacheableCommand reports whether the output of the git command with the
+// given arguments (excluding "git") can be cached, because it is deterministic
+// and all revisions it references are immutable commit IDs.
+func isCacheableCommand(args []string) bool {
+	if len(args) == 0 || !cacheableCommands[args[0]] {
+		return false
+	}
+
+	var revisions int
+	var hasFormat bool
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			// The remaining arguments are paths.
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			if !immutableRevisionRe.MatchString(arg) {
+				return false
+			}
+			revisions++
+			continue
+		}
+		if cacheableFlags[arg] {
+			continue
+		}
+
+		// Flags with a value, such as "--max-count=1", "-n 1" or "-n1".
+		var flag, value string
+		if j := strings.Index(arg, "="); j > 0 && strings.HasPrefix(arg, "--") {
+			flag, value = arg[:j], arg[j+1:]
+		} else if _, ok := cacheableFlagValues[arg]; ok && i+1 < len(args) {
+			i++
+			flag, value = arg, args[i]
+		} else if len(arg) > 2 && !strings.HasPrefix(arg, "--") {
+			flag, value = arg[:2], arg[2:]
+		} else {
+			return false
+		}
+		re, ok := cacheableFlagValues[flag]
+		if !ok || !re.MatchString(value) {
+			return false
+		}
+		if flag == "--format" || flag == "--pretty" {
+			if !isCacheableFormat(value) {
+				return false
+			}
+			hasFormat = true
+		}
+	}
+
+	if args[0] == "log" && !hasFormat {
+		// The default format shows notes.
+		return false
+	}
+
+	// Without a revision, commands default to HEAD.
+	return revisions > 0
+}
+
+// commandCacheKey returns the key of the cached output of the git command with
+// the given arguments run in repo, or false if its output can't be cached.
+func commandCacheKey(repo api.RepoName, dir GitDir, args []string) (string, bool) {
+	if !isCacheableCommand(args) {
+		return "", false
+	}
+
+	// Output is cached per repository, so that knowing a commit ID never
+	// gives access to the contents of another repository.
+	key := []string{string(repo)}
+	if mailmapCommands[args[0]] {
+		// The .mailmap file is read from HEAD, so the output changes when
+		// HEAD does.
+		head, err := quickRevParseHead(dir)
+		if err != nil || !isAbsoluteRevision(head) {
+			return "", false
+		}
+		key = append(key, head)
+	}
+	return strings.Join(append(key, args...), "\x00"), true
+}
+
+// openCachedCommand returns the cached output of the command with key, or nil
+// if it isn't cached. The caller must close the returned file.
+func (s *Server) openCachedCommand(key string) *diskcache.File {
+	f, err := s.commandCache().Lookup(key)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log15.Warn("failed to read cached command output", "error", err)
+		}
+		return nil
+	}
+	return f
+}
+
+// cacheCommandOutput caches out as the output of the command with key.
+func (s *Server) cacheCommandOutput(ctx context.Context, key string, out []byte) {
+	f, err := s.commandCache().Open(ctx, key, func(context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(out)), nil
+	})
+	if err != nil {
+		log15.Warn("failed to cache command output", "error", err)
+		return
+	}
+	f.Close()
+}
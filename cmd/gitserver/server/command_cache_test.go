@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestIsCacheableCommand(t *testing.T) {
+	const (
+		c1 = "0123456789abcdef0123456789abcdef01234567"
+		c2 = "89abcdef0123456789abcdef0123456789abcdef"
+	)
+	logFormat := "--format=format:%H%x00%aN%x00%at%x00%B%x00%P"
+
+	cacheable := [][]string{
+		{"rev-list", "--count", c1},
+		{"rev-list", "--count", "--left-right", c1 + "..." + c2},
+		{"rev-list", "--count", "-n", "10", "--before=2021-10-01T00:00:00Z", c1, "--", "dir/file"},
+		{"log", logFormat, "-n10", "--skip=5", "^" + c1, c2},
+		{"log", logFormat, "--max-count=1", c1 + "~2.." + c2 + "^"},
+		{"log", logFormat, "--fixed-strings", "--author=alice", "--grep=fix", c1},
+		{"ls-tree", "--long", "--full-name", "-z", c1, "-r", "-t", "--", "dir"},
+		{"ls-tree", c1 + ":dir"},
+		{"blame", "-w", "--porcelain", "-L1,10", c1, "--", "file"},
+		{"diff", c1, c2, "--", "file"},
+		{"merge-base", "--is-ancestor", c1, c2},
+	}
+	for _, args := range cacheable {
+		if !isCacheableCommand(args) {
+			t.Errorf("expected %q to be cacheable", args)
+		}
+	}
+
+	notCacheable := [][]string{
+		// Commands which modify the repository or whose output changes.
+		{"update-ref", "refs/heads/foo", c1},
+		{"for-each-ref"},
+		{"show", c1},
+		// Mutable revisions.
+		{"rev-list", "--count"},
+		{"rev-list", "--count", "HEAD"},
+		{"rev-list", "--count", "main"},
+		{"rev-list", "--count", c1[:7]},
+		{"rev-list", "--count", c1 + ".."},
+		{"rev-list", "--count", "--all"},
+		{"log", "--branches", c1},
+		{"ls-tree", c1, "dir"},
+		// Output depending on the current time or refs.
+		{"log", "--since=2.weeks", c1},
+		{"log", "--before=2021-10-01", c1},
+		{"log", "--format=format:%H %ar", c1},
+		{"log", "--format=%H%d", c1},
+		{"log", "--format=format:%H%x00%N", c1},
+		{"log", "--notes", c1},
+		{"log", "--show-notes", c1},
+		{"log", "--show-notes=refs/notes/review", c1},
+		{"log", c1},
+		{"log", "--pretty=oneline", c1},
+		{"log", "--decorate", c1},
+		{"log", "--date=relative", c1},
+		// Unknown flags and malformed values.
+		{"log", "-c", c1},
+		{"log", "-n", c1},
+		{"log", "-nfoo", c1},
+		{"blame", "-L", "foo", c1},
+	}
+	for _, args := range notCacheable {
+		if isCacheableCommand(args) {
+			t.Errorf("expected %q not to be cacheable", args)
+		}
+	}
+
+	// Literal percent signs are not placeholders.
+	if !isCacheableFormat("format:%H %%d") {
+		t.Error("expected format with a literal percent sign to be cacheable")
+	}
+}
+
+func TestExecCommandCache(t *testing.T) {
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "github.com/foo/bar")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runCmd(t, repoDir, "git", "init", ".")
+	runCmd(t, repoDir, "git", "commit", "--allow-empty", "-m", "foo")
+	runCmd(t, repoDir, "git", "commit", "--allow-empty", "-m", "bar")
+	commit := strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{
+			GitServerCommandCache: &schema.GitServerCommandCache{Enabled: true},
+		},
+	}})
+	defer conf.Mock(nil)
+
+	s := &Server{ReposDir: root}
+	h := s.Handler()
+
+	exec := func(bypass bool, args ...string) string {
+		t.Helper()
+		body, err := json.Marshal(protocol.ExecRequest{Repo: "github.com/foo/bar", Args: args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("POST", "/exec", bytes.NewReader(body))
+		if bypass {
+			req.Header.Set(protocol.BypassCommandCacheHeader, "true")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+		}
+		if status := rec.Header().Get("X-Exec-Exit-Status"); status != "0" {
+			t.Fatalf("unexpected exit status %s: %s", status, rec.Header().Get("X-Exec-Stderr"))
+		}
+		return rec.Body.String()
+	}
+
+	if out := exec(false, "rev-list", "--count", commit); out != "2\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	// Change the cached output, to tell whether it is served from the cache.
+	key, ok := commandCacheKey("github.com/foo/bar", GitDir(filepath.Join(repoDir, ".git")), []string{"rev-list", "--count", commit})
+	if !ok {
+		t.Fatal("expected command to be cacheable")
+	}
+	f := s.openCachedCommand(key)
+	if f == nil {
+		t.Fatal("expected command output to be cached")
+	}
+	f.Close()
+	if err := os.WriteFile(f.Path, []byte("cached\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := exec(false, "rev-list", "--count", commit); out != "cached\n" {
+		t.Fatalf("expected output to be served from the cache, got %q", out)
+	}
+	if out := exec(true, "rev-list", "--count", commit); out != "2\n" {
+		t.Fatalf("expected cache to be bypassed, got %q", out)
+	}
+	// Commands referencing mutable revisions are never cached.
+	if out := exec(false, "rev-list", "--count", "HEAD"); out != "2\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	// The cache is not mistaken for a repository.
+	gitDirs, err := s.findGitDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gitDirs) != 1 {
+		t.Fatalf("expected a single repository, got %v", gitDirs)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// maxLargestRepos is the number of repositories listed in
+// protocol.ReposStats.LargestRepos.
+const maxLargestRepos = 20
+
+// setRepoSize records the on-disk size of the clone of name in the database.
+func (s *Server) setRepoSize(ctx context.Context, name api.RepoName, size int64) error {
+	if s.DB == nil || s.isReplica(name) {
+		return nil
+	}
+	return database.GitserverRepos(s.DB).SetRepoSize(ctx, name, size, s.Hostname)
+}
+
+// addLargestRepo adds repo to the largest repositories in stats if it is one
+// of the maxLargestRepos largest repositories seen so far.
+func addLargestRepo(stats *protocol.ReposStats, repo protocol.RepoSize) {
+	repos := stats.LargestRepos
+	i := sort.Search(len(repos), func(i int) bool { return repos[i].Bytes < repo.Bytes })
+	if i == maxLargestRepos {
+		return
+	}
+	repos = append(repos, protocol.RepoSize{})
+	copy(repos[i+1:], repos[i:])
+	repos[i] = repo
+	if len(repos) > maxLargestRepos {
+		repos = repos[:maxLargestRepos]
+	}
+	stats.LargestRepos = repos
+}
+
+// diskQuotaExceededError is returned when a repository is not cloned because
+// an external service it belongs to has used up its disk quota.
+type diskQuotaExceededError struct {
+	externalServiceID int64
+	used, quota       int64
+}
+
+func (e *diskQuotaExceededError) Error() string {
+	return fmt.Sprintf("disk quota of external service %d exceeded: %d of %d bytes used", e.externalServiceID, e.used, e.quota)
+}
+
+// checkDiskQuota returns a *diskQuotaExceededError if any of the external
+// services repo belongs to has used up its disk quota. It is only checked
+// before cloning a repository, so that repositories which are already cloned
+// continue to be updated.
+func (s *Server) checkDiskQuota(ctx context.Context, repo api.RepoName) error {
+	quotas := conf.Get().ExperimentalFeatures
+	if s.DB == nil || quotas == nil || len(quotas.GitServerDiskQuotas) == 0 {
+		return nil
+	}
+
+	usage, err := database.GitserverRepos(s.DB).ExternalServiceDiskUsageForRepo(ctx, repo)
+	if err != nil {
+		return errors.Wrap(err, "getting disk usage")
+	}
+	for _, q := range quotas.GitServerDiskQuotas {
+		id := int64(q.ExternalService)
+		used, ok := usage[id]
+		if ok && used >= int64(q.MaxBytes) {
+			return &diskQuotaExceededError{externalServiceID: id, used: used, quota: int64(q.MaxBytes)}
+		}
+	}
+	return nil
+}
+
+// markAccessed records that dir has been read at now. The eviction of
+// repositories under disk pressure takes the time of the last access into
+// account. To avoid a write for each request, the time is only recorded with
+// a precision of a minute.
+func markAccessed(dir GitDir, now time.Time) error {
+	path := dir.Path("sg_last_access")
+	fi, err := os.Stat(path)
+	if err == nil && now.Sub(fi.ModTime()) < time.Minute {
+		return nil
+	}
+	if os.IsNotExist(err) {
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return os.Chtimes(path, now, now)
+}
+
+// repoLastAccessed returns the time dir was last read, falling back to its
+// modification time if it hasn't been read since access times were recorded.
+func repoLastAccessed(dir GitDir) (time.Time, error) {
+	fi, err := os.Stat(dir.Path("sg_last_access"))
+	if os.IsNotExist(err) {
+		return gitDirModTime(dir)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
+
+// evictionScore ranks a repository of size bytes which was last accessed at
+// lastAccess for removal under disk pressure at now. Repositories with higher
+// scores are removed first. Large repositories which haven't been read for a
+// long time score highest, since removing them frees the most space while
+// being the least likely to be read again soon.
+func evictionScore(size int64, lastAccess, now time.Time) float64 {
+	idle := now.Sub(lastAccess)
+	if idle < 0 {
+		idle = 0
+	}
+	// Without the extra minute all repositories accessed at now would score
+	// 0, regardless of their size.
+	return float64(size) * (idle + time.Minute).Seconds()
+}
+
+// sortForEviction sorts gitDirs in the order in which they are removed under
+// disk pressure, given their sizes and last access times.
+func sortForEviction(gitDirs []GitDir, sizes map[GitDir]int64, lastAccess map[GitDir]time.Time, now time.Time) {
+	sort.SliceStable(gitDirs, func(i, j int) bool {
+		a, b := gitDirs[i], gitDirs[j]
+		sa, sb := evictionScore(sizes[a], lastAccess[a], now), evictionScore(sizes[b], lastAccess[b], now)
+		if sa != sb {
+			return sa > sb
+		}
+		return lastAccess[a].Before(lastAccess[b])
+	})
+}
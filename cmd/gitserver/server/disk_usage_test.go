@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestSortForEviction(t *testing.T) {
+	now := time.Now()
+	const M = 1024 * 1024
+
+	dirs := []GitDir{"recent-small", "recent-large", "old-small", "old-large", "unknown"}
+	sizes := map[GitDir]int64{
+		"recent-small": 1 * M,
+		"recent-large": 1000 * M,
+		"old-small":    1 * M,
+		"old-large":    1000 * M,
+		"unknown":      1 * M,
+	}
+	lastAccess := map[GitDir]time.Time{
+		"recent-small": now.Add(-time.Minute),
+		"recent-large": now.Add(-time.Minute),
+		"old-small":    now.Add(-24 * time.Hour),
+		"old-large":    now.Add(-24 * time.Hour),
+		"unknown":      now.Add(time.Hour), // clock skew
+	}
+
+	sortForEviction(dirs, sizes, lastAccess, now)
+
+	// A large repository which was accessed a minute ago is removed before a
+	// small one which hasn't been accessed for a day, since removing it frees
+	// far more space.
+	want := []GitDir{"old-large", "recent-large", "old-small", "recent-small", "unknown"}
+	if diff := cmp.Diff(want, dirs); diff != "" {
+		t.Errorf("unexpected eviction order (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddLargestRepo(t *testing.T) {
+	var stats protocol.ReposStats
+	for i := 0; i < 2*maxLargestRepos; i++ {
+		// Sizes alternate between small and large.
+		size := int64(i)
+		if i%2 == 1 {
+			size += 1000
+		}
+		addLargestRepo(&stats, protocol.RepoSize{Name: api.RepoName(fmt.Sprintf("repo%d", i)), Bytes: size})
+	}
+
+	if len(stats.LargestRepos) != maxLargestRepos {
+		t.Fatalf("have %d largest repos, want %d", len(stats.LargestRepos), maxLargestRepos)
+	}
+	for i, repo := range stats.LargestRepos {
+		want := int64(1000 + 2*(maxLargestRepos-i) - 1)
+		if repo.Bytes != want {
+			t.Errorf("largest repo %d: have %d bytes, want %d", i, repo.Bytes, want)
+		}
+	}
+}
+
+func TestMarkAccessed(t *testing.T) {
+	root := t.TempDir()
+	dir := GitDir(filepath.Join(root, ".git"))
+	if err := makeFakeRepo(root, 0); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(dir.Path("HEAD"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	assertLastAccessed := func(want time.Time) {
+		t.Helper()
+		have, err := repoLastAccessed(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !have.Equal(want) {
+			t.Fatalf("have last access %s, want %s", have, want)
+		}
+	}
+
+	// Repositories which haven't been accessed fall back to their
+	// modification time.
+	assertLastAccessed(modTime)
+
+	accessed := modTime.Add(time.Minute)
+	if err := markAccessed(dir, accessed); err != nil {
+		t.Fatal(err)
+	}
+	assertLastAccessed(accessed)
+
+	// Accesses within a minute of the last recorded one are not recorded.
+	if err := markAccessed(dir, accessed.Add(30*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	assertLastAccessed(accessed)
+
+	if err := markAccessed(dir, accessed.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	assertLastAccessed(accessed.Add(2 * time.Minute))
+}
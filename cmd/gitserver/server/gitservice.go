@@ -41,6 +41,11 @@ func (s *Server) gitServiceHandler() *gitservice.Handler {
 		// Limit rate of stdout from git.
 		CommandHook: func(cmd *exec.Cmd) {
 			cmd.Stdout = flowrateWriter(cmd.Stdout)
+
+			// The GIT_DIR is the last argument.
+			if isPartialClone(GitDir(cmd.Args[len(cmd.Args)-1])) {
+				allowMissingPromisorObjects(cmd)
+			}
 		},
 
 		Trace: func(svc, repo, protocol string) func(error) {
@@ -0,0 +1,488 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// lfsCacheDirName is the name of the directory in ReposDir the LFS objects
+// are cached in.
+const lfsCacheDirName = ".lfs-cache"
+
+// lfsPointerMaxSize is the maximum size of a Git LFS pointer file.
+const lfsPointerMaxSize = 1024
+
+const (
+	defaultLFSMaxFileSizeKB = 10240
+	defaultLFSCacheSizeMB   = 10240
+)
+
+// lfsDoer is the HTTP client used to talk to LFS servers.
+var lfsDoer httpcli.Doer = httpcli.ExternalDoer
+
+// lfsConfig returns the maximum size of the LFS objects to resolve and of the
+// LFS cache, or ok=false if LFS objects should not be resolved.
+func lfsConfig() (maxFileSize, cacheSize int64, ok bool) {
+	c := conf.Get().ExperimentalFeatures
+	if c == nil || c.GitLFS == nil || !c.GitLFS.Enabled {
+		return 0, 0, false
+	}
+	maxFileSizeKB, cacheSizeMB := c.GitLFS.MaxFileSizeKB, c.GitLFS.CacheSizeMB
+	if maxFileSizeKB <= 0 {
+		maxFileSizeKB = defaultLFSMaxFileSizeKB
+	}
+	if cacheSizeMB <= 0 {
+		cacheSizeMB = defaultLFSCacheSizeMB
+	}
+	return int64(maxFileSizeKB) * 1024, int64(cacheSizeMB) * 1024 * 1024, true
+}
+
+// lfsPointer is a parsed Git LFS pointer file. See
+// https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+type lfsPointer struct {
+	oid  string // hex encoded SHA-256 of the content
+	size int64
+}
+
+var lfsOIDRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// parseLFSPointer parses b as a Git LFS pointer file. It returns false if b is
+// not one, or if the pointer uses extensions we cannot resolve.
+func parseLFSPointer(b []byte) (lfsPointer, bool) {
+	var p lfsPointer
+	if len(b) > lfsPointerMaxSize || !bytes.HasSuffix(b, []byte("\n")) {
+		return p, false
+	}
+
+	lines := strings.Split(string(b[:len(b)-1]), "\n")
+	if len(lines) < 3 {
+		return p, false
+	}
+	switch lines[0] {
+	case "version https://git-lfs.github.com/spec/v1", "version https://hawser.github.com/spec/v1":
+	default:
+		return p, false
+	}
+
+	var hasOID, hasSize bool
+	for _, line := range lines[1:] {
+		i := strings.IndexByte(line, ' ')
+		if i < 0 {
+			return p, false
+		}
+		key, value := line[:i], line[i+1:]
+		switch key {
+		case "oid":
+			if !strings.HasPrefix(value, "sha256:") || !lfsOIDRe.MatchString(value[len("sha256:"):]) {
+				return p, false
+			}
+			p.oid = value[len("sha256:"):]
+			hasOID = true
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return p, false
+			}
+			p.size = size
+			hasSize = true
+		default:
+			// Objects stored with extensions (ext-*) have to be transformed
+			// by them, so we cannot use their content.
+			return p, false
+		}
+	}
+	return p, hasOID && hasSize
+}
+
+// lfsEndpoint returns the URL of the LFS server of the repository at
+// remoteURL, following the defaults of git-lfs. Credentials of the remote URL
+// are kept for HTTP(S) remotes only.
+func lfsEndpoint(remoteURL *vcs.URL) (*url.URL, error) {
+	u := remoteURL.URL
+	switch u.Scheme {
+	case "http", "https":
+	case "", "ssh", "git":
+		// SSH remotes use the HTTPS endpoint of the same host.
+		u = url.URL{Scheme: "https", Host: u.Hostname(), Path: u.Path}
+	default:
+		return nil, errors.Errorf("unsupported remote URL scheme %q for Git LFS", u.Scheme)
+	}
+
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Path = "/" + strings.Trim(u.Path, "/")
+	if !strings.HasSuffix(u.Path, ".git") {
+		u.Path += ".git"
+	}
+	u.Path = path.Join(u.Path, "info", "lfs")
+	u.RawPath = ""
+	return &u, nil
+}
+
+type lfsBatchRequest struct {
+	Operation string           `json:"operation"`
+	Transfers []string         `json:"transfers"`
+	Objects   []lfsBatchObject `json:"objects"`
+}
+
+type lfsBatchObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions *struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type lfsBatchResponse struct {
+	Objects []lfsBatchObject `json:"objects"`
+}
+
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+// downloadLFSObject writes the content of the LFS object p of the repository
+// at remoteURL to w, using the batch API of its LFS server.
+func downloadLFSObject(ctx context.Context, remoteURL *vcs.URL, p lfsPointer, w io.Writer) error {
+	endpoint, err := lfsEndpoint(remoteURL)
+	if err != nil {
+		return err
+	}
+	user := endpoint.User
+	endpoint.User = nil
+
+	body, err := json.Marshal(lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsBatchObject{{OID: p.oid, Size: p.size}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint.String()+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	setBasicAuth(req, user)
+
+	var batch lfsBatchResponse
+	if err := doLFSRequest(req, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&batch)
+	}); err != nil {
+		return errors.Wrap(err, "LFS batch request")
+	}
+
+	var obj *lfsBatchObject
+	for i := range batch.Objects {
+		if batch.Objects[i].OID == p.oid {
+			obj = &batch.Objects[i]
+		}
+	}
+	switch {
+	case obj == nil:
+		return errors.Errorf("LFS object %s missing from batch response", p.oid)
+	case obj.Error != nil:
+		return errors.Errorf("LFS object %s: %d %s", p.oid, obj.Error.Code, obj.Error.Message)
+	case obj.Actions == nil || obj.Actions.Download == nil:
+		return errors.Errorf("LFS object %s has no download action", p.oid)
+	}
+
+	download := obj.Actions.Download
+	req, err = http.NewRequestWithContext(ctx, "GET", download.Href, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range download.Header {
+		req.Header.Set(k, v)
+	}
+	// Object storage is often on another host, which must not get the
+	// credentials of the code host.
+	if req.URL.Host == endpoint.Host && req.Header.Get("Authorization") == "" {
+		setBasicAuth(req, user)
+	}
+
+	h := sha256.New()
+	var n int64
+	if err := doLFSRequest(req, func(r io.Reader) (err error) {
+		n, err = io.Copy(io.MultiWriter(w, h), io.LimitReader(r, p.size+1))
+		return err
+	}); err != nil {
+		return errors.Wrap(err, "LFS download")
+	}
+	if n != p.size || hex.EncodeToString(h.Sum(nil)) != p.oid {
+		return errors.Errorf("LFS object %s does not match its pointer", p.oid)
+	}
+	return nil
+}
+
+func setBasicAuth(req *http.Request, user *url.Userinfo) {
+	if user == nil {
+		return
+	}
+	password, _ := user.Password()
+	req.SetBasicAuth(user.Username(), password)
+}
+
+func doLFSRequest(req *http.Request, read func(io.Reader) error) error {
+	resp, err := lfsDoer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(b))
+	}
+	return read(resp.Body)
+}
+
+var (
+	lfsObjectsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_lfs_objects_total",
+		Help: "The number of Git LFS pointers found in archives and blob reads, by whether they were resolved, too large or failed to resolve.",
+	}, []string{"status"})
+	lfsCacheSizeBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "src_gitserver_lfs_cache_size_bytes",
+		Help: "The total size of the Git LFS objects cached on disk.",
+	})
+	lfsCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_gitserver_lfs_cache_evictions_total",
+		Help: "The number of Git LFS objects evicted from the cache.",
+	})
+)
+
+func (s *Server) lfsCache() *diskcache.Store {
+	return &diskcache.Store{
+		Dir:               filepath.Join(s.ReposDir, lfsCacheDirName),
+		Component:         "gitserver-lfs",
+		BackgroundTimeout: 10 * time.Minute,
+	}
+}
+
+// evictLFSObjects evicts the least recently used LFS objects until the cache
+// fits in its configured size. The whole cache is evicted if LFS objects are
+// not resolved.
+func (s *Server) evictLFSObjects() {
+	_, cacheSize, _ := lfsConfig()
+	stats, err := s.lfsCache().Evict(cacheSize)
+	if err != nil {
+		log15.Error("failed to evict LFS objects", "error", err)
+		return
+	}
+	lfsCacheSizeBytes.Set(float64(stats.CacheSize))
+	lfsCacheEvictions.Add(float64(stats.Evicted))
+}
+
+// lfsResolver resolves the LFS pointers of a repository to their content.
+type lfsResolver struct {
+	s           *Server
+	repo        api.RepoName
+	maxFileSize int64
+}
+
+// resolve returns the content of the LFS object b points to, or nil if b is
+// not a pointer or its object should not be resolved. The caller must close
+// the returned file.
+func (l *lfsResolver) resolve(ctx context.Context, b []byte) (*diskcache.File, lfsPointer) {
+	p, ok := parseLFSPointer(b)
+	if !ok {
+		return nil, p
+	}
+	if p.size > l.maxFileSize {
+		lfsObjectsCounter.WithLabelValues("too_large").Inc()
+		return nil, p
+	}
+
+	// Objects are cached per repository, so that knowing the hash of an
+	// object never gives access to the object of another repository.
+	key := string(l.repo) + "@" + p.oid
+	f, err := l.s.lfsCache().OpenWithPath(ctx, key, func(ctx context.Context, path string) error {
+		remoteURL, err := l.s.getRemoteURL(ctx, l.repo)
+		if err != nil {
+			return err
+		}
+		w, err := os.OpenFile(path, os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		if err := downloadLFSObject(ctx, remoteURL, p, w); err != nil {
+			w.Close()
+			return err
+		}
+		return w.Close()
+	})
+	if err != nil {
+		lfsObjectsCounter.WithLabelValues("error").Inc()
+		log15.Warn("failed to fetch LFS object", "repo", l.repo, "oid", p.oid, "error", err)
+		return nil, p
+	}
+	lfsObjectsCounter.WithLabelValues("resolved").Inc()
+	return f, p
+}
+
+// resolveTar copies the tar archive read from r to w, substituting the
+// content of the LFS objects for their pointer files.
+func (l *lfsResolver) resolveTar(ctx context.Context, w io.Writer, r io.Reader) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	var buf bytes.Buffer
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > lfsPointerMaxSize {
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		buf.Reset()
+		if _, err := io.Copy(&buf, tr); err != nil {
+			return err
+		}
+		f, p := l.resolve(ctx, buf.Bytes())
+		if f == nil {
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := tw.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		hdr.Size = p.size
+		err = tw.WriteHeader(hdr)
+		if err == nil {
+			_, err = io.Copy(tw, f)
+		}
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// lfsBlobWriter buffers the start of a blob written to it, and writes the
+// content of the LFS object it points to to w on Close if it is a pointer
+// file. Otherwise it writes the blob as is.
+type lfsBlobWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	resolver *lfsResolver
+	buf      bytes.Buffer
+	flushed  bool
+}
+
+func (b *lfsBlobWriter) Write(p []byte) (int, error) {
+	if b.flushed {
+		return b.w.Write(p)
+	}
+	b.buf.Write(p)
+	if b.buf.Len() <= lfsPointerMaxSize {
+		return len(p), nil
+	}
+	// Too large to be a pointer.
+	b.flushed = true
+	if _, err := b.w.Write(b.buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (b *lfsBlobWriter) Close() error {
+	if b.flushed {
+		return nil
+	}
+	f, _ := b.resolver.resolve(b.ctx, b.buf.Bytes())
+	if f == nil {
+		_, err := b.w.Write(b.buf.Bytes())
+		return err
+	}
+	defer f.Close()
+	_, err := io.Copy(b.w, f)
+	return err
+}
+
+// archiveLFSHook returns an execHook for git archive, which substitutes the
+// content of LFS objects for their pointer files in tar archives. Zip archives
+// are left as is.
+func (s *Server) archiveLFSHook(repo api.RepoName, format string) execHook {
+	return func(ctx context.Context, dir GitDir, cmd *exec.Cmd) func() error {
+		maxFileSize, _, ok := lfsConfig()
+		if !ok || format != "tar" {
+			return nil
+		}
+		l := &lfsResolver{s: s, repo: repo, maxFileSize: maxFileSize}
+		fw := newFilterWriter(cmd.Stdout, func(w io.Writer, r io.Reader) error {
+			return l.resolveTar(ctx, w, r)
+		})
+		cmd.Stdout = fw
+		return fw.Close
+	}
+}
+
+// showLFSHook returns an execHook which substitutes the content of an LFS
+// object for its pointer file in blob reads, which are done with
+// `git show <commit>:<path>`. It returns nil for any other command.
+func (s *Server) showLFSHook(repo api.RepoName, args []string) execHook {
+	if len(args) != 2 || args[0] != "show" || !strings.Contains(args[1], ":") || strings.HasPrefix(args[1], "-") {
+		return nil
+	}
+	return func(ctx context.Context, dir GitDir, cmd *exec.Cmd) func() error {
+		maxFileSize, _, ok := lfsConfig()
+		if !ok {
+			return nil
+		}
+		bw := &lfsBlobWriter{
+			ctx:      ctx,
+			w:        cmd.Stdout,
+			resolver: &lfsResolver{s: s, repo: repo, maxFileSize: maxFileSize},
+		}
+		cmd.Stdout = bw
+		return bw.Close
+	}
+}
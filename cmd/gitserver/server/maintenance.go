@@ -0,0 +1,391 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+// enableMaintenance controls whether the janitor runs the maintenance tasks
+// below on repositories. Object statistics are collected either way.
+var enableMaintenance, _ = strconv.ParseBool(env.Get("SRC_ENABLE_MAINTENANCE", "true", "Repack and write commit-graphs and multi-pack-indexes during janitorial cleanup phases"))
+
+// The maintenance tasks, named after their git maintenance counterparts.
+const (
+	// maintenanceLooseObjects packs loose objects into a new packfile.
+	maintenanceLooseObjects = "loose-objects"
+	// maintenanceIncrementalRepack combines the smaller packfiles of a
+	// repository using its multi-pack-index.
+	maintenanceIncrementalRepack = "incremental-repack"
+	// maintenanceFullRepack packs all objects into a single packfile with a
+	// reachability bitmap.
+	maintenanceFullRepack = "full-repack"
+	// maintenanceMultiPackIndex writes a multi-pack-index, so that objects
+	// can be looked up without searching every packfile.
+	maintenanceMultiPackIndex = "multi-pack-index"
+	// maintenanceCommitGraph writes a commit-graph with changed-path bloom
+	// filters, which speeds up commit walks and history of paths.
+	maintenanceCommitGraph = "commit-graph"
+)
+
+const (
+	// maintenanceLooseObjectsLimit is the number of loose objects after which
+	// we pack them.
+	maintenanceLooseObjectsLimit = 1024
+	// maintenanceIncrementalRepackLimit is the number of packfiles after which
+	// we combine them incrementally.
+	maintenanceIncrementalRepackLimit = 8
+	// maintenanceFullRepackLimit is the number of packfiles after which we
+	// repack everything, since the incremental repacks are not keeping up.
+	maintenanceFullRepackLimit = 64
+	// maintenanceFullRepackInterval is how often we repack everything if a
+	// repository has more than one packfile or no bitmap.
+	maintenanceFullRepackInterval = 7 * 24 * time.Hour
+	// maintenanceCommitGraphInterval is how often at most we write a
+	// commit-graph for a repository which is fetched from.
+	maintenanceCommitGraphInterval = time.Hour
+	// maintenanceRetryInterval is how long we wait before running a task
+	// again after it failed.
+	maintenanceRetryInterval = time.Hour
+	// maintenanceMaxBatchSize is the maximum size of the packfile written by
+	// an incremental repack, like git maintenance does.
+	maintenanceMaxBatchSize = 2 << 30
+)
+
+var maintenanceTaskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "src_gitserver_maintenance_task_duration_seconds",
+	Help:    "Duration of the repository maintenance tasks run by the gitserver janitor.",
+	Buckets: []float64{0.1, 1, 5, 10, 30, 60, 300, 600, 1800},
+}, []string{"task", "success"})
+
+// objectStats describes how the objects of a repository are stored.
+type objectStats struct {
+	// LooseObjects is the number of loose objects.
+	LooseObjects int64
+	// PackSizes are the sizes of the packfiles, largest first.
+	PackSizes []int64
+	// NewestPack is the mtime of the most recently written packfile.
+	NewestPack time.Time
+	// HasBitmap is true if a packfile or the multi-pack-index has a
+	// reachability bitmap.
+	HasBitmap bool
+	// MultiPackIndex is the mtime of the multi-pack-index, or zero if there is
+	// none.
+	MultiPackIndex time.Time
+	// HasCommitGraph is true if a commit-graph has been written.
+	HasCommitGraph bool
+}
+
+func getObjectStats(dir GitDir) (objectStats, error) {
+	var stats objectStats
+
+	cmd := exec.Command("git", "count-objects", "-v")
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return stats, errors.Wrap(wrapCmdError(cmd, err), "failed to count objects")
+	}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if v := strings.TrimPrefix(sc.Text(), "count: "); v != sc.Text() {
+			stats.LooseObjects, _ = strconv.ParseInt(v, 10, 64)
+		}
+	}
+
+	packs, err := filepath.Glob(dir.Path("objects", "pack", "*.pack"))
+	if err != nil {
+		return stats, err
+	}
+	for _, pack := range packs {
+		fi, err := os.Stat(pack)
+		if err != nil {
+			// The pack may have been removed concurrently.
+			continue
+		}
+		stats.PackSizes = append(stats.PackSizes, fi.Size())
+		if fi.ModTime().After(stats.NewestPack) {
+			stats.NewestPack = fi.ModTime()
+		}
+	}
+	sort.Slice(stats.PackSizes, func(i, j int) bool { return stats.PackSizes[i] > stats.PackSizes[j] })
+
+	bitmaps, _ := filepath.Glob(dir.Path("objects", "pack", "*.bitmap"))
+	stats.HasBitmap = len(bitmaps) > 0
+
+	if fi, err := os.Stat(dir.Path("objects", "pack", "multi-pack-index")); err == nil {
+		stats.MultiPackIndex = fi.ModTime()
+	}
+
+	for _, p := range []string{
+		dir.Path("objects", "info", "commit-graph"),
+		dir.Path("objects", "info", "commit-graphs", "commit-graph-chain"),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			stats.HasCommitGraph = true
+		}
+	}
+
+	return stats, nil
+}
+
+// maintenanceState is what we remember about the maintenance of a repository.
+// It is stored in the git config of the repository, so it goes away with the
+// clone.
+type maintenanceState struct {
+	// LastSuccess is when each task last succeeded.
+	LastSuccess map[string]time.Time
+	// LastFailure is when each task last failed.
+	LastFailure map[string]time.Time
+}
+
+const (
+	gitConfigMaintenance       = "sourcegraph.maintenance."
+	gitConfigMaintenanceFailed = "sourcegraph.maintenancefailed."
+)
+
+func getMaintenanceState(dir GitDir) (maintenanceState, error) {
+	state := maintenanceState{
+		LastSuccess: map[string]time.Time{},
+		LastFailure: map[string]time.Time{},
+	}
+
+	cmd := exec.Command("git", "config", "--get-regexp", `^sourcegraph\.maintenance(failed)?\.`)
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means no key is set.
+		var e *exec.ExitError
+		if errors.As(err, &e) && e.Sys().(syscall.WaitStatus).ExitStatus() == 1 {
+			return state, nil
+		}
+		return state, errors.Wrap(wrapCmdError(cmd, err), "failed to get maintenance state")
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sec, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		// git lowercases section and key names.
+		if task := strings.TrimPrefix(fields[0], gitConfigMaintenanceFailed); task != fields[0] {
+			state.LastFailure[task] = time.Unix(sec, 0)
+		} else if task := strings.TrimPrefix(fields[0], gitConfigMaintenance); task != fields[0] {
+			state.LastSuccess[task] = time.Unix(sec, 0)
+		}
+	}
+	return state, nil
+}
+
+func setMaintenanceTime(dir GitDir, task string, success bool, t time.Time) error {
+	key := gitConfigMaintenance + task
+	if !success {
+		key = gitConfigMaintenanceFailed + task
+	}
+	return gitConfigSet(dir, key, strconv.FormatInt(t.Unix(), 10))
+}
+
+// maintenanceTasks returns the tasks to run on the repository dir, in order,
+// based on how its objects are stored and when the tasks last ran.
+//
+// Repositories with few loose objects and packfiles are left alone. Loose
+// objects are packed, and packfiles are combined incrementally, once there are
+// enough of them to slow down object lookups. Everything is repacked into a
+// single packfile with a bitmap if incremental repacks are not keeping up, or
+// once a week. The commit-graph is rewritten at most hourly after fetches.
+func maintenanceTasks(dir GitDir, stats objectStats, state maintenanceState, lastFetched, now time.Time) []string {
+	due := func(task string, cond bool) bool {
+		if !cond {
+			return false
+		}
+		// Back off from tasks which keep failing.
+		failed := state.LastFailure[task]
+		return !failed.After(state.LastSuccess[task]) || now.Sub(failed) >= maintenanceRetryInterval
+	}
+
+	var tasks []string
+	packs := len(stats.PackSizes)
+
+	// A missing last full repack is initialized by the caller, so that we
+	// spread out the full repacks of existing repositories.
+	lastFull, ok := state.LastSuccess[maintenanceFullRepack]
+	fullRepackDue := ok && (packs > 1 || !stats.HasBitmap) &&
+		now.Sub(lastFull) > maintenanceFullRepackInterval+jitterDuration(string(dir), maintenanceFullRepackInterval/4)
+	if due(maintenanceFullRepack, packs >= maintenanceFullRepackLimit || fullRepackDue) {
+		tasks = append(tasks, maintenanceFullRepack)
+	} else {
+		if due(maintenanceLooseObjects, stats.LooseObjects >= maintenanceLooseObjectsLimit) {
+			tasks = append(tasks, maintenanceLooseObjects)
+			// Packing the loose objects adds a packfile.
+			packs++
+		}
+		if due(maintenanceIncrementalRepack, packs >= maintenanceIncrementalRepackLimit) {
+			// The incremental repack writes the multi-pack-index too.
+			tasks = append(tasks, maintenanceIncrementalRepack)
+		} else if due(maintenanceMultiPackIndex, packs > 1 && (len(tasks) > 0 || stats.MultiPackIndex.Before(stats.NewestPack))) {
+			tasks = append(tasks, maintenanceMultiPackIndex)
+		}
+	}
+
+	// Empty repositories do not get a commit-graph.
+	hasObjects := len(stats.PackSizes) > 0 || stats.LooseObjects > 0
+	lastCommitGraph := state.LastSuccess[maintenanceCommitGraph]
+	if due(maintenanceCommitGraph, hasObjects && (!stats.HasCommitGraph ||
+		(lastFetched.After(lastCommitGraph) && now.Sub(lastCommitGraph) >= maintenanceCommitGraphInterval))) {
+		tasks = append(tasks, maintenanceCommitGraph)
+	}
+
+	return tasks
+}
+
+// maintenanceTaskCommands returns the git commands which implement task.
+func maintenanceTaskCommands(task string, stats objectStats) [][]string {
+	switch task {
+	case maintenanceLooseObjects:
+		return [][]string{{"repack", "-d"}}
+
+	case maintenanceIncrementalRepack:
+		// Like git maintenance, we combine all but the largest packfile.
+		batchSize := int64(maintenanceMaxBatchSize)
+		if len(stats.PackSizes) > 1 && stats.PackSizes[1]+1 < batchSize {
+			batchSize = stats.PackSizes[1] + 1
+		}
+		return [][]string{
+			{"multi-pack-index", "write"},
+			{"multi-pack-index", "expire"},
+			{"multi-pack-index", "repack", "--batch-size=" + strconv.FormatInt(batchSize, 10)},
+			multiPackIndexWriteArgs(),
+		}
+
+	case maintenanceFullRepack:
+		return [][]string{{"repack", "-A", "-d", "--write-bitmap-index"}}
+
+	case maintenanceMultiPackIndex:
+		return [][]string{multiPackIndexWriteArgs()}
+
+	case maintenanceCommitGraph:
+		args := []string{"commit-graph", "write", "--reachable", "--split"}
+		if gitVersionAtLeast(2, 27) {
+			args = append(args, "--changed-paths")
+		}
+		return [][]string{args}
+	}
+	return nil
+}
+
+func multiPackIndexWriteArgs() []string {
+	if gitVersionAtLeast(2, 34) {
+		return []string{"multi-pack-index", "write", "--bitmap"}
+	}
+	return []string{"multi-pack-index", "write"}
+}
+
+// maintenanceResult is the outcome of running a maintenance task.
+type maintenanceResult struct {
+	Task string
+	Err  error
+}
+
+// maintainRepo runs the maintenance tasks due for the repository dir with
+// object statistics stats.
+func maintainRepo(ctx context.Context, dir GitDir, stats objectStats) ([]maintenanceResult, error) {
+	state, err := getMaintenanceState(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if _, ok := state.LastSuccess[maintenanceFullRepack]; !ok {
+		if err := setMaintenanceTime(dir, maintenanceFullRepack, true, now); err != nil {
+			return nil, err
+		}
+		state.LastSuccess[maintenanceFullRepack] = now
+	}
+
+	lastFetched, err := repoLastFetched(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []maintenanceResult
+	for _, task := range maintenanceTasks(dir, stats, state, lastFetched, now) {
+		start := time.Now()
+		err := runMaintenanceTask(ctx, dir, task, stats)
+		maintenanceTaskDuration.WithLabelValues(task, strconv.FormatBool(err == nil)).Observe(time.Since(start).Seconds())
+		if err != nil {
+			log15.Error("maintenance task failed", "task", task, "repo", dir, "error", err)
+		}
+		if err := setMaintenanceTime(dir, task, err == nil, time.Now()); err != nil {
+			return results, err
+		}
+		results = append(results, maintenanceResult{Task: task, Err: err})
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+func runMaintenanceTask(ctx context.Context, dir GitDir, task string, stats objectStats) error {
+	ctx, cancel := context.WithTimeout(ctx, conf.GitLongCommandTimeout())
+	defer cancel()
+
+	for _, args := range maintenanceTaskCommands(task, stats) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		dir.Set(cmd)
+		if _, err := cmd.Output(); err != nil {
+			return errors.Wrapf(wrapCmdError(cmd, err), "failed to run maintenance task %s", task)
+		}
+	}
+	return nil
+}
+
+var (
+	gitVersionOnce  sync.Once
+	gitVersionMajor int
+	gitVersionMinor int
+)
+
+var gitVersionRe = lazyregexp.New(`^git version (\d+)\.(\d+)`)
+
+// gitVersionAtLeast reports whether the installed git is at least version
+// major.minor.
+func gitVersionAtLeast(major, minor int) bool {
+	gitVersionOnce.Do(func() {
+		out, err := exec.Command("git", "version").Output()
+		if err != nil {
+			log15.Warn("failed to determine git version", "error", err)
+			return
+		}
+		m := gitVersionRe.FindSubmatch(out)
+		if m == nil {
+			log15.Warn("failed to parse git version", "version", string(out))
+			return
+		}
+		gitVersionMajor, _ = strconv.Atoi(string(m[1]))
+		gitVersionMinor, _ = strconv.Atoi(string(m[2]))
+	})
+	return gitVersionMajor > major || (gitVersionMajor == major && gitVersionMinor >= minor)
+}
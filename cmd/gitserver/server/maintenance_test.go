@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMaintenanceTasks(t *testing.T) {
+	now := time.Now()
+	dir := GitDir("/repos/a/.git")
+	recently := map[string]time.Time{
+		maintenanceFullRepack:  now.Add(-time.Hour),
+		maintenanceCommitGraph: now.Add(-time.Hour),
+	}
+	fetched := now.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		stats       objectStats
+		state       maintenanceState
+		lastFetched time.Time
+		want        []string
+	}{{
+		name:  "empty",
+		stats: objectStats{HasBitmap: true},
+		state: maintenanceState{LastSuccess: recently},
+	}, {
+		name:        "maintained",
+		stats:       objectStats{PackSizes: []int64{100}, HasBitmap: true, HasCommitGraph: true},
+		state:       maintenanceState{LastSuccess: recently},
+		lastFetched: now.Add(-2 * time.Hour),
+	}, {
+		name:        "missing commit-graph",
+		stats:       objectStats{PackSizes: []int64{100}, HasBitmap: true},
+		state:       maintenanceState{LastSuccess: recently},
+		lastFetched: now.Add(-2 * time.Hour),
+		want:        []string{maintenanceCommitGraph},
+	}, {
+		name:  "fetched since commit-graph",
+		stats: objectStats{PackSizes: []int64{100}, HasBitmap: true, HasCommitGraph: true},
+		state: maintenanceState{LastSuccess: map[string]time.Time{
+			maintenanceFullRepack:  now.Add(-time.Hour),
+			maintenanceCommitGraph: now.Add(-2 * time.Hour),
+		}},
+		lastFetched: fetched,
+		want:        []string{maintenanceCommitGraph},
+	}, {
+		name:  "fetched soon after commit-graph",
+		stats: objectStats{PackSizes: []int64{100}, HasBitmap: true, HasCommitGraph: true},
+		state: maintenanceState{LastSuccess: map[string]time.Time{
+			maintenanceFullRepack:  now.Add(-time.Hour),
+			maintenanceCommitGraph: now.Add(-30 * time.Minute),
+		}},
+		lastFetched: fetched,
+	}, {
+		name:        "loose objects",
+		stats:       objectStats{LooseObjects: 2000, PackSizes: []int64{100}, HasBitmap: true, HasCommitGraph: true},
+		state:       maintenanceState{LastSuccess: recently},
+		lastFetched: now.Add(-2 * time.Hour),
+		want:        []string{maintenanceLooseObjects, maintenanceMultiPackIndex},
+	}, {
+		name:        "stale multi-pack-index",
+		stats:       objectStats{PackSizes: []int64{100, 10}, NewestPack: now, MultiPackIndex: now.Add(-time.Minute), HasBitmap: true, HasCommitGraph: true},
+		state:       maintenanceState{LastSuccess: recently},
+		lastFetched: now.Add(-2 * time.Hour),
+		want:        []string{maintenanceMultiPackIndex},
+	}, {
+		name:        "many packs",
+		stats:       objectStats{PackSizes: make([]int64, 10), HasBitmap: true, HasCommitGraph: true},
+		state:       maintenanceState{LastSuccess: recently},
+		lastFetched: now.Add(-2 * time.Hour),
+		want:        []string{maintenanceIncrementalRepack},
+	}, {
+		name:        "too many packs",
+		stats:       objectStats{LooseObjects: 2000, PackSizes: make([]int64, 100), HasBitmap: true, HasCommitGraph: true},
+		state:       maintenanceState{LastSuccess: recently},
+		lastFetched: now.Add(-2 * time.Hour),
+		want:        []string{maintenanceFullRepack},
+	}, {
+		name:  "old full repack",
+		stats: objectStats{PackSizes: []int64{100}, HasCommitGraph: true},
+		state: maintenanceState{LastSuccess: map[string]time.Time{
+			maintenanceFullRepack:  now.Add(-30 * 24 * time.Hour),
+			maintenanceCommitGraph: now.Add(-time.Hour),
+		}},
+		lastFetched: now.Add(-2 * time.Hour),
+		want:        []string{maintenanceFullRepack},
+	}, {
+		name:  "old full repack with bitmap",
+		stats: objectStats{PackSizes: []int64{100}, HasBitmap: true, HasCommitGraph: true},
+		state: maintenanceState{LastSuccess: map[string]time.Time{
+			maintenanceFullRepack:  now.Add(-30 * 24 * time.Hour),
+			maintenanceCommitGraph: now.Add(-time.Hour),
+		}},
+		lastFetched: now.Add(-2 * time.Hour),
+	}, {
+		name:  "recently failed",
+		stats: objectStats{PackSizes: []int64{100}, HasBitmap: true},
+		state: maintenanceState{
+			LastSuccess: recently,
+			LastFailure: map[string]time.Time{maintenanceCommitGraph: now.Add(-time.Minute)},
+		},
+		lastFetched: fetched,
+	}, {
+		name:  "failed a while ago",
+		stats: objectStats{PackSizes: []int64{100}, HasBitmap: true},
+		state: maintenanceState{
+			LastSuccess: recently,
+			LastFailure: map[string]time.Time{maintenanceCommitGraph: now.Add(-2 * time.Hour)},
+		},
+		lastFetched: fetched,
+		want:        []string{maintenanceCommitGraph},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have := maintenanceTasks(dir, tc.stats, tc.state, tc.lastFetched, now)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatalf("unexpected tasks (-want +have):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMaintainRepo(t *testing.T) {
+	ctx := context.Background()
+
+	remote := t.TempDir()
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, remote, name, arg...)
+	}
+	cmd("git", "init", ".")
+	// Create a few packfiles, so that we write a multi-pack-index.
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(filepath.Join(remote, "file"), []byte(strconv.Itoa(i)), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cmd("git", "add", "file")
+		cmd("git", "commit", "-m", "commit")
+		cmd("git", "repack", "-d")
+	}
+	dir := GitDir(filepath.Join(remote, ".git"))
+
+	stats, err := getObjectStats(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.PackSizes) != 3 || stats.HasCommitGraph || !stats.MultiPackIndex.IsZero() {
+		t.Fatalf("unexpected object stats %+v", stats)
+	}
+
+	results, err := maintainRepo(ctx, dir, stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []maintenanceResult{{Task: maintenanceMultiPackIndex}, {Task: maintenanceCommitGraph}}
+	if diff := cmp.Diff(want, results, cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Fatalf("unexpected results (-want +have):\n%s", diff)
+	}
+
+	stats, err = getObjectStats(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.HasCommitGraph || stats.MultiPackIndex.IsZero() {
+		t.Fatalf("expected commit-graph and multi-pack-index, have %+v", stats)
+	}
+	if _, err := os.Stat(dir.Path("objects", "info", "commit-graphs", "commit-graph-chain")); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := getMaintenanceState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range []string{maintenanceFullRepack, maintenanceMultiPackIndex, maintenanceCommitGraph} {
+		if state.LastSuccess[task].IsZero() {
+			t.Errorf("expected last success of %s to be recorded", task)
+		}
+	}
+
+	// Nothing is left to do.
+	results, err = maintainRepo(ctx, dir, stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected no tasks to run, have %+v", results)
+	}
+}
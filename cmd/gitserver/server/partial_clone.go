@@ -0,0 +1,375 @@
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// promisorRemote is the name of the remote missing objects of partial clones
+// are fetched from. Its URL is never written to the Git config, since it may
+// contain credentials. Instead, it is passed to every command which may need
+// to fetch missing objects.
+const promisorRemote = "origin"
+
+// fetchMissingBlobsBatchSize is the number of blobs fetched by a single git
+// fetch, to bound the length of its command line.
+const fetchMissingBlobsBatchSize = 1000
+
+var gitPartialClone = conf.Cached(func() interface{} {
+	return buildPartialCloneMappings(conf.Get().ExperimentalFeatures.GitPartialClone)
+})
+
+func buildPartialCloneMappings(c []*schema.GitPartialCloneMapping) map[string]string {
+	pcm := map[string]string{}
+	for _, mapping := range c {
+		pcm[strings.Trim(mapping.DomainPath, "/")] = mapping.Filter
+	}
+	return pcm
+}
+
+// partialCloneFilter returns the filter to clone remoteURL with, or the empty
+// string if it should be fully cloned. The mapping with the longest domain/path
+// prefix of the remote URL wins.
+func partialCloneFilter(remoteURL *vcs.URL) string {
+	pcm := gitPartialClone().(map[string]string)
+	if len(pcm) == 0 {
+		return ""
+	}
+
+	dp := path.Join(remoteURL.Host, remoteURL.Path)
+	dp = strings.TrimSuffix(strings.Trim(dp, "/"), ".git")
+	for p := dp; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if filter, ok := pcm[p]; ok {
+			return filter
+		}
+	}
+	return ""
+}
+
+// isPartialClone returns true if dir is a partial clone. Objects fetched from
+// a promisor remote are stored in packs with a .promisor file, which git keeps
+// when repacking.
+func isPartialClone(dir GitDir) bool {
+	matches, _ := filepath.Glob(dir.Path("objects", "pack", "*.promisor"))
+	return len(matches) > 0
+}
+
+// setupPartialClone configures the empty repository at dir to be a partial
+// clone with filter, so that the blobs excluded by filter are fetched on
+// demand from the promisor remote.
+func setupPartialClone(dir GitDir, filter string) error {
+	for _, kv := range [][2]string{
+		{"core.repositoryformatversion", "1"},
+		{"extensions.partialClone", promisorRemote},
+		{"remote." + promisorRemote + ".promisor", "true"},
+		{"remote." + promisorRemote + ".partialclonefilter", filter},
+	} {
+		if err := gitConfigSet(dir, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addConfigParameter sets the Git config key to value for cmd through the
+// environment, so that it is neither written to disk nor visible in the
+// arguments of the process.
+func addConfigParameter(cmd *exec.Cmd, key, value string) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	param := sqQuote(key) + "=" + sqQuote(value)
+	for i, kv := range cmd.Env {
+		if strings.HasPrefix(kv, "GIT_CONFIG_PARAMETERS=") {
+			cmd.Env[i] = kv + " " + param
+			return
+		}
+	}
+	cmd.Env = append(cmd.Env, "GIT_CONFIG_PARAMETERS="+param)
+}
+
+// sqQuote quotes s for the shell, as git expects in GIT_CONFIG_PARAMETERS.
+func sqQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// configurePromisorRemote configures cmd to fetch missing objects from
+// remoteURL.
+func configurePromisorRemote(cmd *exec.Cmd, remoteURL *vcs.URL) {
+	addConfigParameter(cmd, "remote."+promisorRemote+".url", remoteURL.String())
+}
+
+// configureLazyFetch configures cmd, which runs in a partial clone, to fetch
+// the missing objects it reads on demand.
+func (s *Server) configureLazyFetch(ctx context.Context, repo api.RepoName, cmd *exec.Cmd) {
+	remoteURL, err := s.getRemoteURL(ctx, repo)
+	if err != nil {
+		// Reading missing objects fails with a clear error, and everything
+		// else still works.
+		log15.Warn("failed to get remote URL of partial clone", "repo", repo, "error", err)
+		return
+	}
+	configurePromisorRemote(cmd, remoteURL)
+	configureRemoteGitCommand(cmd, tlsExternal().(*tlsConfig))
+}
+
+// allowMissingPromisorObjects configures cmd, a git upload-pack in a partial
+// clone, to leave out the objects which have not been fetched from the packs it
+// sends instead of fetching them. Fetches with a filter excluding them, such as
+// the ones of zoekt-indexserver, then succeed without fetching anything.
+func allowMissingPromisorObjects(cmd *exec.Cmd) {
+	extraArgs := []string{
+		// The hook is run with the pack-objects command as arguments.
+		"-c", `uploadpack.packObjectsHook=f() { "$@" --missing=allow-promisor; }; f`,
+	}
+	cmd.Args = append(cmd.Args[:1], append(extraArgs, cmd.Args[1:]...)...)
+}
+
+// missingBlob is a blob which has not been fetched in a partial clone.
+type missingBlob struct {
+	oid  string
+	mode int64
+	path string
+}
+
+// listMissingBlobs returns the blobs of the tree treeish which are missing from
+// the partial clone at dir, restricted to paths if non-empty. It never fetches
+// any objects.
+func listMissingBlobs(ctx context.Context, dir GitDir, treeish string, paths []string) ([]missingBlob, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--objects", "--no-walk", "--missing=print", treeish, "--")
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(wrapCmdError(cmd, err), "listing missing objects")
+	}
+
+	missing := map[string]struct{}{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if oid := strings.TrimPrefix(sc.Text(), "?"); oid != sc.Text() {
+			missing[oid] = struct{}{}
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	// Only trees are listed, so missing blobs are not fetched.
+	cmd = exec.CommandContext(ctx, "git", append([]string{"ls-tree", "-r", "-z", "--full-tree", treeish, "--"}, paths...)...)
+	dir.Set(cmd)
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(wrapCmdError(cmd, err), "listing tree")
+	}
+
+	var blobs []missingBlob
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		// <mode> SP <type> SP <object> TAB <file>
+		tab := bytes.IndexByte(entry, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields, name := strings.Fields(string(entry[:tab])), string(entry[tab+1:])
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		if _, ok := missing[fields[2]]; !ok {
+			continue
+		}
+		mode, err := strconv.ParseInt(fields[0], 8, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing mode of %q", name)
+		}
+		blobs = append(blobs, missingBlob{oid: fields[2], mode: mode, path: name})
+	}
+	return blobs, nil
+}
+
+// fetchMissingBlobs fetches blobs from the promisor remote of the partial
+// clone at dir. It batches what git would otherwise fetch one blob at a time.
+func fetchMissingBlobs(ctx context.Context, dir GitDir, remoteURL *vcs.URL, blobs []missingBlob) error {
+	for len(blobs) > 0 {
+		n := len(blobs)
+		if n > fetchMissingBlobsBatchSize {
+			n = fetchMissingBlobsBatchSize
+		}
+
+		// The arguments match the ones git uses when fetching missing
+		// objects itself.
+		args := []string{"-c", "fetch.negotiationAlgorithm=noop", "fetch", promisorRemote, "--no-tags", "--recurse-submodules=no", "--filter=blob:none"}
+		for _, b := range blobs[:n] {
+			args = append(args, b.oid)
+		}
+		cmd := exec.CommandContext(ctx, "git", args...)
+		configurePromisorRemote(cmd, remoteURL)
+		dir.Set(cmd)
+		if output, err := runWith(ctx, cmd, true, nil); err != nil {
+			return errors.Wrapf(err, "failed to fetch missing blobs with output %q", newURLRedactor(remoteURL).redact(string(output)))
+		}
+
+		blobs = blobs[n:]
+	}
+	return nil
+}
+
+// exportIgnoreAttributes returns a gitattributes file which excludes blobs from
+// git archive.
+func exportIgnoreAttributes(blobs []missingBlob) []byte {
+	var b bytes.Buffer
+	for _, blob := range blobs {
+		fmt.Fprintf(&b, "%s export-ignore\n", cQuote("/"+escapeGlob(blob.path)))
+	}
+	return b.Bytes()
+}
+
+// escapeGlob escapes the characters of path which have a special meaning in
+// gitattributes patterns.
+func escapeGlob(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		switch c {
+		case '*', '?', '[', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// cQuote quotes s in the C style git understands in gitattributes, so that
+// patterns may contain whitespace.
+func cQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// appendMissingBlobs copies the tar archive read from r to w, and appends an
+// entry without content for every missing blob to it.
+func appendMissingBlobs(w io.Writer, r io.Reader, blobs []missingBlob) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range blobs {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag:   tar.TypeReg,
+			Name:       b.path,
+			Mode:       b.mode & 0777,
+			Format:     tar.FormatPAX,
+			PAXRecords: map[string]string{protocol.MissingBlobPAXRecord: b.oid},
+		}); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+var missingBlobsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_archive_missing_blobs_total",
+	Help: "The number of blobs missing from partial clones when creating archives, by whether they were skipped instead of fetched.",
+}, []string{"skipped"})
+
+// archiveMissingBlobsHook returns an execHook for git archive in partial
+// clones. If skip is true, the missing blobs are excluded from the archive,
+// and tar archives get an entry without content for each of them. Otherwise
+// they are fetched in batch before the archive is created.
+func (s *Server) archiveMissingBlobsHook(repo api.RepoName, treeish, format string, paths []string, skip bool) execHook {
+	return func(ctx context.Context, dir GitDir, cmd *exec.Cmd) func() error {
+		if !isPartialClone(dir) {
+			return nil
+		}
+
+		blobs, err := listMissingBlobs(ctx, dir, treeish, paths)
+		if err != nil {
+			log15.Warn("failed to list missing blobs of partial clone", "repo", repo, "treeish", treeish, "error", err)
+			return nil
+		}
+		if len(blobs) == 0 {
+			return nil
+		}
+		missingBlobsCounter.WithLabelValues(strconv.FormatBool(skip)).Add(float64(len(blobs)))
+
+		if !skip {
+			remoteURL, err := s.getRemoteURL(ctx, repo)
+			if err == nil {
+				err = fetchMissingBlobs(ctx, dir, remoteURL, blobs)
+			}
+			if err != nil {
+				// git archive falls back to fetching the blobs one at a time.
+				log15.Warn("failed to fetch missing blobs of partial clone", "repo", repo, "treeish", treeish, "error", err)
+			}
+			return nil
+		}
+
+		attributesFile, err := writeTempFile("missing-blobs-attributes", exportIgnoreAttributes(blobs))
+		if err != nil {
+			log15.Warn("failed to write attributes to skip missing blobs", "repo", repo, "error", err)
+			return nil
+		}
+		addConfigParameter(cmd, "core.attributesFile", attributesFile)
+
+		var fw *filterWriter
+		if format == "tar" {
+			fw = newFilterWriter(cmd.Stdout, func(w io.Writer, r io.Reader) error {
+				return appendMissingBlobs(w, r, blobs)
+			})
+			cmd.Stdout = fw
+		}
+
+		return func() error {
+			_ = os.Remove(attributesFile)
+			if fw != nil {
+				return fw.Close()
+			}
+			return nil
+		}
+	}
+}
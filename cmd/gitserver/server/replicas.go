@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// Hot repositories are replicated to additional gitservers, which serve
+// read-only requests for them (see gitserver.Client.addrForRead). A replica is
+// cloned from the primary gitserver of the repository when it is first read,
+// and the primary gitserver requests its replicas to fetch from it after each
+// update. Replicas which have not been read for a while are removed by the
+// janitor, since their repository is no longer hot. Only the primary gitserver
+// of a repository records its state in the database.
+
+// replicaPrimary returns the address of the primary gitserver of repo if this
+// gitserver holds a replica of it, or the empty string otherwise.
+func (s *Server) replicaPrimary(repo api.RepoName) string {
+	cfg := gitserver.ReplicasConfig()
+	if cfg == nil {
+		return ""
+	}
+	addrs := conf.Get().ServiceConnections.GitServers
+	if len(addrs) < 2 {
+		return ""
+	}
+	primary := gitserver.AddrForRepo(repo, addrs)
+	if s.hostnameMatch(primary) {
+		return ""
+	}
+	for _, addr := range gitserver.ReplicaAddrsForRepo(repo, addrs, cfg.Replicas) {
+		if s.hostnameMatch(addr) {
+			return primary
+		}
+	}
+	return ""
+}
+
+// isReplica reports whether this gitserver holds a replica of repo. A clone
+// of a replica remains one until it is removed, even if the replicas have
+// been reconfigured since it was cloned.
+func (s *Server) isReplica(repo api.RepoName) bool {
+	return s.replicaPrimary(repo) != "" || isReplicaDir(s.dir(repo))
+}
+
+// idleReplica reports whether dir is the clone of a replica which has not been
+// read for the configured number of idle days at now. Replicas of repositories
+// which are always replicated are never idle.
+func (s *Server) idleReplica(dir GitDir, now time.Time) (bool, error) {
+	cfg := gitserver.ReplicasConfig()
+	if cfg == nil || !isReplicaDir(dir) || gitserver.AlwaysReplicated(cfg, s.name(dir)) {
+		return false, nil
+	}
+	lastAccess, err := repoLastAccessed(dir)
+	if err != nil {
+		return false, err
+	}
+	idleDays := cfg.IdleDays
+	if idleDays <= 0 {
+		idleDays = 7
+	}
+	return now.Sub(lastAccess) > time.Duration(idleDays)*24*time.Hour, nil
+}
+
+// isReplicaDir reports whether dir is the clone of a replica.
+func isReplicaDir(dir GitDir) bool {
+	val, _ := gitConfigGet(dir, "sourcegraph.replica")
+	return strings.TrimSpace(val) == "true"
+}
+
+// setReplica marks dir as the clone of a replica.
+func setReplica(dir GitDir) error {
+	return gitConfigSet(dir, "sourcegraph.replica", "true")
+}
+
+// getSyncRemote returns the remote URL and VCS syncer with which repo is
+// cloned and fetched. A replica is synced from the primary gitserver of the
+// repository instead of the code host.
+func (s *Server) getSyncRemote(ctx context.Context, repo api.RepoName) (*vcs.URL, VCSSyncer, error) {
+	if primary := s.replicaPrimary(repo); primary != "" {
+		remoteURL, err := vcs.ParseURL("http://" + primary + "/git/" + string(repo))
+		if err != nil {
+			return nil, nil, err
+		}
+		return remoteURL, &GitRepoSyncer{}, nil
+	}
+
+	syncer, err := s.GetVCSSyncer(ctx, repo)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get VCS syncer")
+	}
+	remoteURL, err := s.getRemoteURL(ctx, repo)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to determine Git remote URL")
+	}
+	return remoteURL, syncer, nil
+}
+
+// updateReplicas requests the replicas of repo to fetch from this gitserver,
+// if it is the primary gitserver of repo. Replicas which have not been cloned
+// yet are only cloned if repo is always replicated, since other repositories
+// are only replicated while they are hot. Idle replicas are not fetched.
+func (s *Server) updateReplicas(repo api.RepoName) {
+	cfg := gitserver.ReplicasConfig()
+	if cfg == nil {
+		return
+	}
+	addrs := conf.Get().ServiceConnections.GitServers
+	if len(addrs) < 2 || !s.hostnameMatch(gitserver.AddrForRepo(repo, addrs)) {
+		return
+	}
+	clone := gitserver.AlwaysReplicated(cfg, repo)
+
+	for _, addr := range gitserver.ReplicaAddrsForRepo(repo, addrs, cfg.Replicas) {
+		addr := addr
+		ctx, cancel := s.serverContext()
+		go func() {
+			defer cancel()
+			ctx, cancel := context.WithTimeout(actor.WithInternalActor(ctx), conf.GitLongCommandTimeout()+time.Minute)
+			defer cancel()
+			if err := gitserver.DefaultClient.UpdateReplica(ctx, addr, repo, clone); err != nil {
+				log15.Warn("failed to update replica", "repo", repo, "replica", addr, "error", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func mockReplicas(addrs []string, replicas int) {
+	conf.Mock(&conf.Unified{
+		SiteConfiguration: schema.SiteConfiguration{
+			ExperimentalFeatures: &schema.ExperimentalFeatures{
+				GitServerReplicas: &schema.GitServerReplicas{Replicas: replicas},
+			},
+		},
+		ServiceConnections: conftypes.ServiceConnections{GitServers: addrs},
+	})
+}
+
+func TestReplicaPrimary(t *testing.T) {
+	addrs := []string{"gitserver-0:3178", "gitserver-1:3178", "gitserver-2:3178"}
+	mockReplicas(addrs, 1)
+	defer conf.Mock(nil)
+
+	const repo = api.RepoName("github.com/foo/bar")
+	primary := gitserver.AddrForRepo(repo, addrs)
+	replica := gitserver.ReplicaAddrsForRepo(repo, addrs, 1)[0]
+	hostname := func(addr string) string { return strings.Split(addr, ":")[0] }
+
+	for _, addr := range addrs {
+		s := &Server{Hostname: hostname(addr), ReposDir: t.TempDir()}
+		want := ""
+		if addr == replica {
+			want = primary
+		}
+		if have := s.replicaPrimary(repo); have != want {
+			t.Errorf("%s: have primary %q, want %q", addr, have, want)
+		}
+	}
+
+	s := &Server{Hostname: hostname(replica)}
+	remoteURL, syncer, err := s.getSyncRemote(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://" + primary + "/git/" + string(repo); remoteURL.String() != want {
+		t.Errorf("have remote URL %q, want %q", remoteURL, want)
+	}
+	if syncer.Type() != "git" {
+		t.Errorf("have syncer %q, want git", syncer.Type())
+	}
+
+	// Replicas are disabled.
+	mockReplicas(addrs, 0)
+	if have := s.replicaPrimary(repo); have != "" {
+		t.Errorf("have primary %q, want none", have)
+	}
+}
+
+func TestCleanup_removeStaleReplica(t *testing.T) {
+	addrs := []string{"gitserver-0:3178", "gitserver-1:3178", "gitserver-2:3178"}
+	mockReplicas(addrs, 1)
+	defer conf.Mock(nil)
+
+	// Find a repository replicated to gitserver-0 and one which isn't.
+	var replicated, stale api.RepoName
+	for i := 0; replicated == "" || stale == ""; i++ {
+		repo := api.RepoName(fmt.Sprintf("github.com/foo/bar%d", i))
+		if gitserver.AddrForRepo(repo, addrs) == addrs[0] {
+			continue
+		}
+		if gitserver.ReplicaAddrsForRepo(repo, addrs, 1)[0] == addrs[0] {
+			replicated = repo
+		} else {
+			stale = repo
+		}
+	}
+
+	root := t.TempDir()
+	for _, repo := range []api.RepoName{replicated, stale} {
+		dir := filepath.Join(root, string(repo), ".git")
+		if err := exec.Command("git", "--bare", "init", dir).Run(); err != nil {
+			t.Fatal(err)
+		}
+		if err := setReplica(GitDir(dir)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Server{ReposDir: root, Hostname: "gitserver-0"}
+	s.Handler() // Handler as a side-effect sets up Server
+	s.cleanupRepos()
+
+	if _, err := os.Stat(filepath.Join(root, string(replicated))); err != nil {
+		t.Errorf("expected replica of %s not to be removed: %s", replicated, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, string(stale))); !os.IsNotExist(err) {
+		t.Errorf("expected stale replica of %s to be removed", stale)
+	}
+}
+
+func TestCleanup_removeIdleReplica(t *testing.T) {
+	addrs := []string{"gitserver-0:3178", "gitserver-1:3178", "gitserver-2:3178"}
+
+	// Find three repositories replicated to gitserver-0.
+	var repos []api.RepoName
+	for i := 0; len(repos) < 3; i++ {
+		repo := api.RepoName(fmt.Sprintf("github.com/foo/bar%d", i))
+		if gitserver.AddrForRepo(repo, addrs) != addrs[0] && gitserver.ReplicaAddrsForRepo(repo, addrs, 1)[0] == addrs[0] {
+			repos = append(repos, repo)
+		}
+	}
+	hot, cold, always := repos[0], repos[1], repos[2]
+
+	conf.Mock(&conf.Unified{
+		SiteConfiguration: schema.SiteConfiguration{
+			ExperimentalFeatures: &schema.ExperimentalFeatures{
+				GitServerReplicas: &schema.GitServerReplicas{
+					Replicas: 1,
+					Repos:    []string{string(always)},
+					IdleDays: 2,
+				},
+			},
+		},
+		ServiceConnections: conftypes.ServiceConnections{GitServers: addrs},
+	})
+	defer conf.Mock(nil)
+
+	root := t.TempDir()
+	now := time.Now()
+	lastAccess := map[api.RepoName]time.Time{
+		hot:    now.Add(-time.Hour),
+		cold:   now.Add(-72 * time.Hour),
+		always: now.Add(-72 * time.Hour),
+	}
+	for repo, at := range lastAccess {
+		dir := GitDir(filepath.Join(root, string(repo), ".git"))
+		if err := exec.Command("git", "--bare", "init", string(dir)).Run(); err != nil {
+			t.Fatal(err)
+		}
+		if err := setReplica(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := markAccessed(dir, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Server{ReposDir: root, Hostname: "gitserver-0"}
+	s.Handler() // Handler as a side-effect sets up Server
+
+	// Idle replicas are not fetched anymore.
+	for repo := range lastAccess {
+		idle, err := s.idleReplica(s.dir(repo), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := repo == cold; idle != want {
+			t.Errorf("%s: have idle %t, want %t", repo, idle, want)
+		}
+	}
+
+	s.cleanupRepos()
+
+	for _, repo := range []api.RepoName{hot, always} {
+		if _, err := os.Stat(filepath.Join(root, string(repo))); err != nil {
+			t.Errorf("expected replica of %s not to be removed: %s", repo, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, string(cold))); !os.IsNotExist(err) {
+		t.Errorf("expected idle replica of %s to be removed", cold)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/errors"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/inconshreveable/log15"
 	"github.com/opentracing/opentracing-go/ext"
 	otlog "github.com/opentracing/opentracing-go/log"
@@ -47,7 +48,6 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/search"
 	"github.com/sourcegraph/sourcegraph/internal/honey"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
-	"github.com/sourcegraph/sourcegraph/internal/mutablelimiter"
 	"github.com/sourcegraph/sourcegraph/internal/repotrackutil"
 	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
@@ -205,6 +205,13 @@ type Server struct {
 	// usually set to return a GitRepoSyncer.
 	GetVCSSyncer func(context.Context, api.RepoName) (VCSSyncer, error)
 
+	// GetCodeHostLimitsFunc is a function which returns the limits of the
+	// code host connection a repository is synced from, which apply to its
+	// clones and fetches. In production this will speak to the database to
+	// look up the external service config. If nil, all repositories share
+	// the same limits.
+	GetCodeHostLimitsFunc func(context.Context, api.RepoName) (CodeHostLimits, error)
+
 	// Hostname is how we identify this instance of gitserver. Generally it is the
 	// actual hostname but can also be overridden by the HOSTNAME environment variable.
 	Hostname string
@@ -231,14 +238,20 @@ type Server struct {
 	locker *RepositoryLocker
 
 	// cloneLimiter and cloneableLimiter limits the number of concurrent
-	// clones and ls-remotes respectively. Use s.acquireCloneLimiter() and
-	// s.acquireClonableLimiter() instead of using these directly.
-	cloneLimiter     *mutablelimiter.Limiter
-	cloneableLimiter *mutablelimiter.Limiter
+	// clones and ls-remotes respectively, overall and per code host. Use
+	// s.acquireCloneLimiter() and s.acquireClonableLimiter() instead of using
+	// these directly.
+	cloneLimiter     *codeHostLimiter
+	cloneableLimiter *codeHostLimiter
+
+	// rpsLimiters limits the remote code host git operations done per second
+	// per code host and gitserver instance
+	rpsLimiters *codeHostRateLimiters
 
-	// rpsLimiter limits the remote code host git operations done per second
-	// per gitserver instance
-	rpsLimiter *rate.Limiter
+	// codeHostLimitsCache caches the results of GetCodeHostLimitsFunc, which
+	// is called for every clone and fetch. Use s.codeHostLimits() instead of
+	// using it directly.
+	codeHostLimitsCache *lru.Cache
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
@@ -266,6 +279,20 @@ func shortGitCommandTimeout(args []string) time.Duration {
 		// a larger timeout.
 		return conf.GitLongCommandTimeout()
 
+	case "blame":
+		// An incremental blame streams its results as they are computed, so
+		// a user sees progress long before a large file with a long history
+		// is completely blamed.
+		for _, arg := range args[1:] {
+			if arg == "--" {
+				break
+			}
+			if arg == "--incremental" {
+				return conf.GitLongCommandTimeout()
+			}
+		}
+		return time.Minute
+
 	case "ls-remote":
 		return 30 * time.Second
 
@@ -306,36 +333,20 @@ func (s *Server) Handler() http.Handler {
 	// The new repo-updater scheduler enforces the rate limit across all gitserver,
 	// so ideally this logic could be removed here; however, ensureRevision can also
 	// cause an update to happen and it is called on every exec command.
+	//
+	// Each code host connection can further limit its clones with
+	// gitMaxConcurrentClones in its config.
 	maxConcurrentClones := conf.GitMaxConcurrentClones()
-	s.cloneLimiter = mutablelimiter.New(maxConcurrentClones)
-	s.cloneableLimiter = mutablelimiter.New(maxConcurrentClones)
+	s.cloneLimiter = newCodeHostLimiter("clone", maxConcurrentClones)
+	s.cloneableLimiter = newCodeHostLimiter("lsremote", maxConcurrentClones)
 	conf.Watch(func() {
 		limit := conf.GitMaxConcurrentClones()
 		s.cloneLimiter.SetLimit(limit)
 		s.cloneableLimiter.SetLimit(limit)
 	})
 
-	s.rpsLimiter = rate.NewLimiter(rate.Inf, 10)
-	setRPSLimiter := func() {
-		if maxRequestsPerSecond := conf.GitMaxCodehostRequestsPerSecond(); maxRequestsPerSecond == -1 {
-			// As a special case, -1 means no limiting
-			s.rpsLimiter.SetLimit(rate.Inf)
-			s.rpsLimiter.SetBurst(10)
-		} else if maxRequestsPerSecond == 0 {
-			// A limiter with zero limit but a non-zero burst is not rejecting all events
-			// because the bucket is initially full with N tokens and refilled N tokens
-			// every second, where N is the burst size. See
-			// https://github.com/golang/go/issues/18763 for details.
-			s.rpsLimiter.SetLimit(0)
-			s.rpsLimiter.SetBurst(0)
-		} else {
-			s.rpsLimiter.SetLimit(rate.Limit(maxRequestsPerSecond))
-			s.rpsLimiter.SetBurst(10)
-		}
-	}
-	conf.Watch(func() {
-		setRPSLimiter()
-	})
+	s.rpsLimiters = newCodeHostRateLimiters()
+	s.codeHostLimitsCache, _ = lru.New(maxCachedCodeHostLimits)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/archive", s.handleArchive)
@@ -387,6 +398,8 @@ func (s *Server) Handler() http.Handler {
 func (s *Server) Janitor(interval time.Duration) {
 	for {
 		s.cleanupRepos()
+		s.evictLFSObjects()
+		s.evictCommandCache()
 		time.Sleep(interval)
 	}
 }
@@ -453,25 +466,36 @@ func (s *Server) cloneJobProducer(ctx context.Context, jobs chan<- *cloneJob) {
 	}
 }
 
+// maxStartedCloneJobs is the maximum number of clone jobs taken off the clone
+// queue which are waiting for the clone limiter or cloning. Once reached, jobs
+// stay in the queue until a clone finishes.
+const maxStartedCloneJobs = 100
+
 func (s *Server) cloneJobConsumer(ctx context.Context, jobs <-chan *cloneJob) {
+	started := make(chan struct{}, maxStartedCloneJobs)
 	for j := range jobs {
 		select {
+		case started <- struct{}{}:
 		case <-ctx.Done():
 			log15.Error("cloneJobConsumer: ", "error", ctx.Err())
+			j.lock.Release()
 			return
-		default:
-		}
-
-		ctx, cancel, err := s.acquireCloneLimiter(ctx)
-		if err != nil {
-			log15.Error("cloneJobConsumer: ", "error", err)
-			continue
 		}
 
+		// Wait for the clone limiter in the goroutine, so that the clones of
+		// a busy code host do not hold up the clones of the others.
 		go func(job *cloneJob) {
+			defer func() { <-started }()
+
+			ctx, cancel, err := s.acquireCloneLimiter(ctx, job.repo)
+			if err != nil {
+				log15.Error("cloneJobConsumer: ", "error", err)
+				job.lock.Release()
+				return
+			}
 			defer cancel()
 
-			err := s.doClone(ctx, job.repo, job.dir, job.syncer, job.lock, job.remoteURL, job.options)
+			err = s.doClone(ctx, job.repo, job.dir, job.syncer, job.lock, job.remoteURL, job.options)
 			if err != nil {
 				log15.Error("failed to clone repo", "repo", job.repo, "error", err)
 			}
@@ -687,11 +711,11 @@ func (s *Server) getRemoteURL(ctx context.Context, name api.RepoName) (*vcs.URL,
 }
 
 // acquireCloneLimiter() acquires a cancellable context associated with the
-// clone limiter.
-func (s *Server) acquireCloneLimiter(ctx context.Context) (context.Context, context.CancelFunc, error) {
+// clone limiter of the code host connection repo is synced from.
+func (s *Server) acquireCloneLimiter(ctx context.Context, repo api.RepoName) (context.Context, context.CancelFunc, error) {
 	pendingClones.Inc()
 	defer pendingClones.Dec()
-	return s.cloneLimiter.Acquire(ctx)
+	return s.cloneLimiter.Acquire(ctx, s.codeHostLimits(ctx, repo))
 }
 
 // queryCloneLimiter reports the capacity and length of the clone limiter's queue
@@ -699,10 +723,20 @@ func (s *Server) queryCloneLimiter() (cap, len int) {
 	return s.cloneLimiter.GetLimit()
 }
 
-func (s *Server) acquireCloneableLimiter(ctx context.Context) (context.Context, context.CancelFunc, error) {
+func (s *Server) acquireCloneableLimiter(ctx context.Context, repo api.RepoName) (context.Context, context.CancelFunc, error) {
 	lsRemoteQueue.Inc()
 	defer lsRemoteQueue.Dec()
-	return s.cloneableLimiter.Acquire(ctx)
+	return s.cloneableLimiter.Acquire(ctx, s.codeHostLimits(ctx, repo))
+}
+
+// waitRPSLimiter blocks until the rate limit of the code host connection repo
+// is synced from allows another remote git operation.
+func (s *Server) waitRPSLimiter(ctx context.Context, repo api.RepoName) error {
+	if s.replicaPrimary(repo) != "" {
+		// Replicas are synced from their primary gitserver, not the code host.
+		return nil
+	}
+	return s.rpsLimiters.Wait(ctx, s.codeHostLimits(ctx, repo))
 }
 
 // tempDir is a wrapper around os.MkdirTemp, but using the server's
@@ -722,11 +756,13 @@ func (s *Server) tempDir(prefix string) (name string, err error) {
 }
 
 func (s *Server) ignorePath(path string) bool {
-	// We ignore any path which starts with .tmp in ReposDir
+	// We ignore any path which starts with .tmp in ReposDir, the LFS object
+	// cache and the command cache.
 	if filepath.Dir(path) != s.ReposDir {
 		return false
 	}
-	return strings.HasPrefix(filepath.Base(path), tempDirName)
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, tempDirName) || base == lfsCacheDirName || base == commandCacheDirName
 }
 
 func (s *Server) handleIsRepoCloneable(w http.ResponseWriter, r *http.Request) {
@@ -813,6 +849,21 @@ func (s *Server) handleRepoUpdate(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel2 := context.WithTimeout(ctx, conf.GitLongCommandTimeout())
 	defer cancel2()
 	resp.QueueCap, resp.QueueLen = s.queryCloneLimiter()
+	if req.SkipClone {
+		// The repository is only updated if it is already cloned. Replicas
+		// are not fetched anymore once they are idle, since the janitor
+		// is going to remove them.
+		idle, err := s.idleReplica(dir, time.Now())
+		if err != nil {
+			log15.Warn("failed to determine whether replica is idle", "repo", req.Repo, "error", err)
+		}
+		if !repoCloned(dir) || idle {
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
 	if !repoCloned(dir) && !s.skipCloneForTests {
 		// optimistically, we assume that our cloning attempt might
 		// succeed.
@@ -911,7 +962,23 @@ func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
 	req.Args = append(req.Args, treeish, "--")
 	req.Args = append(req.Args, paths...)
 
-	s.exec(w, r, req)
+	var submodules map[string]api.RepoName
+	if v := q.Get("submodules"); v != "" {
+		if err := json.Unmarshal([]byte(v), &submodules); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log15.Error("gitserver.archive", "error", "invalid submodules", "submodules", v)
+			return
+		}
+	}
+
+	skipMissingBlobs, _ := strconv.ParseBool(q.Get("skipMissingBlobs"))
+	s.exec(w, r, req,
+		s.archiveMissingBlobsHook(req.Repo, treeish, format, paths, skipMissingBlobs),
+		// Before the LFS hook, so that the LFS objects of submodules are not
+		// resolved with the remote of the superproject.
+		archiveSubmodulesHook(req.Repo, treeish, format, submodules),
+		s.archiveLFSHook(req.Repo, format),
+	)
 }
 
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -981,6 +1048,10 @@ func (s *Server) search(w http.ResponseWriter, r *http.Request, args *protocol.S
 		return
 	}
 
+	if err := markAccessed(dir, time.Now()); err != nil {
+		log15.Warn("failed to record repository access", "repo", args.Repo, "error", err)
+	}
+
 	if !conf.Get().DisableAutoGitUpdates {
 		for _, rev := range args.Revisions {
 			// TODO add result to trace
@@ -1091,10 +1162,18 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	s.exec(w, r, &req)
+	s.exec(w, r, &req, s.showLFSHook(req.Repo, req.Args))
 }
 
-func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.ExecRequest) {
+// execHook is run by exec right before the git command is run in dir, and may
+// modify cmd. If the returned function is non-nil, it is called once the
+// command exited, and its error is reported as the error of the command.
+//
+// Hooks run in order, and the functions they return in reverse order, so that
+// a hook wrapping cmd.Stdout sees the output of the hooks after it.
+type execHook func(ctx context.Context, dir GitDir, cmd *exec.Cmd) func() error
+
+func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.ExecRequest, hooks ...execHook) {
 	// Flush writes more aggressively than standard net/http so that clients
 	// with a context deadline see as much partial response body as possible.
 	if fw := newFlushingResponseWriter(w); fw != nil {
@@ -1279,6 +1358,44 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 		}
 	}
 
+	// The special cases above are excluded, since they are run for every
+	// repository in scope of a search.
+	if err := markAccessed(dir, time.Now()); err != nil {
+		log15.Warn("failed to record repository access", "repo", req.Repo, "error", err)
+	}
+
+	// The output of commands modified by hooks is not cached, since it may
+	// depend on more than the commits the command is run on.
+	maxCacheEntrySize, _, cacheEnabled := commandCacheConfig()
+	for _, hook := range hooks {
+		if hook != nil {
+			cacheEnabled = false
+		}
+	}
+	var cacheKey string
+	if cacheEnabled {
+		cacheKey, _ = commandCacheKey(req.Repo, dir, req.Args)
+	}
+	if cacheKey != "" {
+		cmdName := req.Args[0]
+		if r.Header.Get(protocol.BypassCommandCacheHeader) != "" {
+			commandCacheRequests.WithLabelValues(cmdName, "bypass").Inc()
+		} else if f := s.openCachedCommand(cacheKey); f != nil {
+			defer f.Close()
+			commandCacheRequests.WithLabelValues(cmdName, "hit").Inc()
+			cmdStart = time.Now()
+			stdoutN, execErr = io.Copy(w, f)
+			exitStatus = 0
+			status = "0"
+			w.Header().Set("X-Exec-Error", errorString(execErr))
+			w.Header().Set("X-Exec-Exit-Status", status)
+			w.Header().Set("X-Exec-Stderr", "")
+			return
+		} else {
+			commandCacheRequests.WithLabelValues(cmdName, "miss").Inc()
+		}
+	}
+
 	var stderrBuf bytes.Buffer
 	stdoutW := &writeCounter{w: w}
 	stderrW := &writeCounter{w: &limitWriter{W: &stderrBuf, N: 1024}}
@@ -1289,8 +1406,35 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	cmd.Stdout = stdoutW
 	cmd.Stderr = stderrW
 
+	// Output larger than maxCacheEntrySize is not cached, so it is only
+	// buffered up to one byte more to tell.
+	var cacheBuf bytes.Buffer
+	if cacheKey != "" {
+		cmd.Stdout = io.MultiWriter(stdoutW, &limitWriter{W: &cacheBuf, N: int(maxCacheEntrySize) + 1})
+	}
+
+	if isPartialClone(dir) {
+		s.configureLazyFetch(ctx, req.Repo, cmd)
+	}
+
+	var hooksDone []func() error
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		if done := hook(ctx, dir, cmd); done != nil {
+			hooksDone = append(hooksDone, done)
+		}
+	}
+
 	exitStatus, execErr = runCommand(ctx, cmd)
 
+	for i := len(hooksDone) - 1; i >= 0; i-- {
+		if err := hooksDone[i](); err != nil && execErr == nil {
+			execErr = err
+		}
+	}
+
 	status = strconv.Itoa(exitStatus)
 	stdoutN = stdoutW.n
 	stderrN = stderrW.n
@@ -1298,6 +1442,10 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	stderr := stderrBuf.String()
 	checkMaybeCorruptRepo(req.Repo, dir, stderr)
 
+	if cacheKey != "" && exitStatus == 0 && execErr == nil && stderr == "" && int64(cacheBuf.Len()) <= maxCacheEntrySize {
+		s.cacheCommandOutput(ctx, cacheKey, cacheBuf.Bytes())
+	}
+
 	// write trailer
 	w.Header().Set("X-Exec-Error", errorString(execErr))
 	w.Header().Set("X-Exec-Exit-Status", status)
@@ -1465,14 +1613,14 @@ func (s *Server) p4exec(w http.ResponseWriter, r *http.Request, req *protocol.P4
 }
 
 func (s *Server) setLastError(ctx context.Context, name api.RepoName, error string) (err error) {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(name) {
 		return nil
 	}
 	return database.GitserverRepos(s.DB).SetLastError(ctx, name, error, s.Hostname)
 }
 
 func (s *Server) setLastFetched(ctx context.Context, name api.RepoName) error {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(name) {
 		return nil
 	}
 
@@ -1507,7 +1655,7 @@ func (s *Server) setLastErrorNonFatal(ctx context.Context, name api.RepoName, er
 }
 
 func (s *Server) setCloneStatus(ctx context.Context, name api.RepoName, status types.CloneStatus) (err error) {
-	if s.DB == nil {
+	if s.DB == nil || s.isReplica(name) {
 		return nil
 	}
 	return database.GitserverRepos(s.DB).SetCloneStatus(ctx, name, status, s.Hostname)
@@ -1573,13 +1721,17 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 		return progress, nil
 	}
 
-	syncer, err := s.GetVCSSyncer(ctx, repo)
-	if err != nil {
-		return "", errors.Wrap(err, "get VCS syncer")
+	// Repositories which are already cloned are re-cloned regardless of disk
+	// quotas, and replicas don't count towards them.
+	if !repoCloned(dir) && !s.isReplica(repo) {
+		if err := s.checkDiskQuota(ctx, repo); err != nil {
+			s.setLastErrorNonFatal(context.Background(), repo, err)
+			return "", err
+		}
 	}
 
 	// We may be attempting to clone a private repo so we need an internal actor.
-	remoteURL, err := s.getRemoteURL(actor.WithInternalActor(ctx), repo)
+	remoteURL, syncer, err := s.getSyncRemote(actor.WithInternalActor(ctx), repo)
 	if err != nil {
 		return "", err
 	}
@@ -1589,13 +1741,13 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 	// checks being blocked by a few slow clones will lead to poor feedback to
 	// users. We can defer since the rest of the function does not block this
 	// goroutine.
-	ctx, cancel, err := s.acquireCloneableLimiter(ctx)
+	ctx, cancel, err := s.acquireCloneableLimiter(ctx, repo)
 	if err != nil {
 		return "", err // err will be a context error
 	}
 	defer cancel()
 
-	if err = s.rpsLimiter.Wait(ctx); err != nil {
+	if err = s.waitRPSLimiter(ctx, repo); err != nil {
 		return "", err
 	}
 
@@ -1623,7 +1775,7 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 	// clones in the repo tree. This also avoids leaving behind corrupt clones
 	// if the clone is interrupted.
 	if opts != nil && opts.Block {
-		ctx, cancel, err := s.acquireCloneLimiter(ctx)
+		ctx, cancel, err := s.acquireCloneLimiter(ctx, repo)
 		if err != nil {
 			return "", err
 		}
@@ -1655,7 +1807,7 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syncer VCSSyncer, lock *RepositoryLock, remoteURL *vcs.URL, opts *cloneOptions) error {
 	defer lock.Release()
 
-	if err := s.rpsLimiter.Wait(ctx); err != nil {
+	if err := s.waitRPSLimiter(ctx, repo); err != nil {
 		return err
 	}
 
@@ -1739,6 +1891,12 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 		return err
 	}
 
+	if s.replicaPrimary(repo) != "" {
+		if err := setReplica(tmp); err != nil {
+			return err
+		}
+	}
+
 	if overwrite {
 		// remove the current repo by putting it into our temporary directory
 		err := renameAndSync(dstPath, filepath.Join(filepath.Dir(tmpPath), "old"))
@@ -1759,10 +1917,17 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 	if err := s.setLastFetched(ctx, repo); err != nil {
 		log15.Warn("failed setting last fetch in DB", "repo", repo, "error", err)
 	}
+	// The size is recorded right away so that the clone counts towards disk
+	// quotas, and kept up to date by the janitor afterwards.
+	if err := s.setRepoSize(ctx, repo, dirSize(dstPath)); err != nil {
+		log15.Warn("failed setting repo size in DB", "repo", repo, "error", err)
+	}
 
 	log15.Info("repo cloned", "repo", repo)
 	repoClonedCounter.Inc()
 
+	s.updateReplicas(repo)
+
 	return nil
 }
 
@@ -1985,27 +2150,23 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 	// This background process should use our internal actor
 	ctx = actor.WithInternalActor(ctx)
 
-	ctx, cancel2, err := s.acquireCloneLimiter(ctx)
+	repo = protocol.NormalizeRepo(repo)
+
+	ctx, cancel2, err := s.acquireCloneLimiter(ctx, repo)
 	if err != nil {
 		return err
 	}
 	defer cancel2()
 
-	if err = s.rpsLimiter.Wait(ctx); err != nil {
+	if err = s.waitRPSLimiter(ctx, repo); err != nil {
 		return err
 	}
 
-	repo = protocol.NormalizeRepo(repo)
 	dir := s.dir(repo)
 
-	remoteURL, err := s.getRemoteURL(ctx, repo)
+	remoteURL, syncer, err := s.getSyncRemote(ctx, repo)
 	if err != nil {
-		return errors.Wrap(err, "failed to determine Git remote URL")
-	}
-
-	syncer, err := s.GetVCSSyncer(ctx, repo)
-	if err != nil {
-		return errors.Wrap(err, "get VCS syncer")
+		return err
 	}
 
 	// drop temporary pack files after a fetch. this function won't
@@ -2042,6 +2203,8 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 		log15.Warn("failed setting last fetch in DB", "repo", repo, "error", err)
 	}
 
+	s.updateReplicas(repo)
+
 	return nil
 }
 
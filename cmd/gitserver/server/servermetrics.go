@@ -7,10 +7,27 @@ import (
 
 	"github.com/inconshreveable/log15"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
 )
 
+var (
+	codeHostQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "src_gitserver_codehost_queued",
+		Help: "number of clones and fetches (op=clone) or git ls-remotes (op=lsremote) waiting to run, by code host connection.",
+	}, []string{"op", "codehost"})
+	codeHostRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "src_gitserver_codehost_running",
+		Help: "number of clones and fetches (op=clone) or git ls-remotes (op=lsremote) running, by code host connection.",
+	}, []string{"op", "codehost"})
+	codeHostRateLimitWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "src_gitserver_codehost_rate_limit_wait_duration_seconds",
+		Help:    "time spent waiting for the rate limit of a code host connection before running a remote git operation.",
+		Buckets: []float64{0.01, 0.1, 1, 5, 10, 30, 60, 300},
+	}, []string{"codehost"})
+)
+
 func (s *Server) RegisterMetrics() {
 	// test the latency of exec, which may increase under certain memory
 	// conditions
@@ -362,6 +362,35 @@ func (l *limitWriter) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// filterWriter is an io.Writer that copies what is written to it to w through
+// filter, which runs in its own goroutine until Close is called.
+type filterWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newFilterWriter(w io.Writer, filter func(w io.Writer, r io.Reader) error) *filterWriter {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := filter(w, pr)
+		// Drain what is left, so that the writer never blocks if we failed.
+		_, _ = io.Copy(io.Discard, pr)
+		done <- err
+	}()
+	return &filterWriter{pw: pw, done: done}
+}
+
+func (f *filterWriter) Write(p []byte) (int, error) {
+	return f.pw.Write(p)
+}
+
+// Close waits for filter to return, and returns its error.
+func (f *filterWriter) Close() error {
+	_ = f.pw.Close()
+	return <-f.done
+}
+
 // flushingResponseWriter is a http.ResponseWriter that flushes all writes
 // to the underlying connection within a certain time period after Write is
 // called (instead of buffering them indefinitely).
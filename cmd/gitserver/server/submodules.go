@@ -0,0 +1,155 @@
+package server
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+// submoduleArchive returns a tar archive of repo at commit. It is replaced in
+// tests.
+var submoduleArchive = func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+	// The repository is usually on another gitserver.
+	return gitserver.DefaultClient.Archive(actor.WithInternalActor(ctx), repo, gitserver.ArchiveOptions{
+		Treeish: string(commit),
+		Format:  "tar",
+	})
+}
+
+// listSubmoduleCommits returns the commits pinned by the submodules at the
+// given paths in treeish, by path.
+func listSubmoduleCommits(ctx context.Context, dir GitDir, treeish string, paths []string) (map[string]api.CommitID, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"ls-tree", "-r", "-z", "--full-tree", treeish, "--"}, paths...)...)
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(wrapCmdError(cmd, err), "listing tree")
+	}
+
+	commits := map[string]api.CommitID{}
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		tab := strings.IndexByte(entry, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields, name := strings.Fields(entry[:tab]), entry[tab+1:]
+		if len(fields) != 3 || fields[1] != "commit" {
+			continue
+		}
+		commits[name] = api.CommitID(fields[2])
+	}
+	return commits, nil
+}
+
+// includeSubmodulesTar copies the tar archive read from r to w, adding the
+// content of the submodules in commits after their directory entries. The
+// archives of the submodules are opened with open.
+func includeSubmodulesTar(ctx context.Context, w io.Writer, r io.Reader, commits map[string]api.CommitID, open func(ctx context.Context, path string, commit api.CommitID) (io.ReadCloser, error)) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+
+		// git archive archives submodules as empty directories.
+		path := strings.TrimSuffix(hdr.Name, "/")
+		commit, ok := commits[path]
+		if hdr.Typeflag != tar.TypeDir || !ok {
+			continue
+		}
+		rc, err := open(ctx, path, commit)
+		if err != nil {
+			// The pinned commit may not have been fetched yet, which should
+			// not fail the archive of the superproject.
+			log15.Warn("failed to archive submodule", "path", path, "commit", commit, "error", err)
+			continue
+		}
+		err = copySubmoduleTar(tw, rc, path)
+		rc.Close()
+		if err != nil {
+			return errors.Wrapf(err, "archiving submodule %s", path)
+		}
+	}
+	return tw.Close()
+}
+
+// copySubmoduleTar copies the entries of the tar archive read from r to tw,
+// below prefix.
+func copySubmoduleTar(tw *tar.Writer, r io.Reader, prefix string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// The global header records the commit of the submodule, which
+		// would override the one of the superproject.
+		if hdr.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		hdr.Name = prefix + "/" + hdr.Name
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveSubmodulesHook returns an execHook for git archive, which includes
+// the contents of submodules in tar archives. submodules maps the paths of
+// the submodules to include to the repositories they refer to. Nested
+// submodules are archived as empty directories. Zip archives are left as is.
+func archiveSubmodulesHook(repo api.RepoName, treeish, format string, submodules map[string]api.RepoName) execHook {
+	if len(submodules) == 0 || format != "tar" {
+		return nil
+	}
+	return func(ctx context.Context, dir GitDir, cmd *exec.Cmd) func() error {
+		paths := make([]string, 0, len(submodules))
+		for path := range submodules {
+			paths = append(paths, path)
+		}
+		commits, err := listSubmoduleCommits(ctx, dir, treeish, paths)
+		if err != nil {
+			log15.Warn("failed to list submodules", "repo", repo, "treeish", treeish, "error", err)
+			return nil
+		}
+		if len(commits) == 0 {
+			return nil
+		}
+
+		open := func(ctx context.Context, path string, commit api.CommitID) (io.ReadCloser, error) {
+			return submoduleArchive(ctx, submodules[path], commit)
+		}
+		fw := newFilterWriter(cmd.Stdout, func(w io.Writer, r io.Reader) error {
+			return includeSubmodulesTar(ctx, w, r, commits, open)
+		})
+		cmd.Stdout = fw
+		return fw.Close
+	}
+}
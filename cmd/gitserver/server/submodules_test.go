@@ -0,0 +1,125 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestArchiveSubmodules(t *testing.T) {
+	ctx := context.Background()
+
+	sub := t.TempDir()
+	runCmd(t, sub, "git", "init", ".")
+	if err := os.WriteFile(filepath.Join(sub, "sub.txt"), []byte("submodule\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	runCmd(t, sub, "git", "add", ".")
+	runCmd(t, sub, "git", "commit", "-m", "sub")
+	pinned := strings.TrimSpace(runCmd(t, sub, "git", "rev-parse", "HEAD"))
+
+	super := t.TempDir()
+	runCmd(t, super, "git", "init", ".")
+	if err := os.WriteFile(filepath.Join(super, "hello.txt"), []byte("hello world\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	runCmd(t, super, "git", "add", ".")
+	for _, path := range []string{"lib", "missing", "unresolved"} {
+		runCmd(t, super, "git", "update-index", "--add", "--cacheinfo", "160000,"+pinned+","+path)
+	}
+	runCmd(t, super, "git", "commit", "-m", "super")
+	dir := GitDir(filepath.Join(super, ".git"))
+
+	orig := submoduleArchive
+	submoduleArchive = func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
+		if repo != "sub" {
+			return nil, errors.Errorf("repo %s not found", repo)
+		}
+		cmd := exec.Command("git", "archive", "--format=tar", string(commit))
+		cmd.Dir = sub
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(out)), nil
+	}
+	defer func() { submoduleArchive = orig }()
+
+	archive := func(format string, submodules map[string]api.RepoName) map[string]string {
+		t.Helper()
+		cmd := exec.Command("git", "archive", "--format="+format, "HEAD", "--")
+		dir.Set(cmd)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		var done func() error
+		if hook := archiveSubmodulesHook("super", "HEAD", format, submodules); hook != nil {
+			done = hook(ctx, dir, cmd)
+		}
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("archive failed: %s\n%s", err, stderr.String())
+		}
+		if done != nil {
+			if err := done(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		files := map[string]string{}
+		tr := tar.NewReader(&stdout)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch hdr.Typeflag {
+			case tar.TypeDir:
+				files[hdr.Name] = ""
+			case tar.TypeReg:
+				b, err := io.ReadAll(tr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files[hdr.Name] = string(b)
+			}
+		}
+		return files
+	}
+
+	// Submodules are archived as empty directories by default.
+	want := map[string]string{
+		"hello.txt":   "hello world\n",
+		"lib/":        "",
+		"missing/":    "",
+		"unresolved/": "",
+	}
+	if diff := cmp.Diff(want, archive("tar", nil)); diff != "" {
+		t.Fatalf("mismatched archive (-want +have):\n%s", diff)
+	}
+
+	// The submodules to include are archived at their pinned commits.
+	// Submodules which cannot be archived are left empty.
+	want["lib/sub.txt"] = "submodule\n"
+	submodules := map[string]api.RepoName{"lib": "sub", "missing": "missing"}
+	if diff := cmp.Diff(want, archive("tar", submodules)); diff != "" {
+		t.Fatalf("mismatched archive (-want +have):\n%s", diff)
+	}
+
+	if hook := archiveSubmodulesHook("super", "HEAD", "zip", submodules); hook != nil {
+		t.Fatal("expected zip archives to be left as is")
+	}
+}
@@ -78,12 +78,20 @@ func (s *GitRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tm
 		return nil, errors.Wrapf(err, "clone setup failed")
 	}
 
-	cmd, _ = s.fetchCommand(ctx, remoteURL)
+	partialClone := false
+	if filter := partialCloneFilter(remoteURL); filter != "" && customFetchCmd(ctx, remoteURL) == nil && !useRefspecOverrides() {
+		if err := setupPartialClone(GitDir(tmpPath), filter); err != nil {
+			return nil, errors.Wrapf(err, "clone setup failed")
+		}
+		partialClone = true
+	}
+
+	cmd, _ = s.fetchCommand(ctx, remoteURL, partialClone)
 	cmd.Dir = tmpPath
 	return cmd, nil
 }
 
-func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, configRemoteOpts bool) {
+func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL, partialClone bool) (cmd *exec.Cmd, configRemoteOpts bool) {
 	configRemoteOpts = true
 	if customCmd := customFetchCmd(ctx, remoteURL); customCmd != nil {
 		cmd = customCmd
@@ -91,8 +99,14 @@ func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (c
 	} else if useRefspecOverrides() {
 		cmd = refspecOverridesFetchCmd(ctx, remoteURL)
 	} else {
+		remote := remoteURL.String()
+		if partialClone {
+			// Partial clones fetch from their promisor remote, so that its
+			// filter applies.
+			remote = promisorRemote
+		}
 		cmd = exec.CommandContext(ctx, "git", "fetch",
-			"--progress", "--prune", remoteURL.String(),
+			"--progress", "--prune", remote,
 			// Normal git refs
 			"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*",
 			// GitHub pull requests
@@ -105,13 +119,16 @@ func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (c
 			"+refs/changes/*:refs/changes/*",
 			// Possibly deprecated refs for sourcegraph zap experiment?
 			"+refs/sourcegraph/*:refs/sourcegraph/*")
+		if partialClone {
+			configurePromisorRemote(cmd, remoteURL)
+		}
 	}
 	return cmd, configRemoteOpts
 }
 
 // Fetch tries to fetch updates of a Git repository.
 func (s *GitRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
-	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL)
+	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL, isPartialClone(dir))
 	dir.Set(cmd)
 	if output, err := runWith(ctx, cmd, configRemoteOpts, nil); err != nil {
 		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
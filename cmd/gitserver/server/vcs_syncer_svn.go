@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// svnAskpassFile is the name of the script in the Git directory which git
+	// svn invokes to read the Subversion password. It prints the password from
+	// the environment, so that the password is never written to disk.
+	svnAskpassFile = "sourcegraph-svn-askpass"
+	svnAskpass     = "#!/bin/sh\nprintf '%s\\n' \"$SRC_SVN_PASSWORD\"\n"
+
+	// svnAuthorsFile is the name of the authors file in the Git directory,
+	// generated from the authors of the external service configuration.
+	svnAuthorsFile = "sourcegraph-svn-authors"
+
+	// svnAuthorsProgFile is the name of the script in the Git directory which
+	// maps the Subversion users missing from the authors file.
+	svnAuthorsProgFile = "sourcegraph-svn-authors-prog"
+	svnAuthorsProg     = "#!/bin/sh\nprintf '%s <%s>\\n' \"$1\" \"$1\"\n"
+)
+
+// SubversionRepoSyncer is a syncer for Subversion repositories. It converts
+// them to Git repositories with git svn, mapping the trunk to the master
+// branch, and the branches and tags to Git branches and tags.
+type SubversionRepoSyncer struct {
+	Config *schema.SubversionConnection
+}
+
+var _ VCSSyncer = &SubversionRepoSyncer{}
+
+func (s *SubversionRepoSyncer) Type() string {
+	return "subversion"
+}
+
+// decomposeSubversionRemoteURL splits the credentials from a clone URL for a
+// Subversion repository.
+func decomposeSubversionRemoteURL(remoteURL *vcs.URL) (username, password, repoURL string, err error) {
+	switch remoteURL.Scheme {
+	case "svn", "svn+ssh", "http", "https", "file":
+	default:
+		return "", "", "", errors.Errorf("scheme %q is not a Subversion scheme", remoteURL.Scheme)
+	}
+
+	u := remoteURL.URL
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+	return username, password, u.String(), nil
+}
+
+// layout returns the paths of the trunk, branches and tags of the repository.
+func (s *SubversionRepoSyncer) layout() (trunk, branches, tags string) {
+	trunk, branches, tags = "trunk", "branches", "tags"
+	if s.Config == nil || s.Config.Layout == nil {
+		return trunk, branches, tags
+	}
+	if l := s.Config.Layout; l.Trunk != "" {
+		trunk = l.Trunk
+	}
+	if l := s.Config.Layout; l.Branches != "" {
+		branches = l.Branches
+	}
+	if l := s.Config.Layout; l.Tags != "" {
+		tags = l.Tags
+	}
+	return strings.Trim(trunk, "/"), strings.Trim(branches, "/"), strings.Trim(tags, "/")
+}
+
+// authors returns the content of the git svn authors file.
+func (s *SubversionRepoSyncer) authors() []byte {
+	if s.Config == nil {
+		return nil
+	}
+	users := make([]string, 0, len(s.Config.Authors))
+	for user := range s.Config.Authors {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	var b strings.Builder
+	for _, user := range users {
+		fmt.Fprintf(&b, "%s = %s\n", user, s.Config.Authors[user])
+	}
+	return []byte(b.String())
+}
+
+// writeHelperFiles writes the authors file and the scripts git svn invokes to
+// the Git directory. They are rewritten before every fetch so that they follow
+// changes to the configuration.
+func (s *SubversionRepoSyncer) writeHelperFiles(dir GitDir) error {
+	for _, f := range []struct {
+		name string
+		data []byte
+		perm os.FileMode
+	}{
+		{svnAskpassFile, []byte(svnAskpass), 0700},
+		{svnAuthorsFile, s.authors(), 0600},
+		{svnAuthorsProgFile, []byte(svnAuthorsProg), 0700},
+	} {
+		if err := os.WriteFile(dir.Path(f.name), f.data, f.perm); err != nil {
+			return errors.Wrapf(err, "writing %s", f.name)
+		}
+		// WriteFile does not change the permissions of existing files.
+		if err := os.Chmod(dir.Path(f.name), f.perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchCommand returns the git svn command fetching the new revisions of the
+// repository into dir.
+func (s *SubversionRepoSyncer) fetchCommand(ctx context.Context, dir GitDir, username, password string) *exec.Cmd {
+	args := []string{"svn", "fetch",
+		"--authors-file=" + dir.Path(svnAuthorsFile),
+		"--authors-prog=" + dir.Path(svnAuthorsProgFile),
+	}
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(),
+		// git svn reads passwords with GIT_ASKPASS, and falls back to
+		// SSH_ASKPASS if GIT_ASKPASS prints nothing. We set both, since
+		// runWithRemoteOpts overrides GIT_ASKPASS to disable prompts.
+		"GIT_ASKPASS="+dir.Path(svnAskpassFile),
+		"SSH_ASKPASS="+dir.Path(svnAskpassFile),
+		"SRC_SVN_PASSWORD="+password,
+	)
+	dir.Set(cmd)
+	return cmd
+}
+
+// IsCloneable checks to see if the trunk of the Subversion repository exists.
+func (s *SubversionRepoSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	username, password, repoURL, err := decomposeSubversionRemoteURL(remoteURL)
+	if err != nil {
+		return errors.Wrap(err, "decompose")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	trunk, _, _ := s.layout()
+	args := []string{"info", "--non-interactive", "--no-auth-cache"}
+	if username != "" {
+		args = append(args, "--username", username, "--password-from-stdin")
+	}
+	args = append(args, repoURL+"/"+trunk)
+
+	cmd := exec.CommandContext(ctx, "svn", args...)
+	cmd.Stdin = strings.NewReader(password)
+	out, err := runWith(ctx, cmd, false, nil)
+	if err != nil {
+		if ctxerr := ctx.Err(); ctxerr != nil {
+			err = ctxerr
+		}
+		if len(out) > 0 {
+			err = errors.Errorf("%s (output follows)\n\n%s", err, newURLRedactor(remoteURL).redact(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+// CloneCommand returns the command to be executed for cloning a Subversion
+// repository as a Git repository.
+func (s *SubversionRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tmpPath string) (*exec.Cmd, error) {
+	username, password, repoURL, err := decomposeSubversionRemoteURL(remoteURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompose")
+	}
+
+	if err := os.MkdirAll(tmpPath, os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "clone failed to create tmp dir")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "init", "--bare", ".")
+	cmd.Dir = tmpPath
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "clone setup failed")
+	}
+
+	// We configure the svn-remote ourselves instead of running git svn init,
+	// so that the trunk, branches and tags are fetched straight into branches
+	// and tags rather than remote refs.
+	dir := GitDir(tmpPath)
+	trunk, branches, tags := s.layout()
+	for _, kv := range [][2]string{
+		{"svn-remote.svn.url", repoURL},
+		{"svn-remote.svn.fetch", trunk + ":refs/heads/master"},
+		{"svn-remote.svn.branches", path.Join(branches, "*") + ":refs/heads/*"},
+		{"svn-remote.svn.tags", path.Join(tags, "*") + ":refs/tags/*"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", "config", kv[0], kv[1])
+		dir.Set(cmd)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, errors.Wrapf(err, "clone setup failed with output %q", string(out))
+		}
+	}
+
+	if err := s.writeHelperFiles(dir); err != nil {
+		return nil, errors.Wrap(err, "clone setup failed")
+	}
+
+	return s.fetchCommand(ctx, dir, username, password), nil
+}
+
+// Fetch fetches the new revisions of a Subversion repository.
+func (s *SubversionRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	username, password, _, err := decomposeSubversionRemoteURL(remoteURL)
+	if err != nil {
+		return errors.Wrap(err, "decompose")
+	}
+
+	if err := s.writeHelperFiles(dir); err != nil {
+		return err
+	}
+
+	cmd := s.fetchCommand(ctx, dir, username, password)
+	if output, err := runWith(ctx, cmd, false, nil); err != nil {
+		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+	return nil
+}
+
+// RemoteShowCommand returns the command to be executed for showing Git remote
+// of a Subversion repository.
+func (s *SubversionRepoSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	// Remote info is encoded as in the current repository
+	return exec.CommandContext(ctx, "git", "remote", "show", "./"), nil
+}
@@ -32,6 +32,8 @@ import (
 
 var cacheDir = env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
 var cacheSizeMB = env.Get("SEARCHER_CACHE_SIZE_MB", "100000", "maximum size of the on disk cache in megabytes")
+var indexCacheSizeMB = env.Get("SEARCHER_EPHEMERAL_INDEX_CACHE_SIZE_MB", "10000", "maximum size of the on disk cache of ephemeral indexes in megabytes")
+var indexHotThreshold = env.Get("SEARCHER_EPHEMERAL_INDEX_HOT_THRESHOLD", "5", "number of searches of a revision within 10 minutes after which it is indexed. 0 disables ephemeral indexes.")
 
 const port = "3181"
 
@@ -57,10 +59,27 @@ func main() {
 		cacheSizeBytes = i * 1000 * 1000
 	}
 
+	var indexCacheSizeBytes int64
+	if i, err := strconv.ParseInt(indexCacheSizeMB, 10, 64); err != nil {
+		log.Fatalf("invalid int %q for SEARCHER_EPHEMERAL_INDEX_CACHE_SIZE_MB: %s", indexCacheSizeMB, err)
+	} else {
+		indexCacheSizeBytes = i * 1000 * 1000
+	}
+	hotThreshold, err := strconv.Atoi(indexHotThreshold)
+	if err != nil {
+		log.Fatalf("invalid int %q for SEARCHER_EPHEMERAL_INDEX_HOT_THRESHOLD: %s", indexHotThreshold, err)
+	}
+
 	service := &search.Service{
 		Store: &store.Store{
 			FetchTar: func(ctx context.Context, repo api.RepoName, commit api.CommitID) (io.ReadCloser, error) {
-				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
+				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{
+					Treeish: string(commit),
+					Format:  "tar",
+					// Blobs missing from partial clones are usually too large
+					// to search, so only their names are searched.
+					SkipMissingBlobs: true,
+				})
 			},
 			FilterTar:         search.NewFilter,
 			Path:              filepath.Join(cacheDir, "searcher-archives"),
@@ -68,6 +87,12 @@ func main() {
 		},
 		Log: log15.Root(),
 	}
+	service.Indexes = &search.EphemeralIndexes{
+		Path:              filepath.Join(cacheDir, "searcher-ephemeral-indexes"),
+		MaxCacheSizeBytes: indexCacheSizeBytes,
+		HotThreshold:      hotThreshold,
+		ZipCache:          &service.Store.ZipCache,
+	}
 	service.Store.Start()
 
 	handler := ot.Middleware(trace.HTTPTraceMiddleware(service))
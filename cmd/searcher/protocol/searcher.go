@@ -69,6 +69,12 @@ type PatternInfo struct {
 	// not supported for structural searches.
 	IsNegated bool
 
+	// PatternExpression, if non-nil, is a boolean expression of patterns that is evaluated
+	// against each file in place of Pattern and IsNegated. The leaves of the expression are
+	// interpreted according to IsRegExp, IsWordMatch, and IsCaseSensitive. Expressions are
+	// not supported for structural searches.
+	PatternExpression *PatternNode
+
 	// IsRegExp if true will treat the Pattern as a regular expression.
 	IsRegExp bool
 
@@ -134,6 +140,9 @@ type PatternInfo struct {
 
 func (p *PatternInfo) String() string {
 	args := []string{fmt.Sprintf("%q", p.Pattern)}
+	if p.PatternExpression != nil {
+		args = []string{p.PatternExpression.String()}
+	}
 	if p.IsRegExp {
 		args = append(args, "re")
 	}
@@ -183,6 +192,42 @@ func (p *PatternInfo) String() string {
 	return fmt.Sprintf("PatternInfo{%s}", strings.Join(args, ","))
 }
 
+// Kinds of pattern expression nodes.
+const (
+	PatternNodePattern = "pattern"
+	PatternNodeAnd     = "and"
+	PatternNodeOr      = "or"
+	PatternNodeNot     = "not"
+)
+
+// PatternNode is a node of a boolean pattern expression. A file matches a
+// "pattern" node if the pattern matches its content (or its path, if
+// PatternMatchesPath is set), an "and" node if it matches all operands, an
+// "or" node if it matches any operand, and a "not" node if it does not match
+// its single operand.
+type PatternNode struct {
+	Kind     string
+	Value    string         `json:",omitempty"` // pattern, for Kind == PatternNodePattern
+	Operands []*PatternNode `json:",omitempty"`
+}
+
+func (n *PatternNode) String() string {
+	switch n.Kind {
+	case PatternNodePattern:
+		return fmt.Sprintf("%q", n.Value)
+	case PatternNodeNot:
+		if len(n.Operands) == 1 {
+			return "(not " + n.Operands[0].String() + ")"
+		}
+	}
+
+	operands := make([]string, 0, len(n.Operands))
+	for _, operand := range n.Operands {
+		operands = append(operands, operand.String())
+	}
+	return "(" + n.Kind + " " + strings.Join(operands, " ") + ")"
+}
+
 // Response represents the response from a Search request.
 type Response struct {
 	Matches []FileMatch
@@ -0,0 +1,477 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp/syntax"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/search/casetransform"
+	"github.com/sourcegraph/sourcegraph/internal/store"
+)
+
+// EphemeralIndexes maintains trigram indexes of the archives of revisions
+// that are searched often, such as release branches, which zoekt does not
+// index. Once an archive is hot, its index is built in the background and
+// stored on disk next to the archive cache. Searches of the archive then only
+// read the files which contain all the trigrams of the pattern.
+//
+// Like the archive cache, indexes are evicted from disk based on modification
+// time, and only the most recently used ones are kept in memory.
+type EphemeralIndexes struct {
+	// Path is the directory to store the indexes.
+	Path string
+
+	// MaxCacheSizeBytes is the maximum size of Path in bytes. If zero, indexes
+	// are not evicted.
+	MaxCacheSizeBytes int64
+
+	// HotThreshold is the number of searches of an archive within HotWindow
+	// after which it is indexed. If zero, no archive is indexed.
+	HotThreshold int
+
+	// HotWindow is the period over which searches of an archive are counted.
+	// Defaults to 10 minutes.
+	HotWindow time.Duration
+
+	// MaxIndexesInMemory is the maximum number of indexes kept in memory.
+	// Defaults to 16.
+	MaxIndexesInMemory int
+
+	// ZipCache is used to open archives to index.
+	ZipCache *store.ZipCache
+
+	once    sync.Once
+	cache   *diskcache.Store
+	indexes *lru.Cache
+
+	mu      sync.Mutex
+	hot     map[string]*hotness // zip path -> hotness
+	loading map[string]bool     // zip paths being indexed or loaded
+}
+
+// hotness counts the searches of an archive since start.
+type hotness struct {
+	start time.Time
+	count int
+}
+
+// maxConcurrentIndexLoads is the number of indexes built or loaded from disk
+// concurrently. Building is CPU bound, so we keep this low to not starve
+// searches.
+const maxConcurrentIndexLoads = 2
+
+// Start initializes state and starts evicting indexes from disk. It is called
+// by Get if needed.
+func (s *EphemeralIndexes) Start() {
+	s.once.Do(func() {
+		if s.HotWindow == 0 {
+			s.HotWindow = 10 * time.Minute
+		}
+		size := s.MaxIndexesInMemory
+		if size <= 0 {
+			size = 16
+		}
+		s.indexes, _ = lru.New(size)
+		s.hot = map[string]*hotness{}
+		s.loading = map[string]bool{}
+		s.cache = &diskcache.Store{
+			Dir:               s.Path,
+			Component:         "ephemeralindex",
+			BackgroundTimeout: 10 * time.Minute,
+		}
+		_ = os.MkdirAll(s.Path, 0700)
+		metrics.MustRegisterDiskMonitor(s.Path)
+		go s.watchAndEvict()
+	})
+}
+
+// Get records a search of the archive at zipPath and returns its index, or
+// nil if it has none in memory. If the archive is hot, its index is built or
+// loaded from disk in the background for later searches.
+func (s *EphemeralIndexes) Get(zipPath string) *ephemeralIndex {
+	if s == nil || s.HotThreshold <= 0 {
+		return nil
+	}
+	s.Start()
+
+	if ix, ok := s.indexes.Get(zipPath); ok {
+		return ix.(*ephemeralIndex)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	h, ok := s.hot[zipPath]
+	if !ok || now.Sub(h.start) > s.HotWindow {
+		h = &hotness{start: now}
+		s.hot[zipPath] = h
+	}
+	h.count++
+
+	if h.count >= s.HotThreshold && !s.loading[zipPath] && len(s.loading) < maxConcurrentIndexLoads {
+		s.loading[zipPath] = true
+		go s.load(zipPath)
+	}
+	return nil
+}
+
+// load builds the index of the archive at zipPath, or reads it from disk if
+// it was built before, and adds it to the in-memory cache.
+func (s *EphemeralIndexes) load(zipPath string) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.loading, zipPath)
+		s.mu.Unlock()
+	}()
+
+	zf, err := s.ZipCache.Get(zipPath)
+	if err != nil {
+		log.Printf("ephemeralindex: failed to open archive %q: %s", zipPath, err)
+		return
+	}
+	defer zf.Close()
+
+	// Archive paths are unique per repository, commit and configuration, so
+	// they make good keys.
+	key := filepath.Base(zipPath)
+	f, err := s.cache.Open(context.Background(), key, func(ctx context.Context) (io.ReadCloser, error) {
+		start := time.Now()
+		var buf bytes.Buffer
+		if err := buildEphemeralIndex(zf).write(&buf); err != nil {
+			return nil, err
+		}
+		indexBuildDuration.Observe(time.Since(start).Seconds())
+		return io.NopCloser(&buf), nil
+	})
+	if err != nil {
+		indexBuildFailed.Inc()
+		log.Printf("ephemeralindex: failed to index archive %q: %s", zipPath, err)
+		return
+	}
+	defer f.Close()
+
+	ix, err := readEphemeralIndex(f)
+	if err == nil && !ix.matches(zf) {
+		err = errors.New("index does not match archive")
+	}
+	if err != nil {
+		// Remove the file so that the next load rebuilds it.
+		_ = os.Remove(f.Path)
+		indexBuildFailed.Inc()
+		log.Printf("ephemeralindex: failed to read index of archive %q: %s", zipPath, err)
+		return
+	}
+	s.indexes.Add(zipPath, ix)
+}
+
+// watchAndEvict is a loop which periodically forgets archives which are no
+// longer hot, and evicts indexes from disk if they use too much space.
+func (s *EphemeralIndexes) watchAndEvict() {
+	for {
+		time.Sleep(time.Minute)
+
+		s.mu.Lock()
+		for zipPath, h := range s.hot {
+			if time.Since(h.start) > s.HotWindow {
+				delete(s.hot, zipPath)
+			}
+		}
+		s.mu.Unlock()
+
+		if s.MaxCacheSizeBytes == 0 {
+			continue
+		}
+		stats, err := s.cache.Evict(s.MaxCacheSizeBytes)
+		if err != nil {
+			log.Printf("ephemeralindex: failed to Evict: %s", err)
+			continue
+		}
+		indexCacheSizeBytes.Set(float64(stats.CacheSize))
+		indexEvictions.Add(float64(stats.Evicted))
+	}
+}
+
+// ephemeralIndex maps the trigrams of the contents of the files of an archive
+// to the files containing them. Trigrams are lowercased, so lookups return a
+// superset of the files matching case sensitive patterns.
+type ephemeralIndex struct {
+	// numFiles and dataSize describe the archive the index was built from.
+	numFiles uint32
+	dataSize uint64
+
+	// trigrams is sorted. The postings of trigrams[i] end at ends[i] in
+	// postings, which holds the deltas of increasing file numbers as
+	// uvarints.
+	trigrams []uint32
+	ends     []uint32
+	postings []byte
+}
+
+// buildEphemeralIndex returns the index of the files in zf. File numbers are
+// indices in zf.Files.
+func buildEphemeralIndex(zf *store.ZipFile) *ephemeralIndex {
+	type posting struct {
+		last uint32 // last file number added plus one
+		buf  []byte
+	}
+	postings := map[uint32]*posting{}
+
+	var varint [binary.MaxVarintLen64]byte
+	lower := make([]byte, zf.MaxLen)
+	for i := range zf.Files {
+		data := zf.DataFor(&zf.Files[i])
+		lower := lower[:len(data)]
+		casetransform.BytesToLowerASCII(lower, data)
+
+		file := uint32(i) + 1
+		for j := 0; j+3 <= len(lower); j++ {
+			t := trigram(lower[j:])
+			p, ok := postings[t]
+			if !ok {
+				p = &posting{}
+				postings[t] = p
+			}
+			if p.last == file {
+				continue
+			}
+			n := binary.PutUvarint(varint[:], uint64(file-p.last))
+			p.buf = append(p.buf, varint[:n]...)
+			p.last = file
+		}
+	}
+
+	ix := &ephemeralIndex{
+		numFiles: uint32(len(zf.Files)),
+		dataSize: uint64(len(zf.Data)),
+		trigrams: make([]uint32, 0, len(postings)),
+		ends:     make([]uint32, 0, len(postings)),
+	}
+	for t := range postings {
+		ix.trigrams = append(ix.trigrams, t)
+	}
+	sort.Slice(ix.trigrams, func(i, j int) bool { return ix.trigrams[i] < ix.trigrams[j] })
+	for _, t := range ix.trigrams {
+		ix.postings = append(ix.postings, postings[t].buf...)
+		ix.ends = append(ix.ends, uint32(len(ix.postings)))
+	}
+	return ix
+}
+
+func trigram(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+// matches returns whether ix was built from zf.
+func (ix *ephemeralIndex) matches(zf *store.ZipFile) bool {
+	return ix.numFiles == uint32(len(zf.Files)) && ix.dataSize == uint64(len(zf.Data))
+}
+
+// files returns the numbers of the files containing trigram t.
+func (ix *ephemeralIndex) files(t uint32) []uint32 {
+	i := sort.Search(len(ix.trigrams), func(i int) bool { return ix.trigrams[i] >= t })
+	if i == len(ix.trigrams) || ix.trigrams[i] != t {
+		return nil
+	}
+	start := uint32(0)
+	if i > 0 {
+		start = ix.ends[i-1]
+	}
+	buf := ix.postings[start:ix.ends[i]]
+
+	var files []uint32
+	file := uint32(0)
+	for len(buf) > 0 {
+		delta, n := binary.Uvarint(buf)
+		buf = buf[n:]
+		file += uint32(delta)
+		files = append(files, file-1)
+	}
+	return files
+}
+
+// candidates returns the files of zf which contain literal, ignoring ASCII
+// case. ok is false if the index cannot be used to find them.
+func (ix *ephemeralIndex) candidates(zf *store.ZipFile, literal []byte) (files []store.SrcFile, ok bool) {
+	if len(literal) < 3 || !ix.matches(zf) {
+		return nil, false
+	}
+	lower := make([]byte, len(literal))
+	casetransform.BytesToLowerASCII(lower, literal)
+
+	seen := map[uint32]bool{}
+	var lists [][]uint32
+	for i := 0; i+3 <= len(lower); i++ {
+		t := trigram(lower[i:])
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		lists = append(lists, ix.files(t))
+	}
+
+	// Intersect the shortest lists first to keep intermediate results small.
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	result := lists[0]
+	for _, list := range lists[1:] {
+		result = intersect(result, list)
+	}
+
+	files = make([]store.SrcFile, 0, len(result))
+	for _, file := range result {
+		files = append(files, zf.Files[file])
+	}
+	return files, true
+}
+
+// intersect returns the elements of the sorted slices a and b which appear in
+// both. It reuses a.
+func intersect(a, b []uint32) []uint32 {
+	out := a[:0]
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// ephemeralIndexMagic starts every index file. The last byte is the version
+// of the format.
+var ephemeralIndexMagic = []byte("SGEIDX\x00\x01")
+
+// write writes ix to w. The format is the magic, followed by the number of
+// files, the size of the archive data and the number of trigrams, then the
+// trigrams, the ends of their postings and the postings. All integers are
+// little endian.
+func (ix *ephemeralIndex) write(w io.Writer) error {
+	header := make([]byte, 16)
+	binary.LittleEndian.PutUint32(header[0:], ix.numFiles)
+	binary.LittleEndian.PutUint64(header[4:], ix.dataSize)
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(ix.trigrams)))
+	if _, err := w.Write(ephemeralIndexMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, ix.trigrams); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, ix.ends); err != nil {
+		return err
+	}
+	_, err := w.Write(ix.postings)
+	return err
+}
+
+// readEphemeralIndex reads an index written by write.
+func readEphemeralIndex(r io.Reader) (*ephemeralIndex, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	headerLen := len(ephemeralIndexMagic) + 16
+	if len(data) < headerLen || !bytes.Equal(data[:len(ephemeralIndexMagic)], ephemeralIndexMagic) {
+		return nil, errors.New("not an index file")
+	}
+	header := data[len(ephemeralIndexMagic):]
+	ix := &ephemeralIndex{
+		numFiles: binary.LittleEndian.Uint32(header),
+		dataSize: binary.LittleEndian.Uint64(header[4:]),
+	}
+	n := int(binary.LittleEndian.Uint32(header[12:]))
+	data = data[headerLen:]
+	if len(data) < 8*n {
+		return nil, errors.New("truncated index file")
+	}
+
+	ix.trigrams = make([]uint32, n)
+	ix.ends = make([]uint32, n)
+	for i := 0; i < n; i++ {
+		ix.trigrams[i] = binary.LittleEndian.Uint32(data[4*i:])
+		ix.ends[i] = binary.LittleEndian.Uint32(data[4*(n+i):])
+	}
+	ix.postings = data[8*n:]
+	if n > 0 && int(ix.ends[n-1]) != len(ix.postings) {
+		return nil, errors.New("truncated index file")
+	}
+	return ix, nil
+}
+
+// indexLiteral returns a substring which appears in all matches of rg, to
+// look up in an ephemeral index. It returns nil if there is none.
+func indexLiteral(rg *readerGrep) []byte {
+	if rg.re == nil {
+		return nil
+	}
+	ast, err := syntax.Parse(rg.re.String(), syntax.Perl)
+	if err != nil || hasFoldCase(ast) {
+		// Case folding matches non-ASCII runes, such as the Kelvin sign for
+		// k, which we do not lowercase in the index.
+		return nil
+	}
+	literal := longestLiteral(ast.Simplify())
+	if prefix, _ := rg.re.LiteralPrefix(); len(prefix) > len(literal) {
+		literal = prefix
+	}
+	return []byte(literal)
+}
+
+func hasFoldCase(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase != 0 {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasFoldCase(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	indexBuildDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "searcher_ephemeral_index_build_duration_seconds",
+		Help:    "Time taken to build the index of a hot archive.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+	indexBuildFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_ephemeral_index_build_failed",
+		Help: "The total number of indexes which failed to build or load.",
+	})
+	indexSearches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "searcher_ephemeral_index_searches",
+		Help: "The total number of regexp searches by whether they used an index.",
+	}, []string{"indexed"})
+	indexCacheSizeBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "searcher_ephemeral_index_cache_size_bytes",
+		Help: "The total size of the indexes on disk.",
+	})
+	indexEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_ephemeral_index_evictions",
+		Help: "The total number of indexes evicted from disk.",
+	})
+)
@@ -0,0 +1,202 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/store"
+	storetest "github.com/sourcegraph/sourcegraph/internal/store/testutil"
+)
+
+var ephemeralIndexFiles = map[string]string{
+	"README.md": "# Hello World\n\nHello world example in go",
+	"main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Hello world\")\n}\n",
+	"abc.txt":   "w",
+	"lib.go":    "package lib\n\nfunc Helper() string { return \"help\" }\n",
+}
+
+func mockEphemeralZip(t *testing.T) *store.ZipFile {
+	t.Helper()
+	data, err := storetest.CreateZip(ephemeralIndexFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf, err := storetest.MockZipFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zf
+}
+
+func TestEphemeralIndexCandidates(t *testing.T) {
+	zf := mockEphemeralZip(t)
+
+	var buf bytes.Buffer
+	if err := buildEphemeralIndex(zf).write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := readEphemeralIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		literal string
+		want    []string
+		ok      bool
+	}{
+		{literal: "hello", want: []string{"README.md", "main.go"}, ok: true},
+		{literal: "HELLO", want: []string{"README.md", "main.go"}, ok: true},
+		{literal: "func", want: []string{"lib.go", "main.go"}, ok: true},
+		{literal: "package lib", want: []string{"lib.go"}, ok: true},
+		{literal: "missing", want: []string{}, ok: true},
+		{literal: "go", ok: false},
+		{literal: "", ok: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.literal, func(t *testing.T) {
+			files, ok := ix.candidates(zf, []byte(tc.literal))
+			if ok != tc.ok {
+				t.Fatalf("got ok %v, want %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			got := []string{}
+			for _, f := range files {
+				got = append(got, f.Name)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected candidates (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("mismatched archive", func(t *testing.T) {
+		other := &store.ZipFile{Files: zf.Files[:1], Data: zf.Data}
+		if _, ok := ix.candidates(other, []byte("hello")); ok {
+			t.Error("expected index not to be used for another archive")
+		}
+	})
+}
+
+func TestReadEphemeralIndexInvalid(t *testing.T) {
+	if _, err := readEphemeralIndex(bytes.NewReader([]byte("not an index"))); err == nil {
+		t.Error("expected error for invalid index")
+	}
+
+	var buf bytes.Buffer
+	if err := buildEphemeralIndex(mockEphemeralZip(t)).write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readEphemeralIndex(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Error("expected error for truncated index")
+	}
+}
+
+func TestIndexLiteral(t *testing.T) {
+	cases := []struct {
+		pattern protocol.PatternInfo
+		want    string
+	}{
+		{protocol.PatternInfo{Pattern: "Hello"}, "hello"},
+		{protocol.PatternInfo{Pattern: "Hello", IsCaseSensitive: true}, "Hello"},
+		{protocol.PatternInfo{Pattern: "func +main", IsRegExp: true}, "func "},
+		{protocol.PatternInfo{Pattern: "[a-z]+ main", IsRegExp: true}, " main"},
+		{protocol.PatternInfo{Pattern: "^package [a-z]+$", IsRegExp: true}, "package "},
+		{protocol.PatternInfo{Pattern: "(?i)Hello", IsRegExp: true, IsCaseSensitive: true}, ""},
+		{protocol.PatternInfo{Pattern: "a|b", IsRegExp: true}, ""},
+		{protocol.PatternInfo{IncludePatterns: []string{"\\.go$"}, PathPatternsAreRegExps: true}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.pattern.Pattern, func(t *testing.T) {
+			rg, err := compile(&tc.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(indexLiteral(rg)); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEphemeralIndexes(t *testing.T) {
+	data, err := storetest.CreateZip(ephemeralIndexFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipPath, cleanup, err := storetest.TempZipFileOnDisk(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	dir, err := os.MkdirTemp("", "ephemeral-indexes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &EphemeralIndexes{
+		Path:         dir,
+		HotThreshold: 2,
+		ZipCache:     &store.ZipCache{},
+	}
+	if ix := s.Get(zipPath); ix != nil {
+		t.Fatal("expected no index before the archive is hot")
+	}
+
+	// The second search makes the archive hot and indexes it in the
+	// background.
+	deadline := time.Now().Add(10 * time.Second)
+	var ix *ephemeralIndex
+	for ix == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for index")
+		}
+		ix = s.Get(zipPath)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	zf, err := s.ZipCache.Get(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zf.Close()
+	files, ok := ix.candidates(zf, []byte("helper"))
+	if !ok || len(files) != 1 || files[0].Name != "lib.go" {
+		t.Fatalf("unexpected candidates %v (ok=%v)", files, ok)
+	}
+
+	// Searching with the index returns the same matches as without.
+	svc := &Service{Indexes: s}
+	for _, pattern := range []string{"hello", "func", "nothing here"} {
+		p := &protocol.Request{PatternInfo: protocol.PatternInfo{Pattern: pattern, PatternMatchesContent: true}}
+		rg, err := compile(&p.PatternInfo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _, err := regexSearchBatch(context.Background(), rg, zf, 100, true, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := regexSearchBatch(context.Background(), rg, svc.withIndex(zipPath, zf, rg, p), 100, true, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i].Path < want[j].Path })
+		sort.Slice(got, func(i, j int) bool { return got[i].Path < got[j].Path })
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("%q: indexed search differs (-want +got):\n%s", pattern, diff)
+		}
+	}
+}
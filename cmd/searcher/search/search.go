@@ -49,6 +49,10 @@ const (
 type Service struct {
 	Store *store.Store
 	Log   log15.Logger
+
+	// Indexes, if non-nil, indexes the archives of hot revisions to speed up
+	// their regexp searches.
+	Indexes *EphemeralIndexes
 }
 
 // ServeHTTP handles HTTP based search requests
@@ -112,10 +116,11 @@ func (s *Service) streamSearch(ctx context.Context, w http.ResponseWriter, p pro
 	ctx, cancel, stream := newLimitedStream(ctx, p.Limit, onMatches)
 	defer cancel()
 
-	deadlineHit, err := s.search(ctx, &p, stream)
+	missingBlobs, deadlineHit, err := s.search(ctx, &p, stream)
 	doneEvent := searcher.EventDone{
-		DeadlineHit: deadlineHit,
-		LimitHit:    stream.LimitHit(),
+		DeadlineHit:  deadlineHit,
+		LimitHit:     stream.LimitHit(),
+		MissingBlobs: missingBlobs,
 	}
 	if err != nil {
 		doneEvent.Error = err.Error()
@@ -130,7 +135,10 @@ func (s *Service) streamSearch(ctx context.Context, w http.ResponseWriter, p pro
 	}
 }
 
-func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchSender) (deadlineHit bool, err error) {
+// search searches p.Repo@p.Commit, and sends the matches to sender.
+// missingBlobs is the number of files of which only the name was searched
+// since gitserver has not fetched their content from a partial clone.
+func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchSender) (missingBlobs int, deadlineHit bool, err error) {
 	tr := nettrace.New("search", fmt.Sprintf("%s@%s", p.Repo, p.Commit))
 	tr.LazyPrintf("%s", p.Pattern)
 
@@ -140,6 +148,9 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 	span.SetTag("url", p.URL)
 	span.SetTag("commit", p.Commit)
 	span.SetTag("pattern", p.Pattern)
+	if p.PatternExpression != nil {
+		span.SetTag("patternExpression", p.PatternExpression.String())
+	}
 	span.SetTag("isRegExp", strconv.FormatBool(p.IsRegExp))
 	span.SetTag("isStructuralPat", strconv.FormatBool(p.IsStructuralPat))
 	span.SetTag("languages", p.Languages)
@@ -193,15 +204,24 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 	if p.IsStructuralPat && p.Indexed {
 		// Execute the new structural search path that directly calls Zoekt.
 		// TODO use limit in indexed structural search
-		return structuralSearchWithZoekt(ctx, p, sender)
+		deadlineHit, err = structuralSearchWithZoekt(ctx, p, sender)
+		return 0, deadlineHit, err
 	}
 
 	// Compile pattern before fetching from store incase it is bad.
-	var rg *readerGrep
-	if !p.IsStructuralPat {
+	var (
+		rg *readerGrep
+		eg *expressionGrep
+	)
+	if p.PatternExpression != nil {
+		eg, err = compileExpression(&p.PatternInfo)
+		if err != nil {
+			return 0, false, badRequestError{err.Error()}
+		}
+	} else if !p.IsStructuralPat {
 		rg, err = compile(&p.PatternInfo)
 		if err != nil {
-			return false, badRequestError{err.Error()}
+			return 0, false, badRequestError{err.Error()}
 		}
 	}
 
@@ -210,7 +230,7 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 	}
 	fetchTimeout, err := time.ParseDuration(p.FetchTimeout)
 	if err != nil {
-		return false, err
+		return 0, false, err
 	}
 	prepareCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
 	defer cancel()
@@ -226,26 +246,49 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender matchS
 
 	zipPath, zf, err := store.GetZipFileWithRetry(getZf)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to get archive")
+		return 0, false, errors.Wrap(err, "failed to get archive")
 	}
 	defer zf.Close()
+	missingBlobs = zf.MissingBlobs
 
 	nFiles := uint64(len(zf.Files))
 	bytes := int64(len(zf.Data))
 	tr.LazyPrintf("files=%d bytes=%d", nFiles, bytes)
 	span.LogFields(
 		otlog.Uint64("archive.files", nFiles),
-		otlog.Int64("archive.size", bytes))
+		otlog.Int64("archive.size", bytes),
+		otlog.Int("archive.missingBlobs", missingBlobs))
 	archiveFiles.Observe(float64(nFiles))
 	archiveSize.Observe(float64(bytes))
 
 	if p.IsStructuralPat {
-		return false, filteredStructuralSearch(ctx, zipPath, zf, &p.PatternInfo, p.Repo, sender)
+		return missingBlobs, false, filteredStructuralSearch(ctx, zipPath, zf, &p.PatternInfo, p.Repo, sender)
+	} else if eg != nil {
+		return missingBlobs, false, expressionSearch(ctx, eg, zf, p.PatternMatchesContent, p.PatternMatchesPath, sender)
 	} else {
-		return false, regexSearch(ctx, rg, zf, p.Limit, p.PatternMatchesContent, p.PatternMatchesPath, p.IsNegated, sender)
+		return missingBlobs, false, regexSearch(ctx, rg, s.withIndex(zipPath, zf, rg, p), p.Limit, p.PatternMatchesContent, p.PatternMatchesPath, p.IsNegated, sender)
 	}
 }
 
+// withIndex returns zf restricted to the files which may match rg according
+// to the ephemeral index of the archive at zipPath. It returns zf if there is
+// no such index or it cannot be used for p.
+func (s *Service) withIndex(zipPath string, zf *store.ZipFile, rg *readerGrep, p *protocol.Request) *store.ZipFile {
+	ix := s.Indexes.Get(zipPath)
+	if ix == nil || !p.PatternMatchesContent || p.PatternMatchesPath || p.IsNegated {
+		indexSearches.WithLabelValues("false").Inc()
+		return zf
+	}
+	files, ok := ix.candidates(zf, indexLiteral(rg))
+	if !ok {
+		indexSearches.WithLabelValues("false").Inc()
+		return zf
+	}
+	indexSearches.WithLabelValues("true").Inc()
+	// The returned ZipFile shares the data of zf, which the caller closes.
+	return &store.ZipFile{Files: files, MaxLen: zf.MaxLen, Data: zf.Data}
+}
+
 func validateParams(p *protocol.Request) error {
 	if p.Repo == "" {
 		return errors.New("Repo must be non-empty")
@@ -254,12 +297,15 @@ func validateParams(p *protocol.Request) error {
 	if len(p.Commit) != 40 {
 		return errors.Errorf("Commit must be resolved (Commit=%q)", p.Commit)
 	}
-	if p.Pattern == "" && p.ExcludePattern == "" && len(p.IncludePatterns) == 0 {
+	if p.Pattern == "" && p.PatternExpression == nil && p.ExcludePattern == "" && len(p.IncludePatterns) == 0 {
 		return errors.New("At least one of pattern and include/exclude pattners must be non-empty")
 	}
 	if p.IsNegated && p.IsStructuralPat {
 		return errors.New("Negated patterns are not supported for structural searches")
 	}
+	if p.PatternExpression != nil && p.IsStructuralPat {
+		return errors.New("Pattern expressions are not supported for structural searches")
+	}
 	return nil
 }
 
@@ -0,0 +1,263 @@
+package search
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/pathmatch"
+	"github.com/sourcegraph/sourcegraph/internal/store"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// matchTree is a boolean pattern expression compiled for evaluation against a
+// single file at a time. Each leaf holds its own readerGrep, so like
+// readerGrep it is not concurrency safe.
+type matchTree struct {
+	kind     string
+	rg       *readerGrep // non-nil for pattern leaves
+	operands []*matchTree
+}
+
+// expressionGrep is responsible for finding files that satisfy a boolean
+// pattern expression, along with the LineMatches of the patterns that
+// contributed to the match.
+type expressionGrep struct {
+	tree *matchTree
+
+	// matchPath is compiled from the include/exclude path patterns and reports
+	// whether a file path matches (and should be searched).
+	matchPath pathmatch.PathMatcher
+}
+
+// compileExpression returns an expressionGrep for matching p.PatternExpression.
+// Every leaf of the expression is compiled with the options of p.
+func compileExpression(p *protocol.PatternInfo) (*expressionGrep, error) {
+	var compileNode func(n *protocol.PatternNode) (*matchTree, error)
+	compileNode = func(n *protocol.PatternNode) (*matchTree, error) {
+		switch n.Kind {
+		case protocol.PatternNodePattern:
+			if n.Value == "" {
+				return nil, errors.New("patterns in an expression must be non-empty")
+			}
+			leaf := *p
+			leaf.Pattern = n.Value
+			leaf.PatternExpression = nil
+			rg, err := compile(&leaf)
+			if err != nil {
+				return nil, err
+			}
+			return &matchTree{kind: n.Kind, rg: rg}, nil
+
+		case protocol.PatternNodeNot:
+			if len(n.Operands) != 1 {
+				return nil, errors.Errorf("not expression requires exactly one operand, got %d", len(n.Operands))
+			}
+
+		case protocol.PatternNodeAnd, protocol.PatternNodeOr:
+			if len(n.Operands) == 0 {
+				return nil, errors.Errorf("%s expression requires at least one operand", n.Kind)
+			}
+
+		default:
+			return nil, errors.Errorf("unknown expression kind %q", n.Kind)
+		}
+
+		operands := make([]*matchTree, 0, len(n.Operands))
+		for _, operand := range n.Operands {
+			t, err := compileNode(operand)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, t)
+		}
+		return &matchTree{kind: n.Kind, operands: operands}, nil
+	}
+
+	tree, err := compileNode(p.PatternExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	pathOptions := pathmatch.CompileOptions{
+		RegExp:        p.PathPatternsAreRegExps,
+		CaseSensitive: p.PathPatternsAreCaseSensitive,
+	}
+	matchPath, err := pathmatch.CompilePathPatterns(p.IncludePatterns, p.ExcludePattern, pathOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &expressionGrep{tree: tree, matchPath: matchPath}, nil
+}
+
+// Copy returns a copied version of eg that is safe to use from another
+// goroutine.
+func (eg *expressionGrep) Copy() *expressionGrep {
+	return &expressionGrep{tree: eg.tree.copy(), matchPath: eg.matchPath}
+}
+
+func (t *matchTree) copy() *matchTree {
+	c := &matchTree{kind: t.kind}
+	if t.rg != nil {
+		c.rg = t.rg.Copy()
+	}
+	for _, operand := range t.operands {
+		c.operands = append(c.operands, operand.copy())
+	}
+	return c
+}
+
+// match reports whether f satisfies t. The returned LineMatches are those of
+// the non-negated patterns that were required to satisfy t; they are not
+// merged or sorted.
+func (t *matchTree) match(zf *store.ZipFile, f *store.SrcFile, limit int, matchContent, matchPaths bool) (bool, []protocol.LineMatch, error) {
+	switch t.kind {
+	case protocol.PatternNodePattern:
+		var matches []protocol.LineMatch
+		if matchContent {
+			var err error
+			if matches, err = t.rg.Find(zf, f, limit); err != nil {
+				return false, nil, err
+			}
+		}
+		if len(matches) > 0 {
+			return true, matches, nil
+		}
+		return matchPaths && t.rg.matchString(f.Name), nil, nil
+
+	case protocol.PatternNodeNot:
+		matched, _, err := t.operands[0].match(zf, f, limit, matchContent, matchPaths)
+		return !matched, nil, err
+
+	case protocol.PatternNodeAnd:
+		var matches []protocol.LineMatch
+		for _, operand := range t.operands {
+			matched, lm, err := operand.match(zf, f, limit, matchContent, matchPaths)
+			if err != nil || !matched {
+				return false, nil, err
+			}
+			matches = append(matches, lm...)
+		}
+		return true, matches, nil
+
+	case protocol.PatternNodeOr:
+		// Every operand is evaluated (rather than stopping at the first
+		// match) so that all matching patterns are highlighted.
+		var (
+			matchedAny bool
+			matches    []protocol.LineMatch
+		)
+		for _, operand := range t.operands {
+			matched, lm, err := operand.match(zf, f, limit, matchContent, matchPaths)
+			if err != nil {
+				return false, nil, err
+			}
+			matchedAny = matchedAny || matched
+			matches = append(matches, lm...)
+		}
+		return matchedAny, matches, nil
+	}
+
+	return false, nil, errors.Errorf("unknown expression kind %q", t.kind)
+}
+
+// mergeLineMatches combines the LineMatches of several patterns into a single
+// list ordered by line number, with one LineMatch per line whose ranges are
+// ordered by offset.
+func mergeLineMatches(matches []protocol.LineMatch) []protocol.LineMatch {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	byLine := make(map[int]int, len(matches)) // line number -> index into merged
+	merged := make([]protocol.LineMatch, 0, len(matches))
+	for _, lm := range matches {
+		if i, ok := byLine[lm.LineNumber]; ok {
+			merged[i].OffsetAndLengths = append(merged[i].OffsetAndLengths, lm.OffsetAndLengths...)
+			continue
+		}
+
+		lm.OffsetAndLengths = append([][2]int(nil), lm.OffsetAndLengths...)
+		byLine[lm.LineNumber] = len(merged)
+		merged = append(merged, lm)
+	}
+
+	sort.Slice(merged, func(i, j int) bool { return merged[i].LineNumber < merged[j].LineNumber })
+
+	for i := range merged {
+		ranges := merged[i].OffsetAndLengths
+		sort.Slice(ranges, func(i, j int) bool {
+			if ranges[i][0] == ranges[j][0] {
+				return ranges[i][1] < ranges[j][1]
+			}
+			return ranges[i][0] < ranges[j][0]
+		})
+
+		deduped := ranges[:0]
+		for _, r := range ranges {
+			if n := len(deduped); n == 0 || deduped[n-1] != r {
+				deduped = append(deduped, r)
+			}
+		}
+		merged[i].OffsetAndLengths = deduped
+	}
+
+	return merged
+}
+
+func expressionSearchBatch(ctx context.Context, eg *expressionGrep, zf *store.ZipFile, limit int, patternMatchesContent, patternMatchesPaths bool) ([]protocol.FileMatch, bool, error) {
+	ctx, cancel, sender := newLimitedStreamCollector(ctx, limit)
+	defer cancel()
+	err := expressionSearch(ctx, eg, zf, patternMatchesContent, patternMatchesPaths, sender)
+	return sender.Collected(), sender.LimitHit(), err
+}
+
+// expressionSearch concurrently searches files in zf for files that satisfy
+// the boolean pattern expression of eg. Unlike evaluating each pattern over
+// the whole archive and intersecting the results, every file is considered
+// exactly once.
+func expressionSearch(ctx context.Context, eg *expressionGrep, zf *store.ZipFile, patternMatchesContent, patternMatchesPaths bool, sender matchSender) error {
+	var err error
+	span, ctx := ot.StartSpanFromContext(ctx, "ExpressionSearch")
+	ext.Component.Set(span, "expression_search")
+	span.SetTag("path", eg.matchPath.String())
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	if !patternMatchesContent && !patternMatchesPaths {
+		patternMatchesContent = true
+	}
+
+	err = searchFiles(ctx, span, zf, eg.matchPath, func() fileMatchFunc {
+		eg := eg.Copy()
+		return func(f *store.SrcFile, limit int) (protocol.FileMatch, bool, error) {
+			matched, lm, err := eg.tree.match(zf, f, limit, patternMatchesContent, patternMatchesPaths)
+			if err != nil || !matched {
+				return protocol.FileMatch{}, false, err
+			}
+
+			lm = mergeLineMatches(lm)
+			matchCount := len(lm)
+			if matchCount == 0 {
+				// The file matched on its path or by the absence of a
+				// pattern; count it like a path match.
+				matchCount = 1
+			}
+			return protocol.FileMatch{
+				Path:        f.Name,
+				LineMatches: lm,
+				MatchCount:  matchCount,
+			}, true, nil
+		}
+	}, sender)
+	return err
+}
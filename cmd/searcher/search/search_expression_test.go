@@ -0,0 +1,177 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	storetest "github.com/sourcegraph/sourcegraph/internal/store/testutil"
+)
+
+func TestExpressionSearch(t *testing.T) {
+	zipData, err := storetest.CreateZip(map[string]string{
+		"both.go":    "package foo\n\nfunc bar() {}\n",
+		"foo.go":     "package foo\n",
+		"bar.go":     "func bar() {}\n",
+		"neither.go": "package baz\n",
+		"foo/bar.go": "package baz\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf, err := storetest.MockZipFile(zipData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := func(value string) *protocol.PatternNode {
+		return &protocol.PatternNode{Kind: protocol.PatternNodePattern, Value: value}
+	}
+	node := func(kind string, operands ...*protocol.PatternNode) *protocol.PatternNode {
+		return &protocol.PatternNode{Kind: kind, Operands: operands}
+	}
+
+	cases := []struct {
+		name         string
+		expression   *protocol.PatternNode
+		matchesPaths bool
+		want         []string
+	}{
+		{
+			name:       "and",
+			expression: node(protocol.PatternNodeAnd, pattern("foo"), pattern("bar")),
+			want:       []string{"both.go"},
+		},
+		{
+			name:       "or",
+			expression: node(protocol.PatternNodeOr, pattern("foo"), pattern("bar")),
+			want:       []string{"bar.go", "both.go", "foo.go"},
+		},
+		{
+			name:       "and not",
+			expression: node(protocol.PatternNodeAnd, pattern("foo"), node(protocol.PatternNodeNot, pattern("bar"))),
+			want:       []string{"foo.go"},
+		},
+		{
+			name:       "not",
+			expression: node(protocol.PatternNodeNot, node(protocol.PatternNodeOr, pattern("foo"), pattern("bar"))),
+			want:       []string{"foo/bar.go", "neither.go"},
+		},
+		{
+			name:         "and with paths",
+			expression:   node(protocol.PatternNodeAnd, pattern("foo"), pattern("bar")),
+			matchesPaths: true,
+			want:         []string{"both.go", "foo/bar.go"},
+		},
+		{
+			name: "nested",
+			expression: node(protocol.PatternNodeOr,
+				node(protocol.PatternNodeAnd, pattern("package"), pattern("baz")),
+				node(protocol.PatternNodeAnd, pattern("func"), node(protocol.PatternNodeNot, pattern("package"))),
+			),
+			want: []string{"bar.go", "foo/bar.go", "neither.go"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eg, err := compileExpression(&protocol.PatternInfo{
+				PatternExpression: tc.expression,
+				IsRegExp:          true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fileMatches, _, err := expressionSearchBatch(context.Background(), eg, zf, 100, true, tc.matchesPaths)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(fileMatches))
+			for _, fm := range fileMatches {
+				got = append(got, fm.Path)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got file matches %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpressionSearchLineMatches(t *testing.T) {
+	zipData, err := storetest.CreateZip(map[string]string{
+		"main.go": "package main\n\nfunc main() { main() }\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf, err := storetest.MockZipFile(zipData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eg, err := compileExpression(&protocol.PatternInfo{
+		PatternExpression: &protocol.PatternNode{
+			Kind: protocol.PatternNodeAnd,
+			Operands: []*protocol.PatternNode{
+				{Kind: protocol.PatternNodePattern, Value: "main"},
+				{Kind: protocol.PatternNodePattern, Value: "func"},
+				{Kind: protocol.PatternNodeNot, Operands: []*protocol.PatternNode{
+					{Kind: protocol.PatternNodePattern, Value: "import"},
+				}},
+			},
+		},
+		IsRegExp: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileMatches, _, err := expressionSearchBatch(context.Background(), eg, zf, 100, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []protocol.FileMatch{{
+		Path: "main.go",
+		LineMatches: []protocol.LineMatch{
+			{
+				Preview:          "package main",
+				LineNumber:       0,
+				OffsetAndLengths: [][2]int{{8, 4}},
+			},
+			{
+				Preview:          "func main() { main() }",
+				LineNumber:       2,
+				OffsetAndLengths: [][2]int{{0, 4}, {5, 4}, {14, 4}},
+			},
+		},
+		MatchCount: 2,
+	}}
+	if diff := cmp.Diff(want, fileMatches); diff != "" {
+		t.Fatalf("unexpected file matches (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompileExpression_invalid(t *testing.T) {
+	for _, expression := range []*protocol.PatternNode{
+		{Kind: protocol.PatternNodeAnd},
+		{Kind: protocol.PatternNodeNot, Operands: []*protocol.PatternNode{
+			{Kind: protocol.PatternNodePattern, Value: "a"},
+			{Kind: protocol.PatternNodePattern, Value: "b"},
+		}},
+		{Kind: protocol.PatternNodePattern},
+		{Kind: "xor"},
+		{Kind: protocol.PatternNodePattern, Value: "("},
+	} {
+		if _, err := compileExpression(&protocol.PatternInfo{PatternExpression: expression, IsRegExp: true}); err == nil {
+			t.Errorf("expected error compiling %s", expression)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"go.uber.org/atomic"
@@ -324,27 +325,10 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, limit i
 		patternMatchesContent = true
 	}
 
-	// If we reach limit we use cancel to stop the search
-	var cancel context.CancelFunc
-	if deadline, ok := ctx.Deadline(); ok {
-		// If a deadline is set, try to finish before the deadline expires.
-		timeout := time.Duration(0.9 * float64(time.Until(deadline)))
-		span.LogFields(otlog.Int64("RegexSearchTimeout", int64(timeout)))
-		ctx, cancel = context.WithTimeout(ctx, timeout)
-	} else {
-		ctx, cancel = context.WithCancel(ctx)
-	}
-	defer cancel()
-
-	var (
-		filesmu sync.Mutex // protects files
-		files   = zf.Files
-	)
-
 	if rg.re == nil || (patternMatchesPaths && !patternMatchesContent) {
 		// Fast path for only matching file paths (or with a nil pattern, which matches all files,
 		// so is effectively matching only on file paths).
-		for _, f := range files {
+		for _, f := range zf.Files {
 			if match := rg.matchPath.MatchPath(f.Name) && rg.matchString(f.Name); match == !isPatternNegated {
 				if ctx.Err() != nil {
 					return ctx.Err()
@@ -356,7 +340,51 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, limit i
 		return nil
 	}
 
+	err = searchFiles(ctx, span, zf, rg.matchPath, func() fileMatchFunc {
+		rg := rg.Copy()
+		return func(f *store.SrcFile, limit int) (protocol.FileMatch, bool, error) {
+			fm, err := rg.FindZip(zf, f, limit)
+			if err != nil {
+				return fm, false, err
+			}
+			match := len(fm.LineMatches) > 0
+			if !match && patternMatchesPaths {
+				// Try matching against the file path.
+				match = rg.matchString(f.Name)
+				if match {
+					fm.Path = f.Name
+				}
+			}
+			return fm, match == !isPatternNegated, nil
+		}
+	}, sender)
+	return err
+}
+
+// fileMatchFunc returns the match of a single file, and whether the file
+// matches.
+type fileMatchFunc func(f *store.SrcFile, limit int) (protocol.FileMatch, bool, error)
+
+// searchFiles concurrently searches the files in zf whose paths match
+// matchPath, and sends the files that match. newMatch is called once per
+// worker, since match functions are not concurrency safe.
+func searchFiles(ctx context.Context, span opentracing.Span, zf *store.ZipFile, matchPath pathmatch.PathMatcher, newMatch func() fileMatchFunc, sender matchSender) error {
+	// If we reach limit we use cancel to stop the search
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		// If a deadline is set, try to finish before the deadline expires.
+		timeout := time.Duration(0.9 * float64(time.Until(deadline)))
+		span.LogFields(otlog.Int64("SearchTimeout", int64(timeout)))
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
 	var (
+		filesmu sync.Mutex // protects files
+		files   = zf.Files
+
 		filesSkipped  atomic.Uint32
 		filesSearched atomic.Uint32
 	)
@@ -365,7 +393,7 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, limit i
 
 	// Start workers. They read from files and write to matches.
 	for i := 0; i < numWorkers; i++ {
-		rg := rg.Copy()
+		match := newMatch()
 		g.Go(func() error {
 			for ctx.Err() == nil {
 				// grab a file to work on
@@ -379,26 +407,18 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, limit i
 				filesmu.Unlock()
 
 				// decide whether to process, record that decision
-				if !rg.matchPath.MatchPath(f.Name) {
+				if !matchPath.MatchPath(f.Name) {
 					filesSkipped.Inc()
 					continue
 				}
 				filesSearched.Inc()
 
 				// process
-				fm, err := rg.FindZip(zf, f, sender.Remaining())
+				fm, ok, err := match(f, sender.Remaining())
 				if err != nil {
 					return err
 				}
-				match := len(fm.LineMatches) > 0
-				if !match && patternMatchesPaths {
-					// Try matching against the file path.
-					match = rg.matchString(f.Name)
-					if match {
-						fm.Path = f.Name
-					}
-				}
-				if match == !isPatternNegated {
+				if ok {
 					sender.Send(fm)
 				}
 			}
@@ -406,7 +426,7 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, limit i
 		})
 	}
 
-	err = g.Wait()
+	err := g.Wait()
 	if err == nil && ctx.Err() == context.DeadlineExceeded {
 		// We stopped early because we were about to hit the deadline.
 		err = ctx.Err()
@@ -379,6 +379,41 @@ func TestSearch_badrequest(t *testing.T) {
 				IsStructuralPat:        true,
 			},
 		},
+
+		// structural search with pattern expression
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			PatternInfo: protocol.PatternInfo{
+				PatternExpression: &protocol.PatternNode{
+					Kind: protocol.PatternNodeAnd,
+					Operands: []*protocol.PatternNode{
+						{Kind: protocol.PatternNodePattern, Value: "fmt.Println(:[_])"},
+						{Kind: protocol.PatternNodePattern, Value: "log.Println(:[_])"},
+					},
+				},
+				PathPatternsAreRegExps: true,
+				IsStructuralPat:        true,
+			},
+		},
+
+		// bad regexp in pattern expression
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			PatternInfo: protocol.PatternInfo{
+				PatternExpression: &protocol.PatternNode{
+					Kind: protocol.PatternNodeOr,
+					Operands: []*protocol.PatternNode{
+						{Kind: protocol.PatternNodePattern, Value: "test"},
+						{Kind: protocol.PatternNodePattern, Value: `\F`},
+					},
+				},
+				IsRegExp: true,
+			},
+		},
 	}
 
 	store, cleanup, err := newStore(nil)
@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+)
+
+type CallHierarchyConnectionResolver struct {
+	calls            []resolvers.AdjustedCall
+	cursor           *string
+	locationResolver *CachedLocationResolver
+}
+
+func NewCallHierarchyConnectionResolver(calls []resolvers.AdjustedCall, cursor *string, locationResolver *CachedLocationResolver) gql.CallHierarchyConnectionResolver {
+	return &CallHierarchyConnectionResolver{
+		calls:            calls,
+		cursor:           cursor,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *CallHierarchyConnectionResolver) Nodes(ctx context.Context) ([]gql.CallHierarchyItemResolver, error) {
+	resolvers := make([]gql.CallHierarchyItemResolver, 0, len(r.calls))
+	for i := range r.calls {
+		symbol, err := resolveLocation(ctx, r.locationResolver, r.calls[i].Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if symbol == nil {
+			// Symbol is defined in a commit unknown to gitserver
+			continue
+		}
+
+		resolvers = append(resolvers, &CallHierarchyItemResolver{
+			symbol:           symbol,
+			callSites:        r.calls[i].CallSites,
+			locationResolver: r.locationResolver,
+		})
+	}
+
+	return resolvers, nil
+}
+
+func (r *CallHierarchyConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	return encodeCursor(r.cursor), nil
+}
+
+type CallHierarchyItemResolver struct {
+	symbol           gql.LocationResolver
+	callSites        []resolvers.AdjustedLocation
+	locationResolver *CachedLocationResolver
+}
+
+func (r *CallHierarchyItemResolver) Symbol(ctx context.Context) (gql.LocationResolver, error) {
+	return r.symbol, nil
+}
+
+func (r *CallHierarchyItemResolver) CallSites(ctx context.Context) ([]gql.LocationResolver, error) {
+	return resolveLocations(ctx, r.locationResolver, r.callSites)
+}
@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+type PackageDependencyConnectionResolver struct {
+	dependencies     []store.PackageDependency
+	totalCount       int
+	nextOffset       *int
+	locationResolver *CachedLocationResolver
+}
+
+func NewPackageDependencyConnectionResolver(dependencies []store.PackageDependency, totalCount int, nextOffset *int, locationResolver *CachedLocationResolver) gql.PackageDependencyConnectionResolver {
+	return &PackageDependencyConnectionResolver{
+		dependencies:     dependencies,
+		totalCount:       totalCount,
+		nextOffset:       nextOffset,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *PackageDependencyConnectionResolver) Nodes(ctx context.Context) ([]gql.PackageDependencyResolver, error) {
+	resolvers := make([]gql.PackageDependencyResolver, 0, len(r.dependencies))
+	for _, dependency := range r.dependencies {
+		resolvers = append(resolvers, &PackageDependencyResolver{
+			dependency:       dependency,
+			locationResolver: r.locationResolver,
+		})
+	}
+
+	return resolvers, nil
+}
+
+func (r *PackageDependencyConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	return int32(r.totalCount), nil
+}
+
+func (r *PackageDependencyConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	return encodeIntCursor(toInt32(r.nextOffset)), nil
+}
+
+type PackageDependencyResolver struct {
+	dependency       store.PackageDependency
+	locationResolver *CachedLocationResolver
+}
+
+func (r *PackageDependencyResolver) Scheme() string  { return r.dependency.Scheme }
+func (r *PackageDependencyResolver) Name() string    { return r.dependency.Name }
+func (r *PackageDependencyResolver) Version() string { return r.dependency.Version }
+
+func (r *PackageDependencyResolver) Repository(ctx context.Context) (*gql.RepositoryResolver, error) {
+	if r.dependency.RepositoryID == 0 {
+		return nil, nil
+	}
+
+	return r.locationResolver.Repository(ctx, api.RepoID(r.dependency.RepositoryID))
+}
+
+// nextPackageDependencyOffset returns the offset of the page following the one that begins at the
+// given offset and contains the given number of results. A nil value is returned if the given page
+// is the last page of the result set.
+func nextPackageDependencyOffset(offset, pageSize, totalCount int) *int {
+	if next := offset + pageSize; next < totalCount {
+		return &next
+	}
+
+	return nil
+}
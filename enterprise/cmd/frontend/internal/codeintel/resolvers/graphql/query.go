@@ -12,10 +12,14 @@ import (
 // DefaultReferencesPageSize is the reference result page size when no limit is supplied.
 const DefaultReferencesPageSize = 100
 
+// DefaultCallHierarchyPageSize is the call hierarchy result page size when no limit is supplied.
+const DefaultCallHierarchyPageSize = 50
+
 // DefaultDiagnosticsPageSize is the diagnostic result page size when no limit is supplied.
 const DefaultDiagnosticsPageSize = 100
 
-// ErrIllegalLimit occurs when the user requests less than one object per page.
+// ErrIllegalLimit occurs when the user requests less than one object per page, or a negative
+// number of objects for connections which allow empty pages.
 var ErrIllegalLimit = errors.New("illegal limit")
 
 // ErrIllegalBounds occurs when a negative or zero-width bound is supplied by the user.
@@ -100,6 +104,42 @@ func (r *QueryResolver) References(ctx context.Context, args *gql.LSIFPagedQuery
 	return NewLocationConnectionResolver(locations, strPtr(cursor), r.locationResolver), nil
 }
 
+func (r *QueryResolver) IncomingCalls(ctx context.Context, args *gql.LSIFPagedQueryPositionArgs) (gql.CallHierarchyConnectionResolver, error) {
+	limit := derefInt32(args.First, DefaultCallHierarchyPageSize)
+	if limit <= 0 {
+		return nil, ErrIllegalLimit
+	}
+	cursor, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	calls, cursor, err := r.resolver.IncomingCalls(ctx, int(args.Line), int(args.Character), limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCallHierarchyConnectionResolver(calls, strPtr(cursor), r.locationResolver), nil
+}
+
+func (r *QueryResolver) OutgoingCalls(ctx context.Context, args *gql.LSIFPagedQueryPositionArgs) (gql.CallHierarchyConnectionResolver, error) {
+	limit := derefInt32(args.First, DefaultCallHierarchyPageSize)
+	if limit <= 0 {
+		return nil, ErrIllegalLimit
+	}
+	cursor, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	calls, cursor, err := r.resolver.OutgoingCalls(ctx, int(args.Line), int(args.Character), limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCallHierarchyConnectionResolver(calls, strPtr(cursor), r.locationResolver), nil
+}
+
 func (r *QueryResolver) Hover(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.HoverResolver, error) {
 	text, rx, exists, err := r.resolver.Hover(ctx, int(args.Line), int(args.Character))
 	if err != nil || !exists {
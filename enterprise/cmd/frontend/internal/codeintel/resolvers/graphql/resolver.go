@@ -19,8 +19,10 @@ import (
 )
 
 const (
-	DefaultUploadPageSize = 50
-	DefaultIndexPageSize  = 50
+	DefaultUploadPageSize            = 50
+	DefaultIndexPageSize             = 50
+	DefaultPackageDependencyPageSize = 50
+	MaxPackageDependencyPageSize     = 1000
 )
 
 var errAutoIndexingNotEnabled = errors.New("precise code intelligence auto indexing is not enabled")
@@ -440,6 +442,105 @@ func (r *Resolver) PreviewGitObjectFilter(ctx context.Context, id graphql.ID, ar
 	return previews, nil
 }
 
+// 🚨 SECURITY: dbstore layer handles authz for PackageDependents
+func (r *Resolver) PackageDependents(ctx context.Context, args *gql.PackageDependencyQueryArgs) (gql.PackageDependencyConnectionResolver, error) {
+	limit, err := packageDependencyLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeIntCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents, totalCount, err := r.resolver.PackageDependents(ctx, args.Scheme, args.Name, derefString(args.Version, ""), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPackageDependencyConnectionResolver(dependents, totalCount, nextPackageDependencyOffset(offset, len(dependents), totalCount), r.locationResolver), nil
+}
+
+// 🚨 SECURITY: dbstore layer handles authz for PackageDependencies
+func (r *Resolver) PackageDependencies(ctx context.Context, args *gql.PackageDependencyQueryArgs) (gql.PackageDependencyConnectionResolver, error) {
+	limit, err := packageDependencyLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeIntCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, totalCount, err := r.resolver.PackageDependencies(ctx, args.Scheme, args.Name, derefString(args.Version, ""), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPackageDependencyConnectionResolver(dependencies, totalCount, nextPackageDependencyOffset(offset, len(dependencies), totalCount), r.locationResolver), nil
+}
+
+// 🚨 SECURITY: dbstore layer handles authz for RepositoryDependents
+func (r *Resolver) RepositoryPackageDependents(ctx context.Context, id graphql.ID, args *gql.RepositoryPackageDependencyQueryArgs) (gql.PackageDependencyConnectionResolver, error) {
+	repositoryID, err := gql.UnmarshalRepositoryID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := packageDependencyLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeIntCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	dependents, totalCount, err := r.resolver.RepositoryDependents(ctx, int(repositoryID), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPackageDependencyConnectionResolver(dependents, totalCount, nextPackageDependencyOffset(offset, len(dependents), totalCount), r.locationResolver), nil
+}
+
+// 🚨 SECURITY: dbstore layer handles authz for RepositoryDependencies
+func (r *Resolver) RepositoryPackageDependencies(ctx context.Context, id graphql.ID, args *gql.RepositoryPackageDependencyQueryArgs) (gql.PackageDependencyConnectionResolver, error) {
+	repositoryID, err := gql.UnmarshalRepositoryID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := packageDependencyLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeIntCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, totalCount, err := r.resolver.RepositoryDependencies(ctx, int(repositoryID), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPackageDependencyConnectionResolver(dependencies, totalCount, nextPackageDependencyOffset(offset, len(dependencies), totalCount), r.locationResolver), nil
+}
+
+// packageDependencyLimit returns the page size requested by first, which is capped at
+// MaxPackageDependencyPageSize.
+func packageDependencyLimit(first *int32) (int, error) {
+	limit := derefInt32(first, DefaultPackageDependencyPageSize)
+	if limit < 0 {
+		return 0, ErrIllegalLimit
+	}
+	if limit > MaxPackageDependencyPageSize {
+		limit = MaxPackageDependencyPageSize
+	}
+	return limit, nil
+}
+
 // makeGetUploadsOptions translates the given GraphQL arguments into options defined by the
 // store.GetUploads operations.
 func makeGetUploadsOptions(ctx context.Context, args *gql.LSIFRepositoryUploadsQueryArgs) (store.GetUploadsOptions, error) {
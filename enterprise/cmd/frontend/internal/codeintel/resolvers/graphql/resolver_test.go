@@ -215,3 +215,73 @@ func TestMakeGetIndexesOptionsDefaults(t *testing.T) {
 		t.Errorf("unexpected opts (-want +got):\n%s", diff)
 	}
 }
+
+func TestPackageDependents(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	mockResolver := resolvermocks.NewMockResolver()
+	mockResolver.PackageDependentsFunc.SetDefaultReturn([]store.PackageDependency{
+		{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", RepositoryID: 50, RepositoryName: "n-50"},
+		{Scheme: "gomod", Name: "leftpad", Version: "0.2.0", RepositoryID: 51, RepositoryName: "n-51"},
+	}, 5, nil)
+
+	after := base64.StdEncoding.EncodeToString([]byte("2"))
+	connection, err := NewResolver(db, mockResolver).PackageDependents(context.Background(), &gql.PackageDependencyQueryArgs{
+		ConnectionArgs: graphqlutil.ConnectionArgs{First: intPtr(2)},
+		Scheme:         "gomod",
+		Name:           "leftpad",
+		After:          &after,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(mockResolver.PackageDependentsFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.PackageDependentsFunc.History()))
+	}
+	call := mockResolver.PackageDependentsFunc.History()[0]
+	if diff := cmp.Diff([]interface{}{"gomod", "leftpad", "", 2, 2}, call.Args()[1:]); diff != "" {
+		t.Errorf("unexpected args (-want +got):\n%s", diff)
+	}
+
+	if totalCount, err := connection.TotalCount(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if totalCount != 5 {
+		t.Errorf("unexpected total count. want=%d have=%d", 5, totalCount)
+	}
+
+	pageInfo, err := connection.PageInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if endCursor := pageInfo.EndCursor(); endCursor == nil || *endCursor != base64.StdEncoding.EncodeToString([]byte("4")) {
+		t.Errorf("unexpected end cursor. want=%q have=%v", "4", endCursor)
+	}
+}
+
+func TestPackageDependentsLimit(t *testing.T) {
+	db := new(dbtesting.MockDB)
+	mockResolver := resolvermocks.NewMockResolver()
+	resolver := NewResolver(db, mockResolver)
+
+	if _, err := resolver.PackageDependents(context.Background(), &gql.PackageDependencyQueryArgs{
+		ConnectionArgs: graphqlutil.ConnectionArgs{First: intPtr(-1)},
+	}); err != ErrIllegalLimit {
+		t.Fatalf("unexpected error. want=%q have=%v", ErrIllegalLimit, err)
+	}
+	if len(mockResolver.PackageDependentsFunc.History()) != 0 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 0, len(mockResolver.PackageDependentsFunc.History()))
+	}
+
+	if _, err := resolver.PackageDependents(context.Background(), &gql.PackageDependencyQueryArgs{
+		ConnectionArgs: graphqlutil.ConnectionArgs{First: intPtr(MaxPackageDependencyPageSize + 1)},
+	}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(mockResolver.PackageDependentsFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.PackageDependentsFunc.History()))
+	}
+	if limit := mockResolver.PackageDependentsFunc.History()[0].Arg4; limit != MaxPackageDependencyPageSize {
+		t.Errorf("unexpected limit. want=%d have=%d", MaxPackageDependencyPageSize, limit)
+	}
+}
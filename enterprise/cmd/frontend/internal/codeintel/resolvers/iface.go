@@ -30,6 +30,10 @@ type DBStore interface {
 	FindClosestDumpsFromGraphFragment(ctx context.Context, repositoryID int, commit, path string, rootMustEnclosePath bool, indexer string, graph *gitserver.CommitGraph) ([]dbstore.Dump, error)
 	DefinitionDumps(ctx context.Context, monikers []precise.QualifiedMonikerData) (_ []dbstore.Dump, err error)
 	ReferenceIDsAndFilters(ctx context.Context, repositoryID int, commit string, monikers []precise.QualifiedMonikerData, limit, offset int) (_ dbstore.PackageReferenceScanner, _ int, err error)
+	PackageDependents(ctx context.Context, scheme, name, version string, limit, offset int) ([]dbstore.PackageDependency, int, error)
+	PackageDependencies(ctx context.Context, scheme, name, version string, limit, offset int) ([]dbstore.PackageDependency, int, error)
+	RepositoryDependents(ctx context.Context, repositoryID, limit, offset int) ([]dbstore.PackageDependency, int, error)
+	RepositoryDependencies(ctx context.Context, repositoryID, limit, offset int) ([]dbstore.PackageDependency, int, error)
 	HasRepository(ctx context.Context, repositoryID int) (bool, error)
 	HasCommit(ctx context.Context, repositoryID int, commit string) (bool, error)
 	MarkRepositoryAsDirty(ctx context.Context, repositoryID int) error
@@ -50,6 +54,7 @@ type DBStore interface {
 type LSIFStore interface {
 	Exists(ctx context.Context, bundleID int, path string) (bool, error)
 	Stencil(ctx context.Context, bundelID int, path string) ([]lsifstore.Range, error)
+	SymbolRanges(ctx context.Context, bundleID int, path string) ([]lsifstore.Range, error)
 	Ranges(ctx context.Context, bundleID int, path string, startLine, endLine int) ([]lsifstore.CodeIntelligenceRange, error)
 	Definitions(ctx context.Context, bundleID int, path string, line, character, limit, offset int) ([]lsifstore.Location, int, error)
 	References(ctx context.Context, bundleID int, path string, line, character, limit, offset int) ([]lsifstore.Location, int, error)
@@ -92,12 +92,24 @@ type MockDBStore struct {
 	// MarkRepositoryAsDirtyFunc is an instance of a mock function object
 	// controlling the behavior of the method MarkRepositoryAsDirty.
 	MarkRepositoryAsDirtyFunc *DBStoreMarkRepositoryAsDirtyFunc
+	// PackageDependenciesFunc is an instance of a mock function object
+	// controlling the behavior of the method PackageDependencies.
+	PackageDependenciesFunc *DBStorePackageDependenciesFunc
+	// PackageDependentsFunc is an instance of a mock function object
+	// controlling the behavior of the method PackageDependents.
+	PackageDependentsFunc *DBStorePackageDependentsFunc
 	// ReferenceIDsAndFiltersFunc is an instance of a mock function object
 	// controlling the behavior of the method ReferenceIDsAndFilters.
 	ReferenceIDsAndFiltersFunc *DBStoreReferenceIDsAndFiltersFunc
 	// RepoNameFunc is an instance of a mock function object controlling the
 	// behavior of the method RepoName.
 	RepoNameFunc *DBStoreRepoNameFunc
+	// RepositoryDependenciesFunc is an instance of a mock function object
+	// controlling the behavior of the method RepositoryDependencies.
+	RepositoryDependenciesFunc *DBStoreRepositoryDependenciesFunc
+	// RepositoryDependentsFunc is an instance of a mock function object
+	// controlling the behavior of the method RepositoryDependents.
+	RepositoryDependentsFunc *DBStoreRepositoryDependentsFunc
 	// UpdateConfigurationPolicyFunc is an instance of a mock function
 	// object controlling the behavior of the method
 	// UpdateConfigurationPolicy.
@@ -217,6 +229,16 @@ func NewMockDBStore() *MockDBStore {
 				return nil
 			},
 		},
+		PackageDependenciesFunc: &DBStorePackageDependenciesFunc{
+			defaultHook: func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
+		PackageDependentsFunc: &DBStorePackageDependentsFunc{
+			defaultHook: func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
 		ReferenceIDsAndFiltersFunc: &DBStoreReferenceIDsAndFiltersFunc{
 			defaultHook: func(context.Context, int, string, []precise.QualifiedMonikerData, int, int) (dbstore.PackageReferenceScanner, int, error) {
 				return nil, 0, nil
@@ -227,6 +249,16 @@ func NewMockDBStore() *MockDBStore {
 				return "", nil
 			},
 		},
+		RepositoryDependenciesFunc: &DBStoreRepositoryDependenciesFunc{
+			defaultHook: func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
+		RepositoryDependentsFunc: &DBStoreRepositoryDependentsFunc{
+			defaultHook: func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
 		UpdateConfigurationPolicyFunc: &DBStoreUpdateConfigurationPolicyFunc{
 			defaultHook: func(context.Context, dbstore.ConfigurationPolicy) error {
 				return nil
@@ -307,12 +339,24 @@ func NewMockDBStoreFrom(i DBStore) *MockDBStore {
 		MarkRepositoryAsDirtyFunc: &DBStoreMarkRepositoryAsDirtyFunc{
 			defaultHook: i.MarkRepositoryAsDirty,
 		},
+		PackageDependenciesFunc: &DBStorePackageDependenciesFunc{
+			defaultHook: i.PackageDependencies,
+		},
+		PackageDependentsFunc: &DBStorePackageDependentsFunc{
+			defaultHook: i.PackageDependents,
+		},
 		ReferenceIDsAndFiltersFunc: &DBStoreReferenceIDsAndFiltersFunc{
 			defaultHook: i.ReferenceIDsAndFilters,
 		},
 		RepoNameFunc: &DBStoreRepoNameFunc{
 			defaultHook: i.RepoName,
 		},
+		RepositoryDependenciesFunc: &DBStoreRepositoryDependenciesFunc{
+			defaultHook: i.RepositoryDependencies,
+		},
+		RepositoryDependentsFunc: &DBStoreRepositoryDependentsFunc{
+			defaultHook: i.RepositoryDependents,
+		},
 		UpdateConfigurationPolicyFunc: &DBStoreUpdateConfigurationPolicyFunc{
 			defaultHook: i.UpdateConfigurationPolicy,
 		},
@@ -2690,6 +2734,254 @@ func (c DBStoreMarkRepositoryAsDirtyFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// DBStorePackageDependenciesFunc describes the behavior when the
+// PackageDependencies method of the parent MockDBStore instance is invoked.
+type DBStorePackageDependenciesFunc struct {
+	defaultHook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []DBStorePackageDependenciesFuncCall
+	mutex       sync.Mutex
+}
+
+// PackageDependencies delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDBStore) PackageDependencies(v0 context.Context, v1 string, v2 string, v3 string, v4 int, v5 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.PackageDependenciesFunc.nextHook()(v0, v1, v2, v3, v4, v5)
+	m.PackageDependenciesFunc.appendCall(DBStorePackageDependenciesFuncCall{v0, v1, v2, v3, v4, v5, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the PackageDependencies
+// method of the parent MockDBStore instance is invoked and the hook queue
+// is empty.
+func (f *DBStorePackageDependenciesFunc) SetDefaultHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// PackageDependencies method of the parent MockDBStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBStorePackageDependenciesFunc) PushHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStorePackageDependenciesFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStorePackageDependenciesFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBStorePackageDependenciesFunc) nextHook() func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStorePackageDependenciesFunc) appendCall(r0 DBStorePackageDependenciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStorePackageDependenciesFuncCall objects
+// describing the invocations of this function.
+func (f *DBStorePackageDependenciesFunc) History() []DBStorePackageDependenciesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStorePackageDependenciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStorePackageDependenciesFuncCall is an object that describes an
+// invocation of method PackageDependencies on an instance of MockDBStore.
+type DBStorePackageDependenciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStorePackageDependenciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStorePackageDependenciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBStorePackageDependentsFunc describes the behavior when the
+// PackageDependents method of the parent MockDBStore instance is invoked.
+type DBStorePackageDependentsFunc struct {
+	defaultHook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []DBStorePackageDependentsFuncCall
+	mutex       sync.Mutex
+}
+
+// PackageDependents delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDBStore) PackageDependents(v0 context.Context, v1 string, v2 string, v3 string, v4 int, v5 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.PackageDependentsFunc.nextHook()(v0, v1, v2, v3, v4, v5)
+	m.PackageDependentsFunc.appendCall(DBStorePackageDependentsFuncCall{v0, v1, v2, v3, v4, v5, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the PackageDependents
+// method of the parent MockDBStore instance is invoked and the hook queue
+// is empty.
+func (f *DBStorePackageDependentsFunc) SetDefaultHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// PackageDependents method of the parent MockDBStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBStorePackageDependentsFunc) PushHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStorePackageDependentsFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStorePackageDependentsFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBStorePackageDependentsFunc) nextHook() func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStorePackageDependentsFunc) appendCall(r0 DBStorePackageDependentsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStorePackageDependentsFuncCall objects
+// describing the invocations of this function.
+func (f *DBStorePackageDependentsFunc) History() []DBStorePackageDependentsFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStorePackageDependentsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStorePackageDependentsFuncCall is an object that describes an
+// invocation of method PackageDependents on an instance of MockDBStore.
+type DBStorePackageDependentsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStorePackageDependentsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStorePackageDependentsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // DBStoreReferenceIDsAndFiltersFunc describes the behavior when the
 // ReferenceIDsAndFilters method of the parent MockDBStore instance is
 // invoked.
@@ -2924,6 +3216,245 @@ func (c DBStoreRepoNameFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// DBStoreRepositoryDependenciesFunc describes the behavior when the
+// RepositoryDependencies method of the parent MockDBStore instance is
+// invoked.
+type DBStoreRepositoryDependenciesFunc struct {
+	defaultHook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []DBStoreRepositoryDependenciesFuncCall
+	mutex       sync.Mutex
+}
+
+// RepositoryDependencies delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockDBStore) RepositoryDependencies(v0 context.Context, v1 int, v2 int, v3 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.RepositoryDependenciesFunc.nextHook()(v0, v1, v2, v3)
+	m.RepositoryDependenciesFunc.appendCall(DBStoreRepositoryDependenciesFuncCall{v0, v1, v2, v3, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the
+// RepositoryDependencies method of the parent MockDBStore instance is
+// invoked and the hook queue is empty.
+func (f *DBStoreRepositoryDependenciesFunc) SetDefaultHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RepositoryDependencies method of the parent MockDBStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *DBStoreRepositoryDependenciesFunc) PushHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreRepositoryDependenciesFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreRepositoryDependenciesFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBStoreRepositoryDependenciesFunc) nextHook() func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreRepositoryDependenciesFunc) appendCall(r0 DBStoreRepositoryDependenciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStoreRepositoryDependenciesFuncCall
+// objects describing the invocations of this function.
+func (f *DBStoreRepositoryDependenciesFunc) History() []DBStoreRepositoryDependenciesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreRepositoryDependenciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreRepositoryDependenciesFuncCall is an object that describes an
+// invocation of method RepositoryDependencies on an instance of
+// MockDBStore.
+type DBStoreRepositoryDependenciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreRepositoryDependenciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreRepositoryDependenciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBStoreRepositoryDependentsFunc describes the behavior when the
+// RepositoryDependents method of the parent MockDBStore instance is
+// invoked.
+type DBStoreRepositoryDependentsFunc struct {
+	defaultHook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []DBStoreRepositoryDependentsFuncCall
+	mutex       sync.Mutex
+}
+
+// RepositoryDependents delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDBStore) RepositoryDependents(v0 context.Context, v1 int, v2 int, v3 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.RepositoryDependentsFunc.nextHook()(v0, v1, v2, v3)
+	m.RepositoryDependentsFunc.appendCall(DBStoreRepositoryDependentsFuncCall{v0, v1, v2, v3, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the RepositoryDependents
+// method of the parent MockDBStore instance is invoked and the hook queue
+// is empty.
+func (f *DBStoreRepositoryDependentsFunc) SetDefaultHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RepositoryDependents method of the parent MockDBStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *DBStoreRepositoryDependentsFunc) PushHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreRepositoryDependentsFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreRepositoryDependentsFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBStoreRepositoryDependentsFunc) nextHook() func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreRepositoryDependentsFunc) appendCall(r0 DBStoreRepositoryDependentsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStoreRepositoryDependentsFuncCall objects
+// describing the invocations of this function.
+func (f *DBStoreRepositoryDependentsFunc) History() []DBStoreRepositoryDependentsFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreRepositoryDependentsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreRepositoryDependentsFuncCall is an object that describes an
+// invocation of method RepositoryDependents on an instance of MockDBStore.
+type DBStoreRepositoryDependentsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreRepositoryDependentsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreRepositoryDependentsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // DBStoreUpdateConfigurationPolicyFunc describes the behavior when the
 // UpdateConfigurationPolicy method of the parent MockDBStore instance is
 // invoked.
@@ -5387,6 +5918,9 @@ type MockLSIFStore struct {
 	// StencilFunc is an instance of a mock function object controlling the
 	// behavior of the method Stencil.
 	StencilFunc *LSIFStoreStencilFunc
+	// SymbolRangesFunc is an instance of a mock function object controlling
+	// the behavior of the method SymbolRanges.
+	SymbolRangesFunc *LSIFStoreSymbolRangesFunc
 }
 
 // NewMockLSIFStore creates a new mock of the LSIFStore interface. All
@@ -5463,6 +5997,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return nil, nil
 			},
 		},
+		SymbolRangesFunc: &LSIFStoreSymbolRangesFunc{
+			defaultHook: func(context.Context, int, string) ([]lsifstore.Range, error) {
+				return nil, nil
+			},
+		},
 	}
 }
 
@@ -5512,6 +6051,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		StencilFunc: &LSIFStoreStencilFunc{
 			defaultHook: i.Stencil,
 		},
+		SymbolRangesFunc: &LSIFStoreSymbolRangesFunc{
+			defaultHook: i.SymbolRanges,
+		},
 	}
 }
 
@@ -7185,6 +7727,118 @@ func (c LSIFStoreStencilFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// LSIFStoreSymbolRangesFunc describes the behavior when the SymbolRanges
+// method of the parent MockLSIFStore instance is invoked.
+type LSIFStoreSymbolRangesFunc struct {
+	defaultHook func(context.Context, int, string) ([]lsifstore.Range, error)
+	hooks       []func(context.Context, int, string) ([]lsifstore.Range, error)
+	history     []LSIFStoreSymbolRangesFuncCall
+	mutex       sync.Mutex
+}
+
+// SymbolRanges delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockLSIFStore) SymbolRanges(v0 context.Context, v1 int, v2 string) ([]lsifstore.Range, error) {
+	r0, r1 := m.SymbolRangesFunc.nextHook()(v0, v1, v2)
+	m.SymbolRangesFunc.appendCall(LSIFStoreSymbolRangesFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the SymbolRanges method
+// of the parent MockLSIFStore instance is invoked and the hook queue is
+// empty.
+func (f *LSIFStoreSymbolRangesFunc) SetDefaultHook(hook func(context.Context, int, string) ([]lsifstore.Range, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SymbolRanges method of the parent MockLSIFStore instance invokes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *LSIFStoreSymbolRangesFunc) PushHook(hook func(context.Context, int, string) ([]lsifstore.Range, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreSymbolRangesFunc) SetDefaultReturn(r0 []lsifstore.Range, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, string) ([]lsifstore.Range, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreSymbolRangesFunc) PushReturn(r0 []lsifstore.Range, r1 error) {
+	f.PushHook(func(context.Context, int, string) ([]lsifstore.Range, error) {
+		return r0, r1
+	})
+}
+
+func (f *LSIFStoreSymbolRangesFunc) nextHook() func(context.Context, int, string) ([]lsifstore.Range, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreSymbolRangesFunc) appendCall(r0 LSIFStoreSymbolRangesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreSymbolRangesFuncCall objects
+// describing the invocations of this function.
+func (f *LSIFStoreSymbolRangesFunc) History() []LSIFStoreSymbolRangesFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreSymbolRangesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreSymbolRangesFuncCall is an object that describes an invocation
+// of method SymbolRanges on an instance of MockLSIFStore.
+type LSIFStoreSymbolRangesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []lsifstore.Range
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreSymbolRangesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreSymbolRangesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // MockRepoUpdaterClient is a mock implementation of the RepoUpdaterClient
 // interface (from the package
 // github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers)
@@ -40,6 +40,12 @@ type MockQueryResolver struct {
 	// HoverFunc is an instance of a mock function object controlling the
 	// behavior of the method Hover.
 	HoverFunc *QueryResolverHoverFunc
+	// IncomingCallsFunc is an instance of a mock function object
+	// controlling the behavior of the method IncomingCalls.
+	IncomingCallsFunc *QueryResolverIncomingCallsFunc
+	// OutgoingCallsFunc is an instance of a mock function object
+	// controlling the behavior of the method OutgoingCalls.
+	OutgoingCallsFunc *QueryResolverOutgoingCallsFunc
 	// RangesFunc is an instance of a mock function object controlling the
 	// behavior of the method Ranges.
 	RangesFunc *QueryResolverRangesFunc
@@ -95,6 +101,16 @@ func NewMockQueryResolver() *MockQueryResolver {
 				return "", lsifstore.Range{}, false, nil
 			},
 		},
+		IncomingCallsFunc: &QueryResolverIncomingCallsFunc{
+			defaultHook: func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+				return nil, "", nil
+			},
+		},
+		OutgoingCallsFunc: &QueryResolverOutgoingCallsFunc{
+			defaultHook: func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+				return nil, "", nil
+			},
+		},
 		RangesFunc: &QueryResolverRangesFunc{
 			defaultHook: func(context.Context, int, int) ([]resolvers.AdjustedCodeIntelligenceRange, error) {
 				return nil, nil
@@ -142,6 +158,12 @@ func NewMockQueryResolverFrom(i resolvers.QueryResolver) *MockQueryResolver {
 		HoverFunc: &QueryResolverHoverFunc{
 			defaultHook: i.Hover,
 		},
+		IncomingCallsFunc: &QueryResolverIncomingCallsFunc{
+			defaultHook: i.IncomingCalls,
+		},
+		OutgoingCallsFunc: &QueryResolverOutgoingCallsFunc{
+			defaultHook: i.OutgoingCalls,
+		},
 		RangesFunc: &QueryResolverRangesFunc{
 			defaultHook: i.Ranges,
 		},
@@ -1065,6 +1087,248 @@ func (c QueryResolverHoverFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2, c.Result3}
 }
 
+// QueryResolverIncomingCallsFunc describes the behavior when the
+// IncomingCalls method of the parent MockQueryResolver instance is invoked.
+type QueryResolverIncomingCallsFunc struct {
+	defaultHook func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)
+	hooks       []func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)
+	history     []QueryResolverIncomingCallsFuncCall
+	mutex       sync.Mutex
+}
+
+// IncomingCalls delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockQueryResolver) IncomingCalls(v0 context.Context, v1 int, v2 int, v3 int, v4 string) ([]resolvers.AdjustedCall, string, error) {
+	r0, r1, r2 := m.IncomingCallsFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.IncomingCallsFunc.appendCall(QueryResolverIncomingCallsFuncCall{v0, v1, v2, v3, v4, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the IncomingCalls method
+// of the parent MockQueryResolver instance is invoked and the hook queue is
+// empty.
+func (f *QueryResolverIncomingCallsFunc) SetDefaultHook(hook func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// IncomingCalls method of the parent MockQueryResolver instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *QueryResolverIncomingCallsFunc) PushHook(hook func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *QueryResolverIncomingCallsFunc) SetDefaultReturn(r0 []resolvers.AdjustedCall, r1 string, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *QueryResolverIncomingCallsFunc) PushReturn(r0 []resolvers.AdjustedCall, r1 string, r2 error) {
+	f.PushHook(func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *QueryResolverIncomingCallsFunc) nextHook() func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *QueryResolverIncomingCallsFunc) appendCall(r0 QueryResolverIncomingCallsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of QueryResolverIncomingCallsFuncCall objects
+// describing the invocations of this function.
+func (f *QueryResolverIncomingCallsFunc) History() []QueryResolverIncomingCallsFuncCall {
+	f.mutex.Lock()
+	history := make([]QueryResolverIncomingCallsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// QueryResolverIncomingCallsFuncCall is an object that describes an
+// invocation of method IncomingCalls on an instance of MockQueryResolver.
+type QueryResolverIncomingCallsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []resolvers.AdjustedCall
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 string
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c QueryResolverIncomingCallsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c QueryResolverIncomingCallsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// QueryResolverOutgoingCallsFunc describes the behavior when the
+// OutgoingCalls method of the parent MockQueryResolver instance is invoked.
+type QueryResolverOutgoingCallsFunc struct {
+	defaultHook func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)
+	hooks       []func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)
+	history     []QueryResolverOutgoingCallsFuncCall
+	mutex       sync.Mutex
+}
+
+// OutgoingCalls delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockQueryResolver) OutgoingCalls(v0 context.Context, v1 int, v2 int, v3 int, v4 string) ([]resolvers.AdjustedCall, string, error) {
+	r0, r1, r2 := m.OutgoingCallsFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.OutgoingCallsFunc.appendCall(QueryResolverOutgoingCallsFuncCall{v0, v1, v2, v3, v4, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the OutgoingCalls method
+// of the parent MockQueryResolver instance is invoked and the hook queue is
+// empty.
+func (f *QueryResolverOutgoingCallsFunc) SetDefaultHook(hook func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// OutgoingCalls method of the parent MockQueryResolver instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *QueryResolverOutgoingCallsFunc) PushHook(hook func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *QueryResolverOutgoingCallsFunc) SetDefaultReturn(r0 []resolvers.AdjustedCall, r1 string, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *QueryResolverOutgoingCallsFunc) PushReturn(r0 []resolvers.AdjustedCall, r1 string, r2 error) {
+	f.PushHook(func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *QueryResolverOutgoingCallsFunc) nextHook() func(context.Context, int, int, int, string) ([]resolvers.AdjustedCall, string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *QueryResolverOutgoingCallsFunc) appendCall(r0 QueryResolverOutgoingCallsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of QueryResolverOutgoingCallsFuncCall objects
+// describing the invocations of this function.
+func (f *QueryResolverOutgoingCallsFunc) History() []QueryResolverOutgoingCallsFuncCall {
+	f.mutex.Lock()
+	history := make([]QueryResolverOutgoingCallsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// QueryResolverOutgoingCallsFuncCall is an object that describes an
+// invocation of method OutgoingCalls on an instance of MockQueryResolver.
+type QueryResolverOutgoingCallsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []resolvers.AdjustedCall
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 string
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c QueryResolverOutgoingCallsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c QueryResolverOutgoingCallsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // QueryResolverRangesFunc describes the behavior when the Ranges method of
 // the parent MockQueryResolver instance is invoked.
 type QueryResolverRangesFunc struct {
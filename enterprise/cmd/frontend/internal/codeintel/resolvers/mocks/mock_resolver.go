@@ -63,6 +63,12 @@ type MockResolver struct {
 	// object controlling the behavior of the method
 	// InferredIndexConfiguration.
 	InferredIndexConfigurationFunc *ResolverInferredIndexConfigurationFunc
+	// PackageDependenciesFunc is an instance of a mock function object
+	// controlling the behavior of the method PackageDependencies.
+	PackageDependenciesFunc *ResolverPackageDependenciesFunc
+	// PackageDependentsFunc is an instance of a mock function object
+	// controlling the behavior of the method PackageDependents.
+	PackageDependentsFunc *ResolverPackageDependentsFunc
 	// PreviewGitObjectFilterFunc is an instance of a mock function object
 	// controlling the behavior of the method PreviewGitObjectFilter.
 	PreviewGitObjectFilterFunc *ResolverPreviewGitObjectFilterFunc
@@ -73,6 +79,12 @@ type MockResolver struct {
 	// object controlling the behavior of the method
 	// QueueAutoIndexJobsForRepo.
 	QueueAutoIndexJobsForRepoFunc *ResolverQueueAutoIndexJobsForRepoFunc
+	// RepositoryDependenciesFunc is an instance of a mock function object
+	// controlling the behavior of the method RepositoryDependencies.
+	RepositoryDependenciesFunc *ResolverRepositoryDependenciesFunc
+	// RepositoryDependentsFunc is an instance of a mock function object
+	// controlling the behavior of the method RepositoryDependents.
+	RepositoryDependentsFunc *ResolverRepositoryDependentsFunc
 	// UpdateConfigurationPolicyFunc is an instance of a mock function
 	// object controlling the behavior of the method
 	// UpdateConfigurationPolicy.
@@ -160,6 +172,16 @@ func NewMockResolver() *MockResolver {
 				return nil, false, nil
 			},
 		},
+		PackageDependenciesFunc: &ResolverPackageDependenciesFunc{
+			defaultHook: func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
+		PackageDependentsFunc: &ResolverPackageDependentsFunc{
+			defaultHook: func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
 		PreviewGitObjectFilterFunc: &ResolverPreviewGitObjectFilterFunc{
 			defaultHook: func(context.Context, int, dbstore.GitObjectType, string) (map[string][]string, error) {
 				return nil, nil
@@ -175,6 +197,16 @@ func NewMockResolver() *MockResolver {
 				return nil, nil
 			},
 		},
+		RepositoryDependenciesFunc: &ResolverRepositoryDependenciesFunc{
+			defaultHook: func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
+		RepositoryDependentsFunc: &ResolverRepositoryDependentsFunc{
+			defaultHook: func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+				return nil, 0, nil
+			},
+		},
 		UpdateConfigurationPolicyFunc: &ResolverUpdateConfigurationPolicyFunc{
 			defaultHook: func(context.Context, dbstore.ConfigurationPolicy) error {
 				return nil
@@ -239,6 +271,12 @@ func NewMockResolverFrom(i resolvers.Resolver) *MockResolver {
 		InferredIndexConfigurationFunc: &ResolverInferredIndexConfigurationFunc{
 			defaultHook: i.InferredIndexConfiguration,
 		},
+		PackageDependenciesFunc: &ResolverPackageDependenciesFunc{
+			defaultHook: i.PackageDependencies,
+		},
+		PackageDependentsFunc: &ResolverPackageDependentsFunc{
+			defaultHook: i.PackageDependents,
+		},
 		PreviewGitObjectFilterFunc: &ResolverPreviewGitObjectFilterFunc{
 			defaultHook: i.PreviewGitObjectFilter,
 		},
@@ -248,6 +286,12 @@ func NewMockResolverFrom(i resolvers.Resolver) *MockResolver {
 		QueueAutoIndexJobsForRepoFunc: &ResolverQueueAutoIndexJobsForRepoFunc{
 			defaultHook: i.QueueAutoIndexJobsForRepo,
 		},
+		RepositoryDependenciesFunc: &ResolverRepositoryDependenciesFunc{
+			defaultHook: i.RepositoryDependencies,
+		},
+		RepositoryDependentsFunc: &ResolverRepositoryDependentsFunc{
+			defaultHook: i.RepositoryDependents,
+		},
 		UpdateConfigurationPolicyFunc: &ResolverUpdateConfigurationPolicyFunc{
 			defaultHook: i.UpdateConfigurationPolicy,
 		},
@@ -1819,6 +1863,255 @@ func (c ResolverInferredIndexConfigurationFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
+// ResolverPackageDependenciesFunc describes the behavior when the
+// PackageDependencies method of the parent MockResolver instance is
+// invoked.
+type ResolverPackageDependenciesFunc struct {
+	defaultHook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []ResolverPackageDependenciesFuncCall
+	mutex       sync.Mutex
+}
+
+// PackageDependencies delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockResolver) PackageDependencies(v0 context.Context, v1 string, v2 string, v3 string, v4 int, v5 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.PackageDependenciesFunc.nextHook()(v0, v1, v2, v3, v4, v5)
+	m.PackageDependenciesFunc.appendCall(ResolverPackageDependenciesFuncCall{v0, v1, v2, v3, v4, v5, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the PackageDependencies
+// method of the parent MockResolver instance is invoked and the hook queue
+// is empty.
+func (f *ResolverPackageDependenciesFunc) SetDefaultHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// PackageDependencies method of the parent MockResolver instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *ResolverPackageDependenciesFunc) PushHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverPackageDependenciesFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverPackageDependenciesFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *ResolverPackageDependenciesFunc) nextHook() func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverPackageDependenciesFunc) appendCall(r0 ResolverPackageDependenciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverPackageDependenciesFuncCall objects
+// describing the invocations of this function.
+func (f *ResolverPackageDependenciesFunc) History() []ResolverPackageDependenciesFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverPackageDependenciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverPackageDependenciesFuncCall is an object that describes an
+// invocation of method PackageDependencies on an instance of MockResolver.
+type ResolverPackageDependenciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverPackageDependenciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverPackageDependenciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// ResolverPackageDependentsFunc describes the behavior when the
+// PackageDependents method of the parent MockResolver instance is invoked.
+type ResolverPackageDependentsFunc struct {
+	defaultHook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []ResolverPackageDependentsFuncCall
+	mutex       sync.Mutex
+}
+
+// PackageDependents delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockResolver) PackageDependents(v0 context.Context, v1 string, v2 string, v3 string, v4 int, v5 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.PackageDependentsFunc.nextHook()(v0, v1, v2, v3, v4, v5)
+	m.PackageDependentsFunc.appendCall(ResolverPackageDependentsFuncCall{v0, v1, v2, v3, v4, v5, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the PackageDependents
+// method of the parent MockResolver instance is invoked and the hook queue
+// is empty.
+func (f *ResolverPackageDependentsFunc) SetDefaultHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// PackageDependents method of the parent MockResolver instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *ResolverPackageDependentsFunc) PushHook(hook func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverPackageDependentsFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverPackageDependentsFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *ResolverPackageDependentsFunc) nextHook() func(context.Context, string, string, string, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverPackageDependentsFunc) appendCall(r0 ResolverPackageDependentsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverPackageDependentsFuncCall objects
+// describing the invocations of this function.
+func (f *ResolverPackageDependentsFunc) History() []ResolverPackageDependentsFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverPackageDependentsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverPackageDependentsFuncCall is an object that describes an
+// invocation of method PackageDependents on an instance of MockResolver.
+type ResolverPackageDependentsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverPackageDependentsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverPackageDependentsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // ResolverPreviewGitObjectFilterFunc describes the behavior when the
 // PreviewGitObjectFilter method of the parent MockResolver instance is
 // invoked.
@@ -2163,6 +2456,245 @@ func (c ResolverQueueAutoIndexJobsForRepoFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// ResolverRepositoryDependenciesFunc describes the behavior when the
+// RepositoryDependencies method of the parent MockResolver instance is
+// invoked.
+type ResolverRepositoryDependenciesFunc struct {
+	defaultHook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []ResolverRepositoryDependenciesFuncCall
+	mutex       sync.Mutex
+}
+
+// RepositoryDependencies delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockResolver) RepositoryDependencies(v0 context.Context, v1 int, v2 int, v3 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.RepositoryDependenciesFunc.nextHook()(v0, v1, v2, v3)
+	m.RepositoryDependenciesFunc.appendCall(ResolverRepositoryDependenciesFuncCall{v0, v1, v2, v3, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the
+// RepositoryDependencies method of the parent MockResolver instance is
+// invoked and the hook queue is empty.
+func (f *ResolverRepositoryDependenciesFunc) SetDefaultHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RepositoryDependencies method of the parent MockResolver instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *ResolverRepositoryDependenciesFunc) PushHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverRepositoryDependenciesFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverRepositoryDependenciesFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *ResolverRepositoryDependenciesFunc) nextHook() func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverRepositoryDependenciesFunc) appendCall(r0 ResolverRepositoryDependenciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverRepositoryDependenciesFuncCall
+// objects describing the invocations of this function.
+func (f *ResolverRepositoryDependenciesFunc) History() []ResolverRepositoryDependenciesFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverRepositoryDependenciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverRepositoryDependenciesFuncCall is an object that describes an
+// invocation of method RepositoryDependencies on an instance of
+// MockResolver.
+type ResolverRepositoryDependenciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverRepositoryDependenciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverRepositoryDependenciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// ResolverRepositoryDependentsFunc describes the behavior when the
+// RepositoryDependents method of the parent MockResolver instance is
+// invoked.
+type ResolverRepositoryDependentsFunc struct {
+	defaultHook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	hooks       []func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)
+	history     []ResolverRepositoryDependentsFuncCall
+	mutex       sync.Mutex
+}
+
+// RepositoryDependents delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockResolver) RepositoryDependents(v0 context.Context, v1 int, v2 int, v3 int) ([]dbstore.PackageDependency, int, error) {
+	r0, r1, r2 := m.RepositoryDependentsFunc.nextHook()(v0, v1, v2, v3)
+	m.RepositoryDependentsFunc.appendCall(ResolverRepositoryDependentsFuncCall{v0, v1, v2, v3, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the RepositoryDependents
+// method of the parent MockResolver instance is invoked and the hook queue
+// is empty.
+func (f *ResolverRepositoryDependentsFunc) SetDefaultHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RepositoryDependents method of the parent MockResolver instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *ResolverRepositoryDependentsFunc) PushHook(hook func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverRepositoryDependentsFunc) SetDefaultReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverRepositoryDependentsFunc) PushReturn(r0 []dbstore.PackageDependency, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *ResolverRepositoryDependentsFunc) nextHook() func(context.Context, int, int, int) ([]dbstore.PackageDependency, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverRepositoryDependentsFunc) appendCall(r0 ResolverRepositoryDependentsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverRepositoryDependentsFuncCall
+// objects describing the invocations of this function.
+func (f *ResolverRepositoryDependentsFunc) History() []ResolverRepositoryDependentsFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverRepositoryDependentsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverRepositoryDependentsFuncCall is an object that describes an
+// invocation of method RepositoryDependents on an instance of MockResolver.
+type ResolverRepositoryDependentsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.PackageDependency
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverRepositoryDependentsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverRepositoryDependentsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // ResolverUpdateConfigurationPolicyFunc describes the behavior when the
 // UpdateConfigurationPolicy method of the parent MockResolver instance is
 // invoked.
@@ -23,6 +23,8 @@ type operations struct {
 	documentationPathInfo     *observation.Operation
 	documentationReferences   *observation.Operation
 	hover                     *observation.Operation
+	incomingCalls             *observation.Operation
+	outgoingCalls             *observation.Operation
 	queryResolver             *observation.Operation
 	ranges                    *observation.Operation
 	references                *observation.Operation
@@ -65,6 +67,8 @@ func newOperations(observationContext *observation.Context) *operations {
 		documentationPathInfo:     op("DocumentationPathInfo"),
 		documentationReferences:   op("DocumentationReferences"),
 		hover:                     op("Hover"),
+		incomingCalls:             op("IncomingCalls"),
+		outgoingCalls:             op("OutgoingCalls"),
 		queryResolver:             op("QueryResolver"),
 		ranges:                    op("Ranges"),
 		references:                op("References"),
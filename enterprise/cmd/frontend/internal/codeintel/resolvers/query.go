@@ -17,6 +17,14 @@ type AdjustedLocation struct {
 	AdjustedRange  lsifstore.Range
 }
 
+// AdjustedCall pairs the definition of a calling (or called) symbol with the locations of the call
+// sites that link it to the target symbol of a call hierarchy request. All locations have been
+// adjusted to fit the target (originally requested) commit.
+type AdjustedCall struct {
+	Symbol    AdjustedLocation
+	CallSites []AdjustedLocation
+}
+
 // AdjustedDiagnostic is a diagnostic from within a particular upload. The adjusted commit denotes
 // the target commit for which the location was adjusted (the originally requested commit).
 type AdjustedDiagnostic struct {
@@ -53,6 +61,8 @@ type QueryResolver interface {
 	Ranges(ctx context.Context, startLine, endLine int) ([]AdjustedCodeIntelligenceRange, error)
 	Definitions(ctx context.Context, line, character int) ([]AdjustedLocation, error)
 	References(ctx context.Context, line, character, limit int, rawCursor string) ([]AdjustedLocation, string, error)
+	IncomingCalls(ctx context.Context, line, character, limit int, rawCursor string) ([]AdjustedCall, string, error)
+	OutgoingCalls(ctx context.Context, line, character, limit int, rawCursor string) ([]AdjustedCall, string, error)
 	Hover(ctx context.Context, line, character int) (string, lsifstore.Range, bool, error)
 	Diagnostics(ctx context.Context, limit int) ([]AdjustedDiagnostic, int, error)
 	DocumentationPage(ctx context.Context, pathID string) (*precise.DocumentationPageData, error)
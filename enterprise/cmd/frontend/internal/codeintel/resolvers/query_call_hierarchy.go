@@ -0,0 +1,400 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+const slowCallHierarchyRequestThreshold = time.Second
+
+// IncomingCalls returns the symbols that call the symbol at the given position, along with the
+// call sites within each calling symbol. The returned symbols can be used as the target of a
+// subsequent request to expand the next level of the call hierarchy.
+//
+// The pagination of this method follows the underlying references result set, so a calling
+// symbol may appear on multiple pages when its call sites span a page boundary.
+func (r *queryResolver) IncomingCalls(ctx context.Context, line, character, limit int, rawCursor string) (_ []AdjustedCall, _ string, err error) {
+	ctx, traceLog, endObservation := observeResolver(ctx, &err, "IncomingCalls", r.operations.incomingCalls, slowCallHierarchyRequestThreshold, observation.Args{
+		LogFields: []log.Field{
+			log.Int("repositoryID", r.repositoryID),
+			log.String("commit", r.commit),
+			log.String("path", r.path),
+			log.Int("numUploads", len(r.uploads)),
+			log.String("uploads", uploadIDsToString(r.uploads)),
+			log.Int("line", line),
+			log.Int("character", character),
+		},
+	})
+	defer endObservation()
+
+	cursor, err := decodeCallHierarchyCursor(rawCursor)
+	if err != nil {
+		return nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
+	}
+
+	// Gather a page of locations referencing the target symbol. These locations include references
+	// from other repositories found via a moniker search over the uploads that import the symbol.
+
+	locations, uploadsByID, nextReferencesCursor, err := r.pageReferenceLocations(ctx, line, character, limit, cursor.ReferencesCursor, traceLog)
+	if err != nil {
+		return nil, "", err
+	}
+	traceLog(log.Int("numLocations", len(locations)))
+
+	// Map each reference to the symbol that encloses it. References that are not enclosed by
+	// a symbol (e.g., package-level initializers) and references that are themselves a symbol
+	// definition (e.g., the definition of the target symbol) are not calls and are skipped.
+
+	symbolRangesCache := map[symbolRangesKey][]lsifstore.Range{}
+	groups := newCallGroups()
+
+	for _, location := range locations {
+		symbolRanges, err := r.cachedSymbolRanges(ctx, symbolRangesCache, location.DumpID, location.Path)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if caller, ok := enclosingSymbolRange(symbolRanges, location.Range); ok {
+			groups.add(lsifstore.Location{DumpID: location.DumpID, Path: location.Path, Range: caller}, location)
+		}
+	}
+	traceLog(log.Int("numCallers", len(groups.symbols)))
+
+	adjustedCalls, err := r.adjustCalls(ctx, uploadsByID, groups)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if nextReferencesCursor != "" {
+		nextCursor = encodeCallHierarchyCursor(callHierarchyCursor{ReferencesCursor: nextReferencesCursor})
+	}
+
+	return adjustedCalls, nextCursor, nil
+}
+
+// OutgoingCalls returns the symbols called by the symbol at the given position, along with the call
+// sites of each called symbol within the body of the target symbol. The returned symbols can be used
+// as the target of a subsequent request to expand the next level of the call hierarchy.
+//
+// The body of the target symbol is approximated as the span between its definition and the next
+// symbol defined in the same document. Called symbols are resolved within the index containing the
+// definition of the target symbol, or via a moniker search for symbols defined in another index.
+func (r *queryResolver) OutgoingCalls(ctx context.Context, line, character, limit int, rawCursor string) (_ []AdjustedCall, _ string, err error) {
+	ctx, traceLog, endObservation := observeResolver(ctx, &err, "OutgoingCalls", r.operations.outgoingCalls, slowCallHierarchyRequestThreshold, observation.Args{
+		LogFields: []log.Field{
+			log.Int("repositoryID", r.repositoryID),
+			log.String("commit", r.commit),
+			log.String("path", r.path),
+			log.Int("numUploads", len(r.uploads)),
+			log.String("uploads", uploadIDsToString(r.uploads)),
+			log.Int("line", line),
+			log.Int("character", character),
+		},
+	})
+	defer endObservation()
+
+	cursor, err := decodeCallHierarchyCursor(rawCursor)
+	if err != nil {
+		return nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
+	}
+
+	// Determine the definition of the target symbol. This data may already be stashed in the
+	// cursor decoded above, in which case we don't need to perform a definitions request.
+
+	definition, uploadsByID, ok, err := r.definitionFromCursor(ctx, line, character, &cursor, traceLog)
+	if err != nil || !ok {
+		return nil, "", err
+	}
+
+	// Gather the ranges that occur within the body of the target symbol
+
+	body, callSites, err := r.callSiteRanges(ctx, definition)
+	if err != nil {
+		return nil, "", err
+	}
+	traceLog(log.Int("numCallSites", len(callSites)))
+
+	// Resolve the definitions of the called symbols, starting at the call site offset denoted by the
+	// cursor, until we fill an entire page of called symbols or we run out of call sites.
+
+	definitionUpload := uploadsByID[definition.DumpID]
+	groups := newCallGroups()
+
+	for cursor.Offset < len(callSites) && len(groups.symbols) < limit {
+		callSite := callSites[cursor.Offset]
+		cursor.Offset++
+
+		callSiteLocation := lsifstore.Location{DumpID: definition.DumpID, Path: definition.Path, Range: callSite.Range}
+
+		targets, err := r.callSiteDefinitions(ctx, definitionUpload, callSiteLocation, callSite.Definitions, uploadsByID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, target := range targets {
+			if target.DumpID == definition.DumpID && target.Path == definition.Path && spanContainsPosition(body, target.Range.Start) {
+				// Skip symbols defined within the body of the target symbol
+				continue
+			}
+
+			groups.add(target, callSiteLocation)
+		}
+	}
+	traceLog(log.Int("numCallees", len(groups.symbols)))
+
+	adjustedCalls, err := r.adjustCalls(ctx, uploadsByID, groups)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if cursor.Offset < len(callSites) {
+		nextCursor = encodeCallHierarchyCursor(cursor)
+	}
+
+	return adjustedCalls, nextCursor, nil
+}
+
+// definitionFromCursor returns the definition location of the symbol at the given position along with
+// a map containing its hydrated upload record. The definition location will be cached on the given cursor.
+// If this data is already stashed on the given cursor, we don't need to perform a definitions request. If
+// the symbol has no precise definition, a false-valued flag is returned.
+func (r *queryResolver) definitionFromCursor(ctx context.Context, line, character int, cursor *callHierarchyCursor, traceLog observation.TraceLogger) (lsifstore.Location, map[int]dbstore.Dump, bool, error) {
+	uploadsByID := make(map[int]dbstore.Dump, len(r.uploads))
+	for i := range r.uploads {
+		uploadsByID[r.uploads[i].ID] = r.uploads[i]
+	}
+
+	if cursor.Definition != nil {
+		uploads, err := r.uploadsByIDs(ctx, []int{cursor.Definition.DumpID}, uploadsByID)
+		if err != nil {
+			return lsifstore.Location{}, nil, false, err
+		}
+		if len(uploads) == 0 {
+			return lsifstore.Location{}, nil, false, ErrConcurrentModification
+		}
+		uploadsByID[uploads[0].ID] = uploads[0]
+
+		return *cursor.Definition, uploadsByID, true, nil
+	}
+
+	locations, definitionUploadsByID, err := r.definitionLocations(ctx, line, character, traceLog)
+	if err != nil || len(locations) == 0 {
+		return lsifstore.Location{}, nil, false, err
+	}
+	for id, upload := range definitionUploadsByID {
+		uploadsByID[id] = upload
+	}
+
+	cursor.Definition = &locations[0]
+	return locations[0], uploadsByID, true, nil
+}
+
+// callSiteRanges returns the ranges that occur within the body of the symbol defined at the given
+// location and that do not themselves define a symbol. The end of the body is taken to be the start
+// of the next symbol defined in the same document. The span of the body is also returned, with an
+// exclusive end position.
+func (r *queryResolver) callSiteRanges(ctx context.Context, definition lsifstore.Location) (lsifstore.Range, []lsifstore.CodeIntelligenceRange, error) {
+	symbolRanges, err := r.lsifStore.SymbolRanges(ctx, definition.DumpID, definition.Path)
+	if err != nil {
+		return lsifstore.Range{}, nil, errors.Wrap(err, "lsifStore.SymbolRanges")
+	}
+
+	body := lsifstore.Range{
+		Start: definition.Range.Start,
+		End:   lsifstore.Position{Line: math.MaxInt32},
+	}
+	for _, symbolRange := range symbolRanges {
+		if comparePositions(symbolRange.Start, definition.Range.End) > 0 {
+			body.End = symbolRange.Start
+			break
+		}
+	}
+
+	endLine := body.End.Line
+	if endLine != math.MaxInt32 {
+		// Ranges takes an exclusive end line
+		endLine++
+	}
+
+	ranges, err := r.lsifStore.Ranges(ctx, definition.DumpID, definition.Path, body.Start.Line, endLine)
+	if err != nil {
+		return lsifstore.Range{}, nil, errors.Wrap(err, "lsifStore.Ranges")
+	}
+
+	filtered := make([]lsifstore.CodeIntelligenceRange, 0, len(ranges))
+	for _, rn := range ranges {
+		if comparePositions(rn.Range.Start, definition.Range.End) <= 0 || !spanContainsPosition(body, rn.Range.Start) {
+			// Outside of the body of the target symbol
+			continue
+		}
+
+		if isDefinitionSite(definition, rn) {
+			// Skip definitions of local symbols
+			continue
+		}
+
+		filtered = append(filtered, rn)
+	}
+
+	return body, filtered, nil
+}
+
+// callSiteDefinitions returns the definition locations of the symbol referenced at the given call site.
+// Definitions found in the same index are used when available. Otherwise, a moniker search is performed
+// over the indexes defining one of the import monikers attached to the call site. Any upload records
+// pulled back from the database are added to the given upload map.
+func (r *queryResolver) callSiteDefinitions(ctx context.Context, upload dbstore.Dump, callSite lsifstore.Location, localDefinitions []lsifstore.Location, uploadsByID map[int]dbstore.Dump) ([]lsifstore.Location, error) {
+	if len(localDefinitions) > 0 {
+		return localDefinitions, nil
+	}
+
+	orderedMonikers, err := r.orderedMonikers(ctx, []adjustedUpload{{
+		Upload:               upload,
+		AdjustedPath:         upload.Root + callSite.Path,
+		AdjustedPosition:     callSite.Range.Start,
+		AdjustedPathInBundle: callSite.Path,
+	}}, "import")
+	if err != nil || len(orderedMonikers) == 0 {
+		return nil, err
+	}
+
+	uploads, err := r.definitionUploads(ctx, orderedMonikers)
+	if err != nil {
+		return nil, err
+	}
+	for i := range uploads {
+		uploadsByID[uploads[i].ID] = uploads[i]
+	}
+
+	locations, _, err := r.monikerLocations(ctx, uploads, orderedMonikers, "definitions", DefinitionsLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+// adjustCalls translates the symbol and call site locations of each of the given call groups into
+// an equivalent set of locations in the requested commit.
+func (r *queryResolver) adjustCalls(ctx context.Context, uploadsByID map[int]dbstore.Dump, groups *callGroups) ([]AdjustedCall, error) {
+	adjustedCalls := make([]AdjustedCall, 0, len(groups.symbols))
+	for _, symbol := range groups.symbols {
+		adjustedSymbol, err := r.adjustLocation(ctx, uploadsByID[symbol.DumpID], symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		adjustedCallSites, err := r.adjustLocations(ctx, uploadsByID, groups.callSites[symbol])
+		if err != nil {
+			return nil, err
+		}
+
+		adjustedCalls = append(adjustedCalls, AdjustedCall{
+			Symbol:    adjustedSymbol,
+			CallSites: adjustedCallSites,
+		})
+	}
+
+	return adjustedCalls, nil
+}
+
+type symbolRangesKey struct {
+	dumpID int
+	path   string
+}
+
+// cachedSymbolRanges returns the symbol ranges of the given document. Results are stored in the
+// given cache so that each document is read at most once while resolving a single page.
+func (r *queryResolver) cachedSymbolRanges(ctx context.Context, cache map[symbolRangesKey][]lsifstore.Range, dumpID int, path string) ([]lsifstore.Range, error) {
+	key := symbolRangesKey{dumpID: dumpID, path: path}
+	if symbolRanges, ok := cache[key]; ok {
+		return symbolRanges, nil
+	}
+
+	symbolRanges, err := r.lsifStore.SymbolRanges(ctx, dumpID, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "lsifStore.SymbolRanges")
+	}
+
+	cache[key] = symbolRanges
+	return symbolRanges, nil
+}
+
+// enclosingSymbolRange returns the symbol range that most closely precedes the given range. If the
+// given range is itself a symbol range, or if no symbol range precedes it, a false-valued flag is
+// returned. The given symbol ranges are expected to be ordered by position.
+func enclosingSymbolRange(symbolRanges []lsifstore.Range, rn lsifstore.Range) (lsifstore.Range, bool) {
+	var enclosing lsifstore.Range
+	found := false
+
+	for _, symbolRange := range symbolRanges {
+		if symbolRange == rn {
+			return lsifstore.Range{}, false
+		}
+		if comparePositions(symbolRange.Start, rn.Start) > 0 {
+			break
+		}
+
+		enclosing = symbolRange
+		found = true
+	}
+
+	return enclosing, found
+}
+
+// isDefinitionSite returns true if the given range is one of its own definitions.
+func isDefinitionSite(definition lsifstore.Location, rn lsifstore.CodeIntelligenceRange) bool {
+	for _, location := range rn.Definitions {
+		if location.DumpID == definition.DumpID && location.Path == definition.Path && location.Range == rn.Range {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spanContainsPosition returns true if the given position occurs within the given span. Unlike
+// rangeContainsPosition, the end position of the span is exclusive.
+func spanContainsPosition(span lsifstore.Range, pos lsifstore.Position) bool {
+	return comparePositions(span.Start, pos) <= 0 && comparePositions(pos, span.End) < 0
+}
+
+// comparePositions returns a negative value if a occurs before b, a positive value if a occurs after b,
+// and zero if they are equal.
+func comparePositions(a, b lsifstore.Position) int {
+	if a.Line != b.Line {
+		return a.Line - b.Line
+	}
+
+	return a.Character - b.Character
+}
+
+// callGroups groups call site locations by the symbol they are attributed to. Symbols are kept in
+// the order in which they are first seen.
+type callGroups struct {
+	symbols   []lsifstore.Location
+	callSites map[lsifstore.Location][]lsifstore.Location
+}
+
+func newCallGroups() *callGroups {
+	return &callGroups{callSites: map[lsifstore.Location][]lsifstore.Location{}}
+}
+
+func (g *callGroups) add(symbol, callSite lsifstore.Location) {
+	if _, ok := g.callSites[symbol]; !ok {
+		g.symbols = append(g.symbols, symbol)
+	}
+
+	g.callSites[symbol] = append(g.callSites[symbol], callSite)
+}
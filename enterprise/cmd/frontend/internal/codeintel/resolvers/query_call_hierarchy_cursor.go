@@ -0,0 +1,44 @@
+package resolvers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+)
+
+// callHierarchyCursor stores (enough of) the state of a previous IncomingCalls or OutgoingCalls
+// request used to calculate the offset into the result set to be returned by the current request.
+type callHierarchyCursor struct {
+	// ReferencesCursor is the encoded references cursor of the underlying references result set
+	// used to resolve incoming calls.
+	ReferencesCursor string `json:"referencesCursor"`
+
+	// Definition is the location of the definition of the target symbol used to resolve outgoing
+	// calls. Offset is the index of the next call site within the body of that definition.
+	Definition *lsifstore.Location `json:"definition"`
+	Offset     int                 `json:"offset"`
+}
+
+// decodeCallHierarchyCursor is the inverse of encodeCallHierarchyCursor. If the given encoded
+// string is empty, then a fresh cursor is returned.
+func decodeCallHierarchyCursor(rawEncoded string) (callHierarchyCursor, error) {
+	if rawEncoded == "" {
+		return callHierarchyCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(rawEncoded)
+	if err != nil {
+		return callHierarchyCursor{}, err
+	}
+
+	var cursor callHierarchyCursor
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+// encodeCallHierarchyCursor returns an encoding of the given cursor suitable for a URL or a GraphQL token.
+func encodeCallHierarchyCursor(cursor callHierarchyCursor) string {
+	rawEncoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(rawEncoded)
+}
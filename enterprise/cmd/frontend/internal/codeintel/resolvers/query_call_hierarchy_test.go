@@ -0,0 +1,226 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestIncomingCalls(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+
+	// Empty result set (prevents nil pointer as scanner is always non-nil)
+	mockDBStore.ReferenceIDsAndFiltersFunc.PushReturn(dbstore.PackageReferenceScannerFromSlice(), 0, nil)
+
+	locations := []lsifstore.Location{
+		{DumpID: 51, Path: "a.go", Range: callHierarchyTestRange(5, 2)},
+		{DumpID: 51, Path: "a.go", Range: callHierarchyTestRange(12, 4)},
+		{DumpID: 51, Path: "a.go", Range: callHierarchyTestRange(20, 5)}, // definition
+		{DumpID: 51, Path: "a.go", Range: callHierarchyTestRange(25, 2)},
+		{DumpID: 51, Path: "b.go", Range: callHierarchyTestRange(7, 1)}, // not enclosed
+	}
+	mockLSIFStore.ReferencesFunc.PushReturn(locations, len(locations), nil)
+
+	mockLSIFStore.SymbolRangesFunc.SetDefaultHook(func(ctx context.Context, bundleID int, path string) ([]lsifstore.Range, error) {
+		if path == "a.go" {
+			return []lsifstore.Range{callHierarchyTestRange(2, 5), callHierarchyTestRange(20, 5)}, nil
+		}
+
+		return nil, nil
+	})
+
+	uploads := []dbstore.Dump{
+		{ID: 51, Commit: "deadbeef", Root: "sub2/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+	adjustedCalls, cursor, err := resolver.IncomingCalls(context.Background(), 20, 6, 50, "")
+	if err != nil {
+		t.Fatalf("unexpected error querying incoming calls: %s", err)
+	}
+	if cursor != "" {
+		t.Errorf("unexpected cursor. want=%q have=%q", "", cursor)
+	}
+
+	expectedCalls := []AdjustedCall{
+		{
+			Symbol: AdjustedLocation{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(2, 5)},
+			CallSites: []AdjustedLocation{
+				{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(5, 2)},
+				{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(12, 4)},
+			},
+		},
+		{
+			Symbol: AdjustedLocation{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(20, 5)},
+			CallSites: []AdjustedLocation{
+				{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(25, 2)},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedCalls, adjustedCalls); diff != "" {
+		t.Errorf("unexpected calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutgoingCalls(t *testing.T) {
+	resolver, uploads, remoteUpload := newOutgoingCallsTestResolver()
+
+	adjustedCalls, cursor, err := resolver.OutgoingCalls(context.Background(), 10, 6, 50, "")
+	if err != nil {
+		t.Fatalf("unexpected error querying outgoing calls: %s", err)
+	}
+	if cursor != "" {
+		t.Errorf("unexpected cursor. want=%q have=%q", "", cursor)
+	}
+
+	expectedCalls := []AdjustedCall{
+		{
+			Symbol: AdjustedLocation{Dump: uploads[0], Path: "sub2/b.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(3, 5)},
+			CallSites: []AdjustedLocation{
+				{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(12, 2)},
+				{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(16, 2)},
+			},
+		},
+		{
+			Symbol: AdjustedLocation{Dump: remoteUpload, Path: "lib/lib.go", AdjustedCommit: "cafebabe", AdjustedRange: callHierarchyTestRange(1, 5)},
+			CallSites: []AdjustedLocation{
+				{Dump: uploads[0], Path: "sub2/a.go", AdjustedCommit: "deadbeef", AdjustedRange: callHierarchyTestRange(18, 2)},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedCalls, adjustedCalls); diff != "" {
+		t.Errorf("unexpected calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutgoingCallsPagination(t *testing.T) {
+	resolver, _, _ := newOutgoingCallsTestResolver()
+
+	var paths []string
+	var cursor string
+	for i := 0; ; i++ {
+		if i > 10 {
+			t.Fatalf("too many pages")
+		}
+
+		adjustedCalls, nextCursor, err := resolver.OutgoingCalls(context.Background(), 10, 6, 1, cursor)
+		if err != nil {
+			t.Fatalf("unexpected error querying outgoing calls: %s", err)
+		}
+		for _, call := range adjustedCalls {
+			paths = append(paths, call.Symbol.Path)
+		}
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	expectedPaths := []string{"sub2/b.go", "sub2/b.go", "lib/lib.go"}
+	if diff := cmp.Diff(expectedPaths, paths); diff != "" {
+		t.Errorf("unexpected paths (-want +got):\n%s", diff)
+	}
+}
+
+func TestEnclosingSymbolRange(t *testing.T) {
+	symbolRanges := []lsifstore.Range{
+		callHierarchyTestRange(2, 5),
+		callHierarchyTestRange(20, 5),
+	}
+
+	testCases := []struct {
+		rn       lsifstore.Range
+		expected lsifstore.Range
+		ok       bool
+	}{
+		{rn: callHierarchyTestRange(1, 0), ok: false},
+		{rn: callHierarchyTestRange(2, 5), ok: false},
+		{rn: callHierarchyTestRange(2, 9), expected: callHierarchyTestRange(2, 5), ok: true},
+		{rn: callHierarchyTestRange(19, 0), expected: callHierarchyTestRange(2, 5), ok: true},
+		{rn: callHierarchyTestRange(40, 0), expected: callHierarchyTestRange(20, 5), ok: true},
+	}
+
+	for _, testCase := range testCases {
+		enclosing, ok := enclosingSymbolRange(symbolRanges, testCase.rn)
+		if ok != testCase.ok {
+			t.Errorf("unexpected ok for %v. want=%v have=%v", testCase.rn, testCase.ok, ok)
+		} else if enclosing != testCase.expected {
+			t.Errorf("unexpected enclosing range for %v. want=%v have=%v", testCase.rn, testCase.expected, enclosing)
+		}
+	}
+}
+
+func newOutgoingCallsTestResolver() (*queryResolver, []dbstore.Dump, dbstore.Dump) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+	mockGitserverClient.CommitExistsFunc.SetDefaultReturn(true, nil)
+
+	definition := lsifstore.Location{DumpID: 51, Path: "a.go", Range: callHierarchyTestRange(10, 5)}
+	mockLSIFStore.DefinitionsFunc.SetDefaultReturn([]lsifstore.Location{definition}, 1, nil)
+	mockLSIFStore.SymbolRangesFunc.SetDefaultReturn([]lsifstore.Range{callHierarchyTestRange(10, 5), callHierarchyTestRange(30, 5)}, nil)
+
+	localDefinition := lsifstore.Location{DumpID: 51, Path: "a.go", Range: callHierarchyTestRange(14, 2)}
+	calleeDefinition := lsifstore.Location{DumpID: 51, Path: "b.go", Range: callHierarchyTestRange(3, 5)}
+	mockLSIFStore.RangesFunc.SetDefaultReturn([]lsifstore.CodeIntelligenceRange{
+		{Range: callHierarchyTestRange(10, 5), Definitions: []lsifstore.Location{definition}},
+		{Range: callHierarchyTestRange(12, 2), Definitions: []lsifstore.Location{calleeDefinition}},
+		{Range: callHierarchyTestRange(14, 2), Definitions: []lsifstore.Location{localDefinition}},
+		{Range: callHierarchyTestRange(15, 2), Definitions: []lsifstore.Location{localDefinition}},
+		{Range: callHierarchyTestRange(16, 2), Definitions: []lsifstore.Location{calleeDefinition}},
+		{Range: callHierarchyTestRange(18, 2)},
+		{Range: callHierarchyTestRange(31, 2), Definitions: []lsifstore.Location{calleeDefinition}},
+	}, nil)
+
+	remoteUpload := dbstore.Dump{ID: 60, Commit: "cafebabe", Root: "lib/"}
+	mockLSIFStore.MonikersByPositionFunc.SetDefaultReturn([][]precise.MonikerData{
+		{{Kind: "import", Scheme: "gomod", Identifier: "lib.Func", PackageInformationID: "p1"}},
+	}, nil)
+	mockLSIFStore.PackageInformationFunc.SetDefaultReturn(precise.PackageInformationData{Name: "lib", Version: "v1.0.0"}, true, nil)
+	mockDBStore.DefinitionDumpsFunc.SetDefaultReturn([]dbstore.Dump{remoteUpload}, nil)
+	mockLSIFStore.BulkMonikerResultsFunc.SetDefaultReturn([]lsifstore.Location{{DumpID: 60, Path: "lib.go", Range: callHierarchyTestRange(1, 5)}}, 1, nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 51, Commit: "deadbeef", Root: "sub2/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+
+	return resolver, uploads, remoteUpload
+}
+
+func callHierarchyTestRange(line, character int) lsifstore.Range {
+	return lsifstore.Range{
+		Start: lsifstore.Position{Line: line, Character: character},
+		End:   lsifstore.Position{Line: line, Character: character + 3},
+	}
+}
@@ -35,6 +35,29 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	})
 	defer endObservation()
 
+	locations, uploadsByID, nextCursor, err := r.pageReferenceLocations(ctx, line, character, limit, rawCursor, traceLog)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Adjust the locations back to the appropriate range in the target commits. This adjusts
+	// locations within the repository the user is browsing so that it appears all references
+	// are occurring at the same commit they are looking at.
+
+	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
+	if err != nil {
+		return nil, "", err
+	}
+	traceLog(log.Int("numAdjustedLocations", len(adjustedLocations)))
+
+	return adjustedLocations, nextCursor, nil
+}
+
+// pageReferenceLocations returns a page of locations that reference the symbol at the given position.
+// The returned locations are relative to the upload in which they were found and have not been adjusted
+// to the target commit. The returned map contains the hydrated upload record for every location in the
+// page. A non-empty cursor is returned if there are more pages in the result set.
+func (r *queryResolver) pageReferenceLocations(ctx context.Context, line, character, limit int, rawCursor string, traceLog observation.TraceLogger) ([]lsifstore.Location, map[int]dbstore.Dump, string, error) {
 	// Maintain a map from identifers to hydrated upload records from the database. We use
 	// this map as a quick lookup when constructing the resulting location set. Any additional
 	// upload records pulled back from the database while processing this page will be added
@@ -50,7 +73,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	// cursor used to fetch the subsequent page of results in this result set.
 	cursor, err := decodeCursor(rawCursor)
 	if err != nil {
-		return nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
+		return nil, nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
 	}
 
 	// Adjust the path and position for each visible upload based on its git difference to
@@ -59,7 +82,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 
 	adjustedUploads, err := r.adjustedUploadsFromCursor(ctx, line, character, uploadsByID, &cursor)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 
 	// Gather allmonikers attached to the ranges enclosing the requested position. This data
@@ -68,7 +91,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 
 	orderedMonikers, err := r.orderedMonikersFromCursor(ctx, adjustedUploads, &cursor)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	traceLog(
 		log.Int("numMonikers", len(orderedMonikers)),
@@ -81,7 +104,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 
 	definitionUploadIDs, definitionUploads, err := r.definitionUploadIDsFromCursor(ctx, adjustedUploads, orderedMonikers, &cursor)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	traceLog(
 		log.Int("numDefinitionUploads", len(definitionUploadIDs)),
@@ -98,26 +121,16 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	// Query a single page of location results
 	locations, hasMore, err := r.pageReferences(ctx, adjustedUploads, orderedMonikers, definitionUploadIDs, uploadsByID, &cursor, limit)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	traceLog(log.Int("numLocations", len(locations)))
 
-	// Adjust the locations back to the appropriate range in the target commits. This adjusts
-	// locations within the repository the user is browsing so that it appears all references
-	// are occurring at the same commit they are looking at.
-
-	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
-	if err != nil {
-		return nil, "", err
-	}
-	traceLog(log.Int("numAdjustedLocations", len(adjustedLocations)))
-
 	nextCursor := ""
 	if hasMore {
 		nextCursor = encodeCursor(cursor)
 	}
 
-	return adjustedLocations, nextCursor, nil
+	return locations, uploadsByID, nextCursor, nil
 }
 
 // ErrConcurrentModification occurs when a page of a references request cannot be resolved as
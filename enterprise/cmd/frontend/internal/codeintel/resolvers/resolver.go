@@ -41,6 +41,10 @@ type Resolver interface {
 	InferredIndexConfiguration(ctx context.Context, repositoryID int) (*config.IndexConfiguration, bool, error)
 	UpdateIndexConfigurationByRepositoryID(ctx context.Context, repositoryID int, configuration string) error
 	PreviewGitObjectFilter(ctx context.Context, repositoryID int, gitObjectType dbstore.GitObjectType, pattern string) (map[string][]string, error)
+	PackageDependents(ctx context.Context, scheme, name, version string, limit, offset int) ([]store.PackageDependency, int, error)
+	PackageDependencies(ctx context.Context, scheme, name, version string, limit, offset int) ([]store.PackageDependency, int, error)
+	RepositoryDependents(ctx context.Context, repositoryID, limit, offset int) ([]store.PackageDependency, int, error)
+	RepositoryDependencies(ctx context.Context, repositoryID, limit, offset int) ([]store.PackageDependency, int, error)
 }
 
 type resolver struct {
@@ -246,3 +250,19 @@ func (r *resolver) PreviewGitObjectFilter(ctx context.Context, repositoryID int,
 
 	return namesByCommit, nil
 }
+
+func (r *resolver) PackageDependents(ctx context.Context, scheme, name, version string, limit, offset int) ([]store.PackageDependency, int, error) {
+	return r.dbStore.PackageDependents(ctx, scheme, name, version, limit, offset)
+}
+
+func (r *resolver) PackageDependencies(ctx context.Context, scheme, name, version string, limit, offset int) ([]store.PackageDependency, int, error) {
+	return r.dbStore.PackageDependencies(ctx, scheme, name, version, limit, offset)
+}
+
+func (r *resolver) RepositoryDependents(ctx context.Context, repositoryID, limit, offset int) ([]store.PackageDependency, int, error) {
+	return r.dbStore.RepositoryDependents(ctx, repositoryID, limit, offset)
+}
+
+func (r *resolver) RepositoryDependencies(ctx context.Context, repositoryID, limit, offset int) ([]store.PackageDependency, int, error) {
+	return r.dbStore.RepositoryDependencies(ctx, repositoryID, limit, offset)
+}
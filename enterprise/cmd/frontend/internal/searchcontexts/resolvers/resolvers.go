@@ -92,6 +92,7 @@ func (r *Resolver) CreateSearchContext(ctx context.Context, args graphqlbackend.
 			Public:          args.SearchContext.Public,
 			NamespaceUserID: namespaceUserID,
 			NamespaceOrgID:  namespaceOrgID,
+			Query:           stringOrEmpty(args.SearchContext.Query),
 		},
 		repositoryRevisions,
 	)
@@ -121,6 +122,7 @@ func (r *Resolver) UpdateSearchContext(ctx context.Context, args graphqlbackend.
 	updated.Name = args.SearchContext.Name
 	updated.Description = args.SearchContext.Description
 	updated.Public = args.SearchContext.Public
+	updated.Query = stringOrEmpty(args.SearchContext.Query)
 
 	searchContext, err := searchcontexts.UpdateSearchContextWithRepositoryRevisions(
 		ctx,
@@ -134,6 +136,13 @@ func (r *Resolver) UpdateSearchContext(ctx context.Context, args graphqlbackend.
 	return &searchContextResolver{searchContext, r.db}, nil
 }
 
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func repositoryByID(ctx context.Context, id graphql.ID, db dbutil.DB) (*graphqlbackend.RepositoryResolver, error) {
 	var repoID api.RepoID
 	if err := relay.UnmarshalSpec(id, &repoID); err != nil {
@@ -381,7 +390,7 @@ func (r *searchContextResolver) ViewerCanManage(ctx context.Context) bool {
 }
 
 func (r *searchContextResolver) Repositories(ctx context.Context) ([]graphqlbackend.SearchContextRepositoryRevisionsResolver, error) {
-	if searchcontexts.IsAutoDefinedSearchContext(r.sc) {
+	if searchcontexts.IsAutoDefinedSearchContext(r.sc) || searchcontexts.IsQueryDefinedSearchContext(r.sc) {
 		return []graphqlbackend.SearchContextRepositoryRevisionsResolver{}, nil
 	}
 
@@ -397,6 +406,10 @@ func (r *searchContextResolver) Repositories(ctx context.Context) ([]graphqlback
 	return searchContextRepositories, nil
 }
 
+func (r *searchContextResolver) Query() string {
+	return r.sc.Query
+}
+
 type searchContextConnectionResolver struct {
 	afterCursor    int32
 	searchContexts []graphqlbackend.SearchContextResolver
@@ -0,0 +1,22 @@
+package searchjobs
+
+import (
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+type Config struct {
+	env.BaseConfig
+
+	// UploadStoreConfig is the store the results of search jobs are read
+	// from. It must match the store configured for the worker.
+	UploadStoreConfig *uploadstore.Config
+}
+
+var config = &Config{}
+
+func init() {
+	uploadStoreConfig := &uploadstore.Config{}
+	uploadStoreConfig.Load()
+	config.UploadStoreConfig = uploadStoreConfig
+}
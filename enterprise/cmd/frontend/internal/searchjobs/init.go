@@ -0,0 +1,29 @@
+package searchjobs
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+)
+
+func Init(ctx context.Context, db dbutil.DB, outOfBandMigrationRunner *oobmigration.Runner, enterpriseServices *enterprise.Services, observationContext *observation.Context) error {
+	if err := config.UploadStoreConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid upload store configuration")
+	}
+
+	uploadStore, err := uploadstore.CreateLazy(ctx, config.UploadStoreConfig, observationContext)
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize upload store")
+	}
+
+	enterpriseServices.SearchJobsResolver = NewResolver(db)
+	enterpriseServices.SearchJobResultsHandler = newResultsHandler(db, searchjobs.NewStore(db), uploadStore)
+	return nil
+}
@@ -0,0 +1,245 @@
+package searchjobs
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// NewResolver returns a new SearchJobsResolver that uses the given database.
+func NewResolver(db dbutil.DB) graphqlbackend.SearchJobsResolver {
+	return &Resolver{db: db, store: searchjobs.NewStore(db)}
+}
+
+type Resolver struct {
+	db    dbutil.DB
+	store *searchjobs.Store
+}
+
+func (r *Resolver) NodeResolvers() map[string]graphqlbackend.NodeByIDFunc {
+	return map[string]graphqlbackend.NodeByIDFunc{
+		searchjobs.SearchJobKind: func(ctx context.Context, id graphql.ID) (graphqlbackend.Node, error) {
+			return r.searchJobByID(ctx, id)
+		},
+	}
+}
+
+func (r *Resolver) searchJobByID(ctx context.Context, id graphql.ID) (*searchJobResolver, error) {
+	searchJobID, err := searchjobs.UnmarshalSearchJobID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := r.store.GetSearchJob(ctx, searchJobID)
+	if err != nil {
+		if err == searchjobs.ErrNoResults {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Only the initiator and site admins may view a search job.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.db, job.InitiatorID); err != nil {
+		return nil, err
+	}
+	return &searchJobResolver{db: r.db, store: r.store, job: job}, nil
+}
+
+func (r *Resolver) SearchJobs(ctx context.Context, args *graphqlbackend.ListSearchJobsArgs) (graphqlbackend.SearchJobConnectionResolver, error) {
+	// 🚨 SECURITY: Users may only list their own search jobs.
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return nil, backend.ErrNotAuthenticated
+	}
+
+	opts := searchjobs.ListSearchJobsOpts{InitiatorID: a.UID, Limit: int(args.First)}
+	if args.After != nil {
+		before, err := strconv.ParseInt(*args.After, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		opts.Before = before
+	}
+	return &searchJobConnectionResolver{db: r.db, store: r.store, opts: opts}, nil
+}
+
+func (r *Resolver) CreateSearchJob(ctx context.Context, args *graphqlbackend.CreateSearchJobArgs) (graphqlbackend.SearchJobResolver, error) {
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return nil, backend.ErrNotAuthenticated
+	}
+
+	if err := searchjobs.ValidateQuery(args.Query); err != nil {
+		return nil, err
+	}
+
+	job, err := r.store.CreateSearchJob(ctx, a.UID, args.Query)
+	if err != nil {
+		return nil, err
+	}
+	return &searchJobResolver{db: r.db, store: r.store, job: job}, nil
+}
+
+type searchJobConnectionResolver struct {
+	db    dbutil.DB
+	store *searchjobs.Store
+	opts  searchjobs.ListSearchJobsOpts
+
+	// cache results because they are used by multiple fields
+	once sync.Once
+	jobs []*searchjobs.SearchJob
+	next int64
+	err  error
+}
+
+func (r *searchJobConnectionResolver) compute(ctx context.Context) ([]*searchjobs.SearchJob, int64, error) {
+	r.once.Do(func() {
+		opts := r.opts
+		if opts.Limit > 0 {
+			// Request one extra to determine if there are more pages
+			opts.Limit++
+		}
+
+		r.jobs, r.err = r.store.ListSearchJobs(ctx, opts)
+		if r.err != nil {
+			return
+		}
+		if r.opts.Limit > 0 && len(r.jobs) > r.opts.Limit {
+			r.jobs = r.jobs[:r.opts.Limit]
+			r.next = r.jobs[len(r.jobs)-1].ID
+		}
+	})
+	return r.jobs, r.next, r.err
+}
+
+func (r *searchJobConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.SearchJobResolver, error) {
+	jobs, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]graphqlbackend.SearchJobResolver, 0, len(jobs))
+	for _, job := range jobs {
+		resolvers = append(resolvers, &searchJobResolver{db: r.db, store: r.store, job: job})
+	}
+	return resolvers, nil
+}
+
+func (r *searchJobConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := r.store.CountSearchJobs(ctx, r.opts)
+	return int32(count), err
+}
+
+func (r *searchJobConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if next == 0 {
+		return graphqlutil.HasNextPage(false), nil
+	}
+	return graphqlutil.NextPageCursor(strconv.FormatInt(next, 10)), nil
+}
+
+type searchJobResolver struct {
+	db    dbutil.DB
+	store *searchjobs.Store
+	job   *searchjobs.SearchJob
+
+	statsOnce sync.Once
+	stats     searchjobs.RepoJobStats
+	statsErr  error
+}
+
+func (r *searchJobResolver) repoJobStats(ctx context.Context) (searchjobs.RepoJobStats, error) {
+	r.statsOnce.Do(func() {
+		r.stats, r.statsErr = r.store.GetRepoJobStats(ctx, r.job.ID)
+	})
+	return r.stats, r.statsErr
+}
+
+func (r *searchJobResolver) ID() graphql.ID {
+	return searchjobs.MarshalSearchJobID(r.job.ID)
+}
+
+func (r *searchJobResolver) Query() string {
+	return r.job.Query
+}
+
+func (r *searchJobResolver) State(ctx context.Context) (string, error) {
+	stats, err := r.repoJobStats(ctx)
+	if err != nil {
+		return "", err
+	}
+	return r.job.AggregateState(stats), nil
+}
+
+func (r *searchJobResolver) Creator(ctx context.Context) (*graphqlbackend.UserResolver, error) {
+	user, err := graphqlbackend.UserByIDInt32(ctx, r.db, r.job.InitiatorID)
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *searchJobResolver) CreatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.job.CreatedAt}
+}
+
+func (r *searchJobResolver) StartedAt() *graphqlbackend.DateTime {
+	return graphqlbackend.DateTimeOrNil(r.job.StartedAt)
+}
+
+func (r *searchJobResolver) FinishedAt() *graphqlbackend.DateTime {
+	return graphqlbackend.DateTimeOrNil(r.job.FinishedAt)
+}
+
+func (r *searchJobResolver) FailureMessage() *string {
+	return r.job.FailureMessage
+}
+
+func (r *searchJobResolver) RepoStats(ctx context.Context) (graphqlbackend.SearchJobRepoStatsResolver, error) {
+	stats, err := r.repoJobStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &repoStatsResolver{stats: stats}, nil
+}
+
+func (r *searchJobResolver) CSVURL() string {
+	return searchjobs.ResultsURLPath(r.job.ID, searchjobs.FormatCSV)
+}
+
+func (r *searchJobResolver) JSONLinesURL() string {
+	return searchjobs.ResultsURLPath(r.job.ID, searchjobs.FormatJSONLines)
+}
+
+type repoStatsResolver struct {
+	stats searchjobs.RepoJobStats
+}
+
+func (r *repoStatsResolver) Total() int32      { return r.stats.Total }
+func (r *repoStatsResolver) Completed() int32  { return r.stats.Completed }
+func (r *repoStatsResolver) Failed() int32     { return r.stats.Failed }
+func (r *repoStatsResolver) InProgress() int32 { return r.stats.InProgress }
+
+func (r *repoStatsResolver) MatchCount() int32 {
+	if r.stats.MatchCount > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(r.stats.MatchCount)
+}
@@ -0,0 +1,100 @@
+package searchjobs
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// newResultsHandler returns an HTTP handler that streams the results of a
+// search job found so far as CSV or JSON Lines. The search job and format are
+// read from the "id" and "format" route variables.
+func newResultsHandler(db dbutil.DB, store *searchjobs.Store, uploadStore uploadstore.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		vars := mux.Vars(r)
+
+		searchJobID, err := searchjobs.UnmarshalSearchJobID(graphql.ID(vars["id"]))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job, err := store.GetSearchJob(ctx, searchJobID)
+		if err != nil {
+			if err == searchjobs.ErrNoResults {
+				http.Error(w, "search job not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 🚨 SECURITY: Only the initiator and site admins may download the
+		// results of a search job.
+		if err := backend.CheckSiteAdminOrSameUser(ctx, db, job.InitiatorID); err != nil {
+			http.Error(w, err.Error(), errcode.HTTP(err))
+			return
+		}
+
+		repoJobs, err := store.ListCompletedRepoJobs(ctx, job.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		format := vars["format"]
+		switch format {
+		case searchjobs.FormatCSV:
+			w.Header().Set("Content-Type", "text/csv")
+		default:
+			w.Header().Set("Content-Type", "application/x-ndjson")
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="search-job-`+vars["id"]+`.`+format+`"`)
+
+		// Headers have been written from here on, so errors can only be
+		// logged.
+		if err := writeResults(ctx, w, uploadStore, format, job.ID, repoJobs); err != nil {
+			log15.Error("searchjobs: failed to write results", "searchJobID", job.ID, "error", err)
+		}
+	})
+}
+
+func writeResults(ctx context.Context, w io.Writer, uploadStore uploadstore.Store, format string, searchJobID int64, repoJobs []*searchjobs.RepoJob) error {
+	var csvWriter *searchjobs.CSVWriter
+	if format == searchjobs.FormatCSV {
+		csvWriter = searchjobs.NewCSVWriter(w)
+	}
+
+	for _, repoJob := range repoJobs {
+		rc, err := uploadStore.Get(ctx, searchjobs.ResultsKey(searchJobID, repoJob.ID))
+		if err != nil {
+			return err
+		}
+
+		if csvWriter != nil {
+			err = csvWriter.WriteResults(rc)
+		} else {
+			_, err = io.Copy(w, rc)
+		}
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if csvWriter != nil {
+		return csvWriter.Flush()
+	}
+	return nil
+}
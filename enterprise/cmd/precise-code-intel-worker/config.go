@@ -14,6 +14,8 @@ type Config struct {
 	WorkerPollInterval time.Duration
 	WorkerConcurrency  int
 	WorkerBudget       int64
+
+	CorrelationMemoryBudget int64
 }
 
 func (c *Config) Load() {
@@ -24,4 +26,5 @@ func (c *Config) Load() {
 	c.WorkerPollInterval = c.GetInterval("PRECISE_CODE_INTEL_WORKER_POLL_INTERVAL", "1s", "Interval between queries to the upload queue.")
 	c.WorkerConcurrency = c.GetInt("PRECISE_CODE_INTEL_WORKER_CONCURRENCY", "1", "The maximum number of indexes that can be processed concurrently.")
 	c.WorkerBudget = int64(c.GetInt("PRECISE_CODE_INTEL_WORKER_BUDGET", "0", "The amount of compressed input data (in bytes) a worker can process concurrently. Zero acts as an infinite budget."))
+	c.CorrelationMemoryBudget = int64(c.GetInt("PRECISE_CODE_INTEL_CORRELATION_MEMORY_BUDGET", "0", "The amount of hover, diagnostic, and documentation text (in bytes) a single upload can hold in memory during conversion before it is moved to a temporary file on disk. Zero acts as an infinite budget."))
 }
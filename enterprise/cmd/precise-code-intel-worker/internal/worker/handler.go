@@ -37,6 +37,7 @@ type handler struct {
 	gitserverClient GitserverClient
 	enableBudget    bool
 	budgetRemaining int64
+	memoryBudget    int64
 }
 
 var (
@@ -116,14 +117,18 @@ func (h *handler) handle(ctx context.Context, upload store.Upload) (requeued boo
 	}
 
 	return false, withUploadData(ctx, h.uploadStore, upload.ID, func(r io.Reader) (err error) {
-		groupedBundleData, err := conversion.Correlate(ctx, r, upload.Root, getChildren)
+		groupedBundleData, closeCorrelator, err := conversion.CorrelateWithOptions(ctx, r, upload.Root, getChildren, conversion.CorrelateOptions{
+			MemoryBudget: h.memoryBudget,
+		})
 		if err != nil {
 			return errors.Wrap(err, "conversion.Correlate")
 		}
+		// Ensure temporary files are released if we return before all data has been written
+		defer func() { _ = closeCorrelator() }()
 
 		// Note: this is writing to a different database than the block below, so we need to use a
 		// different transaction context (managed by the writeData function).
-		if err := writeData(ctx, h.lsifStore, upload, repo, isDefaultBranch, groupedBundleData); err != nil {
+		if err := writeData(ctx, h.lsifStore, upload, repo, isDefaultBranch, groupedBundleData, closeCorrelator); err != nil {
 			if isUniqueConstraintViolation(err) {
 				// If this is a unique constraint violation, then we've previously processed this same
 				// upload record up to this point, but failed to perform the transaction below. We can
@@ -265,8 +270,10 @@ func withUploadData(ctx context.Context, uploadStore uploadstore.Store, id int,
 	return nil
 }
 
-// writeData transactionally writes the given grouped bundle data into the given LSIF store.
-func writeData(ctx context.Context, lsifStore LSIFStore, upload dbstore.Upload, repo *types.Repo, isDefaultBranch bool, groupedBundleData *precise.GroupedBundleDataChans) (err error) {
+// writeData transactionally writes the given grouped bundle data into the given LSIF store. The given
+// close function is invoked once all data has been written; the transaction is rolled back if it fails,
+// as the data read from the correlator's temporary files may be incomplete.
+func writeData(ctx context.Context, lsifStore LSIFStore, upload dbstore.Upload, repo *types.Repo, isDefaultBranch bool, groupedBundleData *precise.GroupedBundleDataChans, closeCorrelator func() error) (err error) {
 	// Upsert values used for documentation search that have high contention. We do this with the raw LSIF store
 	// instead of in the transaction below because the rows being upserted tend to have heavy contention.
 	repositoryNameID, languageNameID, err := lsifStore.WriteDocumentationSearchPrework(ctx, upload, repo, isDefaultBranch)
@@ -304,6 +311,9 @@ func writeData(ctx context.Context, lsifStore LSIFStore, upload dbstore.Upload,
 	if err := tx.WriteDocumentationMappings(ctx, upload.ID, groupedBundleData.DocumentationMappings); err != nil {
 		return errors.Wrap(err, "store.WriteDocumentationMappings")
 	}
+	if err := closeCorrelator(); err != nil {
+		return errors.Wrap(err, "conversion.Correlate")
+	}
 
 	return nil
 }
@@ -23,6 +23,7 @@ func NewWorker(
 	pollInterval time.Duration,
 	numProcessorRoutines int,
 	budgetMax int64,
+	correlationMemoryBudget int64,
 	workerMetrics workerutil.WorkerMetrics,
 ) *workerutil.Worker {
 	rootContext := actor.WithActor(context.Background(), &actor.Actor{Internal: true})
@@ -35,6 +36,7 @@ func NewWorker(
 		gitserverClient: gitserverClient,
 		enableBudget:    budgetMax > 0,
 		budgetRemaining: budgetMax,
+		memoryBudget:    correlationMemoryBudget,
 	}
 
 	return dbworker.NewWorker(rootContext, workerStore, handler, workerutil.WorkerOptions{
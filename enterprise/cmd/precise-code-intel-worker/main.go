@@ -106,6 +106,7 @@ func main() {
 		config.WorkerPollInterval,
 		config.WorkerConcurrency,
 		config.WorkerBudget,
+		config.CorrelationMemoryBudget,
 		makeWorkerMetrics(observationContext),
 	)
 
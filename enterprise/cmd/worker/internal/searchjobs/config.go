@@ -0,0 +1,34 @@
+package searchjobs
+
+import (
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+type config struct {
+	env.BaseConfig
+
+	// UploadStoreConfig is the store the results of search jobs are written
+	// to. Results expire with the TTL of the store.
+	UploadStoreConfig *uploadstore.Config
+
+	RepoSearchConcurrency int
+}
+
+var configInst = &config{}
+
+func (c *config) Load() {
+	c.UploadStoreConfig = &uploadstore.Config{}
+	c.UploadStoreConfig.Load()
+
+	c.RepoSearchConcurrency = c.GetInt("SEARCH_JOBS_REPO_SEARCH_CONCURRENCY", "4", "The maximum number of repositories searched concurrently for search jobs.")
+}
+
+func (c *config) Validate() error {
+	var errs *multierror.Error
+	errs = multierror.Append(errs, c.BaseConfig.Validate())
+	errs = multierror.Append(errs, c.UploadStoreConfig.Validate())
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,52 @@
+package searchjobs
+
+import (
+	"context"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/cmd/worker/shared"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs/background"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+type searchJobsJob struct{}
+
+// NewSearchJobsJob returns the job that runs exhaustive search jobs and stores
+// their results for download.
+func NewSearchJobsJob() shared.Job {
+	return &searchJobsJob{}
+}
+
+func (j *searchJobsJob) Config() []env.Config {
+	return []env.Config{configInst}
+}
+
+func (j *searchJobsJob) Routines(ctx context.Context) ([]goroutine.BackgroundRoutine, error) {
+	observationContext := &observation.Context{
+		Logger:     log15.Root(),
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+
+	db, err := shared.InitDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	uploadStore, err := uploadstore.CreateLazy(context.Background(), configInst.UploadStoreConfig, observationContext)
+	if err != nil {
+		return nil, err
+	}
+
+	rootContext := actor.WithActor(context.Background(), &actor.Actor{Internal: true})
+	return background.NewRoutines(rootContext, searchjobs.NewStore(db), uploadStore, configInst.RepoSearchConcurrency, observationContext), nil
+}
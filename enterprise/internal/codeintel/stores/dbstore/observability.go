@@ -54,11 +54,15 @@ type operations struct {
 	markIndexErrored                       *observation.Operation
 	markQueued                             *observation.Operation
 	markRepositoryAsDirty                  *observation.Operation
+	packageDependencies                    *observation.Operation
+	packageDependents                      *observation.Operation
 	queueSize                              *observation.Operation
 	referenceIDsAndFilters                 *observation.Operation
 	referencesForUpload                    *observation.Operation
 	refreshCommitResolvability             *observation.Operation
 	repoName                               *observation.Operation
+	repositoryDependencies                 *observation.Operation
+	repositoryDependents                   *observation.Operation
 	requeue                                *observation.Operation
 	requeueIndex                           *observation.Operation
 	selectRepositoriesForIndexScan         *observation.Operation
@@ -145,11 +149,15 @@ func newOperations(observationContext *observation.Context, metrics *metrics.Ope
 		markIndexErrored:                       op("MarkIndexErrored"),
 		markQueued:                             op("MarkQueued"),
 		markRepositoryAsDirty:                  op("MarkRepositoryAsDirty"),
+		packageDependencies:                    op("PackageDependencies"),
+		packageDependents:                      op("PackageDependents"),
 		queueSize:                              op("QueueSize"),
 		referenceIDsAndFilters:                 op("ReferenceIDsAndFilters"),
 		referencesForUpload:                    op("ReferencesForUpload"),
 		refreshCommitResolvability:             op("RefreshCommitResolvability"),
 		repoName:                               op("RepoName"),
+		repositoryDependencies:                 op("RepositoryDependencies"),
+		repositoryDependents:                   op("RepositoryDependents"),
 		requeue:                                op("Requeue"),
 		requeueIndex:                           op("RequeueIndex"),
 		selectRepositoriesForIndexScan:         op("SelectRepositoriesForIndexScan"),
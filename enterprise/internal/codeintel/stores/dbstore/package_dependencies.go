@@ -0,0 +1,245 @@
+package dbstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+// PackageDependency pairs a package with a repository. For dependent queries, the repository
+// has an upload visible at the tip of its default branch that references the package. For
+// dependency queries, the repository has an upload visible at the tip of its default branch
+// that provides the package. The repository fields of a dependency are zero-valued when no
+// such repository exists (or is visible to the current user).
+type PackageDependency struct {
+	Scheme         string
+	Name           string
+	Version        string
+	RepositoryID   int
+	RepositoryName string
+}
+
+// scanPackageDependencies scans a slice of package dependencies from the return value of `*Store.query`.
+func scanPackageDependencies(rows *sql.Rows, queryErr error) (_ []PackageDependency, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var dependencies []PackageDependency
+	for rows.Next() {
+		var dependency PackageDependency
+		if err := rows.Scan(
+			&dependency.Scheme,
+			&dependency.Name,
+			&dependency.Version,
+			&dbutil.NullInt{N: &dependency.RepositoryID},
+			&dbutil.NullString{S: &dependency.RepositoryName},
+		); err != nil {
+			return nil, err
+		}
+
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, nil
+}
+
+// PackageDependents returns the repositories that reference the package with the given scheme and
+// name from an upload visible at the tip of their default branch, along with the referenced version.
+// If version is non-empty, only references to that version are returned. The result set is filtered
+// by the current user's repository permissions.
+func (s *Store) PackageDependents(ctx context.Context, scheme, name, version string, limit, offset int) (_ []PackageDependency, _ int, err error) {
+	ctx, traceLog, endObservation := s.operations.packageDependents.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("scheme", scheme),
+		log.String("name", name),
+		log.String("version", version),
+		log.Int("limit", limit),
+		log.Int("offset", offset),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	conds := makePackageConditions("r", scheme, name, version)
+	return s.dependents(ctx, traceLog, sqlf.Join(conds, " AND "), limit, offset)
+}
+
+// PackageDependencies returns the packages referenced by uploads that provide the package with the
+// given scheme and name and are visible at the tip of their repository's default branch. If version
+// is non-empty, only uploads providing that version are considered. Each dependency is paired with a
+// repository providing it, if one exists. The result set is filtered by the current user's repository
+// permissions.
+func (s *Store) PackageDependencies(ctx context.Context, scheme, name, version string, limit, offset int) (_ []PackageDependency, _ int, err error) {
+	ctx, traceLog, endObservation := s.operations.packageDependencies.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("scheme", scheme),
+		log.String("name", name),
+		log.String("version", version),
+		log.Int("limit", limit),
+		log.Int("offset", offset),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	conds := makePackageConditions("p", scheme, name, version)
+	cond := sqlf.Sprintf("r.dump_id IN (SELECT p.dump_id FROM lsif_packages p WHERE %s)", sqlf.Join(conds, " AND "))
+	return s.dependencies(ctx, traceLog, cond, limit, offset)
+}
+
+// RepositoryDependents returns the repositories that reference a package provided by an upload
+// visible at the tip of the given repository's default branch. References from the repository
+// itself are excluded. The result set is filtered by the current user's repository permissions.
+func (s *Store) RepositoryDependents(ctx context.Context, repositoryID, limit, offset int) (_ []PackageDependency, _ int, err error) {
+	ctx, traceLog, endObservation := s.operations.repositoryDependents.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("repositoryID", repositoryID),
+		log.Int("limit", limit),
+		log.Int("offset", offset),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	cond := sqlf.Sprintf("(r.scheme, r.name, r.version) IN ("+repositoryPackagesQuery+") AND repo.id != %s", repositoryID, repositoryID)
+	return s.dependents(ctx, traceLog, cond, limit, offset)
+}
+
+// RepositoryDependencies returns the packages referenced by an upload visible at the tip of the given
+// repository's default branch. Packages provided by the repository itself are excluded. Each dependency
+// is paired with a repository providing it, if one exists. The result set is filtered by the current
+// user's repository permissions.
+func (s *Store) RepositoryDependencies(ctx context.Context, repositoryID, limit, offset int) (_ []PackageDependency, _ int, err error) {
+	ctx, traceLog, endObservation := s.operations.repositoryDependencies.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("repositoryID", repositoryID),
+		log.Int("limit", limit),
+		log.Int("offset", offset),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	cond := sqlf.Sprintf("uvt.repository_id = %s AND (r.scheme, r.name, r.version) NOT IN ("+repositoryPackagesQuery+")", repositoryID, repositoryID)
+	return s.dependencies(ctx, traceLog, cond, limit, offset)
+}
+
+const repositoryPackagesQuery = `
+SELECT p.scheme, p.name, p.version
+FROM lsif_packages p
+JOIN lsif_uploads_visible_at_tip puvt ON puvt.upload_id = p.dump_id AND puvt.is_default_branch
+WHERE puvt.repository_id = %s
+`
+
+// makePackageConditions returns a set of conditions matching rows of the given lsif_packages or
+// lsif_references table alias to the given package. An empty version matches all versions.
+func makePackageConditions(alias, scheme, name, version string) []*sqlf.Query {
+	conds := []*sqlf.Query{
+		sqlf.Sprintf(alias+".scheme = %s", scheme),
+		sqlf.Sprintf(alias+".name = %s", name),
+	}
+	if version != "" {
+		conds = append(conds, sqlf.Sprintf(alias+".version = %s", version))
+	}
+
+	return conds
+}
+
+// dependents returns a page of distinct (package, repository) pairs from references matching the given
+// condition, along with the total number of such pairs.
+func (s *Store) dependents(ctx context.Context, traceLog observation.TraceLogger, cond *sqlf.Query, limit, offset int) ([]PackageDependency, int, error) {
+	authzConds, err := database.AuthzQueryConds(ctx, s.Store.Handle().DB())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount, _, err := basestore.ScanFirstInt(s.Store.Query(ctx, sqlf.Sprintf(packageDependentsCountQuery, cond, authzConds)))
+	if err != nil {
+		return nil, 0, err
+	}
+	traceLog(log.Int("totalCount", totalCount))
+
+	dependents, err := scanPackageDependencies(s.Store.Query(ctx, sqlf.Sprintf(packageDependentsQuery, cond, authzConds, limit, offset)))
+	if err != nil {
+		return nil, 0, err
+	}
+	traceLog(log.Int("numDependents", len(dependents)))
+
+	return dependents, totalCount, nil
+}
+
+const packageReferencesBaseQuery = `
+FROM lsif_references r
+JOIN lsif_uploads_visible_at_tip uvt ON uvt.upload_id = r.dump_id AND uvt.is_default_branch
+JOIN repo ON repo.id = uvt.repository_id
+WHERE repo.deleted_at IS NULL AND (%s) AND %s
+`
+
+const packageDependentsQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/package_dependencies.go:dependents
+SELECT DISTINCT r.scheme, r.name, r.version, repo.id, repo.name
+` + packageReferencesBaseQuery + `
+ORDER BY repo.name, r.scheme, r.name, r.version
+LIMIT %s OFFSET %s
+`
+
+const packageDependentsCountQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/package_dependencies.go:dependents
+SELECT COUNT(*) FROM (
+	SELECT DISTINCT r.scheme, r.name, r.version, repo.id
+	` + packageReferencesBaseQuery + `
+) s
+`
+
+// dependencies returns a page of distinct packages from references matching the given condition, each
+// paired with a repository providing that package, along with the total number of distinct packages.
+func (s *Store) dependencies(ctx context.Context, traceLog observation.TraceLogger, cond *sqlf.Query, limit, offset int) ([]PackageDependency, int, error) {
+	authzConds, err := database.AuthzQueryConds(ctx, s.Store.Handle().DB())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount, _, err := basestore.ScanFirstInt(s.Store.Query(ctx, sqlf.Sprintf(packageDependenciesCountQuery, cond, authzConds)))
+	if err != nil {
+		return nil, 0, err
+	}
+	traceLog(log.Int("totalCount", totalCount))
+
+	dependencies, err := scanPackageDependencies(s.Store.Query(ctx, sqlf.Sprintf(packageDependenciesQuery, cond, authzConds, authzConds, limit, offset)))
+	if err != nil {
+		return nil, 0, err
+	}
+	traceLog(log.Int("numDependencies", len(dependencies)))
+
+	return dependencies, totalCount, nil
+}
+
+const packageDependenciesQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/package_dependencies.go:dependencies
+SELECT d.scheme, d.name, d.version, provider.id, provider.name
+FROM (
+	SELECT DISTINCT r.scheme, r.name, r.version
+	` + packageReferencesBaseQuery + `
+) d
+LEFT JOIN LATERAL (
+	SELECT repo.id, repo.name
+	FROM lsif_packages p
+	JOIN lsif_uploads_visible_at_tip uvt ON uvt.upload_id = p.dump_id AND uvt.is_default_branch
+	JOIN repo ON repo.id = uvt.repository_id
+	WHERE
+		p.scheme = d.scheme AND
+		p.name = d.name AND
+		p.version = d.version AND
+		repo.deleted_at IS NULL AND
+		%s
+	ORDER BY repo.name
+	LIMIT 1
+) provider ON true
+ORDER BY d.scheme, d.name, d.version
+LIMIT %s OFFSET %s
+`
+
+const packageDependenciesCountQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/package_dependencies.go:dependencies
+SELECT COUNT(*) FROM (
+	SELECT DISTINCT r.scheme, r.name, r.version
+	` + packageReferencesBaseQuery + `
+) d
+`
@@ -0,0 +1,134 @@
+package dbstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/shared"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+)
+
+func TestPackageDependencyGraph(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	store := testStore(db)
+
+	insertUploads(t, db,
+		Upload{ID: 1, RepositoryID: 50},
+		Upload{ID: 2, RepositoryID: 51},
+		Upload{ID: 3, RepositoryID: 52},
+		Upload{ID: 4, RepositoryID: 52}, // not visible at tip
+	)
+	insertVisibleAtTip(t, db, 50, 1)
+	insertVisibleAtTip(t, db, 51, 2)
+	insertVisibleAtTip(t, db, 52, 3)
+
+	insertPackages(t, store, []shared.Package{
+		{DumpID: 1, Scheme: "gomod", Name: "leftpad", Version: "0.1.0"},
+		{DumpID: 2, Scheme: "gomod", Name: "rightpad", Version: "1.0.0"},
+	})
+	insertPackageReferences(t, store, []shared.PackageReference{
+		{Package: shared.Package{DumpID: 1, Scheme: "gomod", Name: "rightpad", Version: "1.0.0"}},
+		{Package: shared.Package{DumpID: 2, Scheme: "gomod", Name: "leftpad", Version: "0.1.0"}},
+		{Package: shared.Package{DumpID: 3, Scheme: "gomod", Name: "leftpad", Version: "0.2.0"}},
+		{Package: shared.Package{DumpID: 3, Scheme: "gomod", Name: "rightpad", Version: "1.0.0"}},
+		{Package: shared.Package{DumpID: 4, Scheme: "gomod", Name: "leftpad", Version: "0.1.0"}},
+	})
+
+	leftpad010 := PackageDependency{Scheme: "gomod", Name: "leftpad", Version: "0.1.0"}
+	leftpad020 := PackageDependency{Scheme: "gomod", Name: "leftpad", Version: "0.2.0"}
+	rightpad100 := PackageDependency{Scheme: "gomod", Name: "rightpad", Version: "1.0.0", RepositoryID: 51, RepositoryName: "n-51"}
+
+	withRepository := func(dependency PackageDependency, repositoryID int, repositoryName string) PackageDependency {
+		dependency.RepositoryID = repositoryID
+		dependency.RepositoryName = repositoryName
+		return dependency
+	}
+
+	testCases := []struct {
+		name               string
+		query              func(limit, offset int) ([]PackageDependency, int, error)
+		limit              int
+		offset             int
+		expected           []PackageDependency
+		expectedTotalCount int
+	}{
+		{
+			name: "package dependents",
+			query: func(limit, offset int) ([]PackageDependency, int, error) {
+				return store.PackageDependents(context.Background(), "gomod", "leftpad", "", limit, offset)
+			},
+			limit: 10,
+			expected: []PackageDependency{
+				withRepository(leftpad010, 51, "n-51"),
+				withRepository(leftpad020, 52, "n-52"),
+			},
+			expectedTotalCount: 2,
+		},
+		{
+			name: "package dependents at version",
+			query: func(limit, offset int) ([]PackageDependency, int, error) {
+				return store.PackageDependents(context.Background(), "gomod", "leftpad", "0.1.0", limit, offset)
+			},
+			limit:              10,
+			expected:           []PackageDependency{withRepository(leftpad010, 51, "n-51")},
+			expectedTotalCount: 1,
+		},
+		{
+			name: "package dependencies",
+			query: func(limit, offset int) ([]PackageDependency, int, error) {
+				return store.PackageDependencies(context.Background(), "gomod", "leftpad", "", limit, offset)
+			},
+			limit:              10,
+			expected:           []PackageDependency{rightpad100},
+			expectedTotalCount: 1,
+		},
+		{
+			name: "repository dependents",
+			query: func(limit, offset int) ([]PackageDependency, int, error) {
+				return store.RepositoryDependents(context.Background(), 50, limit, offset)
+			},
+			limit:              10,
+			expected:           []PackageDependency{withRepository(leftpad010, 51, "n-51")},
+			expectedTotalCount: 1,
+		},
+		{
+			name: "repository dependencies",
+			query: func(limit, offset int) ([]PackageDependency, int, error) {
+				return store.RepositoryDependencies(context.Background(), 52, limit, offset)
+			},
+			limit:              10,
+			expected:           []PackageDependency{leftpad020, rightpad100},
+			expectedTotalCount: 2,
+		},
+		{
+			name: "repository dependencies (paged)",
+			query: func(limit, offset int) ([]PackageDependency, int, error) {
+				return store.RepositoryDependencies(context.Background(), 52, limit, offset)
+			},
+			limit:              1,
+			offset:             1,
+			expected:           []PackageDependency{rightpad100},
+			expectedTotalCount: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dependencies, totalCount, err := testCase.query(testCase.limit, testCase.offset)
+			if err != nil {
+				t.Fatalf("unexpected error querying dependency graph: %s", err)
+			}
+			if totalCount != testCase.expectedTotalCount {
+				t.Errorf("unexpected total count. want=%d have=%d", testCase.expectedTotalCount, totalCount)
+			}
+			if diff := cmp.Diff(testCase.expected, dependencies); diff != "" {
+				t.Errorf("unexpected dependencies (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
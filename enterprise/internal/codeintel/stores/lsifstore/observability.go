@@ -29,6 +29,7 @@ type operations struct {
 	ranges                          *observation.Operation
 	references                      *observation.Operation
 	stencil                         *observation.Operation
+	symbolRanges                    *observation.Operation
 	writeDefinitions                *observation.Operation
 	writeDocumentationMappings      *observation.Operation
 	writeDocumentationPages         *observation.Operation
@@ -91,6 +92,7 @@ func newOperations(observationContext *observation.Context) *operations {
 		ranges:                          op("Ranges"),
 		references:                      op("References"),
 		stencil:                         op("Stencil"),
+		symbolRanges:                    op("SymbolRanges"),
 		writeDefinitions:                op("WriteDefinitions"),
 		writeDocumentationMappings:      op("WriteDocumentationMappings"),
 		writeDocumentationPages:         op("WriteDocumentationPages"),
@@ -0,0 +1,101 @@
+package lsifstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+// SymbolRanges returns the ranges within a single document that define a named symbol, ordered
+// by their position in the document. A range defines a named symbol when it is a member of its
+// own definition result and it carries either a non-import moniker or a documentation result.
+// This excludes block-local definitions (variables, parameters) for most indexers.
+//
+// LSIF does not encode the extent of a symbol's body, so the next symbol range in the same
+// document is used as an approximation for where the current symbol ends.
+func (s *Store) SymbolRanges(ctx context.Context, bundleID int, path string) (_ []Range, err error) {
+	ctx, traceLog, endObservation := s.operations.symbolRanges.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+		log.String("path", path),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	documentData, exists, err := s.scanFirstDocumentData(s.Store.Query(ctx, sqlf.Sprintf(symbolRangesDocumentQuery, bundleID, path)))
+	if err != nil || !exists {
+		return nil, err
+	}
+	traceLog(log.Int("numRanges", len(documentData.Document.Ranges)))
+
+	candidates := make([]precise.RangeData, 0, len(documentData.Document.Ranges))
+	for _, r := range documentData.Document.Ranges {
+		if r.DefinitionResultID != "" && isNamedSymbol(r, documentData.Document.Monikers) {
+			candidates = append(candidates, r)
+		}
+	}
+	traceLog(log.Int("numCandidateRanges", len(candidates)))
+
+	definitionResultIDs := extractResultIDs(candidates, func(r precise.RangeData) precise.ID { return r.DefinitionResultID })
+	definitionLocations, err := s.locationsWithinFile(ctx, bundleID, definitionResultIDs, path, documentData.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]Range, 0, len(candidates))
+	for _, r := range candidates {
+		rn := newRange(r.StartLine, r.StartCharacter, r.EndLine, r.EndCharacter)
+
+		for _, location := range definitionLocations[r.DefinitionResultID] {
+			if location.Range == rn {
+				ranges = append(ranges, rn)
+				break
+			}
+		}
+	}
+	traceLog(log.Int("numSymbolRanges", len(ranges)))
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return compareBundleRanges(ranges[i], ranges[j])
+	})
+
+	return ranges, nil
+}
+
+// isNamedSymbol returns true if the given range has a documentation result or is attached to a
+// moniker that is not an import moniker.
+func isNamedSymbol(r precise.RangeData, monikers map[precise.ID]precise.MonikerData) bool {
+	if r.DocumentationResultID != "" {
+		return true
+	}
+
+	for _, monikerID := range r.MonikerIDs {
+		if moniker, ok := monikers[monikerID]; ok && moniker.Kind != "import" {
+			return true
+		}
+	}
+
+	return false
+}
+
+const symbolRangesDocumentQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/symbols.go:SymbolRanges
+SELECT
+	dump_id,
+	path,
+	data,
+	ranges,
+	NULL AS hovers,
+	monikers,
+	NULL AS packages,
+	NULL AS diagnostics
+FROM
+	lsif_data_documents
+WHERE
+	dump_id = %s AND
+	path = %s
+LIMIT 1
+`
@@ -0,0 +1,72 @@
+package lsifstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestDatabaseSymbolRanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	populateTestStore(t)
+	store := NewStore(db, &observation.TestContext)
+
+	//   13: type Writer struct {
+	//   14:     w           io.Writer
+	//   15:     addContents bool
+	//   ...
+	//   21: func NewWriter(w io.Writer, addContents bool) *Writer {
+	//   ...
+	//   28: func (w *Writer) NumElements() int {
+
+	if actual, err := store.SymbolRanges(context.Background(), testBundleID, "protocol/writer.go"); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	} else {
+		// Struct fields, parameters, and receivers are not named symbols
+		expected := []Range{
+			newRange(12, 5, 12, 11),
+			newRange(20, 5, 20, 14),
+			newRange(27, 17, 27, 28),
+			newRange(31, 17, 31, 23),
+			newRange(41, 17, 41, 29),
+			newRange(46, 17, 46, 31),
+			newRange(51, 17, 51, 29),
+			newRange(56, 17, 56, 28),
+			newRange(61, 17, 61, 29),
+			newRange(75, 17, 75, 29),
+			newRange(80, 17, 80, 30),
+			newRange(85, 17, 85, 26),
+			newRange(90, 17, 90, 25),
+			newRange(95, 17, 95, 37),
+			newRange(100, 17, 100, 43),
+			newRange(105, 17, 105, 32),
+			newRange(110, 17, 110, 38),
+			newRange(115, 17, 115, 36),
+			newRange(120, 17, 120, 43),
+			newRange(125, 17, 125, 25),
+			newRange(130, 17, 130, 38),
+			newRange(135, 17, 135, 37),
+			newRange(140, 17, 140, 39),
+			newRange(145, 17, 145, 28),
+			newRange(150, 17, 150, 43),
+			newRange(155, 17, 155, 32),
+		}
+
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Errorf("unexpected symbol ranges (-want +got):\n%s", diff)
+		}
+	}
+
+	if actual, err := store.SymbolRanges(context.Background(), testBundleID, "missing.go"); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	} else if len(actual) != 0 {
+		t.Errorf("unexpected symbol ranges for missing path: %v", actual)
+	}
+}
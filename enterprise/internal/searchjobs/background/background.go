@@ -0,0 +1,30 @@
+package background
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+// NewRoutines returns the background routines processing search jobs: a
+// worker resolving the repositories of search jobs, a worker searching each of
+// those repositories with numRepoHandlers concurrent searches, their
+// resetters, and a notifier emailing initiators of finished search jobs.
+func NewRoutines(ctx context.Context, store *searchjobs.Store, uploadStore uploadstore.Store, numRepoHandlers int, observationContext *observation.Context) []goroutine.BackgroundRoutine {
+	client := &searchClient{frontendInternalURL: api.InternalClient.URL + "/.internal"}
+
+	searchJobMetrics := newMetrics(observationContext, "search_jobs")
+	repoJobMetrics := newMetrics(observationContext, "search_job_repos")
+
+	return []goroutine.BackgroundRoutine{
+		newSearchJobWorker(ctx, store, &searchJobHandler{store: store, client: client}, searchJobMetrics),
+		newSearchJobResetter(store, searchJobMetrics),
+		newRepoJobWorker(ctx, store, &repoJobHandler{store: store, uploadStore: uploadStore, client: client}, numRepoHandlers, repoJobMetrics),
+		newRepoJobResetter(store, repoJobMetrics),
+		newNotifier(ctx, store),
+	}
+}
@@ -0,0 +1,129 @@
+package background
+
+import (
+	"context"
+	"io"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+)
+
+// searchJobHandler resolves the repositories searched by a search job and
+// enqueues a RepoJob for each of them.
+type searchJobHandler struct {
+	store  *searchjobs.Store
+	client *searchClient
+}
+
+var _ workerutil.Handler = &searchJobHandler{}
+
+func (h *searchJobHandler) Handle(ctx context.Context, record workerutil.Record) error {
+	job := record.(*searchjobs.SearchJob)
+
+	repoQueries, err := searchjobs.RepoQueries(job.Query)
+	if err != nil {
+		return err
+	}
+
+	seen := map[api.RepoID]struct{}{}
+	for _, q := range repoQueries {
+		err := h.client.search(ctx, q, func(matches []streamhttp.EventMatch) error {
+			for _, m := range matches {
+				if repo, ok := m.(*streamhttp.EventRepoMatch); ok {
+					seen[api.RepoID(repo.RepositoryID)] = struct{}{}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "resolving repositories with %q", q)
+		}
+	}
+
+	repoIDs := make([]api.RepoID, 0, len(seen))
+	for id := range seen {
+		repoIDs = append(repoIDs, id)
+	}
+	if len(repoIDs) == 0 {
+		return nil
+	}
+
+	// 🚨 SECURITY: The search ran as the internal actor, so we use
+	// database.Repos.List as the initiator to only search the repositories
+	// the initiator has access to.
+	initiatorCtx := actor.WithActor(ctx, actor.FromUser(job.InitiatorID))
+	accessibleRepos, err := database.ReposWith(h.store).ListRepoNames(initiatorCtx, database.ReposListOptions{IDs: repoIDs})
+	if err != nil {
+		return err
+	}
+
+	accessibleIDs := make([]api.RepoID, 0, len(accessibleRepos))
+	for _, repo := range accessibleRepos {
+		accessibleIDs = append(accessibleIDs, repo.ID)
+	}
+	return h.store.CreateRepoJobs(ctx, job.ID, accessibleIDs)
+}
+
+// repoJobHandler searches a single repository for the query of a search job
+// and stores all results in the upload store.
+type repoJobHandler struct {
+	store       *searchjobs.Store
+	uploadStore uploadstore.Store
+	client      *searchClient
+}
+
+var _ workerutil.Handler = &repoJobHandler{}
+
+func (h *repoJobHandler) Handle(ctx context.Context, record workerutil.Record) error {
+	repoJob := record.(*searchjobs.RepoJob)
+
+	job, err := h.store.GetSearchJob(ctx, repoJob.SearchJobID)
+	if err != nil {
+		return err
+	}
+
+	// 🚨 SECURITY: The repository is looked up as the initiator, so that a
+	// repository they lost access to since the job was created is not
+	// searched.
+	initiatorCtx := actor.WithActor(ctx, actor.FromUser(job.InitiatorID))
+	repo, err := database.ReposWith(h.store).Get(initiatorCtx, repoJob.RepoID)
+	if err != nil {
+		return err
+	}
+
+	q, err := searchjobs.RepoJobQuery(job.Query, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	// The results are streamed to the upload store while searching. A failed
+	// search closes the pipe with its error, which fails the upload.
+	matchCount := 0
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(h.client.search(ctx, q, func(matches []streamhttp.EventMatch) error {
+			for _, m := range matches {
+				if err := searchjobs.WriteResult(pw, m); err != nil {
+					return err
+				}
+			}
+			matchCount += len(matches)
+			return nil
+		}))
+	}()
+
+	if _, err := h.uploadStore.Upload(ctx, searchjobs.ResultsKey(job.ID, repoJob.ID), pr); err != nil {
+		// Unblock the search if the upload failed before reading all results.
+		_ = pr.CloseWithError(err)
+		return err
+	}
+
+	return h.store.SetRepoJobMatchCount(ctx, repoJob.ID, matchCount)
+}
@@ -0,0 +1,44 @@
+package background
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+)
+
+type searchJobsMetrics struct {
+	workerMetrics workerutil.WorkerMetrics
+	resets        prometheus.Counter
+	resetFailures prometheus.Counter
+	errors        prometheus.Counter
+}
+
+func newMetrics(observationContext *observation.Context, name string) searchJobsMetrics {
+	resetFailures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: fmt.Sprintf("src_%s_reset_failures_total", name),
+		Help: "The number of reset failures.",
+	})
+	observationContext.Registerer.MustRegister(resetFailures)
+
+	resets := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: fmt.Sprintf("src_%s_resets_total", name),
+		Help: "The number of records reset.",
+	})
+	observationContext.Registerer.MustRegister(resets)
+
+	errors := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: fmt.Sprintf("src_%s_reset_errors_total", name),
+		Help: "The number of errors that occur during reset.",
+	})
+	observationContext.Registerer.MustRegister(errors)
+
+	return searchJobsMetrics{
+		workerMetrics: workerutil.NewMetrics(observationContext, name, nil),
+		resets:        resets,
+		resetFailures: resetFailures,
+		errors:        errors,
+	}
+}
@@ -0,0 +1,135 @@
+package background
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/txemail"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txtypes"
+)
+
+// notifyBatchSize is the maximum number of initiators notified per run of the
+// notifier.
+const notifyBatchSize = 100
+
+func newNotifier(ctx context.Context, store *searchjobs.Store) goroutine.BackgroundRoutine {
+	notify := goroutine.NewHandlerWithErrorMessage(
+		"search_jobs_notifier",
+		func(ctx context.Context) error {
+			return notifyInitiators(ctx, store)
+		})
+	return goroutine.NewPeriodicGoroutine(ctx, 30*time.Second, notify)
+}
+
+// notifyInitiators emails the initiators of search jobs that are done.
+func notifyInitiators(ctx context.Context, store *searchjobs.Store) error {
+	jobs, err := store.ListSearchJobsToNotify(ctx, notifyBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		stats, err := store.GetRepoJobStats(ctx, job.ID)
+		if err != nil {
+			return err
+		}
+		data, err := newTemplateData(ctx, job, stats)
+		if err != nil {
+			return err
+		}
+		if err := sendEmail(ctx, job.InitiatorID, data); err != nil {
+			return err
+		}
+		if err := store.MarkSearchJobNotified(ctx, job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type templateData struct {
+	Query          string
+	Failed         bool
+	FailureMessage string
+	Stats          searchjobs.RepoJobStats
+	CSVURL         string
+	JSONLinesURL   string
+}
+
+func newTemplateData(ctx context.Context, job *searchjobs.SearchJob, stats searchjobs.RepoJobStats) (*templateData, error) {
+	data := &templateData{
+		Query:  job.Query,
+		Failed: job.AggregateState(stats) == searchjobs.AggregateStateFailed,
+		Stats:  stats,
+	}
+	if job.FailureMessage != nil {
+		data.FailureMessage = *job.FailureMessage
+	}
+
+	externalURLStr, err := api.InternalClient.ExternalURL(ctx)
+	if err != nil {
+		return nil, errors.Errorf("failed to get ExternalURL: %w", err)
+	}
+	externalURL, err := url.Parse(externalURLStr)
+	if err != nil {
+		return nil, errors.Errorf("failed to get ExternalURL: %w", err)
+	}
+	data.CSVURL = externalURL.ResolveReference(&url.URL{Path: searchjobs.ResultsURLPath(job.ID, searchjobs.FormatCSV)}).String()
+	data.JSONLinesURL = externalURL.ResolveReference(&url.URL{Path: searchjobs.ResultsURLPath(job.ID, searchjobs.FormatJSONLines)}).String()
+	return data, nil
+}
+
+func sendEmail(ctx context.Context, userID int32, data *templateData) error {
+	email, err := api.InternalClient.UserEmailsGetEmail(ctx, userID)
+	if err != nil {
+		return errors.Errorf("InternalClient.UserEmailsGetEmail for userID=%d: %w", userID, err)
+	}
+	if email == nil {
+		return errors.Errorf("unable to send email to user ID %d with unknown email address", userID)
+	}
+	if err := api.InternalClient.SendEmail(ctx, txtypes.Message{
+		To:       []string{*email},
+		Template: searchJobDoneEmailTemplates,
+		Data:     data,
+	}); err != nil {
+		return errors.Errorf("InternalClient.SendEmail to email=%q userID=%d: %w", *email, userID, err)
+	}
+	return nil
+}
+
+var searchJobDoneEmailTemplates = txemail.MustValidate(txtypes.Templates{
+	Subject: `{{ if .Failed }}Your search job failed{{ else }}Your search job is done{{ end }}`,
+	Text: `
+{{ if .Failed }}Your search job failed:{{ else }}Your search job is done:{{ end }}
+
+{{.Query}}
+
+{{ if .Failed }}{{.FailureMessage}}{{ else }}{{.Stats.MatchCount}} results were found in {{.Stats.Completed}} of {{.Stats.Total}} repositories.{{ if .Stats.Failed }} {{.Stats.Failed}} repositories could not be searched.{{ end }}
+
+Download the results as CSV: {{.CSVURL}}
+
+Download the results as JSON Lines: {{.JSONLinesURL}}{{ end }}
+`,
+	HTML: `
+<p>{{ if .Failed }}Your search job failed:{{ else }}Your search job is done:{{ end }}</p>
+
+<p><code>{{.Query}}</code></p>
+
+{{ if .Failed }}
+<p>{{.FailureMessage}}</p>
+{{ else }}
+<p>
+  {{.Stats.MatchCount}} results were found in {{.Stats.Completed}} of {{.Stats.Total}} repositories.
+  {{ if .Stats.Failed }}{{.Stats.Failed}} repositories could not be searched.{{ end }}
+</p>
+
+<p>Download the results as <a href="{{.CSVURL}}">CSV</a> or <a href="{{.JSONLinesURL}}">JSON Lines</a>.</p>
+{{ end }}
+`,
+})
@@ -0,0 +1,91 @@
+package background
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	streamapi "github.com/sourcegraph/sourcegraph/internal/search/streaming/api"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+)
+
+const internalSearchClientUserAgent = "Search jobs"
+
+// incompleteReasons are the reasons for skipping parts of a search which mean
+// that not all results were found. A search skipping any of them is retried.
+var incompleteReasons = map[streamapi.SkippedReason]struct{}{
+	streamapi.DocumentMatchLimit: {},
+	streamapi.ShardMatchLimit:    {},
+	streamapi.DisplayLimit:       {},
+	streamapi.RepositoryLimit:    {},
+	streamapi.ShardTimeout:       {},
+	streamapi.RepositoryCloning:  {},
+	streamapi.RepositoryMissing:  {},
+}
+
+// searchClient runs searches against the streaming search API of the frontend.
+type searchClient struct {
+	frontendInternalURL string
+}
+
+// search runs query and calls onMatches with every batch of matches. It
+// returns an error if the search did not find every result, for example
+// because it timed out.
+func (c *searchClient) search(ctx context.Context, query string, onMatches func(matches []streamhttp.EventMatch) error) (err error) {
+	req, err := streamhttp.NewRequest(c.frontendInternalURL, query)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	// We don't set an auth token here and don't authenticate on the users
+	// behalf in any way, because the repositories are checked against the
+	// permissions of the initiator of the search job by the caller.
+	req.Header.Set("User-Agent", internalSearchClientUserAgent)
+
+	resp, err := httpcli.InternalClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var (
+		matchesErr error
+		skipped    []string
+	)
+	dec := streamhttp.FrontendStreamDecoder{
+		OnMatches: func(matches []streamhttp.EventMatch) {
+			if matchesErr == nil {
+				matchesErr = onMatches(matches)
+			}
+		},
+		OnError: func(ee *streamhttp.EventError) {
+			err = errors.New(ee.Message)
+		},
+		OnProgress: func(p *streamapi.Progress) {
+			if !p.Done {
+				return
+			}
+			for _, s := range p.Skipped {
+				if _, ok := incompleteReasons[s.Reason]; ok {
+					skipped = append(skipped, s.Message)
+				}
+			}
+		},
+	}
+	if decErr := dec.ReadAll(resp.Body); decErr != nil {
+		return decErr
+	}
+	if err != nil {
+		return err
+	}
+	if matchesErr != nil {
+		return matchesErr
+	}
+	if len(skipped) > 0 {
+		return errors.Errorf("search did not find all results: %s", strings.Join(skipped, " "))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package background
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	streamapi "github.com/sourcegraph/sourcegraph/internal/search/streaming/api"
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+)
+
+func newStreamSearchTestServer(t *testing.T, matches []streamhttp.EventMatch, skipped []streamapi.Skipped) string {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ew, err := streamhttp.NewWriter(w)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ew.Event("matches", matches)
+		ew.Event("progress", &streamapi.Progress{Done: true, MatchCount: len(matches), Skipped: skipped})
+		ew.Event("done", struct{}{})
+	}))
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+func TestSearchClient(t *testing.T) {
+	matches := []streamhttp.EventMatch{
+		&streamhttp.EventRepoMatch{Type: streamhttp.RepoMatchType, RepositoryID: 1, Repository: "r1"},
+		&streamhttp.EventRepoMatch{Type: streamhttp.RepoMatchType, RepositoryID: 2, Repository: "r2"},
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		client := &searchClient{frontendInternalURL: newStreamSearchTestServer(t, matches, []streamapi.Skipped{
+			{Reason: streamapi.ExcludedFork, Message: "forks were excluded"},
+		})}
+
+		var got []string
+		err := client.search(context.Background(), "type:repo", func(matches []streamhttp.EventMatch) error {
+			for _, m := range matches {
+				got = append(got, m.(*streamhttp.EventRepoMatch).Repository)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, ",") != "r1,r2" {
+			t.Fatalf("unexpected matches %v", got)
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		client := &searchClient{frontendInternalURL: newStreamSearchTestServer(t, matches, []streamapi.Skipped{
+			{Reason: streamapi.ShardTimeout, Message: "r3 timed out"},
+		})}
+
+		err := client.search(context.Background(), "type:repo", func(matches []streamhttp.EventMatch) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "r3 timed out") {
+			t.Fatalf("expected an error mentioning the timeout, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker"
+	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
+)
+
+func newSearchJobWorker(ctx context.Context, s *searchjobs.Store, handler workerutil.Handler, metrics searchJobsMetrics) *workerutil.Worker {
+	options := workerutil.WorkerOptions{
+		Name:              "search_jobs_worker",
+		NumHandlers:       1,
+		Interval:          5 * time.Second,
+		HeartbeatInterval: 15 * time.Second,
+		Metrics:           metrics.workerMetrics,
+	}
+	return dbworker.NewWorker(ctx, createDBWorkerStoreForSearchJobs(s), handler, options)
+}
+
+func newSearchJobResetter(s *searchjobs.Store, metrics searchJobsMetrics) *dbworker.Resetter {
+	options := dbworker.ResetterOptions{
+		Name:     "search_jobs_worker_resetter",
+		Interval: 1 * time.Minute,
+		Metrics: dbworker.ResetterMetrics{
+			Errors:              metrics.errors,
+			RecordResetFailures: metrics.resetFailures,
+			RecordResets:        metrics.resets,
+		},
+	}
+	return dbworker.NewResetter(createDBWorkerStoreForSearchJobs(s), options)
+}
+
+func newRepoJobWorker(ctx context.Context, s *searchjobs.Store, handler workerutil.Handler, numHandlers int, metrics searchJobsMetrics) *workerutil.Worker {
+	options := workerutil.WorkerOptions{
+		Name:              "search_job_repos_worker",
+		NumHandlers:       numHandlers,
+		Interval:          1 * time.Second,
+		HeartbeatInterval: 15 * time.Second,
+		Metrics:           metrics.workerMetrics,
+	}
+	return dbworker.NewWorker(ctx, createDBWorkerStoreForRepoJobs(s), handler, options)
+}
+
+func newRepoJobResetter(s *searchjobs.Store, metrics searchJobsMetrics) *dbworker.Resetter {
+	options := dbworker.ResetterOptions{
+		Name:     "search_job_repos_worker_resetter",
+		Interval: 1 * time.Minute,
+		Metrics: dbworker.ResetterMetrics{
+			Errors:              metrics.errors,
+			RecordResetFailures: metrics.resetFailures,
+			RecordResets:        metrics.resets,
+		},
+	}
+	return dbworker.NewResetter(createDBWorkerStoreForRepoJobs(s), options)
+}
+
+func createDBWorkerStoreForSearchJobs(s *searchjobs.Store) dbworkerstore.Store {
+	return dbworkerstore.New(s.Handle(), dbworkerstore.Options{
+		Name:              "search_jobs_worker_store",
+		TableName:         "search_jobs",
+		ColumnExpressions: searchjobs.SearchJobsColumns,
+		Scan:              searchjobs.ScanSearchJobs,
+		StalledMaxAge:     60 * time.Second,
+		RetryAfter:        1 * time.Minute,
+		MaxNumRetries:     3,
+		MaxNumResets:      3,
+		OrderByExpression: sqlf.Sprintf("search_jobs.id"),
+	})
+}
+
+func createDBWorkerStoreForRepoJobs(s *searchjobs.Store) dbworkerstore.Store {
+	return dbworkerstore.New(s.Handle(), dbworkerstore.Options{
+		Name:              "search_job_repos_worker_store",
+		TableName:         "search_job_repos",
+		ColumnExpressions: searchjobs.RepoJobsColumns,
+		Scan:              searchjobs.ScanRepoJobs,
+		StalledMaxAge:     60 * time.Second,
+		RetryAfter:        1 * time.Minute,
+		MaxNumRetries:     5,
+		MaxNumResets:      3,
+		OrderByExpression: sqlf.Sprintf("search_job_repos.search_job_id, search_job_repos.id"),
+	})
+}
@@ -0,0 +1,84 @@
+package searchjobs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// repoScopeFields are the fields of a query that determine which repositories
+// are searched.
+var repoScopeFields = map[string]struct{}{
+	query.FieldRepo:               {},
+	query.FieldRepoGroup:          {},
+	query.FieldContext:            {},
+	query.FieldFork:               {},
+	query.FieldArchived:           {},
+	query.FieldVisibility:         {},
+	query.FieldRepoHasFile:        {},
+	query.FieldRepoHasCommitAfter: {},
+}
+
+// ValidateQuery returns an error if q cannot be run as a search job.
+func ValidateQuery(q string) error {
+	plan, err := query.Pipeline(query.InitLiteral(q))
+	if err != nil {
+		return err
+	}
+	for _, basic := range plan {
+		if basic.FindValue(query.FieldCount) != "" {
+			return errors.New("search jobs always find all results: remove count: from the query")
+		}
+	}
+	return nil
+}
+
+// RepoQueries returns the queries which together resolve the repositories
+// searched by q. Every repository matching any of the queries must be searched
+// with RepoJobQuery to find all results of q.
+func RepoQueries(q string) ([]string, error) {
+	plan, err := query.Pipeline(query.InitLiteral(q))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		queries []string
+		seen    = map[string]struct{}{}
+	)
+	for _, basic := range plan {
+		var scope []query.Node
+		for _, p := range basic.Parameters {
+			if _, ok := repoScopeFields[p.Field]; ok {
+				scope = append(scope, p)
+			}
+		}
+
+		repoQuery := "type:repo count:all"
+		if len(scope) > 0 {
+			repoQuery = query.StringHuman(scope) + " " + repoQuery
+		}
+		if _, ok := seen[repoQuery]; ok {
+			continue
+		}
+		seen[repoQuery] = struct{}{}
+		queries = append(queries, repoQuery)
+	}
+	return queries, nil
+}
+
+// RepoJobQuery returns the query that finds all results of q in the given
+// repository.
+func RepoJobQuery(q string, repo api.RepoName) (string, error) {
+	if err := ValidateQuery(q); err != nil {
+		return "", err
+	}
+
+	// The query is grouped so that the repository filter applies to every
+	// operand of a top-level "or".
+	return fmt.Sprintf("repo:^%s$ count:all (%s)", regexp.QuoteMeta(string(repo)), q), nil
+}
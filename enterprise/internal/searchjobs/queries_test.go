@@ -0,0 +1,57 @@
+package searchjobs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRepoQueries(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{
+			query: "secret",
+			want:  []string{"type:repo count:all"},
+		},
+		{
+			query: "repo:^github\\.com/sourcegraph/ file:\\.go$ fork:yes lang:go secret",
+			want:  []string{"repo:^github\\.com/sourcegraph/ fork:yes type:repo count:all"},
+		},
+		{
+			query: "(repo:a secret) or (repo:b password)",
+			want:  []string{"repo:a type:repo count:all", "repo:b type:repo count:all"},
+		},
+		{
+			query: "repo:a secret or password",
+			want:  []string{"repo:a type:repo count:all"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			got, err := RepoQueries(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected queries (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepoJobQuery(t *testing.T) {
+	got, err := RepoJobQuery("secret or password", "github.com/foo/bar.baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "repo:^github\\.com/foo/bar\\.baz$ count:all (secret or password)"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if _, err := RepoJobQuery("count:100 secret", "github.com/foo/bar"); err == nil {
+		t.Fatal("expected an error for a query with count:")
+	}
+}
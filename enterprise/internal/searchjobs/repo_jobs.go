@@ -0,0 +1,167 @@
+package searchjobs
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+)
+
+// RepoJob is the unit of work of a search job: searching the query of the
+// search job in a single repository.
+type RepoJob struct {
+	ID          int64
+	SearchJobID int64
+	RepoID      api.RepoID
+	MatchCount  *int32
+	CreatedAt   time.Time
+
+	// Fields demanded for any dbworker.
+	State          string
+	FailureMessage *string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	ProcessAfter   *time.Time
+	NumResets      int32
+	NumFailures    int32
+}
+
+func (j *RepoJob) RecordID() int {
+	return int(j.ID)
+}
+
+var RepoJobsColumns = []*sqlf.Query{
+	sqlf.Sprintf("search_job_repos.id"),
+	sqlf.Sprintf("search_job_repos.search_job_id"),
+	sqlf.Sprintf("search_job_repos.repo_id"),
+	sqlf.Sprintf("search_job_repos.match_count"),
+	sqlf.Sprintf("search_job_repos.created_at"),
+	sqlf.Sprintf("search_job_repos.state"),
+	sqlf.Sprintf("search_job_repos.failure_message"),
+	sqlf.Sprintf("search_job_repos.started_at"),
+	sqlf.Sprintf("search_job_repos.finished_at"),
+	sqlf.Sprintf("search_job_repos.process_after"),
+	sqlf.Sprintf("search_job_repos.num_resets"),
+	sqlf.Sprintf("search_job_repos.num_failures"),
+}
+
+func ScanRepoJobs(rows *sql.Rows, err error) (workerutil.Record, bool, error) {
+	jobs, err := scanRepoJobs(rows, err)
+	if err != nil || len(jobs) == 0 {
+		return &RepoJob{}, false, err
+	}
+	return jobs[0], true, nil
+}
+
+func scanRepoJobs(rows *sql.Rows, queryErr error) (_ []*RepoJob, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var jobs []*RepoJob
+	for rows.Next() {
+		j := &RepoJob{}
+		if err := rows.Scan(
+			&j.ID,
+			&j.SearchJobID,
+			&j.RepoID,
+			&j.MatchCount,
+			&j.CreatedAt,
+			&j.State,
+			&j.FailureMessage,
+			&j.StartedAt,
+			&j.FinishedAt,
+			&j.ProcessAfter,
+			&j.NumResets,
+			&j.NumFailures,
+		); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+const createRepoJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/repo_jobs.go:CreateRepoJobs
+INSERT INTO search_job_repos (search_job_id, repo_id, created_at)
+SELECT %s, repo_id, %s FROM unnest(%s::integer[]) AS repo_id
+ON CONFLICT (search_job_id, repo_id) DO NOTHING
+`
+
+// CreateRepoJobs enqueues a RepoJob for every given repository of the search
+// job. Repositories that already have a RepoJob for the search job are
+// skipped, so that a retried search job does not search a repository twice.
+func (s *Store) CreateRepoJobs(ctx context.Context, searchJobID int64, repoIDs []api.RepoID) error {
+	ids := make([]int64, 0, len(repoIDs))
+	for _, id := range repoIDs {
+		ids = append(ids, int64(id))
+	}
+	return s.Exec(ctx, sqlf.Sprintf(createRepoJobsFmtStr, searchJobID, s.now(), pq.Array(ids)))
+}
+
+const setRepoJobMatchCountFmtStr = `
+-- source: enterprise/internal/searchjobs/repo_jobs.go:SetRepoJobMatchCount
+UPDATE search_job_repos SET match_count = %s WHERE id = %s
+`
+
+// SetRepoJobMatchCount records the number of matches written to the results
+// of the given RepoJob.
+func (s *Store) SetRepoJobMatchCount(ctx context.Context, id int64, matchCount int) error {
+	return s.Exec(ctx, sqlf.Sprintf(setRepoJobMatchCountFmtStr, matchCount, id))
+}
+
+const listCompletedRepoJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/repo_jobs.go:ListCompletedRepoJobs
+SELECT %s FROM search_job_repos
+WHERE search_job_id = %s AND state = 'completed'
+ORDER BY id
+`
+
+// ListCompletedRepoJobs returns the RepoJobs of the given search job whose
+// results have been stored, in the order they were created.
+func (s *Store) ListCompletedRepoJobs(ctx context.Context, searchJobID int64) ([]*RepoJob, error) {
+	return scanRepoJobs(s.Query(ctx, sqlf.Sprintf(listCompletedRepoJobsFmtStr, sqlf.Join(RepoJobsColumns, ", "), searchJobID)))
+}
+
+// RepoJobStats summarizes the progress of the RepoJobs of a search job.
+type RepoJobStats struct {
+	Total      int32
+	Completed  int32
+	Failed     int32
+	InProgress int32
+	MatchCount int64
+}
+
+const getRepoJobStatsFmtStr = `
+-- source: enterprise/internal/searchjobs/repo_jobs.go:GetRepoJobStats
+SELECT
+	COUNT(*),
+	COUNT(*) FILTER (WHERE state = 'completed'),
+	COUNT(*) FILTER (WHERE state = 'failed'),
+	COUNT(*) FILTER (WHERE state IN ('queued', 'processing', 'errored')),
+	COALESCE(SUM(match_count) FILTER (WHERE state = 'completed'), 0)
+FROM search_job_repos
+WHERE search_job_id = %s
+`
+
+// GetRepoJobStats returns the progress of the RepoJobs of the given search
+// job.
+func (s *Store) GetRepoJobStats(ctx context.Context, searchJobID int64) (RepoJobStats, error) {
+	var stats RepoJobStats
+	err := s.QueryRow(ctx, sqlf.Sprintf(getRepoJobStatsFmtStr, searchJobID)).Scan(
+		&stats.Total,
+		&stats.Completed,
+		&stats.Failed,
+		&stats.InProgress,
+		&stats.MatchCount,
+	)
+	return stats, err
+}
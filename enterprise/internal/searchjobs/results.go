@@ -0,0 +1,140 @@
+package searchjobs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+)
+
+// ResultsKey returns the upload store key under which the results of a RepoJob
+// are stored. The results are stored in the JSON Lines format: one match event
+// of the streaming search API per line.
+func ResultsKey(searchJobID, repoJobID int64) string {
+	return fmt.Sprintf("search-jobs/%d/%d.jsonl", searchJobID, repoJobID)
+}
+
+// WriteResult appends match to w as a line of JSON.
+func WriteResult(w io.Writer, match streamhttp.EventMatch) error {
+	b, err := json.Marshal(match)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+var csvHeader = []string{"type", "repository", "commit", "path", "line", "preview"}
+
+// CSVWriter converts results stored in the JSON Lines format into CSV with one
+// row per line match, symbol, or otherwise per match.
+type CSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// result is the union of the fields of the match events that are written to
+// CSV.
+type result struct {
+	Type        string                      `json:"type"`
+	Repository  string                      `json:"repository"`
+	Commit      string                      `json:"commit"`
+	Path        string                      `json:"path"`
+	LineMatches []streamhttp.EventLineMatch `json:"lineMatches"`
+	Symbols     []streamhttp.Symbol         `json:"symbols"`
+	Label       string                      `json:"label"`
+}
+
+// WriteResults writes a CSV row for every result read from r.
+func (c *CSVWriter) WriteResults(r io.Reader) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if err := c.writeResult(line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *CSVWriter) writeResult(line []byte) error {
+	var res result
+	if err := json.Unmarshal(line, &res); err != nil {
+		return errors.Wrap(err, "decoding result")
+	}
+
+	row := func(line, preview string) error {
+		return c.w.Write([]string{res.Type, res.Repository, res.Commit, res.Path, line, preview})
+	}
+
+	switch {
+	case len(res.LineMatches) > 0:
+		for _, lm := range res.LineMatches {
+			if err := row(strconv.Itoa(int(lm.LineNumber)+1), lm.Line); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case len(res.Symbols) > 0:
+		for _, s := range res.Symbols {
+			if err := row("", s.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return row("", res.Label)
+}
+
+func (c *CSVWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(csvHeader)
+}
+
+// Flush writes any buffered rows to the underlying writer. The header is
+// written even if there were no results.
+func (c *CSVWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Result formats supported by ResultsURLPath.
+const (
+	FormatCSV       = "csv"
+	FormatJSONLines = "jsonl"
+)
+
+// ResultsURLPath returns the path, relative to the external URL, from which
+// the results of the given search job can be downloaded in format.
+func ResultsURLPath(searchJobID int64, format string) string {
+	return fmt.Sprintf("/.api/search-jobs/%s/results.%s", MarshalSearchJobID(searchJobID), format)
+}
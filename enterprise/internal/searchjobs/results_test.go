@@ -0,0 +1,96 @@
+package searchjobs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var first, second bytes.Buffer
+	for _, match := range []streamhttp.EventMatch{
+		&streamhttp.EventContentMatch{
+			Type:       streamhttp.ContentMatchType,
+			Repository: "github.com/sourcegraph/sourcegraph",
+			Commit:     "deadbeef",
+			Path:       "main.go",
+			LineMatches: []streamhttp.EventLineMatch{
+				{Line: "func main() {", LineNumber: 4},
+				{Line: `	fmt.Println("hello, world")`, LineNumber: 5},
+			},
+		},
+		&streamhttp.EventPathMatch{
+			Type:       streamhttp.PathMatchType,
+			Repository: "github.com/sourcegraph/sourcegraph",
+			Commit:     "deadbeef",
+			Path:       "README.md",
+		},
+	} {
+		if err := WriteResult(&first, match); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, match := range []streamhttp.EventMatch{
+		&streamhttp.EventSymbolMatch{
+			Type:       streamhttp.SymbolMatchType,
+			Repository: "github.com/sourcegraph/zoekt",
+			Path:       "api.go",
+			Symbols:    []streamhttp.Symbol{{Name: "FileMatch"}, {Name: "LineMatch"}},
+		},
+		&streamhttp.EventRepoMatch{
+			Type:       streamhttp.RepoMatchType,
+			Repository: "github.com/sourcegraph/zoekt",
+		},
+		&streamhttp.EventCommitMatch{
+			Type:       streamhttp.CommitMatchType,
+			Repository: "github.com/sourcegraph/zoekt",
+			Label:      "fix, the build",
+		},
+	} {
+		if err := WriteResult(&second, match); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got bytes.Buffer
+	w := NewCSVWriter(&got)
+	if err := w.WriteResults(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteResults(&second); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `type,repository,commit,path,line,preview
+content,github.com/sourcegraph/sourcegraph,deadbeef,main.go,5,func main() {
+content,github.com/sourcegraph/sourcegraph,deadbeef,main.go,6,"	fmt.Println(""hello, world"")"
+path,github.com/sourcegraph/sourcegraph,deadbeef,README.md,,
+symbol,github.com/sourcegraph/zoekt,,api.go,,FileMatch
+symbol,github.com/sourcegraph/zoekt,,api.go,,LineMatch
+repo,github.com/sourcegraph/zoekt,,,,
+commit,github.com/sourcegraph/zoekt,,,,"fix, the build"
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Fatalf("unexpected CSV (-want +got):\n%s", diff)
+	}
+}
+
+func TestCSVWriter_noResults(t *testing.T) {
+	var got bytes.Buffer
+	w := NewCSVWriter(&got)
+	if err := w.WriteResults(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "type,repository,commit,path,line,preview\n"; got.String() != want {
+		t.Fatalf("got %q, want %q", got.String(), want)
+	}
+}
@@ -0,0 +1,280 @@
+package searchjobs
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+)
+
+// SearchJob is an exhaustive search submitted by a user. The worker processing
+// a search job resolves the repositories matched by its query and creates a
+// RepoJob for each of them.
+type SearchJob struct {
+	ID          int64
+	InitiatorID int32
+	Query       string
+	NotifiedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// Fields demanded for any dbworker.
+	State          string
+	FailureMessage *string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	ProcessAfter   *time.Time
+	NumResets      int32
+	NumFailures    int32
+}
+
+func (j *SearchJob) RecordID() int {
+	return int(j.ID)
+}
+
+// The aggregate states of a search job. They are derived from the state of the
+// search job, which resolves the repositories to search, and of its RepoJobs.
+const (
+	AggregateStateQueued     = "QUEUED"
+	AggregateStateProcessing = "PROCESSING"
+	AggregateStateCompleted  = "COMPLETED"
+	AggregateStateFailed     = "FAILED"
+)
+
+// AggregateState returns the state of the search job as a whole, given the
+// progress of its RepoJobs. A completed search job may have failed RepoJobs;
+// those are reported by stats.
+func (j *SearchJob) AggregateState(stats RepoJobStats) string {
+	switch j.State {
+	case "queued":
+		return AggregateStateQueued
+	case "failed":
+		return AggregateStateFailed
+	case "completed":
+		if stats.InProgress > 0 {
+			return AggregateStateProcessing
+		}
+		return AggregateStateCompleted
+	default:
+		// processing, or errored and about to be retried.
+		return AggregateStateProcessing
+	}
+}
+
+// SearchJobKind is the GraphQL node kind of search jobs.
+const SearchJobKind = "SearchJob"
+
+// MarshalSearchJobID returns the GraphQL ID of the search job with the given
+// ID.
+func MarshalSearchJobID(id int64) graphql.ID {
+	return relay.MarshalID(SearchJobKind, id)
+}
+
+// UnmarshalSearchJobID returns the ID of the search job with the given GraphQL
+// ID.
+func UnmarshalSearchJobID(id graphql.ID) (searchJobID int64, err error) {
+	if kind := relay.UnmarshalKind(id); kind != SearchJobKind {
+		return 0, errors.Errorf("expected graphql ID to have kind %q; got %q", SearchJobKind, kind)
+	}
+	err = relay.UnmarshalSpec(id, &searchJobID)
+	return searchJobID, err
+}
+
+var SearchJobsColumns = []*sqlf.Query{
+	sqlf.Sprintf("search_jobs.id"),
+	sqlf.Sprintf("search_jobs.initiator_id"),
+	sqlf.Sprintf("search_jobs.query"),
+	sqlf.Sprintf("search_jobs.notified_at"),
+	sqlf.Sprintf("search_jobs.created_at"),
+	sqlf.Sprintf("search_jobs.updated_at"),
+	sqlf.Sprintf("search_jobs.state"),
+	sqlf.Sprintf("search_jobs.failure_message"),
+	sqlf.Sprintf("search_jobs.started_at"),
+	sqlf.Sprintf("search_jobs.finished_at"),
+	sqlf.Sprintf("search_jobs.process_after"),
+	sqlf.Sprintf("search_jobs.num_resets"),
+	sqlf.Sprintf("search_jobs.num_failures"),
+}
+
+func ScanSearchJobs(rows *sql.Rows, err error) (workerutil.Record, bool, error) {
+	jobs, err := scanSearchJobs(rows, err)
+	if err != nil || len(jobs) == 0 {
+		return &SearchJob{}, false, err
+	}
+	return jobs[0], true, nil
+}
+
+func scanSearchJobs(rows *sql.Rows, queryErr error) (_ []*SearchJob, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var jobs []*SearchJob
+	for rows.Next() {
+		j := &SearchJob{}
+		if err := rows.Scan(
+			&j.ID,
+			&j.InitiatorID,
+			&j.Query,
+			&j.NotifiedAt,
+			&j.CreatedAt,
+			&j.UpdatedAt,
+			&j.State,
+			&j.FailureMessage,
+			&j.StartedAt,
+			&j.FinishedAt,
+			&j.ProcessAfter,
+			&j.NumResets,
+			&j.NumFailures,
+		); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+const createSearchJobFmtStr = `
+-- source: enterprise/internal/searchjobs/search_jobs.go:CreateSearchJob
+INSERT INTO search_jobs (initiator_id, query, created_at, updated_at)
+VALUES (%s, %s, %s, %s)
+RETURNING %s
+`
+
+// CreateSearchJob enqueues a new search job for query on behalf of the user
+// with the given ID.
+func (s *Store) CreateSearchJob(ctx context.Context, initiatorID int32, query string) (*SearchJob, error) {
+	now := s.now()
+	q := sqlf.Sprintf(
+		createSearchJobFmtStr,
+		initiatorID,
+		query,
+		now,
+		now,
+		sqlf.Join(SearchJobsColumns, ", "),
+	)
+	jobs, err := scanSearchJobs(s.Query(ctx, q))
+	if err != nil {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+const getSearchJobFmtStr = `
+-- source: enterprise/internal/searchjobs/search_jobs.go:GetSearchJob
+SELECT %s FROM search_jobs WHERE id = %s
+`
+
+// GetSearchJob returns the search job with the given ID. It returns
+// ErrNoResults if no such job exists.
+func (s *Store) GetSearchJob(ctx context.Context, id int64) (*SearchJob, error) {
+	jobs, err := scanSearchJobs(s.Query(ctx, sqlf.Sprintf(getSearchJobFmtStr, sqlf.Join(SearchJobsColumns, ", "), id)))
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrNoResults
+	}
+	return jobs[0], nil
+}
+
+// ListSearchJobsOpts are the options for ListSearchJobs and CountSearchJobs.
+type ListSearchJobsOpts struct {
+	// InitiatorID, if non-zero, restricts the jobs to those of the given user.
+	InitiatorID int32
+
+	// Limit, if non-zero, is the maximum number of jobs returned.
+	Limit int
+
+	// Before, if non-zero, restricts the jobs to those with an ID lower than
+	// Before. Jobs are returned newest first.
+	Before int64
+}
+
+func (o ListSearchJobsOpts) conds() *sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.InitiatorID != 0 {
+		conds = append(conds, sqlf.Sprintf("initiator_id = %s", o.InitiatorID))
+	}
+	if o.Before != 0 {
+		conds = append(conds, sqlf.Sprintf("id < %s", o.Before))
+	}
+	return sqlf.Join(conds, " AND ")
+}
+
+const listSearchJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/search_jobs.go:ListSearchJobs
+SELECT %s FROM search_jobs
+WHERE %s
+ORDER BY id DESC
+%s
+`
+
+// ListSearchJobs returns the search jobs matching opts, newest first.
+func (s *Store) ListSearchJobs(ctx context.Context, opts ListSearchJobsOpts) ([]*SearchJob, error) {
+	limit := sqlf.Sprintf("")
+	if opts.Limit > 0 {
+		limit = sqlf.Sprintf("LIMIT %s", opts.Limit)
+	}
+	return scanSearchJobs(s.Query(ctx, sqlf.Sprintf(listSearchJobsFmtStr, sqlf.Join(SearchJobsColumns, ", "), opts.conds(), limit)))
+}
+
+const countSearchJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/search_jobs.go:CountSearchJobs
+SELECT COUNT(*) FROM search_jobs WHERE %s
+`
+
+// CountSearchJobs returns the number of search jobs matching opts, ignoring
+// opts.Limit and opts.Before.
+func (s *Store) CountSearchJobs(ctx context.Context, opts ListSearchJobsOpts) (int, error) {
+	opts.Limit, opts.Before = 0, 0
+	count, _, err := basestore.ScanFirstInt(s.Query(ctx, sqlf.Sprintf(countSearchJobsFmtStr, opts.conds())))
+	return count, err
+}
+
+const listSearchJobsToNotifyFmtStr = `
+-- source: enterprise/internal/searchjobs/search_jobs.go:ListSearchJobsToNotify
+SELECT %s FROM search_jobs
+WHERE
+	notified_at IS NULL AND
+	(
+		state = 'failed' OR
+		(
+			state = 'completed' AND
+			NOT EXISTS (
+				SELECT 1 FROM search_job_repos
+				WHERE search_job_repos.search_job_id = search_jobs.id
+				AND search_job_repos.state IN ('queued', 'processing', 'errored')
+			)
+		)
+	)
+ORDER BY id
+LIMIT %s
+`
+
+// ListSearchJobsToNotify returns up to limit search jobs whose initiator has
+// not yet been notified although the job and all of its repositories have
+// reached a terminal state.
+func (s *Store) ListSearchJobsToNotify(ctx context.Context, limit int) ([]*SearchJob, error) {
+	return scanSearchJobs(s.Query(ctx, sqlf.Sprintf(listSearchJobsToNotifyFmtStr, sqlf.Join(SearchJobsColumns, ", "), limit)))
+}
+
+const markSearchJobNotifiedFmtStr = `
+-- source: enterprise/internal/searchjobs/search_jobs.go:MarkSearchJobNotified
+UPDATE search_jobs SET notified_at = %s, updated_at = %s WHERE id = %s
+`
+
+// MarkSearchJobNotified records that the initiator of the given job has been
+// notified of its completion.
+func (s *Store) MarkSearchJobNotified(ctx context.Context, id int64) error {
+	now := s.now()
+	return s.Exec(ctx, sqlf.Sprintf(markSearchJobNotifiedFmtStr, now, now, id))
+}
@@ -0,0 +1,45 @@
+package searchjobs
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/timeutil"
+)
+
+// ErrNoResults is returned by Store method calls that found no results.
+var ErrNoResults = errors.New("no results")
+
+// Store exposes methods to read and write search jobs and their per-repository
+// units of work from persistent storage.
+type Store struct {
+	*basestore.Store
+	now func() time.Time
+}
+
+// NewStore returns a new Store backed by the given database.
+func NewStore(db dbutil.DB) *Store {
+	return NewStoreWithClock(db, timeutil.Now)
+}
+
+// NewStoreWithClock returns a new Store backed by the given database and
+// clock for timestamps.
+func NewStoreWithClock(db dbutil.DB, clock func() time.Time) *Store {
+	return &Store{Store: basestore.NewWithDB(db, sql.TxOptions{}), now: clock}
+}
+
+// Transact creates a new transaction.
+// It's required to implement this method and wrap the Transact method of the
+// underlying basestore.Store.
+func (s *Store) Transact(ctx context.Context) (*Store, error) {
+	txBase, err := s.Store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Store: txBase, now: s.now}, nil
+}
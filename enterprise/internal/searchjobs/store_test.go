@@ -0,0 +1,161 @@
+package searchjobs
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+)
+
+func TestStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	db := dbtest.NewDB(t, "")
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	s := NewStoreWithClock(db, func() time.Time { return now })
+
+	userID := insertTestUser(t, db, "searchjobs-user")
+	otherUserID := insertTestUser(t, db, "searchjobs-other")
+	repoIDs := []api.RepoID{insertTestRepo(t, db, "r1"), insertTestRepo(t, db, "r2"), insertTestRepo(t, db, "r3")}
+
+	job, err := s.CreateSearchJob(ctx, userID, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != "queued" || job.InitiatorID != userID || job.Query != "secret" || !job.CreatedAt.Equal(now) {
+		t.Fatalf("unexpected search job %+v", job)
+	}
+	if _, err := s.CreateSearchJob(ctx, otherUserID, "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("get and list", func(t *testing.T) {
+		have, err := s.GetSearchJob(ctx, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(job, have); diff != "" {
+			t.Fatalf("unexpected search job (-want +got):\n%s", diff)
+		}
+
+		if _, err := s.GetSearchJob(ctx, job.ID+1000); err != ErrNoResults {
+			t.Fatalf("got error %v, want %v", err, ErrNoResults)
+		}
+
+		jobs, err := s.ListSearchJobs(ctx, ListSearchJobsOpts{InitiatorID: userID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs) != 1 || jobs[0].ID != job.ID {
+			t.Fatalf("unexpected search jobs %+v", jobs)
+		}
+
+		count, err := s.CountSearchJobs(ctx, ListSearchJobsOpts{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 2 {
+			t.Fatalf("got count %d, want 2", count)
+		}
+	})
+
+	t.Run("repo jobs", func(t *testing.T) {
+		if err := s.CreateRepoJobs(ctx, job.ID, repoIDs[:2]); err != nil {
+			t.Fatal(err)
+		}
+		// Creating the repo jobs again, e.g. when the search job is retried,
+		// must not search a repository twice.
+		if err := s.CreateRepoJobs(ctx, job.ID, repoIDs); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.Exec(ctx, sqlf.Sprintf("UPDATE search_jobs SET state = 'completed' WHERE id = %s", job.ID)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Exec(ctx, sqlf.Sprintf("UPDATE search_job_repos SET state = 'completed' WHERE repo_id = %s", repoIDs[0])); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Exec(ctx, sqlf.Sprintf("UPDATE search_job_repos SET state = 'failed' WHERE repo_id = %s", repoIDs[1])); err != nil {
+			t.Fatal(err)
+		}
+
+		completed, err := s.ListCompletedRepoJobs(ctx, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(completed) != 1 || completed[0].RepoID != repoIDs[0] {
+			t.Fatalf("unexpected completed repo jobs %+v", completed)
+		}
+		if err := s.SetRepoJobMatchCount(ctx, completed[0].ID, 42); err != nil {
+			t.Fatal(err)
+		}
+
+		stats, err := s.GetRepoJobStats(ctx, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(RepoJobStats{Total: 3, Completed: 1, Failed: 1, InProgress: 1, MatchCount: 42}, stats); diff != "" {
+			t.Fatalf("unexpected stats (-want +got):\n%s", diff)
+		}
+
+		// The third repository is still queued.
+		toNotify, err := s.ListSearchJobsToNotify(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(toNotify) != 0 {
+			t.Fatalf("unexpected search jobs to notify %+v", toNotify)
+		}
+
+		if err := s.Exec(ctx, sqlf.Sprintf("UPDATE search_job_repos SET state = 'completed' WHERE repo_id = %s", repoIDs[2])); err != nil {
+			t.Fatal(err)
+		}
+		toNotify, err = s.ListSearchJobsToNotify(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(toNotify) != 1 || toNotify[0].ID != job.ID {
+			t.Fatalf("unexpected search jobs to notify %+v", toNotify)
+		}
+
+		if err := s.MarkSearchJobNotified(ctx, job.ID); err != nil {
+			t.Fatal(err)
+		}
+		toNotify, err = s.ListSearchJobsToNotify(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(toNotify) != 0 {
+			t.Fatalf("unexpected search jobs to notify %+v", toNotify)
+		}
+	})
+}
+
+func insertTestUser(t *testing.T, db *sql.DB, name string) (userID int32) {
+	t.Helper()
+
+	q := sqlf.Sprintf("INSERT INTO users (username) VALUES (%s) RETURNING id", name)
+	if err := db.QueryRow(q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	return userID
+}
+
+func insertTestRepo(t *testing.T, db *sql.DB, name string) (repoID api.RepoID) {
+	t.Helper()
+
+	q := sqlf.Sprintf("INSERT INTO repo (name) VALUES (%s) RETURNING id", name)
+	if err := db.QueryRow(q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&repoID); err != nil {
+		t.Fatal(err)
+	}
+	return repoID
+}
@@ -29,6 +29,11 @@ type Actor struct {
 	// cookie, logout would be ineffective.)
 	FromSessionCookie bool `json:"-"`
 
+	// AccessTokenFingerprint identifies the access token used to authenticate the actor, if
+	// any. It is the hex-encoded SHA-256 hash of the token, so that it can be used as a key
+	// without revealing the token.
+	AccessTokenFingerprint string `json:"-"`
+
 	// user is populated lazily by (*Actor).User()
 	user     *types.User
 	userErr  error
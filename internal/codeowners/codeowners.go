@@ -0,0 +1,221 @@
+// Package codeowners parses CODEOWNERS files and matches paths against their
+// rules.
+//
+// Both the GitHub and the GitLab syntax are supported. In the GitHub syntax,
+// the last rule matching a path determines its owners. GitLab additionally
+// groups rules into sections, such as "[Documentation]" or "^[Docs] @owner"
+// with default owners. The last matching rule of each section applies, and the
+// owners of a path are the union of those of all sections.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gobwas/glob"
+)
+
+// Paths are the locations of CODEOWNERS files in a repository, in the order
+// in which they are looked up. Only the first one found is used.
+var Paths = []string{
+	"CODEOWNERS",
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	sections []*section
+}
+
+type section struct {
+	defaultOwners []string
+	rules         []*rule
+}
+
+type rule struct {
+	globs  []glob.Glob
+	owners []string
+}
+
+// sectionHeader matches GitLab section headers, such as "[Docs]",
+// "^[Docs][2] @docs-team" or "[Docs] @docs-team @alice".
+var sectionHeader = regexp.MustCompile(`^\^?\[[^\]]+\](?:\[\d+\])?(.*)$`)
+
+// Parse parses the content of a CODEOWNERS file. Like GitHub, it skips rules
+// with invalid patterns instead of failing.
+func Parse(data []byte) (*Ruleset, error) {
+	rs := &Ruleset{}
+	current := &section{}
+	rs.sections = append(rs.sections, current)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := sectionHeader.FindStringSubmatch(line); m != nil {
+			current = &section{defaultOwners: fields(m[1])}
+			rs.sections = append(rs.sections, current)
+			continue
+		}
+
+		fs := fields(line)
+		if len(fs) == 0 {
+			continue
+		}
+		r, err := newRule(fs[0], fs[1:])
+		if err != nil {
+			continue
+		}
+		current.rules = append(current.rules, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// fields splits a line into its fields, dropping a trailing comment and
+// unescaping escaped spaces and hashes in the pattern.
+func fields(line string) []string {
+	var (
+		fs      []string
+		current strings.Builder
+		escaped bool
+	)
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '#':
+			if current.Len() > 0 {
+				fs = append(fs, current.String())
+			}
+			return fs
+		case c == ' ' || c == '\t':
+			if current.Len() > 0 {
+				fs = append(fs, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(c)
+		}
+	}
+	if current.Len() > 0 {
+		fs = append(fs, current.String())
+	}
+	return fs
+}
+
+// newRule compiles a CODEOWNERS pattern, which follows the rules of
+// .gitignore patterns: A pattern without a slash, other than a trailing one,
+// matches at any depth, and a pattern matching a directory matches everything
+// beneath it. As documented by GitHub, a pattern ending in "/*" only matches
+// the files directly in the directory.
+func newRule(pattern string, owners []string) (*rule, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	recursive := p != "*" && !strings.HasSuffix(p, "/*")
+
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		// "/" owns the whole repository.
+		p = "**"
+	}
+
+	var exprs []string
+	if anchored {
+		exprs = append(exprs, p)
+	} else {
+		exprs = append(exprs, p, "**/"+p)
+	}
+
+	var globExprs []string
+	for _, expr := range exprs {
+		if !dirOnly {
+			globExprs = append(globExprs, expr)
+		}
+		if recursive {
+			globExprs = append(globExprs, expr+"/**")
+		}
+	}
+
+	r := &rule{owners: owners}
+	for _, expr := range globExprs {
+		g, err := glob.Compile(expr, '/')
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+		r.globs = append(r.globs, g)
+	}
+	return r, nil
+}
+
+func (r *rule) match(path string) bool {
+	for _, g := range r.globs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the owners of the file at path, relative to the root of the
+// repository. It returns nil if the file has no owners.
+func (rs *Ruleset) Match(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+
+	var owners []string
+	seen := map[string]struct{}{}
+	for _, s := range rs.sections {
+		for i := len(s.rules) - 1; i >= 0; i-- {
+			r := s.rules[i]
+			if !r.match(path) {
+				continue
+			}
+			ruleOwners := r.owners
+			if len(ruleOwners) == 0 {
+				ruleOwners = s.defaultOwners
+			}
+			for _, o := range ruleOwners {
+				key := strings.ToLower(o)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				owners = append(owners, o)
+			}
+			break
+		}
+	}
+	return owners
+}
+
+// IsOwnedBy returns whether the file at path is owned by owner. Owners are
+// compared case-insensitively, and the leading @ of users and teams is
+// optional.
+func (rs *Ruleset) IsOwnedBy(path, owner string) bool {
+	for _, o := range rs.Match(path) {
+		if SameOwner(o, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// SameOwner returns whether a and b refer to the same owner.
+func SameOwner(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, "@"), strings.TrimPrefix(b, "@"))
+}
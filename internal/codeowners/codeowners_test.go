@@ -0,0 +1,140 @@
+package codeowners
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		paths map[string][]string
+	}{
+		{
+			name: "github",
+			file: `
+# Default owners
+*       @global-owner1 @global-owner2
+*.js    @js-owner # JavaScript
+*.go    docs@example.com
+/build/logs/ @doctocat
+docs/*  @docs-team
+apps/   @octocat
+/scripts/ @doctocat @octocat
+/apps/github
+file\ with\ spaces.txt @spaces
+`,
+			paths: map[string][]string{
+				"README.md":                         {"@global-owner1", "@global-owner2"},
+				"web/app.js":                        {"@js-owner"},
+				"main.go":                           {"docs@example.com"},
+				"build/logs/out.txt":                {"@doctocat"},
+				"build/logs/nested/out.txt":         {"@doctocat"},
+				"src/build/logs/out.txt":            {"@global-owner1", "@global-owner2"},
+				"docs/getting-started.md":           {"@docs-team"},
+				"docs/build-app/troubleshooting.md": {"@global-owner1", "@global-owner2"},
+				"apps/index.md":                     {"@octocat"},
+				"nested/apps/index.md":              {"@octocat"},
+				"apps/github/index.md":              nil,
+				"scripts/deploy.sh":                 {"@doctocat", "@octocat"},
+				"file with spaces.txt":              {"@spaces"},
+			},
+		},
+		{
+			name: "gitlab sections",
+			file: `
+* @default
+
+[Documentation] @docs-team
+docs/
+README.md @tech-writer
+
+^[Backend][2] @backend-team
+*.go
+/internal/ @platform
+`,
+			paths: map[string][]string{
+				"README.md":         {"@default", "@tech-writer"},
+				"docs/index.md":     {"@default", "@docs-team"},
+				"cmd/main.go":       {"@default", "@backend-team"},
+				"internal/x.go":     {"@default", "@platform"},
+				"internal/x.txt":    {"@default", "@platform"},
+				"web/src/index.tsx": {"@default"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, err := Parse([]byte(test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range test.paths {
+				if diff := cmp.Diff(want, rs.Match(path)); diff != "" {
+					t.Errorf("unexpected owners of %s (-want +got):\n%s", path, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestIsOwnedBy(t *testing.T) {
+	rs, err := Parse([]byte("*.go @OurOrg/Payments\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, owner := range []string{"@ourorg/payments", "ourorg/payments", "@OURORG/PAYMENTS"} {
+		if !rs.IsOwnedBy("main.go", owner) {
+			t.Errorf("expected main.go to be owned by %s", owner)
+		}
+	}
+	if rs.IsOwnedBy("main.go", "@ourorg/billing") {
+		t.Error("expected main.go not to be owned by @ourorg/billing")
+	}
+	if rs.IsOwnedBy("README.md", "@ourorg/payments") {
+		t.Error("expected README.md not to be owned by @ourorg/payments")
+	}
+}
+
+func TestForCommit(t *testing.T) {
+	reads := 0
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		reads++
+		if commit == "abc" && name == ".github/CODEOWNERS" {
+			return []byte("* @owner\n"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		rs, err := ForCommit(ctx, "github.com/foo/bar", "abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"@owner"}, rs.Match("main.go")); diff != "" {
+			t.Fatalf("unexpected owners (-want +got):\n%s", diff)
+		}
+	}
+	if reads != 2 {
+		t.Fatalf("expected the ruleset to be read once from 2 paths and then cached, got %d reads", reads)
+	}
+
+	rs, err := ForCommit(ctx, "github.com/foo/bar", "def")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs != nil {
+		t.Fatalf("expected no ruleset, got %+v", rs)
+	}
+}
@@ -0,0 +1,56 @@
+package codeowners
+
+import (
+	"context"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// maxFileSize is the maximum size of CODEOWNERS files. GitHub ignores larger
+// files as well.
+const maxFileSize = 3 * 1024 * 1024
+
+// rulesets caches the rulesets of repositories by commit. Since commits are
+// immutable, entries never need to be invalidated.
+var rulesets, _ = lru.New(1000)
+
+type cacheKey struct {
+	repo   api.RepoName
+	commit api.CommitID
+}
+
+// ForCommit returns the ruleset of the CODEOWNERS file of the repository at
+// the given commit. It returns nil if the repository has no CODEOWNERS file at
+// any of Paths.
+func ForCommit(ctx context.Context, repo api.RepoName, commit api.CommitID) (*Ruleset, error) {
+	key := cacheKey{repo: repo, commit: commit}
+	if v, ok := rulesets.Get(key); ok {
+		return v.(*Ruleset), nil
+	}
+
+	rs, err := load(ctx, repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	rulesets.Add(key, rs)
+	return rs, nil
+}
+
+func load(ctx context.Context, repo api.RepoName, commit api.CommitID) (*Ruleset, error) {
+	for _, path := range Paths {
+		data, err := git.ReadFile(ctx, repo, commit, path, maxFileSize)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s of %s@%s", path, repo, commit)
+		}
+		return Parse(data)
+	}
+	return nil, nil
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/jsonx"
 
@@ -139,6 +140,22 @@ func (c *cachedConfigurationSource) Write(ctx context.Context, input conftypes.R
 	return nil
 }
 
+func (c *cachedConfigurationSource) WriteIfUpToDate(ctx context.Context, input conftypes.RawUnified, lastID int32) error {
+	source, ok := c.source.(ConditionalConfigurationSource)
+	if !ok {
+		return errors.New("configuration source does not support conditional writes")
+	}
+
+	c.entryMu.Lock()
+	defer c.entryMu.Unlock()
+	if err := source.WriteIfUpToDate(ctx, input, lastID); err != nil {
+		return err
+	}
+	c.entry = &input
+	c.entryTime = time.Now()
+	return nil
+}
+
 // InitConfigurationServerFrontendOnly creates and returns a configuration
 // server. This should only be invoked by the frontend, or else a panic will
 // occur. This function should only ever be called once.
@@ -0,0 +1,48 @@
+package conf
+
+import (
+	"regexp"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sourcegraph/jsonx"
+)
+
+// RedactedSecret is the value that replaces secrets in redacted site configuration.
+const RedactedSecret = `"REDACTED"`
+
+// secretPropertyPattern matches the names of site configuration properties whose string
+// values are secrets (e.g., "clientSecret", "accessToken", "password", and
+// "licenseKey").
+var secretPropertyPattern = regexp.MustCompile(`(?i)(password|secret|token|key|credentials)$`)
+
+// RedactSecrets returns the given site configuration with the values of all string properties
+// that likely hold secrets replaced by RedactedSecret. Comments and formatting are preserved so
+// that redacted revisions of a configuration can be compared with one another.
+func RedactSecrets(raw string) (string, error) {
+	root, errs := jsonx.ParseTree(raw, jsonx.ParseOptions{Comments: true, TrailingCommas: true})
+	if len(errs) > 0 {
+		return "", errors.Errorf("failed to parse JSON: %v", errs)
+	}
+	if root == nil {
+		return raw, nil
+	}
+
+	var edits []jsonx.Edit
+	var visit func(node *jsonx.Node)
+	visit = func(node *jsonx.Node) {
+		if node.Type == jsonx.Property && len(node.Children) == 2 {
+			name, _ := node.Children[0].Value.(string)
+			if value := node.Children[1]; value.Type == jsonx.String && secretPropertyPattern.MatchString(name) {
+				edits = append(edits, jsonx.Edit{Offset: value.Offset, Length: value.Length, Content: RedactedSecret})
+				return
+			}
+		}
+
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	return jsonx.ApplyEdits(raw, edits...)
+}
@@ -0,0 +1,53 @@
+package conf
+
+import "testing"
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "no secrets",
+			input: `{"externalURL": "https://example.com"}`,
+			want:  `{"externalURL": "https://example.com"}`,
+		},
+		{
+			name: "nested secrets",
+			input: `{
+  // OAuth provider
+  "auth.providers": [{"type": "github", "clientID": "id", "clientSecret": "s3cr3t"}],
+  "email.smtp": {"username": "u", "password": "p",},
+  "licenseKey": "abc",
+}`,
+			want: `{
+  // OAuth provider
+  "auth.providers": [{"type": "github", "clientID": "id", "clientSecret": "REDACTED"}],
+  "email.smtp": {"username": "u", "password": "REDACTED",},
+  "licenseKey": "REDACTED",
+}`,
+		},
+		{
+			name:  "non-string values",
+			input: `{"auth.accessTokens": {"allow": "all-users-create"}, "token": 1}`,
+			want:  `{"auth.accessTokens": {"allow": "all-users-create"}, "token": 1}`,
+		},
+		{
+			name:  "multi-byte characters",
+			input: `{"motd": ["héllo 👋"], "password": "pässwörd"}`,
+			want:  `{"motd": ["héllo 👋"], "password": "REDACTED"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := RedactSecrets(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("unexpected redacted config.\nwant: %s\ngot:  %s", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package reposource
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// SubversionRepoName returns the Sourcegraph name for the Subversion repository
+// at path under a Subversion root on host.
+func SubversionRepoName(repositoryPathPattern, host, path string) api.RepoName {
+	if repositoryPathPattern == "" {
+		repositoryPathPattern = "{host}/{path}"
+	}
+
+	name := strings.NewReplacer(
+		"{host}", host,
+		"{path}", strings.Trim(path, "/"),
+	).Replace(repositoryPathPattern)
+	return api.RepoName(strings.Trim(name, "/"))
+}
@@ -21,6 +21,15 @@ type ConfigurationSource interface {
 	Read(ctx context.Context) (conftypes.RawUnified, error)
 }
 
+// ConditionalConfigurationSource is a ConfigurationSource that can reject a
+// write when the site configuration was changed since the caller read it.
+type ConditionalConfigurationSource interface {
+	ConfigurationSource
+	// WriteIfUpToDate updates the configuration iff lastID is the ID of the
+	// most recent revision of the site configuration.
+	WriteIfUpToDate(ctx context.Context, data conftypes.RawUnified, lastID int32) error
+}
+
 // Server provides access and manages modifications to the site configuration.
 type Server struct {
 	Source ConfigurationSource
@@ -71,14 +80,37 @@ func (s *Server) Write(ctx context.Context, input conftypes.RawUnified) error {
 		return err
 	}
 
-	// Wait for the change to the configuration file to be detected. Otherwise
-	// we would return to the caller earlier than server.Raw() would return the
-	// new configuration.
+	s.waitForWrite()
+	return nil
+}
+
+// WriteIfUpToDate is like Write, but atomically rejects the write if lastID is
+// not the ID of the most recent revision of the site configuration.
+func (s *Server) WriteIfUpToDate(ctx context.Context, input conftypes.RawUnified, lastID int32) error {
+	source, ok := s.Source.(ConditionalConfigurationSource)
+	if !ok {
+		return errors.New("configuration source does not support conditional writes")
+	}
+
+	if _, err := ParseConfig(input); err != nil {
+		return err
+	}
+
+	if err := source.WriteIfUpToDate(ctx, input, lastID); err != nil {
+		return err
+	}
+
+	s.waitForWrite()
+	return nil
+}
+
+// waitForWrite waits for a change to the configuration file to be detected.
+// Otherwise we would return to the caller earlier than server.Raw() would
+// return the new configuration.
+func (s *Server) waitForWrite() {
 	doneReading := make(chan struct{}, 1)
 	s.fileWrite <- doneReading
 	<-doneReading
-
-	return nil
 }
 
 // Edits describes some JSON edits to apply to site configuration.
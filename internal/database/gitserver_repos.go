@@ -44,7 +44,7 @@ func (s *GitserverRepoStore) Transact(ctx context.Context) (*GitserverRepoStore,
 func (s *GitserverRepoStore) Upsert(ctx context.Context, repos ...*types.GitserverRepo) error {
 	values := make([]*sqlf.Query, 0, len(repos))
 	for _, gr := range repos {
-		q := sqlf.Sprintf("(%s, %s, %s, %s, %s, %s, %s, now())",
+		q := sqlf.Sprintf("(%s, %s, %s, %s, %s, %s, %s, %s, now())",
 			gr.RepoID,
 			gr.CloneStatus,
 			dbutil.NewNullString(gr.ShardID),
@@ -52,6 +52,7 @@ func (s *GitserverRepoStore) Upsert(ctx context.Context, repos ...*types.Gitserv
 			dbutil.NewNullString(sanitizeToUTF8(gr.LastError)),
 			gr.LastFetched,
 			gr.LastChanged,
+			dbutil.NewNullInt64(gr.RepoSizeBytes),
 		)
 
 		values = append(values, q)
@@ -60,11 +61,11 @@ func (s *GitserverRepoStore) Upsert(ctx context.Context, repos ...*types.Gitserv
 	err := s.Exec(ctx, sqlf.Sprintf(`
 -- source: internal/database/gitserver_repos.go:GitserverRepoStore.Upsert
 INSERT INTO
-    gitserver_repos(repo_id, clone_status, shard_id, last_external_service, last_error, last_fetched, last_changed, updated_at)
+    gitserver_repos(repo_id, clone_status, shard_id, last_external_service, last_error, last_fetched, last_changed, repo_size_bytes, updated_at)
     VALUES %s
     ON CONFLICT (repo_id) DO UPDATE
-    SET (clone_status, shard_id, last_external_service, last_error, last_fetched, last_changed, updated_at) =
-        (EXCLUDED.clone_status, EXCLUDED.shard_id, EXCLUDED.last_external_service, EXCLUDED.last_error, EXCLUDED.last_fetched, EXCLUDED.last_changed, now())
+    SET (clone_status, shard_id, last_external_service, last_error, last_fetched, last_changed, repo_size_bytes, updated_at) =
+        (EXCLUDED.clone_status, EXCLUDED.shard_id, EXCLUDED.last_external_service, EXCLUDED.last_error, EXCLUDED.last_fetched, EXCLUDED.last_changed, EXCLUDED.repo_size_bytes, now())
 `, sqlf.Join(values, ",")))
 
 	return errors.Wrap(err, "creating GitserverRepo")
@@ -111,6 +112,7 @@ func (s *GitserverRepoStore) IterateRepoGitserverStatus(ctx context.Context, opt
 			&dbutil.NullString{S: &gr.LastError},
 			&dbutil.NullTime{Time: &gr.LastFetched},
 			&dbutil.NullTime{Time: &gr.LastChanged},
+			&dbutil.NullInt64{N: &gr.RepoSizeBytes},
 			&dbutil.NullTime{Time: &gr.UpdatedAt},
 		); err != nil {
 			return errors.Wrap(err, "scanning row")
@@ -149,6 +151,7 @@ SELECT
 	gr.last_error,
 	gr.last_fetched,
 	gr.last_changed,
+	gr.repo_size_bytes,
 	gr.updated_at
 FROM repo
 LEFT JOIN gitserver_repos gr ON gr.repo_id = repo.id
@@ -167,6 +170,7 @@ const iterateRepoGitserverStatusWithoutShardQuery = `
 		NULL AS last_error,
 		NULL AS last_fetched,
 		NULL AS last_changed,
+		NULL AS repo_size_bytes,
 		NULL AS updated_at
 	FROM repo
 	WHERE repo.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM gitserver_repos gr WHERE gr.repo_id = repo.id)
@@ -180,6 +184,7 @@ const iterateRepoGitserverStatusWithoutShardQuery = `
 		gr.last_error,
 		gr.last_fetched,
 		gr.last_changed,
+		gr.repo_size_bytes,
 		gr.updated_at
 	FROM repo
 	JOIN gitserver_repos gr ON gr.repo_id = repo.id
@@ -198,6 +203,7 @@ SELECT
        last_error,
        last_fetched,
        last_changed,
+       repo_size_bytes,
        updated_at
 FROM gitserver_repos
 WHERE repo_id = %s
@@ -217,6 +223,7 @@ WHERE repo_id = %s
 		&dbutil.NullString{S: &gr.LastError},
 		&dbutil.NullTime{Time: &gr.LastFetched},
 		&dbutil.NullTime{Time: &gr.LastChanged},
+		&dbutil.NullInt64{N: &gr.RepoSizeBytes},
 		&gr.UpdatedAt,
 	)
 	if err != nil {
@@ -229,7 +236,8 @@ WHERE repo_id = %s
 
 // SetCloneStatus will attempt to update ONLY the clone status of a
 // GitServerRepo. If a matching row does not yet exist a new one will be created.
-// If the status value hasn't changed, the row will not be updated.
+// If the status value hasn't changed, the row will not be updated. Marking a
+// repository as not cloned also clears its recorded size.
 func (s *GitserverRepoStore) SetCloneStatus(ctx context.Context, name api.RepoName, status types.CloneStatus, shardID string) error {
 	err := s.Exec(ctx, sqlf.Sprintf(`
 -- source: internal/database/gitserver_repos.go:GitserverRepoStore.SetCloneStatus
@@ -238,10 +246,10 @@ SELECT id, %s, %s, now()
 FROM repo
 WHERE name = %s
 ON CONFLICT (repo_id) DO UPDATE
-SET (clone_status, shard_id, updated_at) =
-    (EXCLUDED.clone_status, EXCLUDED.shard_id, now())
+SET (clone_status, shard_id, repo_size_bytes, updated_at) =
+    (EXCLUDED.clone_status, EXCLUDED.shard_id, CASE WHEN EXCLUDED.clone_status = %s THEN NULL ELSE gitserver_repos.repo_size_bytes END, now())
     WHERE gitserver_repos.clone_status IS DISTINCT FROM EXCLUDED.clone_status
-`, status, shardID, name))
+`, status, shardID, name, types.CloneStatusNotCloned))
 
 	return errors.Wrap(err, "setting clone status")
 }
@@ -294,6 +302,81 @@ SET (last_fetched, last_changed, shard_id, updated_at) =
 	return errors.Wrap(err, "setting last fetched")
 }
 
+// SetRepoSize will attempt to update ONLY the repo size of a GitServerRepo. If
+// a matching row does not yet exist a new one will be created.
+// If the size value hasn't changed, the row will not be updated.
+func (s *GitserverRepoStore) SetRepoSize(ctx context.Context, name api.RepoName, size int64, shardID string) error {
+	err := s.Exec(ctx, sqlf.Sprintf(`
+-- source: internal/database/gitserver_repos.go:GitserverRepoStore.SetRepoSize
+INSERT INTO gitserver_repos(repo_id, repo_size_bytes, shard_id, updated_at)
+SELECT id, %s, %s, now()
+FROM repo
+WHERE name = %s
+ON CONFLICT (repo_id) DO UPDATE
+SET (repo_size_bytes, shard_id, updated_at) =
+    (EXCLUDED.repo_size_bytes, EXCLUDED.shard_id, now())
+    WHERE gitserver_repos.repo_size_bytes IS DISTINCT FROM EXCLUDED.repo_size_bytes
+`, size, shardID, name))
+
+	return errors.Wrap(err, "setting repo size")
+}
+
+// ExternalServiceDiskUsage returns the total on-disk size in bytes of the
+// cloned repositories of each of the given external services. A repository
+// synced by several external services counts towards each of them.
+func (s *GitserverRepoStore) ExternalServiceDiskUsage(ctx context.Context, ids ...int64) (map[int64]int64, error) {
+	usage := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return usage, nil
+	}
+	conds := make([]*sqlf.Query, 0, len(ids))
+	for _, id := range ids {
+		conds = append(conds, sqlf.Sprintf("%s", id))
+	}
+	return usage, s.scanExternalServiceDiskUsage(ctx, usage, sqlf.Sprintf("esr.external_service_id IN (%s)", sqlf.Join(conds, ",")))
+}
+
+// ExternalServiceDiskUsageForRepo returns the total on-disk size in bytes of
+// the cloned repositories of each external service which syncs the named
+// repository.
+func (s *GitserverRepoStore) ExternalServiceDiskUsageForRepo(ctx context.Context, name api.RepoName) (map[int64]int64, error) {
+	usage := map[int64]int64{}
+	return usage, s.scanExternalServiceDiskUsage(ctx, usage, sqlf.Sprintf(`esr.external_service_id IN (
+	SELECT external_service_id FROM external_service_repos
+	JOIN repo ON repo.id = external_service_repos.repo_id
+	WHERE repo.name = %s
+)`, name))
+}
+
+func (s *GitserverRepoStore) scanExternalServiceDiskUsage(ctx context.Context, usage map[int64]int64, cond *sqlf.Query) error {
+	rows, err := s.Query(ctx, sqlf.Sprintf(externalServiceDiskUsageQuery, cond))
+	if err != nil {
+		return errors.Wrap(err, "fetching external service disk usage")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, bytes int64
+		if err := rows.Scan(&id, &bytes); err != nil {
+			return errors.Wrap(err, "scanning row")
+		}
+		usage[id] = bytes
+	}
+	return errors.Wrap(rows.Err(), "iterating rows")
+}
+
+const externalServiceDiskUsageQuery = `
+-- source: internal/database/gitserver_repos.go:GitserverRepoStore.scanExternalServiceDiskUsage
+SELECT
+	esr.external_service_id,
+	COALESCE(SUM(gr.repo_size_bytes), 0)
+FROM external_service_repos esr
+JOIN repo ON repo.id = esr.repo_id
+LEFT JOIN gitserver_repos gr ON gr.repo_id = esr.repo_id AND gr.clone_status = 'cloned'
+WHERE repo.deleted_at IS NULL AND %s
+GROUP BY esr.external_service_id
+`
+
 // sanitizeToUTF8 will remove any null character terminated string. The null character can be
 // represented in one of the following ways in Go:
 //
@@ -6,10 +6,14 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/keegancsmith/sqlf"
 
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
@@ -342,6 +346,147 @@ func TestSetLastError(t *testing.T) {
 	}
 }
 
+func TestSetRepoSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t, "")
+	ctx := context.Background()
+	const shardID = "test"
+
+	repo1 := &types.Repo{
+		Name:         "github.com/sourcegraph/repo1",
+		URI:          "github.com/sourcegraph/repo1",
+		ExternalRepo: api.ExternalRepoSpec{},
+	}
+	if err := Repos(db).Create(ctx, repo1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting the size creates the row.
+	if err := GitserverRepos(db).SetRepoSize(ctx, repo1.Name, 1024, shardID); err != nil {
+		t.Fatal(err)
+	}
+	fromDB, err := GitserverRepos(db).GetByID(ctx, repo1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &types.GitserverRepo{
+		RepoID:        repo1.ID,
+		ShardID:       shardID,
+		CloneStatus:   types.CloneStatusNotCloned,
+		RepoSizeBytes: 1024,
+	}
+	if diff := cmp.Diff(want, fromDB, cmpopts.IgnoreFields(types.GitserverRepo{}, "LastFetched", "LastChanged", "UpdatedAt")); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Setting the same size doesn't update the row.
+	if err := GitserverRepos(db).SetRepoSize(ctx, repo1.Name, 1024, shardID); err != nil {
+		t.Fatal(err)
+	}
+	after, err := GitserverRepos(db).GetByID(ctx, repo1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fromDB, after); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Removing the repository from disk clears its size.
+	if err := GitserverRepos(db).SetCloneStatus(ctx, repo1.Name, types.CloneStatusCloned, shardID); err != nil {
+		t.Fatal(err)
+	}
+	if err := GitserverRepos(db).SetCloneStatus(ctx, repo1.Name, types.CloneStatusNotCloned, shardID); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := GitserverRepos(db).GetByID(ctx, repo1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed.RepoSizeBytes != 0 {
+		t.Fatalf("unexpected size of removed repo. want=%d have=%d", 0, removed.RepoSizeBytes)
+	}
+}
+
+func TestExternalServiceDiskUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t, "")
+	ctx := actor.WithInternalActor(context.Background())
+
+	confGet := func() *conf.Unified {
+		return &conf.Unified{}
+	}
+	var svcs []*types.ExternalService
+	for _, name := range []string{"GITHUB #1", "GITHUB #2"} {
+		es := &types.ExternalService{
+			Kind:        extsvc.KindGitHub,
+			DisplayName: name,
+			Config:      `{"url": "https://github.com", "repositoryQuery": ["none"], "token": "abc"}`,
+		}
+		if err := ExternalServices(db).Create(ctx, confGet, es); err != nil {
+			t.Fatal(err)
+		}
+		svcs = append(svcs, es)
+	}
+
+	// repo1 is synced by both external services, repo2 only by the first and
+	// repo3 isn't cloned.
+	repos := []struct {
+		name   api.RepoName
+		svcs   []*types.ExternalService
+		size   int64
+		status types.CloneStatus
+	}{
+		{"github.com/sourcegraph/repo1", svcs, 100, types.CloneStatusCloned},
+		{"github.com/sourcegraph/repo2", svcs[:1], 10, types.CloneStatusCloned},
+		{"github.com/sourcegraph/repo3", svcs[1:], 1, types.CloneStatusNotCloned},
+	}
+	for _, r := range repos {
+		repo := &types.Repo{Name: r.name, URI: string(r.name)}
+		if err := Repos(db).Create(ctx, repo); err != nil {
+			t.Fatal(err)
+		}
+		for _, es := range r.svcs {
+			q := sqlf.Sprintf(`
+INSERT INTO external_service_repos (external_service_id, repo_id, clone_url)
+VALUES (%d, %d, '')
+`, es.ID, repo.ID)
+			if _, err := db.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := GitserverRepos(db).Upsert(ctx, &types.GitserverRepo{
+			RepoID:        repo.ID,
+			ShardID:       "test",
+			CloneStatus:   r.status,
+			RepoSizeBytes: r.size,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage, err := GitserverRepos(db).ExternalServiceDiskUsage(ctx, svcs[0].ID, svcs[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[int64]int64{svcs[0].ID: 110, svcs[1].ID: 100}, usage); diff != "" {
+		t.Fatal(diff)
+	}
+
+	usage, err = GitserverRepos(db).ExternalServiceDiskUsageForRepo(ctx, "github.com/sourcegraph/repo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[int64]int64{svcs[1].ID: 100}, usage); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
 func TestGitserverRepoUpsertNullShard(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
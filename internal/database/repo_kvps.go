@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jackc/pgconn"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+var (
+	ErrRepoKVPNotFound = errors.New("repository key-value pair not found")
+	ErrRepoKVPExists   = errors.New("repository key-value pair with this key already exists")
+)
+
+func RepoKVPs(db dbutil.DB) *RepoKVPStore {
+	store := basestore.NewWithDB(db, sql.TxOptions{})
+	return &RepoKVPStore{store}
+}
+
+// RepoKVPStore stores the key-value pairs of repository metadata defined by
+// site admins.
+type RepoKVPStore struct {
+	*basestore.Store
+}
+
+// KeyValuePair is a single pair of repository metadata. A nil Value denotes a
+// pair that only acts as a tag.
+type KeyValuePair struct {
+	Key   string
+	Value *string
+}
+
+// Create adds a new key-value pair to the repository. It returns
+// ErrRepoKVPExists if the repository already has a pair with the same key.
+func (s *RepoKVPStore) Create(ctx context.Context, repoID api.RepoID, kvp KeyValuePair) error {
+	q := sqlf.Sprintf(`
+-- source: internal/database/repo_kvps.go:Create
+INSERT INTO repo_kvps (repo_id, key, value)
+VALUES (%s, %s, %s)
+`, repoID, kvp.Key, kvp.Value)
+	if err := s.Exec(ctx, q); err != nil {
+		var e *pgconn.PgError
+		if errors.As(err, &e) && e.ConstraintName == "repo_kvps_pkey" {
+			return ErrRepoKVPExists
+		}
+		return err
+	}
+	return nil
+}
+
+// Get returns the key-value pair of the repository with the given key. It
+// returns ErrRepoKVPNotFound if no such pair exists.
+func (s *RepoKVPStore) Get(ctx context.Context, repoID api.RepoID, key string) (KeyValuePair, error) {
+	q := sqlf.Sprintf(`
+-- source: internal/database/repo_kvps.go:Get
+SELECT key, value FROM repo_kvps WHERE repo_id = %s AND key = %s
+`, repoID, key)
+	kvps, err := scanKVPs(s.Query(ctx, q))
+	if err != nil {
+		return KeyValuePair{}, err
+	}
+	if len(kvps) == 0 {
+		return KeyValuePair{}, ErrRepoKVPNotFound
+	}
+	return kvps[0], nil
+}
+
+// List returns all key-value pairs of the repository, ordered by key.
+func (s *RepoKVPStore) List(ctx context.Context, repoID api.RepoID) ([]KeyValuePair, error) {
+	if Mocks.RepoKVPs.List != nil {
+		return Mocks.RepoKVPs.List(ctx, repoID)
+	}
+
+	q := sqlf.Sprintf(`
+-- source: internal/database/repo_kvps.go:List
+SELECT key, value FROM repo_kvps WHERE repo_id = %s ORDER BY key
+`, repoID)
+	return scanKVPs(s.Query(ctx, q))
+}
+
+// Update sets the value of the key-value pair of the repository with the given
+// key. It returns ErrRepoKVPNotFound if no such pair exists.
+func (s *RepoKVPStore) Update(ctx context.Context, repoID api.RepoID, kvp KeyValuePair) error {
+	q := sqlf.Sprintf(`
+-- source: internal/database/repo_kvps.go:Update
+UPDATE repo_kvps SET value = %s WHERE repo_id = %s AND key = %s
+`, kvp.Value, repoID, kvp.Key)
+	res, err := s.ExecResult(ctx, q)
+	if err != nil {
+		return err
+	}
+	return checkRepoKVPAffected(res)
+}
+
+// Delete removes the key-value pair of the repository with the given key. It
+// returns ErrRepoKVPNotFound if no such pair exists.
+func (s *RepoKVPStore) Delete(ctx context.Context, repoID api.RepoID, key string) error {
+	q := sqlf.Sprintf(`
+-- source: internal/database/repo_kvps.go:Delete
+DELETE FROM repo_kvps WHERE repo_id = %s AND key = %s
+`, repoID, key)
+	res, err := s.ExecResult(ctx, q)
+	if err != nil {
+		return err
+	}
+	return checkRepoKVPAffected(res)
+}
+
+func checkRepoKVPAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRepoKVPNotFound
+	}
+	return nil
+}
+
+func scanKVPs(rows *sql.Rows, queryErr error) (_ []KeyValuePair, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var kvps []KeyValuePair
+	for rows.Next() {
+		var kvp KeyValuePair
+		if err := rows.Scan(&kvp.Key, &kvp.Value); err != nil {
+			return nil, err
+		}
+		kvps = append(kvps, kvp)
+	}
+	return kvps, nil
+}
@@ -0,0 +1,11 @@
+package database
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+type MockRepoKVPs struct {
+	List func(ctx context.Context, repoID api.RepoID) ([]KeyValuePair, error)
+}
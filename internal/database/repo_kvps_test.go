@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestRepoKVPs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Parallel()
+	db := dbtest.NewDB(t, "")
+	ctx := actor.WithInternalActor(context.Background())
+	store := RepoKVPs(db)
+
+	repo := mustCreate(ctx, t, db, types.MakeGithubRepo())[0]
+
+	teamX, teamY := "team-x", "team-y"
+	if err := store.Create(ctx, repo.ID, KeyValuePair{Key: "owner", Value: &teamX}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(ctx, repo.ID, KeyValuePair{Key: "deprecated"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(ctx, repo.ID, KeyValuePair{Key: "owner", Value: &teamY}); err != ErrRepoKVPExists {
+		t.Fatalf("unexpected error creating duplicate key. want=%q have=%v", ErrRepoKVPExists, err)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		kvp, err := store.Get(ctx, repo.ID, "owner")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(KeyValuePair{Key: "owner", Value: &teamX}, kvp); diff != "" {
+			t.Fatalf("unexpected key-value pair (-want +got):\n%s", diff)
+		}
+
+		if _, err := store.Get(ctx, repo.ID, "missing"); err != ErrRepoKVPNotFound {
+			t.Fatalf("expected ErrRepoKVPNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		if err := store.Update(ctx, repo.ID, KeyValuePair{Key: "owner", Value: &teamY}); err != nil {
+			t.Fatal(err)
+		}
+		kvp, err := store.Get(ctx, repo.ID, "owner")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(KeyValuePair{Key: "owner", Value: &teamY}, kvp); diff != "" {
+			t.Fatalf("unexpected key-value pair (-want +got):\n%s", diff)
+		}
+
+		if err := store.Update(ctx, repo.ID, KeyValuePair{Key: "missing"}); err != ErrRepoKVPNotFound {
+			t.Fatalf("expected ErrRepoKVPNotFound, got %v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		kvps, err := store.List(ctx, repo.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []KeyValuePair{{Key: "deprecated"}, {Key: "owner", Value: &teamY}}
+		if diff := cmp.Diff(want, kvps); diff != "" {
+			t.Fatalf("unexpected key-value pairs (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := store.Delete(ctx, repo.ID, "deprecated"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Delete(ctx, repo.ID, "deprecated"); err != ErrRepoKVPNotFound {
+			t.Fatalf("expected ErrRepoKVPNotFound, got %v", err)
+		}
+	})
+}
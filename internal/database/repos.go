@@ -38,6 +38,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/jvmpackages"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/perforce"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/phabricator"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
@@ -473,6 +474,8 @@ func scanRepo(rows *sql.Rows, r *types.Repo) (err error) {
 		r.Metadata = new(perforce.Depot)
 	case extsvc.TypePhabricator:
 		r.Metadata = new(phabricator.Repo)
+	case extsvc.TypeSubversion:
+		r.Metadata = new(subversion.Repository)
 	case extsvc.TypeOther:
 		r.Metadata = new(extsvc.OtherRepoMetadata)
 	case extsvc.TypeJVMPackages:
@@ -580,6 +583,14 @@ type ReposListOptions struct {
 	// OnlyPrivate excludes non-private repositories from the list.
 	OnlyPrivate bool
 
+	// Topics, if non empty, will only return repos labeled with all of the
+	// topics on their code host. Topics are compared case-insensitively.
+	Topics []string
+
+	// KVPFilters, if non empty, will only return repos with key-value pairs
+	// matching all of the filters.
+	KVPFilters []RepoKVPFilter
+
 	// Index when set will only include repositories which should be indexed
 	// if true. If false it will exclude repositories which should be
 	// indexed. An example use case of this is for indexed search only
@@ -634,6 +645,13 @@ type ReposListOptions struct {
 	*LimitOffset
 }
 
+// RepoKVPFilter matches repositories with a key-value pair of the given key.
+// If Value is non-nil, the value of the pair must be equal to it as well.
+type RepoKVPFilter struct {
+	Key   string
+	Value *string
+}
+
 type RepoListOrderBy []RepoListSort
 
 func (r RepoListOrderBy) SQL() *sqlf.Query {
@@ -809,6 +827,16 @@ func (s *RepoStore) list(ctx context.Context, tr *trace.Trace, opt ReposListOpti
 	return rows.Err()
 }
 
+// repoHasTopicCondFmtStr matches repositories with the topic in their code
+// host metadata. GitHub and GitLab store the topics of a repository in the
+// same "topics" key of the metadata.
+const repoHasTopicCondFmtStr = `EXISTS (
+	SELECT 1 FROM jsonb_array_elements_text(
+		CASE WHEN jsonb_typeof(repo.metadata->'topics') = 'array' THEN repo.metadata->'topics' ELSE '[]'::jsonb END
+	) AS t(topic)
+	WHERE lower(t.topic) = lower(%s)
+)`
+
 func (s *RepoStore) listSQL(ctx context.Context, opt ReposListOptions) (*sqlf.Query, error) {
 	var ctes, from, where []*sqlf.Query
 
@@ -928,6 +956,16 @@ func (s *RepoStore) listSQL(ctx context.Context, opt ReposListOptions) (*sqlf.Qu
 	if opt.OnlyPrivate {
 		where = append(where, sqlf.Sprintf("private"))
 	}
+	for _, topic := range opt.Topics {
+		where = append(where, sqlf.Sprintf(repoHasTopicCondFmtStr, topic))
+	}
+	for _, kvp := range opt.KVPFilters {
+		if kvp.Value == nil {
+			where = append(where, sqlf.Sprintf("EXISTS (SELECT 1 FROM repo_kvps WHERE repo_id = repo.id AND key = %s)", kvp.Key))
+		} else {
+			where = append(where, sqlf.Sprintf("EXISTS (SELECT 1 FROM repo_kvps WHERE repo_id = repo.id AND key = %s AND value = %s)", kvp.Key, *kvp.Value))
+		}
+	}
 
 	if len(opt.Names) > 0 {
 		lowerNames := make([]string, len(opt.Names))
@@ -21,6 +21,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database/query"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
@@ -753,6 +754,63 @@ func TestRepos_List_ids(t *testing.T) {
 	}
 }
 
+func TestRepos_List_topicsAndKVPs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Parallel()
+	db := dbtest.NewDB(t, "")
+	ctx := actor.WithInternalActor(context.Background())
+
+	payments := types.MakeGithubRepo()
+	payments.Metadata = &github.Repository{Topics: []string{"Payments", "go"}}
+	payments = mustCreate(ctx, t, db, payments)[0]
+
+	billing := types.MakeGitlabRepo()
+	billing.Metadata = &gitlab.Project{Topics: []string{"payments"}}
+	billing = mustCreate(ctx, t, db, billing)[0]
+
+	other := mustCreate(ctx, t, db, types.MakeGitoliteRepo())[0]
+
+	teamX, teamY := "team-x", "team-y"
+	for _, kvp := range []struct {
+		repo *types.Repo
+		kvp  KeyValuePair
+	}{
+		{payments, KeyValuePair{Key: "owner", Value: &teamX}},
+		{billing, KeyValuePair{Key: "owner", Value: &teamY}},
+		{other, KeyValuePair{Key: "deprecated"}},
+	} {
+		if err := RepoKVPs(db).Create(ctx, kvp.repo.ID, kvp.kvp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opt  ReposListOptions
+		want []*types.Repo
+	}{
+		{"Topic", ReposListOptions{Topics: []string{"payments"}}, []*types.Repo{payments, billing}},
+		{"Topics", ReposListOptions{Topics: []string{"payments", "GO"}}, []*types.Repo{payments}},
+		{"Key and value", ReposListOptions{KVPFilters: []RepoKVPFilter{{Key: "owner", Value: &teamX}}}, []*types.Repo{payments}},
+		{"Key", ReposListOptions{KVPFilters: []RepoKVPFilter{{Key: "owner"}}}, []*types.Repo{payments, billing}},
+		{"Key without value", ReposListOptions{KVPFilters: []RepoKVPFilter{{Key: "deprecated"}}}, []*types.Repo{other}},
+		{"Topic and key", ReposListOptions{Topics: []string{"payments"}, KVPFilters: []RepoKVPFilter{{Key: "owner", Value: &teamY}}}, []*types.Repo{billing}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repos, err := Repos(db).List(ctx, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, test.want, repos)
+		})
+	}
+}
+
 func TestRepos_List_serviceTypes(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -65,7 +65,7 @@ func searchContextsPermissionsCondition(ctx context.Context, db dbutil.DB) (*sql
 }
 
 const listSearchContextsFmtStr = `
-SELECT sc.id, sc.name, sc.description, sc.public, sc.namespace_user_id, sc.namespace_org_id, sc.updated_at, u.username, o.name
+SELECT sc.id, sc.name, sc.description, sc.public, sc.namespace_user_id, sc.namespace_org_id, sc.updated_at, sc.query, u.username, o.name
 FROM search_contexts sc
 LEFT JOIN users u on sc.namespace_user_id = u.id
 LEFT JOIN orgs o on sc.namespace_org_id = o.id
@@ -303,8 +303,8 @@ func (s *SearchContextsStore) DeleteSearchContext(ctx context.Context, searchCon
 
 const insertSearchContextFmtStr = `
 INSERT INTO search_contexts
-(name, description, public, namespace_user_id, namespace_org_id)
-VALUES (%s, %s, %s, %s, %s)
+(name, description, public, namespace_user_id, namespace_org_id, query)
+VALUES (%s, %s, %s, %s, %s, %s)
 `
 
 // 🚨 SECURITY: The caller must ensure that the actor is a site admin or has permission to create the search context.
@@ -333,6 +333,7 @@ SET
 	name = %s,
 	description = %s,
 	public = %s,
+	query = %s,
 	updated_at = now()
 WHERE id = %d AND deleted_at IS NULL
 `
@@ -358,10 +359,6 @@ func (s *SearchContextsStore) UpdateSearchContextWithRepositoryRevisions(ctx con
 }
 
 func (s *SearchContextsStore) SetSearchContextRepositoryRevisions(ctx context.Context, searchContextID int64, repositoryRevisions []*types.SearchContextRepositoryRevisions) (err error) {
-	if len(repositoryRevisions) == 0 {
-		return nil
-	}
-
 	tx, err := s.Transact(ctx)
 	if err != nil {
 		return err
@@ -373,6 +370,11 @@ func (s *SearchContextsStore) SetSearchContextRepositoryRevisions(ctx context.Co
 		return err
 	}
 
+	// An empty list clears the repositories, e.g. when a search context becomes query-defined.
+	if len(repositoryRevisions) == 0 {
+		return nil
+	}
+
 	values := []*sqlf.Query{}
 	for _, repoRev := range repositoryRevisions {
 		for _, revision := range repoRev.Revisions {
@@ -397,6 +399,7 @@ func (s *SearchContextsStore) createSearchContext(ctx context.Context, searchCon
 		searchContext.Public,
 		nullInt32Column(searchContext.NamespaceUserID),
 		nullInt32Column(searchContext.NamespaceOrgID),
+		nullStringColumn(searchContext.Query),
 	))
 	if err != nil {
 		return nil, err
@@ -414,6 +417,7 @@ func (s *SearchContextsStore) updateSearchContext(ctx context.Context, searchCon
 		searchContext.Name,
 		searchContext.Description,
 		searchContext.Public,
+		nullStringColumn(searchContext.Query),
 		searchContext.ID,
 	))
 	if err != nil {
@@ -449,6 +453,7 @@ func scanSearchContexts(rows *sql.Rows) ([]*types.SearchContext, error) {
 			&dbutil.NullInt32{N: &sc.NamespaceUserID},
 			&dbutil.NullInt32{N: &sc.NamespaceOrgID},
 			&sc.UpdatedAt,
+			&dbutil.NullString{S: &sc.Query},
 			&dbutil.NullString{S: &sc.NamespaceUserName},
 			&dbutil.NullString{S: &sc.NamespaceOrgName},
 		)
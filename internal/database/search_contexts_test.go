@@ -133,6 +133,10 @@ func TestSearchContexts_Update(t *testing.T) {
 			name:    "update name",
 			updated: set(orgSC, func(sc *types.SearchContext) { sc.Name = "testname" }),
 		},
+		{
+			name:    "update query",
+			updated: set(instanceSC, func(sc *types.SearchContext) { sc.Query = "repo:^github\\.com/ourorg/ archived:no" }),
+		},
 	}
 
 	for _, tt := range tests {
@@ -139,6 +139,24 @@ func (s *Store) OpenWithPath(ctx context.Context, key string, fetcher FetcherWit
 	}
 }
 
+// Lookup opens the file of key if it is in the cache. Unlike Open it never
+// fetches missing items, and returns an error satisfying os.IsNotExist for
+// them instead.
+func (s *Store) Lookup(key string) (*File, error) {
+	if s.Dir == "" {
+		return nil, errors.New("diskcache.Store.Dir must be set")
+	}
+
+	path := s.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// Update modified time, as Open does for items found in the cache.
+	touch(path)
+	return &File{File: f, Path: path}, nil
+}
+
 // path returns the path for key.
 func (s *Store) path(key string) string {
 	// path uses a sha256 hash of the key since we want to use it for the
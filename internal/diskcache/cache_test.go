@@ -60,3 +60,35 @@ func TestOpen(t *testing.T) {
 		t.Fatal("Item was not properly evicted")
 	}
 }
+
+func TestLookup(t *testing.T) {
+	store := &Store{
+		Dir:       t.TempDir(),
+		Component: "test",
+	}
+
+	if _, err := store.Lookup("key"); !os.IsNotExist(err) {
+		t.Fatalf("expected missing item not to be found, got %v", err)
+	}
+
+	f, err := store.Open(context.Background(), "key", func(ctx context.Context) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("foobar"))), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	f, err = store.Lookup("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := io.ReadAll(f.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "foobar" {
+		t.Fatalf("got %q, want %q", got, "foobar")
+	}
+}
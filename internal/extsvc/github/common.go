@@ -1660,6 +1660,40 @@ type Repository struct {
 	// Metadata retained for ranking
 	StargazerCount int `json:",omitempty"`
 	ForkCount      int `json:",omitempty"`
+
+	// Topics are the topics the repository is labeled with on the code host.
+	// Stored under the same key as the topics of GitLab projects.
+	Topics []string `json:"topics,omitempty"`
+}
+
+// UnmarshalJSON additionally decodes the topics of a repository returned by
+// the GraphQL API, which are nested in repositoryTopics.
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	type repository Repository
+	var v struct {
+		repository
+		RepositoryTopics *struct {
+			Nodes []struct {
+				Topic struct {
+					Name string
+				}
+			}
+		} `json:"repositoryTopics"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*r = Repository(v.repository)
+	if v.RepositoryTopics != nil {
+		// Topics stay nil for repositories without topics, to match the
+		// repository decoded from our database.
+		r.Topics = nil
+		for _, n := range v.RepositoryTopics.Nodes {
+			r.Topics = append(r.Topics, n.Topic.Name)
+		}
+	}
+	return nil
 }
 
 func ownerNameCacheKey(owner, name string) string       { return "0:" + owner + "/" + name }
@@ -1731,6 +1765,7 @@ type restRepository struct {
 	Permissions restRepositoryPermissions `json:"permissions"`
 	Stars       int                       `json:"stargazers_count"`
 	Forks       int                       `json:"forks_count"`
+	Topics      []string                  `json:"topics"`
 }
 
 // getRepositoryFromAPI attempts to fetch a repository from the GitHub API without use of the redis cache.
@@ -1766,7 +1801,17 @@ func convertRestRepo(restRepo restRepository) *Repository {
 		ViewerPermission: convertRestRepoPermissions(restRepo.Permissions),
 		StargazerCount:   restRepo.Stars,
 		ForkCount:        restRepo.Forks,
+		Topics:           nonEmptyTopics(restRepo.Topics),
+	}
+}
+
+// nonEmptyTopics returns nil for an empty list of topics, so that repositories
+// without topics compare equal to those decoded from our database.
+func nonEmptyTopics(topics []string) []string {
+	if len(topics) == 0 {
+		return nil
 	}
+	return topics
 }
 
 // convertRestRepoPermissions converts repo information returned by the rest API
@@ -0,0 +1,87 @@
+package subversion
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os/exec"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Repository contains information of a Subversion repository.
+type Repository struct {
+	// Path is the path of the repository relative to the Subversion root.
+	Path string `json:"path"`
+
+	// URL is the URL of the repository, without credentials.
+	URL string `json:"url"`
+}
+
+// Client is a client for listing the repositories under a Subversion root.
+//
+// IMPORTANT: the client invokes the `svn` binary, so it must be run from a
+// service which has Subversion installed.
+type Client struct {
+	URL      string
+	Username string
+	Password string
+}
+
+func NewClient(url, username, password string) *Client {
+	return &Client{URL: url, Username: username, Password: password}
+}
+
+// ListRepos returns a repository for every directory directly under the
+// Subversion root.
+func (c *Client) ListRepos(ctx context.Context) ([]*Repository, error) {
+	args := []string{"list", "--xml", "--non-interactive", "--no-auth-cache"}
+	if c.Username != "" {
+		args = append(args, "--username", c.Username, "--password-from-stdin")
+	}
+	args = append(args, c.URL)
+
+	cmd := exec.CommandContext(ctx, "svn", args...)
+	cmd.Stdin = strings.NewReader(c.Password)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "svn list failed with output %q", stderr.String())
+	}
+	return decodeRepos(c.URL, out)
+}
+
+func decodeRepos(root string, svnList []byte) ([]*Repository, error) {
+	var lists struct {
+		List []struct {
+			Entries []struct {
+				Kind string `xml:"kind,attr"`
+				Name string `xml:"name"`
+			} `xml:"entry"`
+		} `xml:"list"`
+	}
+	if err := xml.Unmarshal(svnList, &lists); err != nil {
+		return nil, errors.Wrap(err, "decoding svn list output")
+	}
+
+	var repos []*Repository
+	for _, l := range lists.List {
+		for _, e := range l.Entries {
+			if e.Kind != "dir" {
+				continue
+			}
+			repos = append(repos, &Repository{
+				Path: e.Name,
+				URL:  JoinURL(root, e.Name),
+			})
+		}
+	}
+	return repos, nil
+}
+
+// JoinURL returns the URL of the repository at path under the Subversion root.
+func JoinURL(root, path string) string {
+	return strings.TrimSuffix(root, "/") + "/" + strings.Trim(path, "/")
+}
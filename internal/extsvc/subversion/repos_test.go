@@ -0,0 +1,95 @@
+package subversion
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeRepos(t *testing.T) {
+	svnList := `<?xml version="1.0" encoding="UTF-8"?>
+<lists>
+<list
+   path="https://svn.example.com/repos">
+<entry
+   kind="dir">
+<name>boards</name>
+<commit
+   revision="12">
+<author>jdoe</author>
+<date>2021-10-11T10:00:00.000000Z</date>
+</commit>
+</entry>
+<entry
+   kind="file">
+<name>README</name>
+<size>10</size>
+<commit
+   revision="1">
+<author>jdoe</author>
+<date>2021-10-11T10:00:00.000000Z</date>
+</commit>
+</entry>
+<entry
+   kind="dir">
+<name>firmware</name>
+<commit
+   revision="11">
+<author>jdoe</author>
+<date>2021-10-11T10:00:00.000000Z</date>
+</commit>
+</entry>
+</list>
+</lists>
+`
+
+	have, err := decodeRepos("https://svn.example.com/repos/", []byte(svnList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Repository{
+		{Path: "boards", URL: "https://svn.example.com/repos/boards"},
+		{Path: "firmware", URL: "https://svn.example.com/repos/firmware"},
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatalf("mismatch (-want +have):\n%s", diff)
+	}
+}
+
+func TestClient_ListRepos(t *testing.T) {
+	if _, err := exec.LookPath("svnadmin"); err != nil {
+		t.Skip("svnadmin not found")
+	}
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	run(t, dir, "svnadmin", "create", root)
+	rootURL := "file://" + root
+	run(t, dir, "svn", "mkdir", "--parents", "-m", "init", rootURL+"/boards/trunk", rootURL+"/firmware/trunk")
+
+	have, err := NewClient(rootURL, "", "").ListRepos(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Repository{
+		{Path: "boards", URL: rootURL + "/boards"},
+		{Path: "firmware", URL: rootURL + "/firmware"},
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatalf("mismatch (-want +have):\n%s", diff)
+	}
+}
+
+func run(t *testing.T, dir, name string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "HOME="+dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %v failed: %s\n%s", name, args, err, out)
+	}
+}
@@ -82,6 +82,7 @@ const (
 	KindGitolite        = "GITOLITE"
 	KindPerforce        = "PERFORCE"
 	KindPhabricator     = "PHABRICATOR"
+	KindSubversion      = "SUBVERSION"
 	KindJVMPackages     = "JVMPACKAGES"
 	KindOther           = "OTHER"
 )
@@ -119,6 +120,10 @@ const (
 	// TypePhabricator is the (api.ExternalRepoSpec).ServiceType value for Phabricator projects.
 	TypePhabricator = "phabricator"
 
+	// TypeSubversion is the (api.ExternalRepoSpec).ServiceType value for Subversion repositories. The
+	// ServiceID value is the URL of the Subversion root the repositories are under.
+	TypeSubversion = "subversion"
+
 	// TypeJVMPackages is the (api.ExternalRepoSpec).ServiceType value for Maven packages (Java/JVM ecosystem libraries).
 	TypeJVMPackages = "jvmPackages"
 
@@ -148,6 +153,8 @@ func KindToType(kind string) string {
 		return TypePhabricator
 	case KindPerforce:
 		return TypePerforce
+	case KindSubversion:
+		return TypeSubversion
 	case KindJVMPackages:
 		return TypeJVMPackages
 	case KindOther:
@@ -177,6 +184,8 @@ func TypeToKind(t string) string {
 		return KindPerforce
 	case TypePhabricator:
 		return KindPhabricator
+	case TypeSubversion:
+		return KindSubversion
 	case TypeJVMPackages:
 		return KindJVMPackages
 	case TypeOther:
@@ -213,6 +222,8 @@ func ParseServiceType(s string) (string, bool) {
 		return TypePerforce, true
 	case TypePhabricator:
 		return TypePhabricator, true
+	case TypeSubversion:
+		return TypeSubversion, true
 	case jvmLower:
 		return TypeJVMPackages, true
 	case TypeOther:
@@ -242,6 +253,8 @@ func ParseServiceKind(s string) (string, bool) {
 		return KindPerforce, true
 	case KindPhabricator:
 		return KindPhabricator, true
+	case KindSubversion:
+		return KindSubversion, true
 	case KindJVMPackages:
 		return KindJVMPackages, true
 	case KindOther:
@@ -283,6 +296,8 @@ func ParseConfig(kind, config string) (cfg interface{}, _ error) {
 		cfg = &schema.PerforceConnection{}
 	case KindPhabricator:
 		cfg = &schema.PhabricatorConnection{}
+	case KindSubversion:
+		cfg = &schema.SubversionConnection{}
 	case KindJVMPackages:
 		cfg = &schema.JVMPackagesConnection{}
 	case KindOther:
@@ -431,6 +446,50 @@ func limitOrInf(enabled bool, perHour float64) rate.Limit {
 	return rate.Inf
 }
 
+// GitLimits are the limits of the remote git operations gitserver runs for
+// the repositories of an external service.
+type GitLimits struct {
+	// MaxConcurrentClones is the maximum number of concurrent clones and
+	// fetches, or zero if not configured.
+	MaxConcurrentClones int
+
+	// MaxRequestsPerSecond is the maximum number of remote git operations per
+	// second, or nil if not configured.
+	MaxRequestsPerSecond *int
+}
+
+// ExtractGitLimits extracts the git limits from the given external service
+// config. Kinds which do not support git limits have none configured.
+func ExtractGitLimits(config, kind string) (GitLimits, error) {
+	parsed, err := ParseConfig(kind, config)
+	if err != nil {
+		return GitLimits{}, errors.Wrap(err, "loading service configuration")
+	}
+
+	switch c := parsed.(type) {
+	case *schema.AWSCodeCommitConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.BitbucketCloudConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.BitbucketServerConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.GitHubConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.GitLabConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.GitoliteConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.OtherExternalServiceConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.PerforceConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	case *schema.SubversionConnection:
+		return GitLimits{c.GitMaxConcurrentClones, c.GitMaxCodehostRequestsPerSecond}, nil
+	default:
+		return GitLimits{}, nil
+	}
+}
+
 type ErrRateLimitUnsupported struct {
 	codehostKind string
 }
@@ -498,6 +557,8 @@ func UniqueCodeHostIdentifier(kind, config string) (string, error) {
 		rawURL = c.Url
 	case *schema.GitoliteConnection:
 		rawURL = c.Host
+	case *schema.SubversionConnection:
+		rawURL = c.Url
 	case *schema.AWSCodeCommitConnection:
 		// AWS Code Commit does not have a URL in the config, so we return a
 		// unique string here and return early:
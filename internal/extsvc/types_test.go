@@ -169,6 +169,51 @@ func TestExtractRateLimitConfig(t *testing.T) {
 	}
 }
 
+func TestExtractGitLimits(t *testing.T) {
+	zero, two := 0, 2
+	for _, tc := range []struct {
+		name   string
+		config string
+		kind   string
+		want   GitLimits
+	}{
+		{
+			name:   "GitHub",
+			config: `{"url": "https://github.com", "gitMaxConcurrentClones": 3, "gitMaxCodehostRequestsPerSecond": 2}`,
+			kind:   KindGitHub,
+			want:   GitLimits{MaxConcurrentClones: 3, MaxRequestsPerSecond: &two},
+		},
+		{
+			name:   "Bitbucket Server blocked",
+			config: `{"url": "https://example.com", "gitMaxCodehostRequestsPerSecond": 0}`,
+			kind:   KindBitbucketServer,
+			want:   GitLimits{MaxRequestsPerSecond: &zero},
+		},
+		{
+			name:   "Gitolite not configured",
+			config: `{"host": "git@example.com", "prefix": "example.com/"}`,
+			kind:   KindGitolite,
+			want:   GitLimits{},
+		},
+		{
+			name:   "JVM packages unsupported",
+			config: `{"maven": {"repositories": []}}`,
+			kind:   KindJVMPackages,
+			want:   GitLimits{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := ExtractGitLimits(tc.config, tc.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
 func TestEncodeURN(t *testing.T) {
 	tests := []struct {
 		desc    string
@@ -324,6 +369,11 @@ func TestUniqueCodeHostIdentifier(t *testing.T) {
 			config: `{"url": "https://phabricator.sgdev.org/"}`,
 			want:   "https://phabricator.sgdev.org/",
 		},
+		{
+			kind:   KindSubversion,
+			config: `{"url": "svn://svn.example.com/repos"}`,
+			want:   "svn://svn.example.com/repos/",
+		},
 		{
 			kind:   KindOther,
 			config: `{"url": "ssh://user@host.xz:2333/"}`,
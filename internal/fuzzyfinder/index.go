@@ -0,0 +1,446 @@
+// Package fuzzyfinder finds files by approximate path, as typed into a fuzzy
+// file finder.
+//
+// Matching is done against an in-memory index of all the paths of a commit,
+// which is built from the output of git ls-tree and cached on disk by Store.
+// A query matches a path if its characters appear in the path in order, and
+// paths whose file name is within a few typos of the query are returned after
+// those.
+package fuzzyfinder
+
+import (
+	"bytes"
+	"container/heap"
+	"math/bits"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Index is an index of the paths of the files in a commit.
+type Index struct {
+	// paths are the paths of the index, each terminated by a NUL byte.
+	paths []byte
+	// offsets are the offsets in paths of the start of each path.
+	offsets []uint32
+	// masks are the runeMasks of each path, and baseMasks those of their
+	// file names.
+	masks     []uint64
+	baseMasks []uint64
+}
+
+// NewIndex returns the index of the given NUL-separated paths, as printed by
+// git ls-tree -z.
+func NewIndex(paths []byte) *Index {
+	if len(paths) > 0 && paths[len(paths)-1] != 0 {
+		paths = append(paths, 0)
+	}
+
+	n := bytes.Count(paths, []byte{0})
+	ix := &Index{
+		paths:     paths,
+		offsets:   make([]uint32, 0, n),
+		masks:     make([]uint64, 0, n),
+		baseMasks: make([]uint64, 0, n),
+	}
+	for start := 0; start < len(paths); {
+		end := start + bytes.IndexByte(paths[start:], 0)
+		if end > start {
+			ix.offsets = append(ix.offsets, uint32(start))
+			ix.masks = append(ix.masks, runeMask(paths[start:end]))
+			ix.baseMasks = append(ix.baseMasks, runeMask(basename(paths[start:end])))
+		}
+		start = end + 1
+	}
+	return ix
+}
+
+// Len returns the number of paths in the index.
+func (ix *Index) Len() int {
+	return len(ix.offsets)
+}
+
+func (ix *Index) path(i int) []byte {
+	start := ix.offsets[i]
+	end := start + uint32(bytes.IndexByte(ix.paths[start:], 0))
+	return ix.paths[start:end]
+}
+
+// Match is a path matching a query.
+type Match struct {
+	Path string
+
+	// Typos is the number of edits needed for the query to match the file
+	// name. Matches without typos rank first.
+	Typos int
+
+	// Score ranks matches without typos, higher is better. It favors matches
+	// of consecutive characters, at the start of words and in the file name.
+	Score int
+
+	// Positions are the byte offsets in Path of the characters matched by
+	// the query. It is nil for matches with typos.
+	Positions []int
+}
+
+// Search returns the limit best matches of query, from best to worst.
+func (ix *Index) Search(query string, limit int) []Match {
+	q := []byte(strings.ToLower(strings.Join(strings.Fields(query), "")))
+	if len(q) == 0 || limit <= 0 {
+		return nil
+	}
+	s := &searcher{
+		ix:       ix,
+		q:        q,
+		qmask:    runeMask(q),
+		maxTypos: maxTypos(len(q)),
+	}
+
+	// Split the paths among workers, each of which keeps their limit best
+	// matches.
+	workers := runtime.GOMAXPROCS(0)
+	if min := ix.Len()/minPathsPerWorker + 1; workers > min {
+		workers = min
+	}
+	chunk := (ix.Len() + workers - 1) / workers
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		all []candidate
+	)
+	for w := 0; w < workers; w++ {
+		start, end := w*chunk, (w+1)*chunk
+		if end > ix.Len() {
+			end = ix.Len()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			top := s.search(start, end, limit)
+			mu.Lock()
+			all = append(all, top...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(all, func(i, j int) bool { return s.better(all[i], all[j]) })
+	if len(all) > limit {
+		all = all[:limit]
+	}
+
+	matches := make([]Match, 0, len(all))
+	for _, c := range all {
+		path := ix.path(c.index)
+		m := Match{
+			Path:  string(path),
+			Typos: c.typos,
+			Score: c.score,
+		}
+		if c.typos == 0 {
+			_, m.Positions, _ = matchExact(path, q, true)
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// minPathsPerWorker is the minimum number of paths worth searching in a
+// separate goroutine.
+const minPathsPerWorker = 16 * 1024
+
+// maxTypos returns the number of typos tolerated in a query of length n.
+func maxTypos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type candidate struct {
+	index int
+	typos int
+	score int
+}
+
+type searcher struct {
+	ix       *Index
+	q        []byte
+	qmask    uint64
+	maxTypos int
+}
+
+// search returns the limit best candidates among the paths [start, end). It
+// only looks for matches with typos if there are fewer than limit matches
+// without.
+func (s *searcher) search(start, end, limit int) []candidate {
+	top := &candidateHeap{s: s}
+	add := func(c candidate) {
+		if top.Len() < limit {
+			heap.Push(top, c)
+		} else if s.better(c, top.cs[0]) {
+			top.cs[0] = c
+			heap.Fix(top, 0)
+		}
+	}
+
+	for i := start; i < end; i++ {
+		if s.qmask&^s.ix.masks[i] != 0 {
+			continue
+		}
+		if score, _, ok := matchExact(s.ix.path(i), s.q, false); ok {
+			add(candidate{index: i, score: score})
+		}
+	}
+	if top.Len() >= limit || s.maxTypos == 0 {
+		return top.cs
+	}
+
+	d := newDistance(len(s.q))
+	max := s.maxTypos
+	for i := start; i < end; i++ {
+		if top.Len() >= limit {
+			// Only candidates with at most as many typos as the worst one
+			// kept can make it.
+			max = top.cs[0].typos
+		}
+		if bits.OnesCount64(s.qmask&^s.ix.baseMasks[i]) > max {
+			continue
+		}
+		path := s.ix.path(i)
+		typos := d.typos(basename(path), s.q, max)
+		if typos == 0 || typos > max {
+			// Matches without typos were found above.
+			continue
+		}
+		if _, _, ok := matchExact(path, s.q, false); ok {
+			continue
+		}
+		add(candidate{index: i, typos: typos})
+	}
+	return top.cs
+}
+
+// better returns whether a ranks before b.
+func (s *searcher) better(a, b candidate) bool {
+	if a.typos != b.typos {
+		return a.typos < b.typos
+	}
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	pa, pb := s.ix.path(a.index), s.ix.path(b.index)
+	if len(pa) != len(pb) {
+		return len(pa) < len(pb)
+	}
+	return bytes.Compare(pa, pb) < 0
+}
+
+// candidateHeap is a min-heap of candidates, with the worst at the top.
+type candidateHeap struct {
+	s  *searcher
+	cs []candidate
+}
+
+func (h *candidateHeap) Len() int           { return len(h.cs) }
+func (h *candidateHeap) Less(i, j int) bool { return h.s.better(h.cs[j], h.cs[i]) }
+func (h *candidateHeap) Swap(i, j int)      { h.cs[i], h.cs[j] = h.cs[j], h.cs[i] }
+func (h *candidateHeap) Push(x interface{}) { h.cs = append(h.cs, x.(candidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	c := h.cs[len(h.cs)-1]
+	h.cs = h.cs[:len(h.cs)-1]
+	return c
+}
+
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusSegment     = 10
+	bonusConsecutive = 12
+	bonusBasename    = 24
+	penaltyGap       = 1
+)
+
+// matchExact returns the score of matching the lowercase query q as a
+// subsequence of path, and the positions of the matched characters if
+// positions is true. It prefers the rightmost tightest match, so that matches
+// in the file name win over matches in its directories.
+func matchExact(path, q []byte, positions bool) (score int, pos []int, ok bool) {
+	// Find the rightmost start of a match by matching backwards.
+	start := -1
+	qi := len(q) - 1
+	for i := len(path) - 1; i >= 0; i-- {
+		if lower[path[i]] == q[qi] {
+			qi--
+			if qi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return 0, nil, false
+	}
+
+	// Then match forwards from it to find the tightest match.
+	if positions {
+		pos = make([]int, 0, len(q))
+	}
+	qi = 0
+	prev, last := -2, start
+	for i := start; qi < len(q); i++ {
+		if lower[path[i]] != q[qi] {
+			continue
+		}
+		score += scoreMatch
+		switch {
+		case i == 0 || path[i-1] == '/':
+			score += bonusSegment
+		case isBoundary(path[i-1], path[i]):
+			score += bonusBoundary
+		}
+		if prev == i-1 {
+			score += bonusConsecutive
+		}
+		if positions {
+			pos = append(pos, i)
+		}
+		prev, last = i, i
+		qi++
+	}
+
+	score -= penaltyGap * (last - start + 1 - len(q))
+	if start >= bytes.LastIndexByte(path, '/')+1 {
+		score += bonusBasename
+	}
+	return score, pos, true
+}
+
+// isBoundary returns whether c starts a word after prev.
+func isBoundary(prev, c byte) bool {
+	switch prev {
+	case '_', '-', '.', ' ':
+		return true
+	}
+	return 'a' <= prev && prev <= 'z' && 'A' <= c && c <= 'Z'
+}
+
+func basename(path []byte) []byte {
+	return path[bytes.LastIndexByte(path, '/')+1:]
+}
+
+// distance computes edit distances reusing its buffers.
+type distance struct {
+	prev2, prev, cur []int
+}
+
+func newDistance(n int) *distance {
+	return &distance{
+		prev2: make([]int, n+1),
+		prev:  make([]int, n+1),
+		cur:   make([]int, n+1),
+	}
+}
+
+// typos returns the minimum number of insertions, deletions, substitutions
+// and transpositions for the lowercase query q to match a substring of s. It
+// returns max+1 if there are more than max typos.
+//
+// It only computes the rows of each column up to the last one within max
+// typos, as rows below it cannot be within max typos in the next column
+// (Ukkonen's cutoff).
+func (d *distance) typos(s, q []byte, max int) int {
+	m := len(q)
+	inf := max + 1
+	if len(s) < m-max {
+		return inf
+	}
+
+	// prevLast and prev2Last are the last rows within max typos in the
+	// previous two columns. Rows after them count as inf.
+	for i := 0; i <= m; i++ {
+		d.prev[i] = i
+	}
+	prevLast, prev2Last := max, -1
+	if prevLast > m {
+		prevLast = m
+	}
+
+	best := inf
+	if m <= max {
+		best = m
+	}
+	for j := 1; j <= len(s); j++ {
+		c := lower[s[j-1]]
+		top := prevLast + 1
+		if top > m {
+			top = m
+		}
+
+		d.cur[0] = 0
+		for i := 1; i <= top; i++ {
+			v := d.cur[i-1] + 1
+			if i-1 <= prevLast {
+				diag := d.prev[i-1]
+				if q[i-1] != c {
+					diag++
+				}
+				if diag < v {
+					v = diag
+				}
+			}
+			if i <= prevLast && d.prev[i]+1 < v {
+				v = d.prev[i] + 1
+			}
+			if i > 1 && j > 1 && i-2 <= prev2Last && q[i-1] == lower[s[j-2]] && q[i-2] == c && d.prev2[i-2]+1 < v {
+				v = d.prev2[i-2] + 1
+			}
+			d.cur[i] = v
+		}
+
+		last := top
+		for last > 0 && d.cur[last] > max {
+			last--
+		}
+		if last == m && d.cur[m] < best {
+			best = d.cur[m]
+		}
+
+		d.prev2, d.prev, d.cur = d.prev, d.cur, d.prev2
+		prev2Last, prevLast = prevLast, last
+	}
+	return best
+}
+
+// lower maps ASCII letters to lowercase.
+var lower = func() (t [256]byte) {
+	for i := range t {
+		t[i] = byte(i)
+		if 'A' <= i && i <= 'Z' {
+			t[i] = byte(i) + 'a' - 'A'
+		}
+	}
+	return t
+}()
+
+// runeMask returns a bitmask of the ASCII letters and digits in s, ignoring
+// case. A path can only match a query without typos if its mask includes the
+// mask of the query.
+func runeMask(s []byte) (mask uint64) {
+	for _, c := range s {
+		c = lower[c]
+		switch {
+		case 'a' <= c && c <= 'z':
+			mask |= 1 << (c - 'a')
+		case '0' <= c && c <= '9':
+			mask |= 1 << (26 + c - '0')
+		}
+	}
+	return mask
+}
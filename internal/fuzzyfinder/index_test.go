@@ -0,0 +1,83 @@
+package fuzzyfinder
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIndexSearch(t *testing.T) {
+	paths := []string{
+		"README.md",
+		"cmd/frontend/main.go",
+		"cmd/frontend/graphqlbackend/search.go",
+		"cmd/frontend/graphqlbackend/search_results.go",
+		"internal/search/query/parser.go",
+		"internal/search/searcher/search.go",
+		"web/src/search/SearchPage.tsx",
+	}
+	ix := NewIndex([]byte(strings.Join(paths, "\x00")))
+	if ix.Len() != len(paths) {
+		t.Fatalf("got %d paths, want %d", ix.Len(), len(paths))
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// File names rank above directories, and shorter paths first.
+		{"search.go", []string{"internal/search/searcher/search.go", "cmd/frontend/graphqlbackend/search.go", "cmd/frontend/graphqlbackend/search_results.go"}},
+		{"readme", []string{"README.md"}},
+		{"SearchPage", []string{"web/src/search/SearchPage.tsx"}},
+		{"fe main", []string{"cmd/frontend/main.go"}},
+		// Typos in the file name.
+		{"parsre.go", []string{"internal/search/query/parser.go"}},
+		{"serchpage", []string{"web/src/search/SearchPage.tsx"}},
+		{"xyz", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			var got []string
+			for _, m := range ix.Search(test.query, 3) {
+				got = append(got, m.Path)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected matches (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIndexSearch_positions(t *testing.T) {
+	ix := NewIndex([]byte("cmd/frontend/main.go\x00"))
+	matches := ix.Search("main", 1)
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1", len(matches))
+	}
+	if diff := cmp.Diff([]int{13, 14, 15, 16}, matches[0].Positions); diff != "" {
+		t.Errorf("unexpected positions (-want +got):\n%s", diff)
+	}
+
+	matches = ix.Search("mian.go", 1)
+	if len(matches) != 1 || matches[0].Typos != 1 || matches[0].Positions != nil {
+		t.Errorf("expected a match with one typo, got %+v", matches)
+	}
+}
+
+func BenchmarkIndexSearch(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000000; i++ {
+		fmt.Fprintf(&sb, "src/module%d/pkg%d/component%d/file_%d.go\x00", i%97, i%1013, i%7919, i)
+	}
+	ix := NewIndex([]byte(sb.String()))
+
+	for _, query := range []string{"cmpnt42file", "fiel_42424.go"} {
+		b.Run(query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ix.Search(query, 50)
+			}
+		})
+	}
+}
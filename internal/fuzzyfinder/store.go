@@ -0,0 +1,247 @@
+package fuzzyfinder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/metrics"
+)
+
+// DefaultStore is the Store used by the frontend. Its Dir is configured on
+// startup.
+var DefaultStore = &Store{
+	Dir:        "/tmp/fuzzy-finder-cache",
+	FetchPaths: lsTree,
+}
+
+// Store loads the path indexes of commits. Indexes are cached in memory, and
+// the paths they are built from are cached compressed on disk.
+type Store struct {
+	// Dir is the directory to cache paths in.
+	Dir string
+
+	// MaxCacheSizeBytes is the maximum size of Dir. If zero, the cache is not
+	// evicted.
+	MaxCacheSizeBytes int64
+
+	// MaxIndexesInMemory is the maximum number of indexes kept in memory.
+	// Defaults to 32.
+	MaxIndexesInMemory int
+
+	// FetchPaths returns the NUL-separated paths of the files of repo at
+	// commit.
+	FetchPaths func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]byte, error)
+
+	once    sync.Once
+	cache   *diskcache.Store
+	indexes *lru.Cache
+}
+
+// Start initializes the store and starts evicting its disk cache. It is
+// called by Index if needed.
+func (s *Store) Start() {
+	s.once.Do(func() {
+		size := s.MaxIndexesInMemory
+		if size <= 0 {
+			size = 32
+		}
+		s.indexes, _ = lru.New(size)
+		s.cache = &diskcache.Store{
+			Dir:               s.Dir,
+			Component:         "fuzzyfinder",
+			BackgroundTimeout: 2 * time.Minute,
+		}
+		_ = os.MkdirAll(s.Dir, 0700)
+		metrics.MustRegisterDiskMonitor(s.Dir)
+		go s.watchAndEvict()
+	})
+}
+
+// Index returns the index of the paths of repo at commit, which must be an
+// absolute commit ID.
+func (s *Store) Index(ctx context.Context, repo api.RepoName, commit api.CommitID) (*Index, error) {
+	s.Start()
+
+	if len(commit) != 40 {
+		return nil, errors.Errorf("non-absolute commit ID: %q", commit)
+	}
+
+	key := string(repo) + "@" + string(commit)
+	if ix, ok := s.indexes.Get(key); ok {
+		indexCacheHits.Inc()
+		return ix.(*Index), nil
+	}
+	indexCacheMisses.Inc()
+
+	f, err := s.cache.Open(ctx, key, func(ctx context.Context) (io.ReadCloser, error) {
+		paths, err := s.FetchPaths(ctx, repo, commit)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(paths); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(&buf), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cached paths")
+	}
+	paths, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cached paths")
+	}
+
+	ix := NewIndex(paths)
+	s.indexes.Add(key, ix)
+	return ix, nil
+}
+
+// RepoCommit is a commit of a repository to search.
+type RepoCommit struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+}
+
+// RepoCommitMatch is a match of a path in a commit of a repository.
+type RepoCommitMatch struct {
+	RepoCommit
+	Match
+}
+
+// SearchCommits returns the limit best matches of query across the paths of
+// all commits, from best to worst.
+func (s *Store) SearchCommits(ctx context.Context, commits []RepoCommit, query string, limit int) ([]RepoCommitMatch, error) {
+	var (
+		mu      sync.Mutex
+		matches []RepoCommitMatch
+	)
+	sem := make(chan struct{}, maxConcurrentCommits)
+	g, ctx := errgroup.WithContext(ctx)
+	for _, rc := range commits {
+		rc := rc
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ix, err := s.Index(ctx, rc.Repo, rc.Commit)
+			if err != nil {
+				return errors.Wrapf(err, "loading path index of %s@%s", rc.Repo, rc.Commit)
+			}
+			ms := ix.Search(query, limit)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, m := range ms {
+				matches = append(matches, RepoCommitMatch{RepoCommit: rc, Match: m})
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sortRepoCommitMatches(matches)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func sortRepoCommitMatches(matches []RepoCommitMatch) {
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Typos != b.Typos {
+			return a.Typos < b.Typos
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Path) != len(b.Path) {
+			return len(a.Path) < len(b.Path)
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Path < b.Path
+	})
+}
+
+// maxConcurrentCommits is the number of commits SearchCommits searches
+// concurrently.
+const maxConcurrentCommits = 8
+
+func (s *Store) watchAndEvict() {
+	if s.MaxCacheSizeBytes == 0 {
+		return
+	}
+
+	for {
+		time.Sleep(time.Minute)
+
+		stats, err := s.cache.Evict(s.MaxCacheSizeBytes)
+		if err != nil {
+			log.Printf("fuzzyfinder: failed to Evict: %s", err)
+			continue
+		}
+		cacheSizeBytes.Set(float64(stats.CacheSize))
+		evictions.Add(float64(stats.Evicted))
+	}
+}
+
+// lsTree returns the NUL-separated paths of the files of repo at commit.
+func lsTree(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]byte, error) {
+	cmd := gitserver.DefaultClient.Command("git", "ls-tree", "-r", "-z", "--name-only", "--full-tree", string(commit))
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "git command %v failed", cmd.Args)
+	}
+	return out, nil
+}
+
+var (
+	indexCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_fuzzyfinder_index_cache_hit",
+		Help: "Total number of path indexes found in memory.",
+	})
+	indexCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_fuzzyfinder_index_cache_miss",
+		Help: "Total number of path indexes loaded from the disk cache or gitserver.",
+	})
+	cacheSizeBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "src_fuzzyfinder_cache_size_bytes",
+		Help: "The total size of the cached paths on disk.",
+	})
+	evictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_fuzzyfinder_cache_evictions",
+		Help: "The total number of cached paths evicted from disk.",
+	})
+)
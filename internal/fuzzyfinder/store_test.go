@@ -0,0 +1,63 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestStore(t *testing.T) {
+	fetches := 0
+	s := &Store{
+		Dir: t.TempDir(),
+		FetchPaths: func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]byte, error) {
+			fetches++
+			return []byte(string(repo) + "/main.go\x00" + string(repo) + "/README.md\x00"), nil
+		},
+	}
+
+	ctx := context.Background()
+	commit := api.CommitID(strings.Repeat("a", 40))
+
+	if _, err := s.Index(ctx, "foo", "HEAD"); err == nil {
+		t.Fatal("expected error for non-absolute commit")
+	}
+
+	for i := 0; i < 2; i++ {
+		ix, err := s.Index(ctx, "foo", commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ix.Len() != 2 {
+			t.Fatalf("got %d paths, want 2", ix.Len())
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("expected paths to be fetched once, got %d", fetches)
+	}
+
+	// Indexes evicted from memory are loaded from disk.
+	s.indexes.Purge()
+	if _, err := s.Index(ctx, "foo", commit); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Fatalf("expected paths to be loaded from disk, got %d fetches", fetches)
+	}
+
+	matches, err := s.SearchCommits(ctx, []RepoCommit{{"foo", commit}, {"barbaz", commit}}, "main", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range matches {
+		got = append(got, string(m.Repo)+":"+m.Path)
+	}
+	if diff := cmp.Diff([]string{"foo:foo/main.go", "barbaz:barbaz/main.go"}, got); diff != "" {
+		t.Errorf("unexpected matches (-want +got):\n%s", diff)
+	}
+}
@@ -124,9 +124,14 @@ func AddrForRepo(repo api.RepoName, addrs []string) string {
 // addrForKey returns the gitserver address to use for the given string key,
 // which is hashed for sharding purposes.
 func addrForKey(key string, addrs []string) string {
+	return addrs[addrIndexForKey(key, len(addrs))]
+}
+
+// addrIndexForKey returns the index of the gitserver address to use for the
+// given string key among n addresses.
+func addrIndexForKey(key string, n int) int {
 	sum := md5.Sum([]byte(key))
-	serverIndex := binary.BigEndian.Uint64(sum[:]) % uint64(len(addrs))
-	return addrs[serverIndex]
+	return int(binary.BigEndian.Uint64(sum[:]) % uint64(n))
 }
 
 // ArchiveOptions contains options for the Archive func.
@@ -134,6 +139,17 @@ type ArchiveOptions struct {
 	Treeish string   // the tree or commit to produce an archive for
 	Format  string   // format of the resulting archive (usually "tar" or "zip")
 	Paths   []string // if nonempty, only include these paths
+
+	// SkipMissingBlobs skips the blobs missing from partial clones instead
+	// of fetching them. In tar archives the skipped files are included without
+	// content, marked with the protocol.MissingBlobPAXRecord PAX record.
+	SkipMissingBlobs bool
+
+	// Submodules maps the paths of submodules to the repositories they refer
+	// to. Tar archives include the contents of these repositories at the
+	// commits pinned by the submodules. Other submodules are archived as empty
+	// directories, like git archive does.
+	Submodules map[string]api.RepoName
 }
 
 // archiveReader wraps the StdoutReader yielded by gitserver's
@@ -174,6 +190,16 @@ func (c *Client) ArchiveURL(repo api.RepoName, opt ArchiveOptions) *url.URL {
 		q.Add("path", path)
 	}
 
+	if opt.SkipMissingBlobs {
+		q.Set("skipMissingBlobs", "true")
+	}
+
+	if len(opt.Submodules) > 0 {
+		// Marshaling a map of strings never fails.
+		b, _ := json.Marshal(opt.Submodules)
+		q.Set("submodules", string(b))
+	}
+
 	return &url.URL{
 		Scheme:   "http",
 		Host:     c.AddrForRepo(repo),
@@ -202,7 +228,7 @@ func (c *Client) Archive(ctx context.Context, repo api.RepoName, opt ArchiveOpti
 	}
 
 	u := c.ArchiveURL(repo, opt)
-	resp, err := c.do(ctx, repo, "GET", u.String(), nil)
+	resp, err := c.doRead(ctx, repo, "GET", "archive?"+u.RawQuery, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -267,7 +293,22 @@ func (c *Cmd) sendExec(ctx context.Context) (_ io.ReadCloser, _ http.Header, err
 		EnsureRevision: c.EnsureRevision,
 		Args:           c.Args[1:],
 	}
-	resp, err := c.client.httpPost(ctx, repoName, "exec", req)
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	var header http.Header
+	if c.BypassCommandCache {
+		header = http.Header{protocol.BypassCommandCacheHeader: []string{"true"}}
+	}
+	var resp *http.Response
+	if isReadOnlyCommand(req.Args) && !c.primaryOnly {
+		// Reads of hot repositories may be served by a replica.
+		resp, c.fromReplica, err = c.client.doReadReplica(ctx, repoName, "POST", "exec", b, header)
+	} else {
+		c.fromReplica = false
+		resp, err = c.client.doWithHeader(ctx, repoName, "POST", "exec", b, header)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -316,7 +357,7 @@ func (c *Client) Search(ctx context.Context, args *protocol.SearchRequest, onMat
 		return false, err
 	}
 
-	resp, err := c.do(ctx, repoName, "POST", "search", buf.Bytes())
+	resp, err := c.doRead(ctx, repoName, "POST", "search", buf.Bytes(), nil)
 	if err != nil {
 		return false, err
 	}
@@ -405,6 +446,15 @@ type Cmd struct {
 	Repo           api.RepoName // the repository to execute the command in
 	EnsureRevision string
 	ExitStatus     int
+
+	// BypassCommandCache makes gitserver run the command even if its output
+	// is cached, e.g. to repair a cached output which is known to be wrong.
+	BypassCommandCache bool
+
+	// fromReplica is set by sendExec if the command ran on a replica of the
+	// repository. primaryOnly makes sendExec run the command on the primary
+	// gitserver of the repository.
+	fromReplica, primaryOnly bool
 }
 
 // Command creates a new Cmd. Command name must be 'git',
@@ -438,6 +488,9 @@ func (c *Cmd) DividedOutput(ctx context.Context) ([]byte, []byte, error) {
 	}
 
 	stderr := []byte(trailer.Get("X-Exec-Stderr"))
+	if c.ExitStatus != 0 && c.retryOnPrimary(string(stderr)) {
+		return c.DividedOutput(ctx)
+	}
 	if errorMsg := trailer.Get("X-Exec-Error"); errorMsg != "" {
 		return stdout, stderr, errors.New(errorMsg)
 	}
@@ -475,20 +528,36 @@ func StdoutReader(ctx context.Context, c *Cmd) (io.ReadCloser, error) {
 	}
 
 	return &cmdReader{
+		ctx:     ctx,
+		cmd:     c,
 		rc:      rc,
 		trailer: trailer,
 	}, nil
 }
 
 type cmdReader struct {
+	ctx     context.Context
+	cmd     *Cmd
 	rc      io.ReadCloser
 	trailer http.Header
+	read    int64 // bytes read from rc
 }
 
 func (c *cmdReader) Read(p []byte) (int, error) {
 	n, err := c.rc.Read(p)
+	c.read += int64(n)
 	if err == io.EOF {
 		stderr := c.trailer.Get("X-Exec-Stderr")
+		// The command can only be retried if none of its output was read.
+		if c.read == 0 && c.trailer.Get("X-Exec-Exit-Status") != "0" && c.cmd.retryOnPrimary(stderr) {
+			c.rc.Close()
+			c.rc, c.trailer, err = c.cmd.sendExec(c.ctx)
+			if err != nil {
+				c.rc = io.NopCloser(strings.NewReader(""))
+				return 0, err
+			}
+			return c.Read(p)
+		}
 		if len(stderr) > 100 {
 			stderr = stderr[:100] + "... (truncated)"
 		}
@@ -923,6 +992,11 @@ func (c *Client) httpPost(ctx context.Context, repo api.RepoName, op string, pay
 // do performs a request to a gitserver, sharding based on the given
 // repo name (the repo name is otherwise not used).
 func (c *Client) do(ctx context.Context, repo api.RepoName, method, op string, payload []byte) (resp *http.Response, err error) {
+	return c.doWithHeader(ctx, repo, method, op, payload, nil)
+}
+
+// doWithHeader is like do, but additionally sets the given request headers.
+func (c *Client) doWithHeader(ctx context.Context, repo api.RepoName, method, op string, payload []byte, header http.Header) (resp *http.Response, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Client.do")
 	defer func() {
 		span.LogKV("repo", string(repo), "method", method, "op", op)
@@ -946,6 +1020,9 @@ func (c *Client) do(ctx context.Context, repo api.RepoName, method, op string, p
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", c.UserAgent)
 	req.Header.Set("X-Sourcegraph-Actor", userFromContext(ctx))
+	for k, v := range header {
+		req.Header[k] = v
+	}
 	req = req.WithContext(ctx)
 
 	if c.HTTPLimiter != nil {
@@ -23,6 +23,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/gitserver/server"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 )
 
@@ -370,3 +371,31 @@ func TestClient_P4Exec(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_BypassCommandCache(t *testing.T) {
+	var bypass []string
+	cli := &gitserver.Client{
+		Addrs: func() []string { return []string{"gitserver-0"} },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			bypass = append(bypass, r.Header.Get(protocol.BypassCommandCacheHeader))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("out")),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+
+	for _, bypassCache := range []bool{false, true} {
+		cmd := cli.Command("git", "rev-list", "--count", "0123456789abcdef0123456789abcdef01234567")
+		cmd.Repo = "repo"
+		cmd.BypassCommandCache = bypassCache
+		if _, err := cmd.Output(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := []string{"", "true"}; !cmp.Equal(want, bypass) {
+		t.Errorf("unexpected bypass headers (-want +got):\n%s", cmp.Diff(want, bypass))
+	}
+}
@@ -96,6 +96,16 @@ type ExecRequest struct {
 	Opt            *RemoteOpts `json:"opt"`
 }
 
+// BypassCommandCacheHeader is the header of an ExecRequest which, when set
+// to any value, makes gitserver run the command even if its output is cached.
+// The output is still cached afterwards.
+const BypassCommandCacheHeader = "X-Sourcegraph-Bypass-Command-Cache"
+
+// MissingBlobPAXRecord is the PAX record set on the tar entries of archives
+// created with missing blobs skipped. The entries stand in for the files whose
+// blobs were not fetched in a partial clone, and have no content.
+const MissingBlobPAXRecord = "SOURCEGRAPH.missing-blob"
+
 // P4ExecRequest is a request to execute a p4 command with given arguments.
 //
 // Note that this request is deserialized by both gitserver and the frontend's
@@ -131,6 +141,9 @@ type HTTPSConfig struct {
 type RepoUpdateRequest struct {
 	Repo  api.RepoName  `json:"repo"`  // identifying URL for repo
 	Since time.Duration `json:"since"` // debounce interval for queries, used only with request-repo-update
+
+	// SkipClone only updates the repository if it is already cloned.
+	SkipClone bool `json:"skipClone,omitempty"`
 }
 
 // RepoUpdateResponse returns meta information of the repo enqueued for
@@ -220,6 +233,36 @@ type ReposStats struct {
 
 	// GitDirBytes is the amount of bytes stored in .git directories.
 	GitDirBytes int64
+
+	// LooseObjects is the number of loose objects in .git directories.
+	LooseObjects int64
+
+	// Packfiles is the number of packfiles in .git directories.
+	Packfiles int64
+
+	// Maintenance is the number of repositories each maintenance task ran
+	// for, by task name.
+	Maintenance map[string]MaintenanceTaskStats `json:",omitempty"`
+
+	// LargestRepos are the largest repositories on the gitserver, from
+	// largest to smallest. The sizes of all repositories are recorded in the
+	// gitserver_repos table.
+	LargestRepos []RepoSize `json:",omitempty"`
+}
+
+// RepoSize is the on-disk size of a repository.
+type RepoSize struct {
+	Name  api.RepoName
+	Bytes int64
+}
+
+// MaintenanceTaskStats are statistics of a repository maintenance task.
+type MaintenanceTaskStats struct {
+	// Succeeded is the number of repositories the task succeeded for.
+	Succeeded int
+
+	// Failed is the number of repositories the task failed for.
+	Failed int
 }
 
 // RepoCloneProgressRequest is a request for information about the clone progress of multiple
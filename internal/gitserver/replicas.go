@@ -0,0 +1,259 @@
+package gitserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+var replicaRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_gitserver_client_replica_requests_total",
+	Help: "Number of read requests for hot repositories sent to a replica, by whether they had to be retried on the primary gitserver because the replica had not cloned the repository (fallback) or did not have a revision (missing_revision).",
+}, []string{"result"})
+
+// ReplicasConfig returns the configuration of replicas of hot repositories, or
+// nil if replicas are disabled.
+func ReplicasConfig() *schema.GitServerReplicas {
+	c := conf.Get().ExperimentalFeatures
+	if c == nil || c.GitServerReplicas == nil || c.GitServerReplicas.Replicas <= 0 {
+		return nil
+	}
+	return c.GitServerReplicas
+}
+
+// AlwaysReplicated reports whether repo is one of the repositories which
+// are always replicated, regardless of how often it is read.
+func AlwaysReplicated(cfg *schema.GitServerReplicas, repo api.RepoName) bool {
+	repo = protocol.NormalizeRepo(repo)
+	for _, name := range cfg.Repos {
+		if protocol.NormalizeRepo(api.RepoName(name)) == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// ReplicaAddrsForRepo returns the addresses of the gitservers which hold a
+// replica of repo when it is hot. These are the n gitservers following its
+// primary gitserver AddrForRepo(repo, addrs) in addrs. It should never be
+// called with an empty slice.
+func ReplicaAddrsForRepo(repo api.RepoName, addrs []string, n int) []string {
+	if n > len(addrs)-1 {
+		n = len(addrs) - 1
+	}
+	repo = protocol.NormalizeRepo(repo)
+	primary := addrIndexForKey(string(repo), len(addrs))
+	replicas := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		replicas = append(replicas, addrs[(primary+i)%len(addrs)])
+	}
+	return replicas
+}
+
+// readRequestRates counts the read requests for each repository sent by this
+// process, to find hot repositories.
+var readRequestRates = newRequestRates()
+
+// requestRates counts requests per repository in the current and the previous
+// minute.
+type requestRates struct {
+	mu       sync.Mutex
+	minute   int64
+	current  map[api.RepoName]int
+	previous map[api.RepoName]int
+}
+
+func newRequestRates() *requestRates {
+	return &requestRates{
+		current:  map[api.RepoName]int{},
+		previous: map[api.RepoName]int{},
+	}
+}
+
+// observe records a request for repo at now, and returns the number of
+// requests for repo in the current or the previous minute, whichever is
+// larger.
+func (r *requestRates) observe(repo api.RepoName, now time.Time) int {
+	minute := now.Unix() / 60
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch minute {
+	case r.minute:
+	case r.minute + 1:
+		r.previous, r.current = r.current, map[api.RepoName]int{}
+	default:
+		r.previous, r.current = map[api.RepoName]int{}, map[api.RepoName]int{}
+	}
+	r.minute = minute
+
+	r.current[repo]++
+	if r.previous[repo] > r.current[repo] {
+		return r.previous[repo]
+	}
+	return r.current[repo]
+}
+
+// addrForRead returns the address of the gitserver to send a read-only request
+// for repo to, and whether it holds a replica of repo rather than being its
+// primary gitserver. Requests for hot repositories are spread randomly across
+// the primary gitserver and the replicas of the repository.
+func (c *Client) addrForRead(repo api.RepoName) (string, bool) {
+	addrs := c.Addrs()
+	if len(addrs) == 0 {
+		panic("unexpected state: no gitserver addresses")
+	}
+	repo = protocol.NormalizeRepo(repo)
+	primary := AddrForRepo(repo, addrs)
+
+	cfg := ReplicasConfig()
+	if cfg == nil || len(addrs) == 1 {
+		return primary, false
+	}
+	rate := readRequestRates.observe(repo, time.Now())
+	hot := AlwaysReplicated(cfg, repo) || (cfg.HotRequestsPerMinute > 0 && rate > cfg.HotRequestsPerMinute)
+	if !hot {
+		return primary, false
+	}
+
+	replicas := ReplicaAddrsForRepo(repo, addrs, cfg.Replicas)
+	i := rand.Intn(len(replicas) + 1)
+	if i == len(replicas) {
+		return primary, false
+	}
+	return replicas[i], true
+}
+
+// doRead is like do for read-only requests, which may be sent to a replica of
+// a hot repository. If the replica does not have the repository yet, because
+// it has only just become hot, the request is retried on the primary gitserver
+// while the replica clones it. The given request headers are set on all
+// requests.
+func (c *Client) doRead(ctx context.Context, repo api.RepoName, method, op string, payload []byte, header http.Header) (*http.Response, error) {
+	resp, _, err := c.doReadReplica(ctx, repo, method, op, payload, header)
+	return resp, err
+}
+
+// doReadReplica is like doRead, but additionally reports whether the response
+// is from a replica.
+func (c *Client) doReadReplica(ctx context.Context, repo api.RepoName, method, op string, payload []byte, header http.Header) (*http.Response, bool, error) {
+	addr, replica := c.addrForRead(repo)
+	if !replica {
+		resp, err := c.doWithHeader(ctx, repo, method, op, payload, header)
+		return resp, false, err
+	}
+
+	resp, err := c.doWithHeader(ctx, repo, method, "http://"+addr+"/"+op, payload, header)
+	if err == nil && resp.StatusCode != http.StatusNotFound {
+		replicaRequests.WithLabelValues("replica").Inc()
+		return resp, true, nil
+	}
+	if err == nil {
+		resp.Body.Close()
+	} else if ctx.Err() != nil {
+		return nil, false, err
+	}
+	replicaRequests.WithLabelValues("fallback").Inc()
+	resp, err = c.doWithHeader(ctx, repo, method, op, payload, header)
+	return resp, false, err
+}
+
+// missingRevisionMessages are the messages git fails with when a revision or
+// object does not exist in a repository.
+var missingRevisionMessages = []string{
+	"bad object",
+	"bad revision",
+	"invalid object name",
+	"not a valid object name",
+	"unknown revision",
+}
+
+// retryOnPrimary reports whether c, which failed with stderr, should be
+// retried on the primary gitserver of its repository. Replicas are updated
+// asynchronously, so they may not have a revision which was just pushed to the
+// primary gitserver yet. If so, c is set to run on the primary gitserver.
+func (c *Cmd) retryOnPrimary(stderr string) bool {
+	if !c.fromReplica || c.primaryOnly {
+		return false
+	}
+	for _, msg := range missingRevisionMessages {
+		if strings.Contains(stderr, msg) {
+			replicaRequests.WithLabelValues("missing_revision").Inc()
+			c.primaryOnly = true
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyCommands are the git commands which may be run on a replica of a
+// repository, because they only read it.
+var readOnlyCommands = map[string]bool{
+	"archive":      true,
+	"blame":        true,
+	"cat-file":     true,
+	"diff":         true,
+	"for-each-ref": true,
+	"log":          true,
+	"ls-files":     true,
+	"ls-tree":      true,
+	"merge-base":   true,
+	"rev-list":     true,
+	"rev-parse":    true,
+	"shortlog":     true,
+	"show":         true,
+	"show-ref":     true,
+}
+
+// isReadOnlyCommand reports whether the git command with the given arguments
+// (excluding "git") only reads the repository.
+func isReadOnlyCommand(args []string) bool {
+	return len(args) > 0 && readOnlyCommands[args[0]]
+}
+
+// UpdateReplica requests the replica of repo on the gitserver at addr to be
+// fetched from the primary gitserver of repo. If the replica has not been
+// cloned yet, it is only cloned if clone is true.
+func (c *Client) UpdateReplica(ctx context.Context, addr string, repo api.RepoName, clone bool) error {
+	b, err := json.Marshal(&protocol.RepoUpdateRequest{
+		Repo:      repo,
+		SkipClone: !clone,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, repo, "POST", "http://"+addr+"/repo-update", b)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return &url.Error{URL: resp.Request.URL.String(), Op: "UpdateReplica", Err: errors.Errorf("UpdateReplica: http status %d: %s", resp.StatusCode, body)}
+	}
+
+	var info protocol.RepoUpdateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return err
+	}
+	if info.Error != "" {
+		return errors.New(info.Error)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package gitserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestReplicaAddrsForRepo(t *testing.T) {
+	addrs := []string{"gitserver-1", "gitserver-2", "gitserver-3"}
+
+	// The primary gitserver of repo1 is gitserver-3, see TestAddrForRepo.
+	if diff := cmp.Diff([]string{"gitserver-1"}, ReplicaAddrsForRepo("repo1", addrs, 1)); diff != "" {
+		t.Errorf("unexpected replicas (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"gitserver-1", "gitserver-2"}, ReplicaAddrsForRepo("repo1.git", addrs, 5)); diff != "" {
+		t.Errorf("unexpected replicas (-want +got):\n%s", diff)
+	}
+	if replicas := ReplicaAddrsForRepo("repo1", addrs[:1], 2); len(replicas) != 0 {
+		t.Errorf("expected no replicas with a single gitserver, got %v", replicas)
+	}
+}
+
+func TestRequestRates(t *testing.T) {
+	r := newRequestRates()
+	start := time.Unix(6000, 0)
+
+	for i := 1; i <= 3; i++ {
+		if have := r.observe("a", start.Add(time.Duration(i)*time.Second)); have != i {
+			t.Fatalf("have %d requests, want %d", have, i)
+		}
+	}
+	if have := r.observe("b", start); have != 1 {
+		t.Fatalf("have %d requests, want 1", have)
+	}
+
+	// The requests of the previous minute still count.
+	if have := r.observe("a", start.Add(time.Minute)); have != 3 {
+		t.Fatalf("have %d requests, want 3", have)
+	}
+	// Requests older than the previous minute don't.
+	if have := r.observe("a", start.Add(3*time.Minute)); have != 1 {
+		t.Fatalf("have %d requests, want 1", have)
+	}
+}
+
+func TestClient_readFromReplica(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{
+			GitServerReplicas: &schema.GitServerReplicas{
+				Replicas: 1,
+				Repos:    []string{"repo1"},
+			},
+		},
+	}})
+	defer conf.Mock(nil)
+
+	// The primary gitserver of repo1 is gitserver-3, and its replica is
+	// gitserver-1. The replica has not cloned it yet.
+	var hosts []string
+	cli := &Client{
+		Addrs: func() []string { return []string{"gitserver-1", "gitserver-2", "gitserver-3"} },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			hosts = append(hosts, r.URL.Host)
+			if r.URL.Host == "gitserver-1" {
+				b, _ := json.Marshal(&protocol.NotFoundPayload{CloneInProgress: true})
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       io.NopCloser(bytes.NewReader(b)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("out")),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+
+	ctx := context.Background()
+	run := func(repo api.RepoName, args ...string) {
+		t.Helper()
+		cmd := cli.Command("git", args...)
+		cmd.Repo = repo
+		out, err := cmd.Output(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != "out" {
+			t.Fatalf("unexpected output %q", out)
+		}
+	}
+
+	// Reads are spread randomly, so after enough of them one is sent to the
+	// replica and retried on the primary.
+	for i := 0; i < 50; i++ {
+		run("repo1", "log")
+	}
+	var replicaRequests int
+	for i, host := range hosts {
+		switch host {
+		case "gitserver-1":
+			replicaRequests++
+			if i+1 == len(hosts) || hosts[i+1] != "gitserver-3" {
+				t.Fatalf("expected request to the replica to be retried on the primary, got %v", hosts)
+			}
+		case "gitserver-3":
+		default:
+			t.Fatalf("unexpected request to %s", host)
+		}
+	}
+	if replicaRequests == 0 {
+		t.Fatal("expected some requests to be sent to the replica")
+	}
+
+	// Commands which modify the repository, and repositories which aren't
+	// hot, always go to the primary gitserver.
+	hosts = nil
+	for i := 0; i < 20; i++ {
+		run("repo1", "update-ref", "refs/heads/foo", "HEAD")
+		run("repo2", "log")
+	}
+	for _, host := range hosts {
+		if host == "gitserver-1" {
+			t.Fatalf("unexpected request to the replica, got %v", hosts)
+		}
+	}
+}
+
+func TestClient_retryMissingRevisionOnPrimary(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{
+			GitServerReplicas: &schema.GitServerReplicas{
+				Replicas: 1,
+				Repos:    []string{"repo1"},
+			},
+		},
+	}})
+	defer conf.Mock(nil)
+
+	// The replica gitserver-1 has not fetched a commit which was just pushed
+	// to the primary gitserver-3 yet.
+	var hosts []string
+	cli := &Client{
+		Addrs: func() []string { return []string{"gitserver-1", "gitserver-2", "gitserver-3"} },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			hosts = append(hosts, r.URL.Host)
+			if r.URL.Host == "gitserver-1" {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+					Trailer: http.Header{
+						"X-Exec-Exit-Status": {"128"},
+						"X-Exec-Stderr":      {"fatal: ambiguous argument 'deadbeef': unknown revision or path not in the working tree."},
+					},
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString("deadbeef")),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+
+	ctx := context.Background()
+	outputs := map[string]func(*Cmd) ([]byte, error){
+		"Output": func(cmd *Cmd) ([]byte, error) { return cmd.Output(ctx) },
+		"StdoutReader": func(cmd *Cmd) ([]byte, error) {
+			rc, err := StdoutReader(ctx, cmd)
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		},
+	}
+	for name, output := range outputs {
+		t.Run(name, func(t *testing.T) {
+			hosts = nil
+			// Reads are spread randomly, so after enough of them one is
+			// sent to the replica and retried on the primary.
+			for i := 0; i < 50; i++ {
+				cmd := cli.Command("git", "rev-parse", "deadbeef^0")
+				cmd.Repo = "repo1"
+				out, err := output(cmd)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(out) != "deadbeef" {
+					t.Fatalf("unexpected output %q", out)
+				}
+			}
+
+			var replicaRequests int
+			for i, host := range hosts {
+				if host == "gitserver-1" {
+					replicaRequests++
+					if i+1 == len(hosts) || hosts[i+1] != "gitserver-3" {
+						t.Fatalf("expected request to the replica to be retried on the primary, got %v", hosts)
+					}
+				}
+			}
+			if replicaRequests == 0 {
+				t.Fatal("expected some requests to be sent to the replica")
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/cockroachdb/errors"
 	"github.com/gomodule/redigo/redis"
 	"github.com/inconshreveable/log15"
 
@@ -146,6 +147,40 @@ func (r *Cache) Set(key string, b []byte) {
 	}
 }
 
+// IncrByInt64 adds value to the integer stored at key and returns the result.
+// A missing key counts as zero. If the cache has a TTL, the key expires
+// ttlSeconds after it is created, regardless of later increments, so that it
+// counts within a fixed window.
+func (r *Cache) IncrByInt64(key string, value int64) (int64, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	k := r.rkeyPrefix() + key
+	if r.ttlSeconds == 0 {
+		return redis.Int64(c.Do("INCRBY", k, value))
+	}
+
+	// Creating the key with its expiry and incrementing it in one transaction
+	// ensures the key never exists without an expiry.
+	if err := c.Send("MULTI"); err != nil {
+		return 0, err
+	}
+	if err := c.Send("SET", k, 0, "EX", r.ttlSeconds, "NX"); err != nil {
+		return 0, err
+	}
+	if err := c.Send("INCRBY", k, value); err != nil {
+		return 0, err
+	}
+	replies, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return 0, err
+	}
+	if len(replies) != 2 {
+		return 0, errors.Errorf("unexpected number of replies to transaction: %d", len(replies))
+	}
+	return redis.Int64(replies[1], nil)
+}
+
 // Delete implements httpcache.Cache.Delete
 func (r *Cache) Delete(key string) {
 	c := pool.Get()
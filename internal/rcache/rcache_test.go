@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+
+	"github.com/gomodule/redigo/redis"
 )
 
 func TestCache_namespace(t *testing.T) {
@@ -90,6 +92,37 @@ func TestCache_simple(t *testing.T) {
 	}
 }
 
+func TestCache_IncrByInt64(t *testing.T) {
+	SetupForTest(t)
+
+	c := NewWithTTL("some_prefix", 60)
+	for i, want := range []int64{3, 5, 4} {
+		got, err := c.IncrByInt64("a", []int64{3, 2, -1}[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+	}
+
+	b, ok := c.Get("a")
+	if !ok || string(b) != "4" {
+		t.Fatalf("got %q, want %q", b, "4")
+	}
+
+	// The key expires, even though it was created by an increment.
+	conn := pool.Get()
+	defer conn.Close()
+	ttl, err := redis.Int(conn.Do("TTL", c.rkeyPrefix()+"a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 || ttl > 60 {
+		t.Fatalf("got TTL %d, want (0, 60]", ttl)
+	}
+}
+
 func TestCache_multi(t *testing.T) {
 	SetupForTest(t)
 
@@ -58,6 +58,8 @@ func NewSource(svc *types.ExternalService, cf *httpcli.Factory) (Source, error)
 		return NewAWSCodeCommitSource(svc, cf)
 	case extsvc.KindPerforce:
 		return NewPerforceSource(svc)
+	case extsvc.KindSubversion:
+		return NewSubversionSource(svc)
 	case extsvc.KindJVMPackages:
 		return NewJVMPackagesSource(svc)
 	case extsvc.KindOther:
@@ -0,0 +1,110 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A SubversionSource yields repositories from a single Subversion connection
+// configured in Sourcegraph via the external services configuration.
+type SubversionSource struct {
+	svc    *types.ExternalService
+	config *schema.SubversionConnection
+	root   *url.URL
+	// listRepos lists the repositories under the Subversion root when none
+	// are configured explicitly.
+	listRepos func(ctx context.Context) ([]*subversion.Repository, error)
+}
+
+// NewSubversionSource returns a new SubversionSource from the given external
+// service.
+func NewSubversionSource(svc *types.ExternalService) (*SubversionSource, error) {
+	var c schema.SubversionConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return newSubversionSource(svc, &c)
+}
+
+func newSubversionSource(svc *types.ExternalService, c *schema.SubversionConnection) (*SubversionSource, error) {
+	root, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "external service id=%d config error: parsing url", svc.ID)
+	}
+	return &SubversionSource{
+		svc:       svc,
+		config:    c,
+		root:      root,
+		listRepos: subversion.NewClient(c.Url, c.Username, c.Password).ListRepos,
+	}, nil
+}
+
+// ListRepos returns the configured Subversion repositories, or all the
+// repositories under the Subversion root if none are configured.
+func (s SubversionSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	if len(s.config.Repos) > 0 {
+		for _, path := range s.config.Repos {
+			results <- SourceResult{Source: s, Repo: s.makeRepo(&subversion.Repository{
+				Path: path,
+				URL:  subversion.JoinURL(s.config.Url, path),
+			})}
+		}
+		return
+	}
+
+	all, err := s.listRepos(ctx)
+	if err != nil {
+		results <- SourceResult{Source: s, Err: err}
+		return
+	}
+	for _, r := range all {
+		results <- SourceResult{Source: s, Repo: s.makeRepo(r)}
+	}
+}
+
+func (s SubversionSource) makeRepo(repo *subversion.Repository) *types.Repo {
+	urn := s.svc.URN()
+	serviceID := *s.root
+	serviceID.User = nil
+
+	return &types.Repo{
+		Name: reposource.SubversionRepoName(
+			s.config.RepositoryPathPattern,
+			s.root.Hostname(),
+			repo.Path,
+		),
+		URI: string(reposource.SubversionRepoName(
+			"",
+			s.root.Hostname(),
+			repo.Path,
+		)),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          repo.Path,
+			ServiceType: extsvc.TypeSubversion,
+			ServiceID:   extsvc.NormalizeBaseURL(&serviceID).String(),
+		},
+		Private: true,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: repo.URL,
+			},
+		},
+		Metadata: repo,
+	}
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s SubversionSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
@@ -0,0 +1,120 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSubversionSource_ListRepos(t *testing.T) {
+	listed := []*subversion.Repository{
+		{Path: "boards", URL: "https://svn.example.com/repos/boards"},
+		{Path: "firmware", URL: "https://svn.example.com/repos/firmware"},
+	}
+
+	testCases := []struct {
+		name string
+		conf *schema.SubversionConnection
+		want []string
+	}{
+		{
+			name: "configured",
+			conf: &schema.SubversionConnection{
+				Url:   "https://svn.example.com/repos",
+				Repos: []string{"hardware/firmware"},
+			},
+			want: []string{"svn.example.com/hardware/firmware"},
+		},
+		{
+			name: "listed",
+			conf: &schema.SubversionConnection{
+				Url: "https://svn.example.com/repos",
+			},
+			want: []string{"svn.example.com/boards", "svn.example.com/firmware"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &types.ExternalService{
+				Kind:   extsvc.KindSubversion,
+				Config: marshalJSON(t, tc.conf),
+			}
+
+			src, err := newSubversionSource(svc, tc.conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			src.listRepos = func(context.Context) ([]*subversion.Repository, error) {
+				return listed, nil
+			}
+
+			repos, err := listAll(context.Background(), src)
+			if have, want := fmt.Sprint(err), "<nil>"; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			have := types.Repos(repos).Names()
+			sort.Strings(have)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Errorf("Mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubversionSource_makeRepo(t *testing.T) {
+	repos := []*subversion.Repository{
+		{Path: "boards", URL: "https://svn.example.com/repos/boards"},
+		{Path: "hardware/firmware", URL: "https://svn.example.com/repos/hardware/firmware"},
+	}
+
+	svc := types.ExternalService{ID: 1, Kind: extsvc.KindSubversion}
+
+	tests := []struct {
+		name   string
+		schema *schema.SubversionConnection
+	}{
+		{
+			name: "simple",
+			schema: &schema.SubversionConnection{
+				Url:      "https://svn.example.com/repos",
+				Username: "admin",
+				Password: "pa$$word",
+			},
+		}, {
+			name: "path-pattern",
+			schema: &schema.SubversionConnection{
+				Url:                   "https://svn.example.com/repos",
+				Username:              "admin",
+				Password:              "pa$$word",
+				RepositoryPathPattern: "svn/{path}",
+			},
+		},
+	}
+	for _, test := range tests {
+		test.name = "SubversionSource_makeRepo_" + test.name
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newSubversionSource(&svc, test.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*types.Repo
+			for _, r := range repos {
+				got = append(got, s.makeRepo(r))
+			}
+
+			testutil.AssertGolden(t, "testdata/golden/"+test.name, update(test.name), got)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+// Package cost estimates the cost of searches and enforces the per-user and
+// per-access token search cost budgets configured in site configuration.
+package cost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// Estimate returns the estimated cost of evaluating plan over repoCount
+// repositories. Every basic query of the plan costs one unit per repository,
+// multiplied by a factor for each expensive option it uses.
+func Estimate(plan query.Plan, repoCount int) int64 {
+	var total int64
+	for _, b := range plan {
+		total += int64(repoCount) * factor(b)
+	}
+	return total
+}
+
+func factor(b query.Basic) int64 {
+	f := int64(1)
+
+	types, _ := b.ToParseTree().StringValues(query.FieldType)
+	var typeFactor int64 = 1
+	for _, t := range types {
+		switch t {
+		case "diff":
+			typeFactor = max(typeFactor, 10)
+		case "commit":
+			typeFactor = max(typeFactor, 5)
+		case "symbol":
+			typeFactor = max(typeFactor, 2)
+		}
+	}
+	f *= typeFactor
+
+	if b.IsStructural() {
+		f *= 10
+	}
+
+	if count := b.GetCount(); count != "" {
+		// count:all is substituted with a very large count during parsing.
+		if c, err := strconv.Atoi(count); err == nil {
+			switch {
+			case c >= 10000:
+				f *= 5
+			case c > search.DefaultMaxSearchResultsStreaming:
+				f *= 2
+			}
+		}
+	}
+
+	if timeout := b.GetTimeout(); timeout != nil && *timeout > search.DefaultTimeout {
+		f *= 2
+	}
+
+	return f
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Budgets returns the search cost budgets in c, with defaults applied.
+func Budgets(c *conf.Unified) schema.SearchCostBudgets {
+	var budgets schema.SearchCostBudgets
+	if c.SearchCostBudgets != nil {
+		budgets = *c.SearchCostBudgets
+	}
+	if budgets.WindowSeconds <= 0 {
+		budgets.WindowSeconds = 3600
+	}
+	if budgets.OverBudget == "" {
+		budgets.OverBudget = "reject"
+	}
+	if budgets.MaxQueueSeconds <= 0 {
+		budgets.MaxQueueSeconds = 30
+	}
+	return budgets
+}
+
+// Enabled returns true if any search cost budget is configured in c.
+func Enabled(c *conf.Unified) bool {
+	budgets := Budgets(c)
+	return budgets.PerUser > 0 || budgets.PerAccessToken > 0
+}
+
+// OverBudgetError is returned by Charge when a search is over the budget of
+// its user or access token.
+type OverBudgetError struct {
+	// Scope is the budget which was exceeded: "user" or "access token".
+	Scope string
+	// Cost is the estimated cost of the search.
+	Cost int64
+	// Budget is the budget for a window.
+	Budget int64
+	// RetryAfter is the time until the next window starts.
+	RetryAfter time.Duration
+}
+
+func (e *OverBudgetError) Error() string {
+	return fmt.Sprintf("search with estimated cost %d is over the %s search cost budget of %d", e.Cost, e.Scope, e.Budget)
+}
+
+var (
+	// The metrics are not labeled by user, since the number of users is
+	// unbounded. The cost charged to each user is tracked by the budget
+	// counters in Redis, and read with GetUserUsage.
+	costCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "src_search_cost_total",
+		Help: "Total estimated cost of the searches charged to users.",
+	})
+
+	overBudgetCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_search_cost_over_budget_total",
+		Help: "Number of searches which were over budget, by the budget exceeded and whether they were queued or rejected.",
+	}, []string{"scope", "action"})
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// MockCharge, if set, is called by Charge instead of charging the budgets in
+// Redis.
+var MockCharge func(ctx context.Context, cost int64) error
+
+// Charged returns true if the searches of the actor in ctx are charged to
+// search cost budgets. Searches of anonymous and internal actors are not
+// charged. Callers are expected to also exempt site admins.
+func Charged(ctx context.Context) bool {
+	a := actor.FromContext(ctx)
+	return a.IsAuthenticated() && !a.IsInternal() && Enabled(conf.Get())
+}
+
+// Charge charges cost to the search cost budgets of the actor in ctx. If the
+// search is over budget, it is either queued until the next window starts or
+// rejected with an *OverBudgetError, depending on the site configuration.
+//
+// Searches of anonymous and internal actors are not charged. Callers are
+// expected to skip Charge for site admins.
+func Charge(ctx context.Context, cost int64) error {
+	if !Charged(ctx) {
+		return nil
+	}
+	if MockCharge != nil {
+		return MockCharge(ctx, cost)
+	}
+	a := actor.FromContext(ctx)
+	budgets := Budgets(conf.Get())
+
+	err := charge(a, budgets, cost)
+	var e *OverBudgetError
+	if !errors.As(err, &e) {
+		return err
+	}
+
+	// A search which costs more than the whole budget would never fit into
+	// the next window, so there is no point in queueing it.
+	queue := budgets.OverBudget == "queue" && e.Cost <= e.Budget && e.RetryAfter <= time.Duration(budgets.MaxQueueSeconds)*time.Second
+	if !queue {
+		overBudgetCounter.WithLabelValues(e.Scope, "rejected").Inc()
+		return e
+	}
+
+	overBudgetCounter.WithLabelValues(e.Scope, "queued").Inc()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(e.RetryAfter):
+	}
+	return charge(a, budgets, cost)
+}
+
+// budgetCounter counts the cost of the searches charged to a budget in a
+// window.
+type budgetCounter struct {
+	scope  string
+	key    string
+	budget int64
+}
+
+// window returns the index and end of the budget window containing t.
+func window(budgets schema.SearchCostBudgets, t time.Time) (index int64, end time.Time) {
+	index = t.Unix() / int64(budgets.WindowSeconds)
+	return index, time.Unix((index+1)*int64(budgets.WindowSeconds), 0)
+}
+
+// budgetCache returns the cache of the budget counters.
+func budgetCache(budgets schema.SearchCostBudgets) *rcache.Cache {
+	// Keys outlive their window by one window so that they expire whichever
+	// point of the window they were created at.
+	return rcache.NewWithTTL("search_cost", 2*budgets.WindowSeconds)
+}
+
+func userKey(userID int32, index int64) string {
+	return fmt.Sprintf("user:%d:%d", userID, index)
+}
+
+// charge adds cost to the counters of the current window, and takes it back
+// if the search is over budget so that rejected searches are free.
+func charge(a *actor.Actor, budgets schema.SearchCostBudgets, cost int64) error {
+	t := now()
+	index, end := window(budgets, t)
+	retryAfter := end.Sub(t)
+	c := budgetCache(budgets)
+
+	var counters []budgetCounter
+	if budgets.PerUser > 0 {
+		counters = append(counters, budgetCounter{
+			scope:  "user",
+			key:    userKey(a.UID, index),
+			budget: int64(budgets.PerUser),
+		})
+	}
+	if budgets.PerAccessToken > 0 && a.AccessTokenFingerprint != "" {
+		counters = append(counters, budgetCounter{
+			scope:  "access token",
+			key:    fmt.Sprintf("token:%s:%d", a.AccessTokenFingerprint, index),
+			budget: int64(budgets.PerAccessToken),
+		})
+	}
+
+	for i, ctr := range counters {
+		total, err := c.IncrByInt64(ctr.key, cost)
+		if err != nil {
+			refund(c, counters[:i], cost)
+			return err
+		}
+		if total > ctr.budget {
+			refund(c, counters[:i+1], cost)
+			return &OverBudgetError{
+				Scope:      ctr.scope,
+				Cost:       cost,
+				Budget:     ctr.budget,
+				RetryAfter: retryAfter,
+			}
+		}
+	}
+
+	costCounter.Add(float64(cost))
+	return nil
+}
+
+func refund(c *rcache.Cache, counters []budgetCounter, cost int64) {
+	for _, ctr := range counters {
+		_, _ = c.IncrByInt64(ctr.key, -cost)
+	}
+}
+
+// UserUsage is the search cost charged to a user in the current window.
+type UserUsage struct {
+	// Spent is the estimated cost of the searches charged in the window.
+	Spent int64
+	// Budget is the per-user budget for a window.
+	Budget int64
+	// WindowEnd is the time the window ends.
+	WindowEnd time.Time
+}
+
+// GetUserUsage returns the search cost charged to the user in the current
+// window. It returns nil if no per-user budget is configured.
+//
+// 🚨 SECURITY: This function does NOT verify that the current user may see
+// the usage of the user. The caller is responsible for ensuring this.
+func GetUserUsage(userID int32) (*UserUsage, error) {
+	budgets := Budgets(conf.Get())
+	if budgets.PerUser <= 0 {
+		return nil, nil
+	}
+
+	index, end := window(budgets, now())
+	usage := &UserUsage{Budget: int64(budgets.PerUser), WindowEnd: end}
+	if b, ok := budgetCache(budgets).Get(userKey(userID, index)); ok {
+		spent, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing search cost counter")
+		}
+		usage.Spent = spent
+	}
+	return usage, nil
+}
@@ -0,0 +1,131 @@
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestEstimate(t *testing.T) {
+	cases := []struct {
+		query      string
+		structural bool
+		want       int64
+	}{
+		{query: `foo`, want: 100},
+		{query: `foo count:1000`, want: 200},
+		{query: `foo count:all`, want: 500},
+		{query: `foo type:diff`, want: 1000},
+		{query: `foo type:commit count:all`, want: 2500},
+		{query: `foo timeout:2m`, want: 200},
+		{query: `foo :[bar]`, structural: true, want: 1000},
+		{query: `foo or bar`, want: 200},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			init := query.InitLiteral(tc.query)
+			if tc.structural {
+				init = query.InitStructural(tc.query)
+			}
+			plan, err := query.Pipeline(init)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Estimate(plan, 100); got != tc.want {
+				t.Fatalf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCharge(t *testing.T) {
+	rcache.SetupForTest(t)
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		SearchCostBudgets: &schema.SearchCostBudgets{
+			PerUser:        100,
+			PerAccessToken: 50,
+			WindowSeconds:  60,
+		},
+	}})
+	defer conf.Mock(nil)
+
+	now = func() time.Time { return time.Unix(6000, 0) }
+	defer func() { now = time.Now }()
+
+	user := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	token := actor.WithActor(context.Background(), &actor.Actor{UID: 1, AccessTokenFingerprint: "abc"})
+
+	if err := Charge(context.Background(), 1000); err != nil {
+		t.Fatalf("anonymous actors should not be charged: %v", err)
+	}
+	if err := Charge(user, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	// The token budget is not exceeded, but the user budget is.
+	var e *OverBudgetError
+	if err := Charge(token, 50); !errors.As(err, &e) || e.Scope != "user" {
+		t.Fatalf("got %v, want user over budget error", err)
+	}
+	if e.RetryAfter != time.Minute {
+		t.Fatalf("got retry after %s, want %s", e.RetryAfter, time.Minute)
+	}
+
+	// The rejected search was not charged.
+	if err := Charge(token, 40); err != nil {
+		t.Fatal(err)
+	}
+	if err := Charge(token, 20); !errors.As(err, &e) || e.Scope != "access token" {
+		t.Fatalf("got %v, want access token over budget error", err)
+	}
+
+	// The next window has a new budget.
+	now = func() time.Time { return time.Unix(6060, 0) }
+	if err := Charge(token, 50); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetUserUsage(t *testing.T) {
+	rcache.SetupForTest(t)
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		SearchCostBudgets: &schema.SearchCostBudgets{
+			PerUser:       100,
+			WindowSeconds: 60,
+		},
+	}})
+	defer conf.Mock(nil)
+
+	now = func() time.Time { return time.Unix(6000, 0) }
+	defer func() { now = time.Now }()
+
+	if err := Charge(actor.WithActor(context.Background(), &actor.Actor{UID: 1}), 60); err != nil {
+		t.Fatal(err)
+	}
+
+	want := UserUsage{Spent: 60, Budget: 100, WindowEnd: time.Unix(6060, 0)}
+	if usage, err := GetUserUsage(1); err != nil {
+		t.Fatal(err)
+	} else if *usage != want {
+		t.Fatalf("got %+v, want %+v", *usage, want)
+	}
+
+	// Users who have not searched in the window have not spent anything.
+	want.Spent = 0
+	if usage, err := GetUserUsage(2); err != nil {
+		t.Fatal(err)
+	} else if *usage != want {
+		t.Fatalf("got %+v, want %+v", *usage, want)
+	}
+}
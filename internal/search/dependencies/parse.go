@@ -0,0 +1,229 @@
+package dependencies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+)
+
+// Parse returns the names of the repos which may contain the dependencies
+// listed in the file at path, which must match query.DependencyFilesPattern.
+// Names are not checked to exist.
+func Parse(filePath string, data []byte) ([]api.RepoName, error) {
+	var (
+		names []api.RepoName
+		err   error
+	)
+	switch path.Base(filePath) {
+	case "pom.xml":
+		names, err = parsePom(data)
+	case "go.sum":
+		names, err = parseGoSum(data)
+	case "package-lock.json":
+		names, err = parsePackageLock(data)
+	default:
+		return nil, errors.Errorf("unsupported dependency file %q", filePath)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", filePath)
+	}
+	return dedupe(names), nil
+}
+
+// parsePom returns the repos of the Maven modules a pom.xml depends on, as
+// synced by the JVM packages code host.
+func parsePom(data []byte) ([]api.RepoName, error) {
+	type dependency struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+	}
+	var pom struct {
+		GroupID string `xml:"groupId"`
+		Parent  struct {
+			GroupID string `xml:"groupId"`
+		} `xml:"parent"`
+		Dependencies         []dependency `xml:"dependencies>dependency"`
+		DependencyManagement []dependency `xml:"dependencyManagement>dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+
+	projectGroupID := pom.GroupID
+	if projectGroupID == "" {
+		projectGroupID = pom.Parent.GroupID
+	}
+
+	var names []api.RepoName
+	for _, d := range append(pom.Dependencies, pom.DependencyManagement...) {
+		groupID := strings.TrimSpace(d.GroupID)
+		switch groupID {
+		case "${project.groupId}", "${groupId}":
+			groupID = projectGroupID
+		}
+		artifactID := strings.TrimSpace(d.ArtifactID)
+		if groupID == "" || artifactID == "" || strings.Contains(groupID+artifactID, "${") {
+			// We don't resolve other properties.
+			continue
+		}
+		module := reposource.MavenModule{GroupID: groupID, ArtifactID: artifactID}
+		names = append(names, module.RepoName())
+	}
+	return names, nil
+}
+
+// parseGoSum returns the repos of the Go modules listed in a go.sum file.
+func parseGoSum(data []byte) ([]api.RepoName, error) {
+	var names []api.RepoName
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		names = append(names, goModuleRepoNames(fields[0])...)
+	}
+	return names, scanner.Err()
+}
+
+// goModuleRepoNames returns the candidate repo names of the Go module at
+// modulePath: the module path itself, without its major version suffix, and
+// the repo it lives in for code hosts whose repos are always two path
+// elements deep.
+func goModuleRepoNames(modulePath string) []api.RepoName {
+	elems := strings.Split(modulePath, "/")
+	if n := len(elems); n > 1 && isMajorVersionSuffix(elems[n-1]) {
+		elems = elems[:n-1]
+	}
+
+	names := []api.RepoName{api.RepoName(strings.Join(elems, "/"))}
+	switch elems[0] {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		if len(elems) > 3 {
+			names = append(names, api.RepoName(strings.Join(elems[:3], "/")))
+		}
+	}
+	return names
+}
+
+func isMajorVersionSuffix(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	for _, c := range elem[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePackageLock returns the repos of the npm packages listed in a
+// package-lock.json file. Packages from the registry map to npm/<name>, and
+// packages installed from GitHub to the repo they were installed from.
+func parsePackageLock(data []byte) ([]api.RepoName, error) {
+	type lockDependency struct {
+		Version      string                     `json:"version"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	var lock struct {
+		// Dependencies are nested by name in lockfile versions 1 and 2.
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+		// Packages are keyed by their install path in lockfile versions 2
+		// and 3.
+		Packages map[string]struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	var names []api.RepoName
+	add := func(name, version string) {
+		if name == "" {
+			return
+		}
+		if repo, ok := gitHubPackageRepo(version); ok {
+			names = append(names, repo)
+			return
+		}
+		names = append(names, api.RepoName("npm/"+name))
+	}
+
+	var walk func(deps map[string]json.RawMessage) error
+	walk = func(deps map[string]json.RawMessage) error {
+		for name, raw := range deps {
+			var d lockDependency
+			if err := json.Unmarshal(raw, &d); err != nil {
+				return err
+			}
+			add(name, d.Version)
+			if err := walk(d.Dependencies); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(lock.Dependencies); err != nil {
+		return nil, err
+	}
+
+	for installPath, p := range lock.Packages {
+		if installPath == "" {
+			// The root package is the repo itself.
+			continue
+		}
+		name := p.Name
+		if i := strings.LastIndex(installPath, "node_modules/"); i >= 0 && name == "" {
+			name = installPath[i+len("node_modules/"):]
+		}
+		add(name, p.Version)
+	}
+	return names, nil
+}
+
+// gitHubPackageRepo returns the repo of an npm package version installed from
+// GitHub, such as github:owner/repo#commit or
+// git+ssh://git@github.com/owner/repo.git#commit.
+func gitHubPackageRepo(version string) (api.RepoName, bool) {
+	if i := strings.IndexByte(version, '#'); i >= 0 {
+		version = version[:i]
+	}
+	var ownerRepo string
+	switch {
+	case strings.HasPrefix(version, "github:"):
+		ownerRepo = strings.TrimPrefix(version, "github:")
+	case strings.Contains(version, "github.com/"), strings.Contains(version, "github.com:"):
+		i := strings.Index(version, "github.com")
+		ownerRepo = version[i+len("github.com")+1:]
+	default:
+		return "", false
+	}
+	ownerRepo = strings.TrimSuffix(ownerRepo, ".git")
+	if strings.Count(ownerRepo, "/") != 1 {
+		return "", false
+	}
+	return api.RepoName("github.com/" + ownerRepo), true
+}
+
+func dedupe(names []api.RepoName) []api.RepoName {
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	out := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
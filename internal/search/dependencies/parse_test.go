@@ -0,0 +1,125 @@
+package dependencies
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		data string
+		want []api.RepoName
+	}{
+		{
+			name: "pom.xml",
+			path: "service/pom.xml",
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>com.example</groupId>
+  <artifactId>service</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>30.1-jre</version>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>common</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>${custom.group}</groupId>
+      <artifactId>ignored</artifactId>
+    </dependency>
+  </dependencies>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>junit</groupId>
+        <artifactId>junit</artifactId>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`,
+			want: []api.RepoName{"maven/com.example/common", "maven/com.google.guava/guava", "maven/junit/junit"},
+		},
+		{
+			name: "go.sum",
+			path: "go.sum",
+			data: `github.com/cockroachdb/errors v1.8.6 h1:Am9evxl/po3RzpokemQvq7S7Cd0mxv24xy0B/trlQF4=
+github.com/cockroachdb/errors v1.8.6/go.mod h1:hOm5fabihW+xEyY1kuypGwqT+Vt7rafg04ytBtIpeIQ=
+github.com/go-redis/redis/v8 v8.11.0 h1:O1Td0mQ8UFChQ3N9zFQqo6kTU2cJ+/it88gDB+zg0wo=
+github.com/aws/aws-sdk-go-v2/service/s3 v1.16.1 h1:z+P3r4LrwdudLKBoEVWxIORrk4sVg4/iqpG3+CS53AY=
+golang.org/x/sync v0.0.0-20210220032951-036812b2e83c h1:5KslGYwFpkhGh+Q16bwMP3cOontH8FOep7tGV86Y7SQ=
+`,
+			want: []api.RepoName{
+				"github.com/aws/aws-sdk-go-v2",
+				"github.com/aws/aws-sdk-go-v2/service/s3",
+				"github.com/cockroachdb/errors",
+				"github.com/go-redis/redis",
+				"golang.org/x/sync",
+			},
+		},
+		{
+			name: "package-lock.json v1",
+			path: "client/package-lock.json",
+			data: `{
+  "name": "client",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "react": {
+      "version": "17.0.2",
+      "dependencies": {
+        "loose-envify": {"version": "1.4.0"}
+      }
+    },
+    "@babel/core": {"version": "7.15.0"},
+    "my-fork": {"version": "github:sourcegraph/my-fork#abc123"}
+  }
+}`,
+			want: []api.RepoName{"github.com/sourcegraph/my-fork", "npm/@babel/core", "npm/loose-envify", "npm/react"},
+		},
+		{
+			name: "package-lock.json v3",
+			path: "package-lock.json",
+			data: `{
+  "name": "client",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "client"},
+    "node_modules/react": {"version": "17.0.2"},
+    "node_modules/react/node_modules/loose-envify": {"version": "1.4.0"},
+    "node_modules/other": {"version": "git+ssh://git@github.com/sourcegraph/other.git#abc123"}
+  }
+}`,
+			want: []api.RepoName{"github.com/sourcegraph/other", "npm/loose-envify", "npm/react"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.path, []byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected repos (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, path := range []string{"pom.xml", "package-lock.json"} {
+		if _, err := Parse(path, []byte("{<")); err == nil {
+			t.Errorf("expected error parsing invalid %s", path)
+		}
+	}
+	if _, err := Parse("Gemfile.lock", nil); err == nil {
+		t.Error("expected error for unsupported file")
+	}
+}
@@ -0,0 +1,121 @@
+// Package dependencies expands repositories to the repositories of the
+// packages they depend on, for the repo:dependencies() predicate.
+//
+// Dependencies are read from Maven pom.xml, go.sum and package-lock.json
+// files. They map to the repos synced by package code hosts, such as
+// maven/<group>/<artifact> for JVM packages, or to the repos of the code hosts
+// they are fetched from, such as github.com/<owner>/<repo> for Go modules.
+// Only repos which exist on the instance are returned.
+package dependencies
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// maxFileSize is the size limit of the dependency files we read.
+const maxFileSize = 10 << 20
+
+// maxConcurrentReads is the number of dependency files read concurrently.
+const maxConcurrentReads = 8
+
+// Resolve returns a repo match for each repo of matches, and for each
+// existing repo of the dependencies listed in the dependency files among
+// matches. Files which can't be read or parsed are skipped.
+func Resolve(ctx context.Context, db dbutil.DB, matches []result.Match) ([]result.Match, error) {
+	var (
+		mu    sync.Mutex
+		repos = map[api.RepoName]*result.RepoMatch{}
+		names = map[api.RepoName]struct{}{}
+	)
+
+	sem := make(chan struct{}, maxConcurrentReads)
+	g, ctx := errgroup.WithContext(ctx)
+	for _, m := range matches {
+		repos[m.RepoName().Name] = &result.RepoMatch{Name: m.RepoName().Name, ID: m.RepoName().ID}
+		fm, ok := m.(*result.FileMatch)
+		if !ok {
+			continue
+		}
+
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			deps, err := readDependencies(ctx, fm)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log15.Warn("dependencies: failed to read dependencies", "repo", fm.Repo.Name, "path", fm.Path, "error", err)
+				return nil
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, name := range deps {
+				names[name] = struct{}{}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if len(names) > 0 {
+		opts := database.ReposListOptions{Names: make([]string, 0, len(names))}
+		for name := range names {
+			opts.Names = append(opts.Names, string(name))
+		}
+		existing, err := database.Repos(db).ListRepoNames(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range existing {
+			repos[r.Name] = &result.RepoMatch{Name: r.Name, ID: r.ID}
+		}
+	}
+
+	resolved := make([]result.Match, 0, len(repos))
+	for _, r := range repos {
+		resolved = append(resolved, r)
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].(*result.RepoMatch).Name < resolved[j].(*result.RepoMatch).Name
+	})
+	return resolved, nil
+}
+
+// readDependencies returns the candidate repo names of the dependencies in the
+// file of fm.
+func readDependencies(ctx context.Context, fm *result.FileMatch) ([]api.RepoName, error) {
+	commit := fm.CommitID
+	if commit == "" {
+		rev := "HEAD"
+		if fm.InputRev != nil && *fm.InputRev != "" {
+			rev = *fm.InputRev
+		}
+		var err error
+		commit, err = git.ResolveRevision(ctx, fm.Repo.Name, rev, git.ResolveRevisionOptions{NoEnsureRevision: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := git.ReadFile(ctx, fm.Repo.Name, commit, fm.Path, maxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(fm.Path, data)
+}
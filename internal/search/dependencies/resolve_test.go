@@ -0,0 +1,74 @@
+package dependencies
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestResolve(t *testing.T) {
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		switch name {
+		case "go.sum":
+			return []byte("github.com/cockroachdb/errors v1.8.6 h1:abc=\ngithub.com/missing/dep v1.0.0 h1:abc=\n"), nil
+		case "java/pom.xml":
+			return []byte("<project><dependencies><dependency><groupId>junit</groupId><artifactId>junit</artifactId></dependency></dependencies></project>"), nil
+		}
+		return []byte("invalid"), nil
+	}
+	var gotNames []string
+	database.Mocks.Repos.ListRepoNames = func(_ context.Context, opt database.ReposListOptions) ([]types.RepoName, error) {
+		gotNames = opt.Names
+		return []types.RepoName{
+			{ID: 10, Name: "github.com/cockroachdb/errors"},
+			{ID: 11, Name: "maven/junit/junit"},
+		}, nil
+	}
+	t.Cleanup(func() {
+		git.ResetMocks()
+		database.Mocks.Repos = database.MockRepos{}
+	})
+
+	fileMatch := func(repo api.RepoName, id api.RepoID, path string) *result.FileMatch {
+		return &result.FileMatch{File: result.File{
+			Repo:     types.RepoName{ID: id, Name: repo},
+			CommitID: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			Path:     path,
+		}}
+	}
+	matches := []result.Match{
+		&result.RepoMatch{ID: 1, Name: "github.com/sourcegraph/service"},
+		&result.RepoMatch{ID: 2, Name: "github.com/sourcegraph/no-deps"},
+		fileMatch("github.com/sourcegraph/service", 1, "go.sum"),
+		fileMatch("github.com/sourcegraph/service", 1, "java/pom.xml"),
+		fileMatch("github.com/sourcegraph/service", 1, "package-lock.json"),
+	}
+
+	got, err := Resolve(context.Background(), nil, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []result.Match{
+		&result.RepoMatch{ID: 10, Name: "github.com/cockroachdb/errors"},
+		&result.RepoMatch{ID: 2, Name: "github.com/sourcegraph/no-deps"},
+		&result.RepoMatch{ID: 1, Name: "github.com/sourcegraph/service"},
+		&result.RepoMatch{ID: 11, Name: "maven/junit/junit"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected repos (-want +got):\n%s", diff)
+	}
+
+	sort.Strings(gotNames)
+	wantNames := []string{"github.com/cockroachdb/errors", "github.com/missing/dep", "maven/junit/junit"}
+	if diff := cmp.Diff(wantNames, gotNames); diff != "" {
+		t.Errorf("unexpected names looked up (-want +got):\n%s", diff)
+	}
+}
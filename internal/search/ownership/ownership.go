@@ -0,0 +1,147 @@
+// Package ownership filters search results by the code owners of the matched
+// files, as defined by the CODEOWNERS files of their repositories.
+package ownership
+
+import (
+	"context"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// Filter restricts file matches to those owned by all of a set of owners,
+// and optionally maps them to their owners for select:file.owners.
+type Filter struct {
+	owners       []string
+	selectOwners bool
+}
+
+// FromQuery removes the file:has.owner() predicates from q, which no backend
+// understands, and returns the filter that resolves them on the results. It
+// returns a nil filter if q neither has such predicates nor selects
+// file.owners.
+func FromQuery(q query.Basic) (query.Basic, *Filter, error) {
+	f := &Filter{}
+	if sp, err := filter.SelectPathFromString(q.FindValue(query.FieldSelect)); err == nil {
+		f.selectOwners = len(sp) > 1 && sp.Root() == filter.File && sp[1] == filter.Owners
+	}
+
+	parameters := make([]query.Parameter, 0, len(q.Parameters))
+	hasFileFilter := false
+	for _, p := range q.Parameters {
+		if p.Field == query.FieldFile && p.Annotation.Labels.IsSet(query.IsPredicate) {
+			name, params := query.ParseAsPredicate(p.Value)
+			if pred, ok := query.DefaultPredicateRegistry.Get(p.Field, name).(*query.FileHasOwnerPredicate); ok {
+				if err := pred.ParseParams(params); err != nil {
+					return q, nil, err
+				}
+				f.owners = append(f.owners, pred.Owner)
+				continue
+			}
+		}
+		if p.Field == query.FieldFile {
+			hasFileFilter = true
+		}
+		parameters = append(parameters, p)
+	}
+
+	if len(f.owners) == 0 && !f.selectOwners {
+		return q, nil, nil
+	}
+	if len(f.owners) > 0 && !hasFileFilter && q.Pattern == nil {
+		// Without a pattern or another file filter, the backends would only
+		// return repositories. Match every path so that there are files to
+		// filter.
+		parameters = append(parameters, query.Parameter{Field: query.FieldFile, Value: "."})
+	}
+	return q.MapParameters(parameters), f, nil
+}
+
+// Apply returns the matches that pass the filter. File matches are dropped
+// unless they are owned by all owners of the filter, and other matches are
+// dropped if the filter has owners. When selecting owners, each file match is
+// replaced by the owners of its file.
+func (f *Filter) Apply(ctx context.Context, matches []result.Match) []result.Match {
+	filtered := make([]result.Match, 0, len(matches))
+	for _, m := range matches {
+		fm, ok := m.(*result.FileMatch)
+		if !ok {
+			if len(f.owners) == 0 {
+				filtered = append(filtered, m)
+			}
+			continue
+		}
+
+		rs, commit := f.ruleset(ctx, fm)
+		if rs == nil {
+			// Files without a CODEOWNERS file have no owners.
+			continue
+		}
+		if !f.isOwned(rs, fm.Path) {
+			continue
+		}
+
+		if !f.selectOwners {
+			filtered = append(filtered, fm)
+			continue
+		}
+		for _, owner := range rs.Match(fm.Path) {
+			filtered = append(filtered, &result.OwnerMatch{
+				Owner:    owner,
+				Repo:     fm.Repo,
+				CommitID: commit,
+			})
+		}
+	}
+	return filtered
+}
+
+// Stream returns a sender that applies the filter to the results sent to
+// parent.
+func (f *Filter) Stream(ctx context.Context, parent streaming.Sender) streaming.Sender {
+	return streaming.StreamFunc(func(event streaming.SearchEvent) {
+		event.Results = f.Apply(ctx, event.Results)
+		parent.Send(event)
+	})
+}
+
+func (f *Filter) isOwned(rs *codeowners.Ruleset, path string) bool {
+	for _, owner := range f.owners {
+		if !rs.IsOwnedBy(path, owner) {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleset returns the CODEOWNERS ruleset of the commit of fm, along with the
+// commit. It returns a nil ruleset if there is none or it fails to load.
+func (f *Filter) ruleset(ctx context.Context, fm *result.FileMatch) (*codeowners.Ruleset, api.CommitID) {
+	commit := fm.CommitID
+	if commit == "" {
+		rev := "HEAD"
+		if fm.InputRev != nil && *fm.InputRev != "" {
+			rev = *fm.InputRev
+		}
+		var err error
+		commit, err = git.ResolveRevision(ctx, fm.Repo.Name, rev, git.ResolveRevisionOptions{NoEnsureRevision: true})
+		if err != nil {
+			log15.Warn("ownership: failed to resolve revision", "repo", fm.Repo.Name, "rev", rev, "error", err)
+			return nil, ""
+		}
+	}
+
+	rs, err := codeowners.ForCommit(ctx, fm.Repo.Name, commit)
+	if err != nil {
+		log15.Warn("ownership: failed to load CODEOWNERS", "repo", fm.Repo.Name, "commit", commit, "error", err)
+		return nil, commit
+	}
+	return rs, commit
+}
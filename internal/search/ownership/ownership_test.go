@@ -0,0 +1,107 @@
+package ownership
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantQuery  string
+		wantFilter *Filter
+	}{{
+		query:     `foo file:\.go$`,
+		wantQuery: `file:\.go$ foo`,
+	}, {
+		query:      `foo file:has.owner(@alice) file:has.owner(@bob)`,
+		wantQuery:  `foo`,
+		wantFilter: &Filter{owners: []string{"@alice", "@bob"}},
+	}, {
+		query:      `repo:foo file:has.owner(@alice)`,
+		wantQuery:  `repo:foo file:.`,
+		wantFilter: &Filter{owners: []string{"@alice"}},
+	}, {
+		query:      `foo select:file.owners`,
+		wantQuery:  `select:file.owners foo`,
+		wantFilter: &Filter{selectOwners: true},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			q, err := query.ParseLiteral(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := query.ToBasicQuery(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotQuery, gotFilter, err := FromQuery(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.wantQuery, query.StringHuman(gotQuery.ToParseTree())); diff != "" {
+				t.Errorf("unexpected query (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantFilter, gotFilter, cmp.AllowUnexported(Filter{})); diff != "" {
+				t.Errorf("unexpected filter (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if name == "CODEOWNERS" {
+			return []byte("* @alice\n*.go @bob @alice\n"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+
+	repo := types.RepoName{ID: 1, Name: "github.com/foo/filter-apply"}
+	fileMatch := func(path string) *result.FileMatch {
+		return &result.FileMatch{File: result.File{Repo: repo, CommitID: "abc", Path: path}}
+	}
+	matches := func() []result.Match {
+		return []result.Match{
+			fileMatch("main.go"),
+			fileMatch("README.md"),
+			&result.RepoMatch{Name: repo.Name, ID: repo.ID},
+		}
+	}
+
+	ctx := context.Background()
+
+	t.Run("owners", func(t *testing.T) {
+		f := &Filter{owners: []string{"@bob", "alice"}}
+		want := []result.Match{fileMatch("main.go")}
+		if diff := cmp.Diff(want, f.Apply(ctx, matches())); diff != "" {
+			t.Errorf("unexpected matches (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("select owners", func(t *testing.T) {
+		f := &Filter{selectOwners: true}
+		want := []result.Match{
+			&result.OwnerMatch{Owner: "@bob", Repo: repo, CommitID: "abc"},
+			&result.OwnerMatch{Owner: "@alice", Repo: repo, CommitID: "abc"},
+			&result.OwnerMatch{Owner: "@alice", Repo: repo, CommitID: "abc"},
+			&result.RepoMatch{Name: repo.Name, ID: repo.ID},
+		}
+		if diff := cmp.Diff(want, f.Apply(ctx, matches())); diff != "" {
+			t.Errorf("unexpected matches (-want +got):\n%s", diff)
+		}
+	})
+}
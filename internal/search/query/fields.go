@@ -20,6 +20,7 @@ const (
 	FieldVisibility         = "visibility"
 	FieldRev                = "rev"
 	FieldContext            = "context"
+	FieldSubmodules         = "submodules"
 
 	// For diff and commit search only:
 	FieldBefore    = "before"
@@ -72,6 +73,7 @@ var allFields = map[string]struct{}{
 	FieldRev:                empty,
 	"revision":              empty,
 	FieldSelect:             empty,
+	FieldSubmodules:         empty,
 }
 
 var aliases = map[string]string{
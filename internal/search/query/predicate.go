@@ -31,10 +31,14 @@ var DefaultPredicateRegistry = predicateRegistry{
 		"contains.file":         func() Predicate { return &RepoContainsFilePredicate{} },
 		"contains.content":      func() Predicate { return &RepoContainsContentPredicate{} },
 		"contains.commit.after": func() Predicate { return &RepoContainsCommitAfterPredicate{} },
+		"has":                   func() Predicate { return &RepoHasKVPPredicate{} },
+		"has.topic":             func() Predicate { return &RepoHasTopicPredicate{} },
+		"dependencies":          func() Predicate { return &RepoDependenciesPredicate{} },
 	},
 	FieldFile: {
 		"contains.content": func() Predicate { return &FileContainsContentPredicate{} },
 		"contains":         func() Predicate { return &FileContainsContentPredicate{} },
+		"has.owner":        func() Predicate { return &FileHasOwnerPredicate{} },
 	},
 }
 
@@ -249,6 +253,140 @@ func (f *RepoContainsCommitAfterPredicate) Plan(parent Basic) (Plan, error) {
 	return ToPlan(Dnf(nodes))
 }
 
+/* repo:has(key:value) */
+
+// RepoHasKVPPredicate represents the `repo:has(key:value)` predicate, which
+// filters to repos with the key-value pair of metadata defined by site
+// admins. Without a value, as in `repo:has(key)`, it filters to repos with the
+// key, regardless of its value.
+type RepoHasKVPPredicate struct {
+	Key   string
+	Value *string
+}
+
+func (f *RepoHasKVPPredicate) ParseParams(params string) error {
+	parts := strings.SplitN(params, ":", 2)
+	if parts[0] == "" {
+		return errors.New("has argument should be of the form key:value or key")
+	}
+	f.Key = parts[0]
+	if len(parts) == 2 {
+		f.Value = &parts[1]
+	}
+	return nil
+}
+
+func (f *RepoHasKVPPredicate) Field() string { return FieldRepo }
+func (f *RepoHasKVPPredicate) Name() string  { return "has" }
+func (f *RepoHasKVPPredicate) Plan(parent Basic) (Plan, error) {
+	return nil, errors.New("repo:has() is resolved while resolving repositories")
+}
+
+/* repo:has.topic(topic) */
+
+// RepoHasTopicPredicate represents the `repo:has.topic(topic)` predicate,
+// which filters to repos labeled with the topic on their code host.
+type RepoHasTopicPredicate struct {
+	Topic string
+}
+
+func (f *RepoHasTopicPredicate) ParseParams(params string) error {
+	if params == "" {
+		return errors.New("has.topic argument should not be empty")
+	}
+	f.Topic = params
+	return nil
+}
+
+func (f *RepoHasTopicPredicate) Field() string { return FieldRepo }
+func (f *RepoHasTopicPredicate) Name() string  { return "has.topic" }
+func (f *RepoHasTopicPredicate) Plan(parent Basic) (Plan, error) {
+	return nil, errors.New("repo:has.topic() is resolved while resolving repositories")
+}
+
+/* repo:dependencies(pattern) */
+
+// DependencyFilesPattern matches the paths of the manifests and lockfiles
+// listing the dependencies of a repository.
+const DependencyFilesPattern = `(^|/)(pom\.xml|go\.sum|package-lock\.json)$`
+
+// RepoDependenciesPredicate represents the `repo:dependencies(pattern)`
+// predicate, which expands to the repos matching pattern and the repos of the
+// packages they depend on.
+type RepoDependenciesPredicate struct {
+	Pattern string
+}
+
+func (f *RepoDependenciesPredicate) ParseParams(params string) error {
+	if params == "" {
+		return errors.Errorf("dependencies argument should not be empty")
+	}
+	if _, err := regexp.Compile(params); err != nil {
+		return errors.Errorf("dependencies argument: %w", err)
+	}
+	f.Pattern = params
+	return nil
+}
+
+func (f *RepoDependenciesPredicate) Field() string { return FieldRepo }
+func (f *RepoDependenciesPredicate) Name() string  { return "dependencies" }
+
+// Plan returns queries for the repos matching the pattern and their
+// dependency files. Mapping the files to the repos of the dependencies they
+// list is up to the caller.
+func (f *RepoDependenciesPredicate) Plan(parent Basic) (Plan, error) {
+	repos := []Node{
+		Parameter{
+			Field: FieldRepo,
+			Value: f.Pattern,
+		},
+		Parameter{
+			Field: FieldSelect,
+			Value: "repo",
+		},
+		Parameter{
+			Field: FieldCount,
+			Value: "99999",
+		},
+	}
+	files := []Node{
+		Parameter{
+			Field: FieldRepo,
+			Value: f.Pattern,
+		},
+		Parameter{
+			Field: FieldFile,
+			Value: DependencyFilesPattern,
+		},
+		Parameter{
+			Field: FieldSelect,
+			Value: "file",
+		},
+		Parameter{
+			Field: FieldCount,
+			Value: "99999",
+		},
+	}
+	return ToPlan(Dnf([]Node{Operator{
+		Kind: Or,
+		Operands: []Node{
+			Operator{Kind: And, Operands: repos},
+			Operator{Kind: And, Operands: files},
+		},
+	}}))
+}
+
+// IsRepoMetadataPredicate returns whether the predicate filters repositories
+// by their metadata. Unlike other predicates, these are not expanded by
+// running subqueries, but resolved together with the other repo: filters.
+func IsRepoMetadataPredicate(p Predicate) bool {
+	switch p.(type) {
+	case *RepoHasKVPPredicate, *RepoHasTopicPredicate:
+		return true
+	}
+	return false
+}
+
 type FileContainsContentPredicate struct {
 	Pattern string
 }
@@ -284,13 +422,37 @@ func (f *FileContainsContentPredicate) Plan(parent Basic) (Plan, error) {
 	return ToPlan(Dnf(nodes))
 }
 
+/* file:has.owner(owner) */
+
+// FileHasOwnerPredicate represents the `file:has.owner(owner)` predicate,
+// which filters to files owned by the owner according to the CODEOWNERS file
+// of their repository.
+type FileHasOwnerPredicate struct {
+	Owner string
+}
+
+func (f *FileHasOwnerPredicate) ParseParams(params string) error {
+	if params == "" {
+		return errors.New("has.owner argument should not be empty")
+	}
+	f.Owner = params
+	return nil
+}
+
+func (f *FileHasOwnerPredicate) Field() string { return FieldFile }
+func (f *FileHasOwnerPredicate) Name() string  { return "has.owner" }
+func (f *FileHasOwnerPredicate) Plan(parent Basic) (Plan, error) {
+	return nil, errors.New("file:has.owner() is resolved while filtering search results")
+}
+
 // nonPredicateRepos returns the repo nodes in a query that aren't predicates,
 // respecting parameters that determine repo results.
 func nonPredicateRepos(q Basic) []Node {
 	var res []Node
 	VisitParameter(q.ToParseTree(), func(field, value string, negated bool, ann Annotation) {
-		if ann.Labels.IsSet(IsPredicate) {
-			// Skip predicates
+		if ann.Labels.IsSet(IsPredicate) && !isRepoMetadataPredicateValue(field, value) {
+			// Skip predicates, except those resolved together with repo:
+			// filters.
 			return
 		}
 		switch field {
@@ -313,3 +475,8 @@ func nonPredicateRepos(q Basic) []Node {
 	})
 	return res
 }
+
+func isRepoMetadataPredicateValue(field, value string) bool {
+	name, _ := ParseAsPredicate(value)
+	return IsRepoMetadataPredicate(DefaultPredicateRegistry.Get(field, name))
+}
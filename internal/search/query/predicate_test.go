@@ -56,6 +56,115 @@ func TestRepoContainsPredicate(t *testing.T) {
 	})
 }
 
+func TestRepoHasKVPPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		value := func(s string) *string { return &s }
+
+		type test struct {
+			name     string
+			params   string
+			expected *RepoHasKVPPredicate
+		}
+
+		valid := []test{
+			{`key and value`, `owner:team-x`, &RepoHasKVPPredicate{Key: "owner", Value: value("team-x")}},
+			{`only key`, `deprecated`, &RepoHasKVPPredicate{Key: "deprecated"}},
+			{`empty value`, `owner:`, &RepoHasKVPPredicate{Key: "owner", Value: value("")}},
+			{`value with colon`, `url:https://example.com`, &RepoHasKVPPredicate{Key: "url", Value: value("https://example.com")}},
+		}
+
+		for _, tc := range valid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &RepoHasKVPPredicate{}
+				err := p.ParseParams(tc.params)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !reflect.DeepEqual(tc.expected, p) {
+					t.Fatalf("expected %#v, got %#v", tc.expected, p)
+				}
+			})
+		}
+
+		invalid := []test{
+			{`empty`, ``, nil},
+			{`empty key`, `:team-x`, nil},
+		}
+
+		for _, tc := range invalid {
+			t.Run(tc.name, func(t *testing.T) {
+				p := &RepoHasKVPPredicate{}
+				err := p.ParseParams(tc.params)
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+			})
+		}
+	})
+}
+
+func TestFileHasOwnerPredicate(t *testing.T) {
+	p := &FileHasOwnerPredicate{}
+	if err := p.ParseParams("@org/team"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := (&FileHasOwnerPredicate{Owner: "@org/team"}); !reflect.DeepEqual(want, p) {
+		t.Fatalf("expected %#v, got %#v", want, p)
+	}
+
+	if err := (&FileHasOwnerPredicate{}).ParseParams(""); err == nil {
+		t.Fatal("expected error for empty owner")
+	}
+}
+
+func TestRepoDependenciesPredicate(t *testing.T) {
+	p := &RepoDependenciesPredicate{}
+	if err := p.ParseParams(`^github\.com/sourcegraph/sourcegraph$`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	plan, err := p.Plan(Basic{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(plan))
+	}
+	want := []string{
+		`"repo:^github\\.com/sourcegraph/sourcegraph$" "select:repo" "count:99999"`,
+		`"repo:^github\\.com/sourcegraph/sourcegraph$" "file:(^|/)(pom\\.xml|go\\.sum|package-lock\\.json)$" "select:file" "count:99999"`,
+	}
+	for i, q := range plan {
+		if got := q.ToParseTree().String(); got != want[i] {
+			t.Errorf("expected %s, got %s", want[i], got)
+		}
+	}
+
+	for params, want := range map[string]string{
+		"":    "dependencies argument should not be empty",
+		"([)": "dependencies argument: error parsing regexp: missing closing ]: `[)`",
+	} {
+		err := (&RepoDependenciesPredicate{}).ParseParams(params)
+		if err == nil || err.Error() != want {
+			t.Fatalf("unexpected error for %q. want=%q have=%v", params, want, err)
+		}
+	}
+}
+
+func TestNonPredicateRepos(t *testing.T) {
+	plan, err := Pipeline(InitRegexp(`repo:foo repo:contains.file(bar) repo:has.topic(payments) -repo:baz`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Q(nonPredicateRepos(plan[0])).String()
+	want := `"repo:foo" "repo:has.topic(payments)" "-repo:baz"`
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
 func TestParseAsPredicate(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -197,6 +197,12 @@ func (b Basic) IsCaseSensitive() bool {
 	return Q(ToNodes(b.Parameters)).IsCaseSensitive()
 }
 
+// IncludeSubmodules returns whether unindexed search should search the
+// contents of submodules at their pinned commits.
+func (b Basic) IncludeSubmodules() bool {
+	return Q(ToNodes(b.Parameters)).BoolValue(FieldSubmodules)
+}
+
 func (b Basic) Index() YesNoOnly {
 	v := Q(ToNodes(b.Parameters)).yesNoOnlyValue(FieldIndex)
 	if v == nil {
@@ -326,8 +332,13 @@ func (q Q) IsCaseSensitive() bool {
 	return q.BoolValue("case")
 }
 
+// Repositories returns the values of the repo: filters of the query, skipping
+// predicates.
 func (q Q) Repositories() (repos []string, negatedRepos []string) {
-	VisitField(q, FieldRepo, func(value string, negated bool, _ Annotation) {
+	VisitField(q, FieldRepo, func(value string, negated bool, annotation Annotation) {
+		if annotation.Labels.IsSet(IsPredicate) {
+			return
+		}
 		if negated {
 			negatedRepos = append(negatedRepos, value)
 			return
@@ -367,7 +378,8 @@ func (q Q) valueToTypedValue(field, value string, label labels) []*Value {
 		return []*Value{{String: &value}}
 
 	case
-		FieldCase:
+		FieldCase,
+		FieldSubmodules:
 		b, _ := parseBool(value)
 		return []*Value{{Bool: &b}}
 
@@ -118,3 +118,18 @@ func VisitField(nodes []Node, field string, callback func(value string, negated
 	visitor := &FieldVisitor{callback: callback, field: field}
 	visitor.VisitNodes(visitor, nodes)
 }
+
+// VisitPredicate convenience function that calls callback on all predicates
+// of the field argument. callback supplies the predicate with its parameters
+// parsed. It assumes the predicates are validated prior.
+func VisitPredicate(nodes []Node, field string, callback func(predicate Predicate)) {
+	VisitField(nodes, field, func(value string, _ bool, annotation Annotation) {
+		if !annotation.Labels.IsSet(IsPredicate) {
+			return
+		}
+		name, params := ParseAsPredicate(value)
+		predicate := DefaultPredicateRegistry.Get(field, name)
+		_ = predicate.ParseParams(params)
+		callback(predicate)
+	})
+}
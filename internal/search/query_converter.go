@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/go-enry/go-enry/v2"
 	"github.com/go-enry/go-enry/v2/data"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
@@ -110,10 +111,12 @@ const (
 	Batch
 )
 
-// ToTextPatternInfo converts a an atomic query to internal values that drive
-// text search. An atomic query is a Basic query where the Pattern is either
-// nil, or comprises only one Pattern node (hence, an atom, and not an
-// expression). See TextPatternInfo for the values it computes and populates.
+// ToTextPatternInfo converts a Basic query to internal values that drive text
+// search. If the Pattern of the query is a single Pattern node, it populates
+// Pattern and IsNegated. If it is an expression of and/or operators, it
+// populates PatternExpression instead, so that backends evaluate the
+// expression per file (see ToPatternExpression). See TextPatternInfo for the
+// values it computes and populates.
 func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *TextPatternInfo {
 	q = transform(q)
 	// Handle file: and -file: filters.
@@ -152,14 +155,25 @@ func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *Te
 		negated = p.Negated
 	}
 
+	var expression *PatternNode
+	if _, ok := q.Pattern.(query.Operator); ok {
+		expression, ok = ToPatternExpression(q)
+		if ok {
+			// Like literal atoms, literal patterns of an expression are
+			// escaped regular expressions.
+			isRegexp = true
+		}
+	}
+
 	return &TextPatternInfo{
 		// Values dependent on pattern atom.
-		IsRegExp:        isRegexp,
-		IsStructuralPat: q.IsStructural(),
-		IsCaseSensitive: q.IsCaseSensitive(),
-		FileMatchLimit:  int32(count),
-		Pattern:         pattern,
-		IsNegated:       negated,
+		IsRegExp:          isRegexp,
+		IsStructuralPat:   q.IsStructural(),
+		IsCaseSensitive:   q.IsCaseSensitive(),
+		FileMatchLimit:    int32(count),
+		Pattern:           pattern,
+		IsNegated:         negated,
+		PatternExpression: expression,
 
 		// Values dependent on parameters.
 		IncludePatterns:              filesInclude,
@@ -171,9 +185,67 @@ func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *Te
 		CombyRule:                    q.FindValue(query.FieldCombyRule),
 		Index:                        q.Index(),
 		Select:                       selector,
+		IncludeSubmodules:            q.IncludeSubmodules(),
 	}
 }
 
+// ToPatternExpression converts the pattern of a query to a boolean pattern
+// expression that backends can evaluate per file. The patterns of the
+// expression are regular expressions; literal patterns are escaped. It returns
+// false if the pattern contains nodes other than (possibly negated) literal or
+// regexp patterns combined with and or or operators.
+func ToPatternExpression(q query.Basic) (*PatternNode, bool) {
+	if q.Pattern == nil {
+		return nil, false
+	}
+
+	var convert func(node query.Node) (*PatternNode, bool)
+	convert = func(node query.Node) (*PatternNode, bool) {
+		switch n := node.(type) {
+		case query.Pattern:
+			value := n.Value
+			switch {
+			case n.Annotation.Labels.IsSet(query.Literal):
+				value = regexp.QuoteMeta(value)
+			case n.Annotation.Labels.IsSet(query.Regexp):
+				// Used as is.
+			default:
+				return nil, false
+			}
+			leaf := &PatternNode{Kind: PatternNodePattern, Value: value}
+			if n.Negated {
+				return &PatternNode{Kind: PatternNodeNot, Operands: []*PatternNode{leaf}}, true
+			}
+			return leaf, true
+
+		case query.Operator:
+			var kind PatternNodeKind
+			switch n.Kind {
+			case query.And:
+				kind = PatternNodeAnd
+			case query.Or:
+				kind = PatternNodeOr
+			default:
+				return nil, false
+			}
+
+			operands := make([]*PatternNode, 0, len(n.Operands))
+			for _, operand := range n.Operands {
+				converted, ok := convert(operand)
+				if !ok {
+					return nil, false
+				}
+				operands = append(operands, converted)
+			}
+			return &PatternNode{Kind: kind, Operands: operands}, true
+		}
+
+		return nil, false
+	}
+
+	return convert(q.Pattern)
+}
+
 func TimeoutDuration(b query.Basic) time.Duration {
 	d := DefaultTimeout
 	maxTimeout := time.Duration(SearchLimits(conf.Get()).MaxTimeoutSeconds) * time.Second
@@ -228,12 +300,51 @@ func parseRe(pattern string, filenameOnly bool, contentOnly bool, queryIsCaseSen
 	}, nil
 }
 
+// patternExpressionToZoektQuery converts a boolean pattern expression to a
+// single zoekt query, so that zoekt evaluates the expression for each file
+// rather than returning a result set per pattern.
+func patternExpressionToZoektQuery(p *TextPatternInfo, n *PatternNode) (zoekt.Q, error) {
+	switch n.Kind {
+	case PatternNodePattern:
+		fileNameOnly := p.PatternMatchesPath && !p.PatternMatchesContent
+		contentOnly := !p.PatternMatchesPath && p.PatternMatchesContent
+		return parseRe(n.Value, fileNameOnly, contentOnly, p.IsCaseSensitive)
+	case PatternNodeNot:
+		if len(n.Operands) != 1 {
+			return nil, errors.Errorf("not expression requires exactly one operand, got %d", len(n.Operands))
+		}
+		child, err := patternExpressionToZoektQuery(p, n.Operands[0])
+		if err != nil {
+			return nil, err
+		}
+		return &zoekt.Not{Child: child}, nil
+	}
+
+	operands := make([]zoekt.Q, 0, len(n.Operands))
+	for _, operand := range n.Operands {
+		q, err := patternExpressionToZoektQuery(p, operand)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, q)
+	}
+	if n.Kind == PatternNodeAnd {
+		return zoekt.NewAnd(operands...), nil
+	}
+	return zoekt.NewOr(operands...), nil
+}
+
 func QueryToZoektQuery(p *TextPatternInfo, typ IndexedRequestType) (zoekt.Q, error) {
 	var and []zoekt.Q
 
 	var q zoekt.Q
 	var err error
-	if p.IsRegExp {
+	if p.PatternExpression != nil {
+		q, err = patternExpressionToZoektQuery(p, p.PatternExpression)
+		if err != nil {
+			return nil, err
+		}
+	} else if p.IsRegExp {
 		fileNameOnly := p.PatternMatchesPath && !p.PatternMatchesContent
 		contentOnly := !p.PatternMatchesPath && p.PatternMatchesContent
 		q, err = parseRe(p.Pattern, fileNameOnly, contentOnly, p.IsCaseSensitive)
@@ -250,7 +361,7 @@ func QueryToZoektQuery(p *TextPatternInfo, typ IndexedRequestType) (zoekt.Q, err
 		}
 	}
 
-	if p.IsNegated {
+	if p.IsNegated && p.PatternExpression == nil {
 		q = &zoekt.Not{Child: q}
 	}
 
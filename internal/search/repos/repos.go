@@ -59,6 +59,23 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 		tr.Finish()
 	}()
 
+	searchContext, err := searchcontexts.ResolveSearchContextSpec(ctx, r.DB, op.SearchContextSpec)
+	if err != nil {
+		return Resolved{}, err
+	}
+
+	if searchcontexts.IsQueryDefinedSearchContext(searchContext) {
+		contextQuery, err := searchcontexts.ParseSearchContextQuery(searchContext.Query)
+		if err != nil {
+			return Resolved{}, errors.Wrapf(err, "search context %q", searchcontexts.GetSearchContextSpec(searchContext))
+		}
+		var ok bool
+		if op, ok = withSearchContextQuery(op, contextQuery); !ok {
+			tr.LazyPrintf("search context query conflicts with query")
+			return Resolved{}, nil
+		}
+	}
+
 	includePatterns := op.RepoFilters
 	if includePatterns != nil {
 		// Copy to avoid race condition.
@@ -100,14 +117,11 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 		return Resolved{}, err
 	}
 
-	searchContext, err := searchcontexts.ResolveSearchContextSpec(ctx, r.DB, op.SearchContextSpec)
-	if err != nil {
-		return Resolved{}, err
-	}
-
 	var searchableRepos []types.RepoName
 
-	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 && !query.HasTypeRepo(op.Query) && searchcontexts.IsGlobalSearchContext(searchContext) {
+	hasMetadataFilters := len(op.HasTopics) > 0 || len(op.HasKVPs) > 0
+
+	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 && !hasMetadataFilters && !query.HasTypeRepo(op.Query) && searchcontexts.IsGlobalSearchContext(searchContext) {
 		start := time.Now()
 		searchableRepos, err = searchableRepositories(ctx, r.SearchableReposFunc, excludePatterns)
 		if err != nil {
@@ -142,10 +156,21 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 			OnlyArchived: op.OnlyArchived,
 			NoPrivate:    op.Visibility == query.Public,
 			OnlyPrivate:  op.Visibility == query.Private,
+			Topics:       op.HasTopics,
+		}
+
+		for _, kvp := range op.HasKVPs {
+			options.KVPFilters = append(options.KVPFilters, database.RepoKVPFilter{
+				Key:   kvp.Key,
+				Value: kvp.Value,
+			})
 		}
 
 		if searchContext.ID != 0 {
-			options.SearchContextID = searchContext.ID
+			// The repo filters of query-defined search contexts were already added to the options.
+			if !searchcontexts.IsQueryDefinedSearchContext(searchContext) {
+				options.SearchContextID = searchContext.ID
+			}
 		} else if searchContext.NamespaceUserID != 0 {
 			options.UserID = searchContext.NamespaceUserID
 			options.IncludeUserPublicRepos = true
@@ -183,9 +208,10 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 	var missingRepoRevs []*search.RepositoryRevisions
 	tr.LazyPrintf("Associate/validate revs - start")
 
-	// For auto-defined search contexts we only search the main branch
+	// For auto-defined search contexts we only search the main branch. Query-defined search
+	// contexts specify revisions in their repo: filters, which are handled like those of the query.
 	var searchContextRepositoryRevisions []*search.RepositoryRevisions
-	if !searchcontexts.IsAutoDefinedSearchContext(searchContext) {
+	if !searchcontexts.IsAutoDefinedSearchContext(searchContext) && !searchcontexts.IsQueryDefinedSearchContext(searchContext) {
 		searchContextRepositoryRevisions, err = searchcontexts.GetRepositoryRevisions(ctx, r.DB, searchContext.ID)
 		if err != nil {
 			return Resolved{}, err
@@ -293,6 +319,46 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 	}, err
 }
 
+// withSearchContextQuery returns op restricted to the repositories matched by the query of a
+// query-defined search context. Repository filters of both queries must match. Fork and archived
+// filters of the search context replace the defaults of op unless they are set in the query of op,
+// in which case both must match. It returns false if the queries can't match any repository.
+func withSearchContextQuery(op search.RepoOptions, contextQuery query.Q) (search.RepoOptions, bool) {
+	repoFilters, minusRepoFilters := contextQuery.Repositories()
+	op.RepoFilters = append(append([]string{}, op.RepoFilters...), repoFilters...)
+	op.MinusRepoFilters = append(append([]string{}, op.MinusRepoFilters...), minusRepoFilters...)
+
+	userForkSet := op.Query != nil && op.Query.Fork() != nil
+	if fork := contextQuery.Fork(); fork != nil {
+		if !userForkSet {
+			op.NoForks, op.OnlyForks = false, false
+		}
+		op.NoForks = op.NoForks || *fork == query.No
+		op.OnlyForks = op.OnlyForks || *fork == query.Only
+	}
+
+	userArchivedSet := op.Query != nil && op.Query.Archived() != nil
+	if archived := contextQuery.Archived(); archived != nil {
+		if !userArchivedSet {
+			op.NoArchived, op.OnlyArchived = false, false
+		}
+		op.NoArchived = op.NoArchived || *archived == query.No
+		op.OnlyArchived = op.OnlyArchived || *archived == query.Only
+	}
+
+	if visibilityStr, _ := contextQuery.StringValue(query.FieldVisibility); visibilityStr != "" {
+		visibility := query.ParseVisibility(visibilityStr)
+		switch {
+		case op.Visibility == query.Any || op.Visibility == "":
+			op.Visibility = visibility
+		case visibility != query.Any && visibility != op.Visibility:
+			return op, false
+		}
+	}
+
+	return op, true
+}
+
 // ExactlyOneRepo returns whether exactly one repo: literal field is specified and
 // delineated by regex anchors ^ and $. This function helps determine whether we
 // should return results for a single repo regardless of whether it is a fork or
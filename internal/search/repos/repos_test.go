@@ -10,6 +10,7 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/zoekt"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
@@ -584,3 +585,183 @@ func TestResolveRepositoriesWithSearchContext(t *testing.T) {
 		t.Errorf("got repository revisions %+v, want %+v", resolved.RepoRevs, wantRepositoryRevisions)
 	}
 }
+
+func TestResolveRepositoriesWithQuerySearchContext(t *testing.T) {
+	db := dbtest.NewDB(t, *dsn)
+	searchContext := &types.SearchContext{ID: 1, Name: "searchcontext", Query: "repo:^example\\.com/ rev:branch-1 -repo:archive archived:no"}
+	repoA := types.RepoName{ID: 1, Name: "example.com/a"}
+
+	git.Mocks.ResolveRevision = func(spec string, opt git.ResolveRevisionOptions) (api.CommitID, error) {
+		return api.CommitID(spec), nil
+	}
+	database.Mocks.Repos.ListRepoNames = func(ctx context.Context, op database.ReposListOptions) ([]types.RepoName, error) {
+		if op.SearchContextID != 0 {
+			t.Fatalf("got search context ID %d, want 0", op.SearchContextID)
+		}
+		if want := []string{"a", "^example\\.com/"}; !reflect.DeepEqual(op.IncludePatterns, want) {
+			t.Fatalf("got include patterns %q, want %q", op.IncludePatterns, want)
+		}
+		if want := "archive"; op.ExcludePattern != want {
+			t.Fatalf("got exclude pattern %q, want %q", op.ExcludePattern, want)
+		}
+		if !op.NoArchived {
+			t.Fatal("want archived repositories to be excluded")
+		}
+		return []types.RepoName{repoA}, nil
+	}
+	database.Mocks.Repos.Count = func(ctx context.Context, op database.ReposListOptions) (int, error) { return 1, nil }
+	database.Mocks.SearchContexts.GetSearchContext = func(ctx context.Context, opts database.GetSearchContextOptions) (*types.SearchContext, error) {
+		return searchContext, nil
+	}
+	database.Mocks.SearchContexts.GetSearchContextRepositoryRevisions = func(ctx context.Context, searchContextID int64) ([]*types.SearchContextRepositoryRevisions, error) {
+		t.Fatal("query-defined search contexts have no repository revisions")
+		return nil, nil
+	}
+	defer func() {
+		git.Mocks.ResolveRevision = nil
+		database.Mocks.Repos.ListRepoNames = nil
+		database.Mocks.Repos.Count = nil
+		database.Mocks.SearchContexts.GetSearchContext = nil
+		database.Mocks.SearchContexts.GetSearchContextRepositoryRevisions = nil
+	}()
+
+	queryInfo, err := query.ParseLiteral("repo:a foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := search.RepoOptions{
+		Query:             queryInfo,
+		RepoFilters:       []string{"a"},
+		SearchContextSpec: "searchcontext",
+	}
+	repositoryResolver := &Resolver{DB: db}
+	resolved, err := repositoryResolver.Resolve(context.Background(), op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRepositoryRevisions := []*search.RepositoryRevisions{
+		{Repo: repoA, Revs: stringSliceToRevisionSpecifiers([]string{"branch-1"})},
+	}
+	if !reflect.DeepEqual(resolved.RepoRevs, wantRepositoryRevisions) {
+		t.Errorf("got repository revisions %+v, want %+v", resolved.RepoRevs, wantRepositoryRevisions)
+	}
+}
+
+func TestResolveRepositoriesWithMetadataPredicates(t *testing.T) {
+	db := dbtest.NewDB(t, *dsn)
+	repoA := types.RepoName{ID: 1, Name: "example.com/a"}
+	teamX := "team-x"
+
+	database.Mocks.Repos.ListRepoNames = func(ctx context.Context, op database.ReposListOptions) ([]types.RepoName, error) {
+		if want := []string{"payments"}; !reflect.DeepEqual(op.Topics, want) {
+			t.Fatalf("got topics %q, want %q", op.Topics, want)
+		}
+		if want := []database.RepoKVPFilter{{Key: "owner", Value: &teamX}, {Key: "deprecated"}}; !reflect.DeepEqual(op.KVPFilters, want) {
+			t.Fatalf("got key-value pair filters %+v, want %+v", op.KVPFilters, want)
+		}
+		return []types.RepoName{repoA}, nil
+	}
+	database.Mocks.Repos.Count = func(ctx context.Context, op database.ReposListOptions) (int, error) { return 1, nil }
+	defer func() {
+		database.Mocks.Repos.ListRepoNames = nil
+		database.Mocks.Repos.Count = nil
+	}()
+
+	queryInfo, err := query.ParseLiteral("repo:has.topic(payments) repo:has(owner:team-x) repo:has(deprecated) foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := search.RepoOptions{
+		Query:     queryInfo,
+		HasTopics: []string{"payments"},
+		HasKVPs:   []query.RepoHasKVPPredicate{{Key: "owner", Value: &teamX}, {Key: "deprecated"}},
+	}
+	repositoryResolver := &Resolver{DB: db}
+	resolved, err := repositoryResolver.Resolve(context.Background(), op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRepositoryRevisions := []*search.RepositoryRevisions{
+		{Repo: repoA, Revs: []search.RevisionSpecifier{{RevSpec: ""}}},
+	}
+	if !reflect.DeepEqual(resolved.RepoRevs, wantRepositoryRevisions) {
+		t.Errorf("got repository revisions %+v, want %+v", resolved.RepoRevs, wantRepositoryRevisions)
+	}
+}
+
+func TestWithSearchContextQuery(t *testing.T) {
+	parse := func(t *testing.T, q string) query.Q {
+		t.Helper()
+		parsed, err := query.ParseRegexp(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name         string
+		op           search.RepoOptions
+		contextQuery string
+		want         search.RepoOptions
+		wantOK       bool
+	}{
+		{
+			name:         "repo filters are combined",
+			op:           search.RepoOptions{RepoFilters: []string{"a"}, MinusRepoFilters: []string{"b"}},
+			contextQuery: "repo:c -repo:d",
+			want:         search.RepoOptions{RepoFilters: []string{"a", "c"}, MinusRepoFilters: []string{"b", "d"}},
+			wantOK:       true,
+		},
+		{
+			name:         "context replaces default fork setting",
+			op:           search.RepoOptions{Query: parse(t, "foo"), NoForks: true},
+			contextQuery: "fork:yes",
+			want:         search.RepoOptions{},
+			wantOK:       true,
+		},
+		{
+			name:         "explicit fork setting is intersected",
+			op:           search.RepoOptions{Query: parse(t, "fork:only foo"), OnlyForks: true},
+			contextQuery: "fork:no",
+			want:         search.RepoOptions{NoForks: true, OnlyForks: true},
+			wantOK:       true,
+		},
+		{
+			name:         "context sets visibility",
+			op:           search.RepoOptions{},
+			contextQuery: "visibility:private",
+			want:         search.RepoOptions{Visibility: query.Private},
+			wantOK:       true,
+		},
+		{
+			name:         "file filters are ignored",
+			op:           search.RepoOptions{Query: parse(t, "foo"), NoArchived: true},
+			contextQuery: `repo:^github\.com/ourorg/ lang:go -repo:archive archived:no`,
+			want:         search.RepoOptions{RepoFilters: []string{`^github\.com/ourorg/`}, MinusRepoFilters: []string{"archive"}, NoArchived: true},
+			wantOK:       true,
+		},
+		{
+			name:         "conflicting visibility",
+			op:           search.RepoOptions{Visibility: query.Public},
+			contextQuery: "visibility:private",
+			wantOK:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := withSearchContextQuery(tt.op, parse(t, tt.contextQuery))
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			got.Query = nil
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected options (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
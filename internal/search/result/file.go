@@ -74,6 +74,11 @@ func (fm *FileMatch) Select(selectPath filter.SelectPath) Match {
 			ID:   fm.Repo.ID,
 		}
 	case filter.File:
+		if len(selectPath) > 1 && selectPath[1] == filter.Owners {
+			// Owners are looked up in CODEOWNERS files, which is done before
+			// selecting. See internal/search/ownership.
+			return nil
+		}
 		fm.LineMatches = nil
 		fm.Symbols = nil
 		if len(selectPath) > 1 && selectPath[1] == "directory" {
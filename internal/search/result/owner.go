@@ -0,0 +1,58 @@
+package result
+
+import (
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/filter"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// OwnerMatch is a code owner of files matched in a repository, as selected by
+// select:file.owners.
+type OwnerMatch struct {
+	// Owner is the owner as written in the CODEOWNERS file of the repository,
+	// such as @org/team, @user or an email address.
+	Owner string
+
+	Repo     types.RepoName
+	CommitID api.CommitID
+}
+
+func (om *OwnerMatch) RepoName() types.RepoName {
+	return om.Repo
+}
+
+func (om *OwnerMatch) Limit(limit int) int {
+	// Always represents one result and limit > 0 so we just return limit - 1.
+	return limit - 1
+}
+
+func (om *OwnerMatch) ResultCount() int {
+	return 1
+}
+
+func (om *OwnerMatch) Select(path filter.SelectPath) Match {
+	switch path.Root() {
+	case filter.Repository:
+		return &RepoMatch{
+			Name: om.Repo.Name,
+			ID:   om.Repo.ID,
+		}
+	case filter.File:
+		if len(path) > 1 && path[1] == filter.Owners {
+			return om
+		}
+	}
+	return nil
+}
+
+// Key deduplicates owner matches by repository, regardless of the commit the
+// files they own were matched in.
+func (om *OwnerMatch) Key() Key {
+	return Key{
+		Repo:     om.Repo.Name,
+		Path:     om.Owner,
+		TypeRank: rankOwnerMatch,
+	}
+}
+
+func (om *OwnerMatch) searchResultMarker() {}
@@ -60,6 +60,7 @@ func SearchRepositories(ctx context.Context, args *search.TextParameters, limit
 		query.FieldRepoHasCommitAfter: {},
 		query.FieldPatternType:        {},
 		query.FieldSelect:             {},
+		query.FieldSubmodules:         {},
 	}
 	// Don't return repo results if the search contains fields that aren't on the allowlist.
 	// Matching repositories based whether they contain files at a certain path (etc.) is not yet implemented.
@@ -70,16 +71,25 @@ func SearchRepositories(ctx context.Context, args *search.TextParameters, limit
 		}
 	}
 
-	patternRe := args.PatternInfo.Pattern
-	if !args.Query.IsCaseSensitive() {
-		patternRe = "(?i)" + patternRe
-	}
+	var pattern stringMatcher
+	if args.PatternInfo.PatternExpression != nil {
+		tr.LogFields(
+			otlog.String("pattern", args.PatternInfo.PatternExpression.String()),
+			otlog.Int32("limit", limit))
+
+		pattern, err = compilePatternExpression(args.PatternInfo.PatternExpression, args.Query.IsCaseSensitive())
+	} else {
+		patternRe := args.PatternInfo.Pattern
+		if !args.Query.IsCaseSensitive() {
+			patternRe = "(?i)" + patternRe
+		}
 
-	tr.LogFields(
-		otlog.String("pattern", patternRe),
-		otlog.Int32("limit", limit))
+		tr.LogFields(
+			otlog.String("pattern", patternRe),
+			otlog.Int32("limit", limit))
 
-	pattern, err := regexp.Compile(patternRe)
+		pattern, err = regexp.Compile(patternRe)
+	}
 	if err != nil {
 		return err
 	}
@@ -140,7 +150,68 @@ func repoRevsToRepoMatches(ctx context.Context, repos []*search.RepositoryRevisi
 	return matches
 }
 
-func matchRepos(pattern *regexp.Regexp, resolved []*search.RepositoryRevisions, results chan<- []*search.RepositoryRevisions) {
+// stringMatcher reports whether a repository name matches the pattern of a
+// query.
+type stringMatcher interface {
+	MatchString(string) bool
+}
+
+// patternExpressionMatcher matches repository names that satisfy an and/or
+// pattern expression.
+type patternExpressionMatcher struct {
+	kind     search.PatternNodeKind
+	re       *regexp.Regexp // non-nil for pattern leaves
+	operands []*patternExpressionMatcher
+}
+
+func compilePatternExpression(n *search.PatternNode, caseSensitive bool) (*patternExpressionMatcher, error) {
+	m := &patternExpressionMatcher{kind: n.Kind}
+	if n.Kind == search.PatternNodePattern {
+		expr := n.Value
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		m.re = re
+		return m, nil
+	}
+	for _, operand := range n.Operands {
+		om, err := compilePatternExpression(operand, caseSensitive)
+		if err != nil {
+			return nil, err
+		}
+		m.operands = append(m.operands, om)
+	}
+	return m, nil
+}
+
+func (m *patternExpressionMatcher) MatchString(s string) bool {
+	switch m.kind {
+	case search.PatternNodePattern:
+		return m.re.MatchString(s)
+	case search.PatternNodeNot:
+		return !m.operands[0].MatchString(s)
+	case search.PatternNodeAnd:
+		for _, operand := range m.operands {
+			if !operand.MatchString(s) {
+				return false
+			}
+		}
+		return true
+	case search.PatternNodeOr:
+		for _, operand := range m.operands {
+			if operand.MatchString(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchRepos(pattern stringMatcher, resolved []*search.RepositoryRevisions, results chan<- []*search.RepositoryRevisions) {
 	/*
 		goos: linux
 		goarch: amd64
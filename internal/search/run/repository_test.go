@@ -343,3 +343,36 @@ func mkRepos(names ...string) []types.RepoName {
 	}
 	return repos
 }
+
+func TestCompilePatternExpression(t *testing.T) {
+	expr := &search.PatternNode{Kind: search.PatternNodeAnd, Operands: []*search.PatternNode{
+		{Kind: search.PatternNodePattern, Value: "foo"},
+		{Kind: search.PatternNodeOr, Operands: []*search.PatternNode{
+			{Kind: search.PatternNodePattern, Value: "bar"},
+			{Kind: search.PatternNodeNot, Operands: []*search.PatternNode{
+				{Kind: search.PatternNodePattern, Value: "baz"},
+			}},
+		}},
+	}}
+
+	m, err := compilePatternExpression(expr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"github.com/foo/bar": true,
+		"github.com/FOO/qux": true,
+		"github.com/foo/baz": false,
+		"github.com/bar/qux": false,
+	} {
+		if got := m.MatchString(name); got != want {
+			t.Errorf("unexpected match for %q. want=%v have=%v", name, want, got)
+		}
+	}
+
+	if m, err := compilePatternExpression(expr, true); err != nil {
+		t.Fatal(err)
+	} else if m.MatchString("github.com/FOO/qux") {
+		t.Error("expected case sensitive expression not to match")
+	}
+}
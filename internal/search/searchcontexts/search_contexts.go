@@ -13,6 +13,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
@@ -22,6 +23,7 @@ const (
 	maxSearchContextNameLength        = 32
 	maxSearchContextDescriptionLength = 1024
 	maxRevisionLength                 = 255
+	maxSearchContextQueryLength       = 1024
 )
 
 var (
@@ -145,6 +147,107 @@ func validateSearchContextRepositoryRevisions(repositoryRevisions []*types.Searc
 	return nil
 }
 
+// searchContextQueryFields are the fields allowed in the query of a query-defined search context. The
+// repository filters are resolved together with the repository filters of the user query, and the file
+// filters (see searchContextFileFilterFields) are added to the user query. rev: is allowed because it is
+// merged into the repo: filters.
+var searchContextQueryFields = map[string]struct{}{
+	query.FieldRepo:       {},
+	query.FieldRev:        {},
+	query.FieldFork:       {},
+	query.FieldArchived:   {},
+	query.FieldVisibility: {},
+	query.FieldLang:       {},
+	query.FieldFile:       {},
+}
+
+// searchContextFileFilterFields are the fields of a search context query which restrict the files
+// searched rather than the repositories.
+var searchContextFileFilterFields = map[string]struct{}{
+	query.FieldLang: {},
+	query.FieldFile: {},
+}
+
+// ParseSearchContextQuery parses the query of a query-defined search context, such as
+// `repo:^github\.com/ourorg/ lang:go -repo:archive archived:no`.
+func ParseSearchContextQuery(contextQuery string) (query.Q, error) {
+	plan, err := query.Pipeline(query.InitRegexp(contextQuery))
+	if err != nil {
+		return nil, err
+	}
+	if len(plan) == 0 || (len(plan) == 1 && plan[0].Pattern == nil && len(plan[0].Parameters) == 0) {
+		return nil, errors.New("search context query must contain at least one filter")
+	}
+	if len(plan) > 1 {
+		return nil, errors.New("search context query must not contain 'or' expressions")
+	}
+
+	basic := plan[0]
+	if basic.Pattern != nil {
+		return nil, errors.Errorf("search context query must not contain a search pattern: %s", query.StringHuman([]query.Node{basic.Pattern}))
+	}
+	for _, parameter := range basic.Parameters {
+		if _, ok := searchContextQueryFields[parameter.Field]; !ok {
+			return nil, errors.Errorf("search context query must only contain repo:, rev:, fork:, archived:, visibility:, lang:, and file: filters, got %s:", parameter.Field)
+		}
+		if parameter.Annotation.Labels.IsSet(query.IsPredicate) {
+			return nil, errors.Errorf("search context query must not contain predicates, got %s:%s", parameter.Field, parameter.Value)
+		}
+	}
+	return basic.ToParseTree(), nil
+}
+
+// ApplySearchContextQueries adds the file filters of the query-defined search contexts used in plan,
+// such as lang:go, to the queries they are used in. The repository filters of the search contexts are
+// applied when resolving repositories. Search contexts which can't be resolved are left unchanged for
+// the resolution of repositories to report.
+func ApplySearchContextQueries(ctx context.Context, db dbutil.DB, plan query.Plan) query.Plan {
+	return query.MapPlan(plan, func(b query.Basic) query.Basic {
+		searchContextSpec := b.FindValue(query.FieldContext)
+		if ParseSearchContextSpec(searchContextSpec).SearchContextName == "" || IsGlobalSearchContextSpec(searchContextSpec) {
+			// Only saved search contexts can be query-defined.
+			return b
+		}
+		searchContext, err := ResolveSearchContextSpec(ctx, db, searchContextSpec)
+		if err != nil || !IsQueryDefinedSearchContext(searchContext) {
+			return b
+		}
+		contextQuery, err := ParseSearchContextQuery(searchContext.Query)
+		if err != nil {
+			return b
+		}
+		return withSearchContextFileFilters(b, contextQuery)
+	})
+}
+
+// withSearchContextFileFilters returns b with the file filters of the search context query added. The
+// file filters of both queries must match.
+func withSearchContextFileFilters(b query.Basic, contextQuery query.Q) query.Basic {
+	parameters := append([]query.Parameter{}, b.Parameters...)
+	query.VisitParameter(contextQuery, func(field, value string, negated bool, annotation query.Annotation) {
+		if _, ok := searchContextFileFilterFields[field]; ok {
+			parameters = append(parameters, query.Parameter{Field: field, Value: value, Negated: negated, Annotation: annotation})
+		}
+	})
+	return b.MapParameters(parameters)
+}
+
+func validateSearchContextQuery(contextQuery string, repositoryRevisions []*types.SearchContextRepositoryRevisions) error {
+	if contextQuery == "" {
+		return nil
+	}
+	if len(repositoryRevisions) > 0 {
+		return errors.New("search context query and repositories are mutually exclusive")
+	}
+	if len(contextQuery) > maxSearchContextQueryLength {
+		return errors.Errorf("search context query exceeds maximum allowed length (%d)", maxSearchContextQueryLength)
+	}
+	if _, err := ParseSearchContextQuery(contextQuery); err != nil {
+		return errors.Wrap(err, "invalid search context query")
+	}
+	return nil
+}
+
 func validateSearchContextDoesNotExist(ctx context.Context, db dbutil.DB, searchContext *types.SearchContext) error {
 	_, err := database.SearchContexts(db).GetSearchContext(ctx, database.GetSearchContextOptions{
 		Name:            searchContext.Name,
@@ -186,6 +289,11 @@ func CreateSearchContextWithRepositoryRevisions(ctx context.Context, db dbutil.D
 		return nil, err
 	}
 
+	err = validateSearchContextQuery(searchContext.Query, repositoryRevisions)
+	if err != nil {
+		return nil, err
+	}
+
 	err = validateSearchContextDoesNotExist(ctx, db, searchContext)
 	if err != nil {
 		return nil, err
@@ -223,6 +331,11 @@ func UpdateSearchContextWithRepositoryRevisions(ctx context.Context, db dbutil.D
 		return nil, err
 	}
 
+	err = validateSearchContextQuery(searchContext.Query, repositoryRevisions)
+	if err != nil {
+		return nil, err
+	}
+
 	searchContext, err = database.SearchContexts(db).UpdateSearchContextWithRepositoryRevisions(ctx, searchContext, repositoryRevisions)
 	if err != nil {
 		return nil, err
@@ -277,6 +390,12 @@ func IsAutoDefinedSearchContext(searchContext *types.SearchContext) bool {
 	return searchContext.ID == 0
 }
 
+// IsQueryDefinedSearchContext returns true if the repositories of the search context are defined by a
+// query rather than by a list of repository revisions.
+func IsQueryDefinedSearchContext(searchContext *types.SearchContext) bool {
+	return searchContext.Query != ""
+}
+
 func IsInstanceLevelSearchContext(searchContext *types.SearchContext) bool {
 	return searchContext.NamespaceUserID == 0 && searchContext.NamespaceOrgID == 0
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
@@ -505,3 +506,121 @@ func TestDeletingAutoDefinedSearchContext(t *testing.T) {
 		t.Fatalf("wanted error containing %s, got %s", wantErr, err)
 	}
 }
+
+func TestSearchContextQueryValidation(t *testing.T) {
+	repositoryRevisions := []*types.SearchContextRepositoryRevisions{{Repo: types.RepoName{ID: 1, Name: "example.com/a"}, Revisions: []string{"main"}}}
+
+	tests := []struct {
+		name                string
+		query               string
+		repositoryRevisions []*types.SearchContextRepositoryRevisions
+		wantErr             string
+	}{
+		{name: "no query", repositoryRevisions: repositoryRevisions},
+		{name: "repository filters", query: `repo:^github\.com/ourorg/ -repo:archive archived:no fork:yes visibility:private`},
+		{name: "repository and file filters", query: `repo:^github\.com/ourorg/ lang:go -repo:archive archived:no`},
+		{name: "negated file filters", query: `repo:^github\.com/ourorg/ file:\.go$ -file:_test\.go$ -lang:markdown`},
+		{name: "revisions", query: `repo:^github\.com/ourorg/a$ rev:main`},
+		{name: "query and repositories", query: "repo:a", repositoryRevisions: repositoryRevisions, wantErr: "search context query and repositories are mutually exclusive"},
+		{name: "empty", query: " ", wantErr: "invalid search context query: search context query must contain at least one filter"},
+		{name: "pattern", query: "repo:a foo", wantErr: "invalid search context query: search context query must not contain a search pattern: foo"},
+		{name: "or", query: "repo:a or repo:b", wantErr: "invalid search context query: search context query must not contain 'or' expressions"},
+		{name: "type filter", query: "repo:a type:diff", wantErr: "invalid search context query: search context query must only contain repo:, rev:, fork:, archived:, visibility:, lang:, and file: filters, got type:"},
+		{name: "context filter", query: "context:@user/ctx", wantErr: "invalid search context query: search context query must only contain repo:, rev:, fork:, archived:, visibility:, lang:, and file: filters, got context:"},
+		{name: "predicate", query: "repo:contains.file(go.mod)", wantErr: "invalid search context query: search context query must not contain predicates, got repo:contains.file(go.mod)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSearchContextQuery(tt.query, tt.repositoryRevisions)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestApplySearchContextQueries(t *testing.T) {
+	db := new(dbtesting.MockDB)
+	database.Mocks.Namespaces.GetByName = func(ctx context.Context, name string) (*database.Namespace, error) {
+		return &database.Namespace{Name: name, User: 1}, nil
+	}
+	database.Mocks.SearchContexts.GetSearchContext = func(ctx context.Context, opts database.GetSearchContextOptions) (*types.SearchContext, error) {
+		if opts.Name == "go-services" {
+			return &types.SearchContext{ID: 1, Name: opts.Name, Query: `repo:^github\.com/ourorg/ lang:go -file:_test\.go$ -repo:archive archived:no`}, nil
+		}
+		return &types.SearchContext{ID: 2, Name: opts.Name}, nil
+	}
+	defer func() {
+		database.Mocks.Namespaces.GetByName = nil
+		database.Mocks.SearchContexts.GetSearchContext = nil
+	}()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "context:@user/go-services foo", want: `context:@user/go-services lang:go -file:_test\.go$ foo`},
+		{query: "context:@user/go-services file:cmd/ foo", want: `context:@user/go-services file:cmd/ lang:go -file:_test\.go$ foo`},
+		{query: "context:@user/other foo", want: "context:@user/other foo"},
+		{query: "context:@user foo", want: "context:@user foo"},
+		{query: "foo", want: "foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			plan, err := query.Pipeline(query.InitLiteral(tt.query))
+			require.NoError(t, err)
+			plan = ApplySearchContextQueries(context.Background(), db, plan)
+			require.Equal(t, tt.want, query.StringHuman(plan.ToParseTree()))
+		})
+	}
+}
+
+func TestSavingAndResolvingQueryDefinedSearchContext(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	internalCtx := actor.WithInternalActor(context.Background())
+	db := dbtesting.GetDB(t)
+
+	user, err := database.Users(db).Create(internalCtx, database.NewUser{Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	const contextQuery = `repo:^github\.com/ourorg/ lang:go -repo:archive archived:no`
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: user.ID})
+	_, err = CreateSearchContextWithRepositoryRevisions(ctx, db, &types.SearchContext{Name: "go-services", NamespaceUserID: user.ID, Query: contextQuery}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+
+	searchContext, err := ResolveSearchContextSpec(ctx, db, "@u/go-services")
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	if searchContext.Query != contextQuery {
+		t.Fatalf("got query %q, expected %q", searchContext.Query, contextQuery)
+	}
+
+	q, err := ParseSearchContextQuery(searchContext.Query)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	repoFilters, minusRepoFilters := q.Repositories()
+	require.Equal(t, []string{`^github\.com/ourorg/`}, repoFilters)
+	require.Equal(t, []string{"archive"}, minusRepoFilters)
+
+	plan, err := query.Pipeline(query.InitLiteral("context:@u/go-services foo"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err)
+	}
+	plan = ApplySearchContextQueries(ctx, db, plan)
+	if lang := plan[0].FindValue(query.FieldLang); lang != "go" {
+		t.Fatalf("got lang %q, expected go", lang)
+	}
+}
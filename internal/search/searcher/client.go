@@ -26,10 +26,12 @@ import (
 
 var (
 	searchDoer, _ = httpcli.NewInternalClientFactory("search").Doer()
-	MockSearch    func(ctx context.Context, repo api.RepoName, repoID api.RepoID, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration, onMatches func([]*protocol.FileMatch)) (limitHit bool, err error)
+	MockSearch    func(ctx context.Context, repo api.RepoName, repoID api.RepoID, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration, onMatches func([]*protocol.FileMatch)) (limitHit bool, missingBlobs int, err error)
 )
 
-// Search searches repo@commit with p.
+// Search searches repo@commit with p. missingBlobs is the number of files of
+// which only the name was searched since gitserver has not fetched their
+// content from a partial clone.
 func Search(
 	ctx context.Context,
 	searcherURLs *endpoint.Map,
@@ -42,7 +44,7 @@ func Search(
 	fetchTimeout time.Duration,
 	indexerEndpoints []string,
 	onMatches func([]*protocol.FileMatch),
-) (limitHit bool, err error) {
+) (limitHit bool, missingBlobs int, err error) {
 	if MockSearch != nil {
 		return MockSearch(ctx, repo, repoID, commit, p, fetchTimeout, onMatches)
 	}
@@ -73,6 +75,7 @@ func Search(
 			IsCaseSensitive:              p.IsCaseSensitive,
 			PathPatternsAreCaseSensitive: p.PathPatternsAreCaseSensitive,
 			IsNegated:                    p.IsNegated,
+			PatternExpression:            toProtocolPatternNode(p.PatternExpression),
 			PatternMatchesContent:        p.PatternMatchesContent,
 			PatternMatchesPath:           p.PatternMatchesPath,
 		},
@@ -84,13 +87,13 @@ func Search(
 	if deadline, ok := ctx.Deadline(); ok {
 		t, err := deadline.MarshalText()
 		if err != nil {
-			return false, err
+			return false, 0, err
 		}
 		r.Deadline = string(t)
 	}
 	body, err := json.Marshal(r)
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 
 	// Searcher caches the file contents for repo@commit since it is
@@ -101,43 +104,43 @@ func Search(
 
 	nodes, err := searcherURLs.Endpoints()
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 
 	urls, err := searcherURLs.GetN(consistentHashKey, len(nodes))
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 
 	for attempt := 0; attempt < 2; attempt++ {
 		url := urls[attempt%len(urls)]
 
 		tr.LazyPrintf("attempt %d: %s", attempt, url)
-		limitHit, err = textSearchStream(ctx, url, body, onMatches)
+		limitHit, missingBlobs, err = textSearchStream(ctx, url, body, onMatches)
 		if err == nil || errcode.IsTimeout(err) {
-			return limitHit, err
+			return limitHit, missingBlobs, err
 		}
 
 		// If we are canceled, return that error.
 		if err = ctx.Err(); err != nil {
-			return false, err
+			return false, 0, err
 		}
 
 		// If not temporary or our last attempt then don't try again.
 		if !errcode.IsTemporary(err) {
-			return false, err
+			return false, 0, err
 		}
 
 		tr.LazyPrintf("transient error %s", err.Error())
 	}
 
-	return false, err
+	return false, 0, err
 }
 
-func textSearchStream(ctx context.Context, url string, body []byte, cb func([]*protocol.FileMatch)) (bool, error) {
+func textSearchStream(ctx context.Context, url string, body []byte, cb func([]*protocol.FileMatch)) (bool, int, error) {
 	req, err := http.NewRequest("GET", url, bytes.NewReader(body))
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 	req = req.WithContext(ctx)
 
@@ -156,15 +159,15 @@ func textSearchStream(ctx context.Context, url string, body []byte, cb func([]*p
 		if ctx.Err() != nil {
 			err = ctx.Err()
 		}
-		return false, errors.Wrap(err, "streaming searcher request failed")
+		return false, 0, errors.Wrap(err, "streaming searcher request failed")
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return false, err
+			return false, 0, err
 		}
-		return false, errors.WithStack(&searcherError{StatusCode: resp.StatusCode, Message: string(body)})
+		return false, 0, errors.WithStack(&searcherError{StatusCode: resp.StatusCode, Message: string(body)})
 	}
 
 	var ed EventDone
@@ -178,15 +181,15 @@ func textSearchStream(ctx context.Context, url string, body []byte, cb func([]*p
 		},
 	}
 	if err := dec.ReadAll(resp.Body); err != nil {
-		return false, err
+		return false, 0, err
 	}
 	if ed.Error != "" {
-		return false, errors.New(ed.Error)
+		return false, 0, errors.New(ed.Error)
 	}
 	if ed.DeadlineHit {
 		err = context.DeadlineExceeded
 	}
-	return ed.LimitHit, err
+	return ed.LimitHit, ed.MissingBlobs, err
 }
 
 type searcherError struct {
@@ -205,3 +208,30 @@ func (e *searcherError) Temporary() bool {
 func (e *searcherError) Error() string {
 	return e.Message
 }
+
+// toProtocolPatternNode converts a pattern expression to its searcher protocol
+// representation.
+func toProtocolPatternNode(n *search.PatternNode) *protocol.PatternNode {
+	if n == nil {
+		return nil
+	}
+
+	var kind string
+	switch n.Kind {
+	case search.PatternNodePattern:
+		kind = protocol.PatternNodePattern
+	case search.PatternNodeAnd:
+		kind = protocol.PatternNodeAnd
+	case search.PatternNodeOr:
+		kind = protocol.PatternNodeOr
+	case search.PatternNodeNot:
+		kind = protocol.PatternNodeNot
+	}
+
+	operands := make([]*protocol.PatternNode, 0, len(n.Operands))
+	for _, operand := range n.Operands {
+		operands = append(operands, toProtocolPatternNode(operand))
+	}
+
+	return &protocol.PatternNode{Kind: kind, Value: n.Value, Operands: operands}
+}
@@ -55,4 +55,9 @@ type EventDone struct {
 	LimitHit    bool   `json:"limit_hit"`
 	DeadlineHit bool   `json:"deadline_hit"`
 	Error       string `json:"error"`
+
+	// MissingBlobs is the number of files of which only the name was
+	// searched since gitserver has not fetched their content from a partial
+	// clone.
+	MissingBlobs int `json:"missing_blobs,omitempty"`
 }
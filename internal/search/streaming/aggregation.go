@@ -0,0 +1,183 @@
+package streaming
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/inventory"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// AggregationMode is the attribute by which SearchAggregation groups matches.
+type AggregationMode string
+
+const (
+	AggregateByRepo         AggregationMode = "repo"
+	AggregateByPath         AggregationMode = "path"
+	AggregateByLanguage     AggregationMode = "lang"
+	AggregateByAuthor       AggregationMode = "author"
+	AggregateByCaptureGroup AggregationMode = "capture_group"
+)
+
+// ParseAggregationMode returns the aggregation mode named s.
+func ParseAggregationMode(s string) (AggregationMode, error) {
+	switch m := AggregationMode(s); m {
+	case AggregateByRepo, AggregateByPath, AggregateByLanguage, AggregateByAuthor, AggregateByCaptureGroup:
+		return m, nil
+	}
+	return "", errors.Errorf("unknown aggregation mode %q", s)
+}
+
+// SearchAggregation counts the matches of a search grouped by an attribute of
+// the matches. Unlike the matches sent to the user, it is updated with every
+// result the search finds.
+type SearchAggregation struct {
+	mode    AggregationMode
+	pattern *regexp.Regexp
+
+	counts map[string]int
+
+	// Dirty is true if the counts changed since the last call to Compute.
+	Dirty bool
+}
+
+// NewSearchAggregation returns an aggregation of matches by mode. For
+// AggregateByCaptureGroup, it groups matches by the value of the first
+// capture group of the regexp pattern of the search in plan.
+func NewSearchAggregation(mode AggregationMode, plan query.Plan) (*SearchAggregation, error) {
+	a := &SearchAggregation{
+		mode:   mode,
+		counts: map[string]int{},
+	}
+	if mode == AggregateByCaptureGroup {
+		pattern, err := captureGroupPattern(plan)
+		if err != nil {
+			return nil, err
+		}
+		a.pattern = pattern
+	}
+	return a, nil
+}
+
+// captureGroupPattern compiles the regexp pattern of plan. Only queries with a
+// single regexp pattern containing a capture group can be aggregated by
+// capture group. Note that the parser joins whitespace-separated terms into a
+// pattern with a group per term, so patterns should match whitespace with \s
+// instead.
+func captureGroupPattern(plan query.Plan) (*regexp.Regexp, error) {
+	if len(plan) != 1 {
+		return nil, errors.New("capture group aggregation requires a query without or-expressions")
+	}
+	b := plan[0]
+	pattern, ok := b.Pattern.(query.Pattern)
+	if !ok || pattern.Negated || !b.IsRegexp() {
+		return nil, errors.New("capture group aggregation requires a single regular expression pattern")
+	}
+
+	expr := pattern.Value
+	if !b.IsCaseSensitive() {
+		expr = "(?i:" + expr + ")"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() == 0 {
+		return nil, errors.New("capture group aggregation requires a pattern with a capture group")
+	}
+	return re, nil
+}
+
+// Update counts the results in event.
+func (a *SearchAggregation) Update(event SearchEvent) {
+	for _, match := range event.Results {
+		a.add(match)
+	}
+}
+
+func (a *SearchAggregation) add(match result.Match) {
+	inc := func(label string, count int) {
+		if label == "" || count == 0 {
+			return
+		}
+		a.counts[label] += count
+		a.Dirty = true
+	}
+
+	switch a.mode {
+	case AggregateByRepo:
+		inc(string(match.RepoName().Name), match.ResultCount())
+
+	case AggregateByPath:
+		if fm, ok := match.(*result.FileMatch); ok {
+			inc(fm.Path, fm.ResultCount())
+		}
+
+	case AggregateByLanguage:
+		if fm, ok := match.(*result.FileMatch); ok && path.Ext(fm.Path) != "" {
+			language, _ := inventory.GetLanguageByFilename(fm.Path)
+			inc(strings.ToLower(language), fm.ResultCount())
+		}
+
+	case AggregateByAuthor:
+		if cm, ok := match.(*result.CommitMatch); ok {
+			inc(cm.Commit.Author.Name, 1)
+		}
+
+	case AggregateByCaptureGroup:
+		countCaptures := func(s string) {
+			for _, m := range a.pattern.FindAllStringSubmatch(s, -1) {
+				inc(m[1], 1)
+			}
+		}
+		switch v := match.(type) {
+		case *result.FileMatch:
+			if len(v.LineMatches) == 0 {
+				// The pattern matched the path of the file.
+				countCaptures(v.Path)
+			}
+			for _, lm := range v.LineMatches {
+				countCaptures(lm.Preview)
+			}
+		case *result.CommitMatch:
+			countCaptures(v.Body.Value)
+		}
+	}
+}
+
+// AggregationGroup is the number of matches in a group.
+type AggregationGroup struct {
+	Label string
+	Count int
+}
+
+// Compute returns the limit largest groups ordered by descending count, along
+// with the number of remaining groups and the sum of their counts.
+func (a *SearchAggregation) Compute(limit int) (groups []AggregationGroup, otherGroups, otherCount int) {
+	a.Dirty = false
+
+	groups = make([]AggregationGroup, 0, len(a.counts))
+	for label, count := range a.counts {
+		groups = append(groups, AggregationGroup{Label: label, Count: count})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Label < groups[j].Label
+	})
+
+	if len(groups) > limit {
+		for _, g := range groups[limit:] {
+			otherGroups++
+			otherCount += g.Count
+		}
+		groups = groups[:limit]
+	}
+	return groups, otherGroups, otherCount
+}
@@ -0,0 +1,118 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestSearchAggregation(t *testing.T) {
+	repo := types.RepoName{Name: "foo"}
+	fileMatch := func(path string, lines ...string) *result.FileMatch {
+		fm := &result.FileMatch{File: result.File{Repo: repo, Path: path}}
+		for _, line := range lines {
+			fm.LineMatches = append(fm.LineMatches, &result.LineMatch{
+				Preview:          line,
+				OffsetAndLengths: [][2]int32{{0, 1}},
+			})
+		}
+		return fm
+	}
+	event := SearchEvent{
+		Results: []result.Match{
+			fileMatch("main.go", "import \"fmt\"", "import \"os\""),
+			fileMatch("util.go", "import \"fmt\""),
+			fileMatch("README.md", "Import the package"),
+			&result.RepoMatch{Name: "bar"},
+		},
+	}
+
+	cases := []struct {
+		mode  AggregationMode
+		query string
+		want  []AggregationGroup
+	}{{
+		mode: AggregateByRepo,
+		want: []AggregationGroup{{Label: "foo", Count: 4}, {Label: "bar", Count: 1}},
+	}, {
+		mode: AggregateByPath,
+		want: []AggregationGroup{{Label: "main.go", Count: 2}, {Label: "README.md", Count: 1}, {Label: "util.go", Count: 1}},
+	}, {
+		mode: AggregateByLanguage,
+		want: []AggregationGroup{{Label: "go", Count: 3}, {Label: "markdown", Count: 1}},
+	}, {
+		mode:  AggregateByCaptureGroup,
+		query: `import\s"(\w+)"`,
+		want:  []AggregationGroup{{Label: "fmt", Count: 2}, {Label: "os", Count: 1}},
+	}, {
+		mode:  AggregateByCaptureGroup,
+		query: `import\s(\w+)`,
+		want:  []AggregationGroup{{Label: "the", Count: 1}},
+	}}
+
+	for _, c := range cases {
+		t.Run(string(c.mode)+" "+c.query, func(t *testing.T) {
+			var plan query.Plan
+			if c.query != "" {
+				var err error
+				plan, err = query.Pipeline(query.Init(c.query, query.SearchTypeRegex))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a, err := NewSearchAggregation(c.mode, plan)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.Update(event)
+			got, _, _ := a.Compute(10)
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Fatalf("unexpected groups (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearchAggregation_Compute(t *testing.T) {
+	a, err := NewSearchAggregation(AggregateByRepo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Update(SearchEvent{Results: []result.Match{
+		&result.RepoMatch{Name: "a"},
+		&result.RepoMatch{Name: "a"},
+		&result.RepoMatch{Name: "b"},
+		&result.RepoMatch{Name: "c"},
+	}})
+	if !a.Dirty {
+		t.Fatal("expected aggregation to be dirty after update")
+	}
+
+	groups, otherGroups, otherCount := a.Compute(1)
+	if diff := cmp.Diff([]AggregationGroup{{Label: "a", Count: 2}}, groups); diff != "" {
+		t.Fatalf("unexpected groups (-want +got):\n%s", diff)
+	}
+	if otherGroups != 2 || otherCount != 2 {
+		t.Fatalf("got %d other groups with %d matches, want 2 and 2", otherGroups, otherCount)
+	}
+	if a.Dirty {
+		t.Fatal("expected aggregation not to be dirty after compute")
+	}
+}
+
+func TestNewSearchAggregation_captureGroupErrors(t *testing.T) {
+	for _, q := range []string{`foo`, `(foo) or (bar)`} {
+		plan, err := query.Pipeline(query.Init(q, query.SearchTypeRegex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewSearchAggregation(AggregateByCaptureGroup, plan); err == nil {
+			t.Errorf("expected error for %q", q)
+		}
+	}
+}
@@ -35,6 +35,7 @@ type ProgressStats struct {
 	RepositoriesCount   *int
 	ExcludedArchived    int
 	ExcludedForks       int
+	MissingBlobs        int
 
 	Timedout []Namer
 	Missing  []Namer
@@ -185,6 +186,20 @@ func excludedArchiveHandler(resultsResolver ProgressStats) (Skipped, bool) {
 	}, true
 }
 
+func partialCloneHandler(resultsResolver ProgressStats) (Skipped, bool) {
+	missing := resultsResolver.MissingBlobs
+	if missing == 0 {
+		return Skipped{}, false
+	}
+
+	return Skipped{
+		Reason:   PartialClone,
+		Title:    fmt.Sprintf("%s %s not fetched", number(missing), plural("file", "files", missing)),
+		Message:  fmt.Sprintf("partial clone: %s %s not fetched, so only %s searched.", number(missing), plural("file was", "files were", missing), plural("its name was", "their names were", missing)),
+		Severity: SeverityInfo,
+	}, true
+}
+
 // TODO implement all skipped reasons
 var skippedHandlers = []func(stats ProgressStats) (Skipped, bool){
 	repositoryMissingHandler,
@@ -195,6 +210,7 @@ var skippedHandlers = []func(stats ProgressStats) (Skipped, bool){
 	shardTimeoutHandler,
 	excludedForkHandler,
 	excludedArchiveHandler,
+	partialCloneHandler,
 	displayLimitHandler,
 }
 
@@ -40,6 +40,7 @@ func TestSearchProgress(t *testing.T) {
 			RepositoriesCount:   intPtr(5),
 			ExcludedArchived:    1,
 			ExcludedForks:       5,
+			MissingBlobs:        3,
 			Timedout:            []Namer{repo{"timedout-1"}},
 			Missing:             []Namer{repo{"missing-1"}, repo{"missing-2"}},
 			Cloning:             []Namer{repo{"cloning-1"}},
@@ -80,6 +80,9 @@ const (
 	// ExcludedArchive is when we did not search a repository because it is
 	// archived.
 	ExcludedArchive SkippedReason = "excluded-archive"
+	// PartialClone is when we only searched the names of files because their
+	// content has not been fetched from a partial clone.
+	PartialClone SkippedReason = "partial-clone"
 )
 
 // SkippedSeverity is an enum for Skipped.Severity.
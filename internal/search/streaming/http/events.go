@@ -146,6 +146,19 @@ type EventCommitMatch struct {
 
 func (e *EventCommitMatch) eventMatch() {}
 
+// EventOwnerMatch is a code owner of matched files, as selected by
+// select:file.owners.
+type EventOwnerMatch struct {
+	// Type is always OwnerMatchType. Included here for marshalling.
+	Type MatchType `json:"type"`
+
+	Owner        string `json:"owner"`
+	RepositoryID int32  `json:"repositoryID"`
+	Repository   string `json:"repository"`
+}
+
+func (e *EventOwnerMatch) eventMatch() {}
+
 // EventFilter is a suggestion for a search filter. Currently has a 1-1
 // correspondance with the SearchFilter graphql type.
 type EventFilter struct {
@@ -156,6 +169,27 @@ type EventFilter struct {
 	Kind     string `json:"kind"`
 }
 
+// EventAggregation is the running count of matches grouped by the
+// aggregation mode requested by the client.
+type EventAggregation struct {
+	Mode   string                  `json:"mode"`
+	Groups []EventAggregationGroup `json:"groups"`
+
+	// OtherGroupCount is the number of groups not included in Groups, and
+	// OtherMatchCount the sum of their counts.
+	OtherGroupCount int `json:"otherGroupCount,omitempty"`
+	OtherMatchCount int `json:"otherMatchCount,omitempty"`
+
+	// Partial is true if the counts don't include all results, e.g. because
+	// the search hit a limit or timed out in some repositories.
+	Partial bool `json:"partial,omitempty"`
+}
+
+type EventAggregationGroup struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
 // EventAlert is GQL.SearchAlert. It replaces when sent to match existing
 // behaviour.
 type EventAlert struct {
@@ -184,6 +218,7 @@ const (
 	SymbolMatchType
 	CommitMatchType
 	PathMatchType
+	OwnerMatchType
 )
 
 func (t MatchType) MarshalJSON() ([]byte, error) {
@@ -198,6 +233,8 @@ func (t MatchType) MarshalJSON() ([]byte, error) {
 		return []byte(`"commit"`), nil
 	case PathMatchType:
 		return []byte(`"path"`), nil
+	case OwnerMatchType:
+		return []byte(`"owner"`), nil
 	default:
 		return nil, errors.Errorf("unknown MatchType: %d", t)
 	}
@@ -214,6 +251,8 @@ func (t *MatchType) UnmarshalJSON(b []byte) error {
 		*t = CommitMatchType
 	} else if bytes.Equal(b, []byte(`"path"`)) {
 		*t = PathMatchType
+	} else if bytes.Equal(b, []byte(`"owner"`)) {
+		*t = OwnerMatchType
 	} else {
 		return errors.Errorf("unknown MatchType: %s", b)
 	}
@@ -33,6 +33,10 @@ type Stats struct {
 
 	// IsIndexUnavailable is true if indexed search was unavailable.
 	IsIndexUnavailable bool
+
+	// MissingBlobs is the count of files of which only the name was searched
+	// since gitserver has not fetched their content from a partial clone.
+	MissingBlobs int
 }
 
 // update updates c with the other data, deduping as necessary. It modifies c but
@@ -56,6 +60,7 @@ func (c *Stats) Update(other *Stats) {
 
 	c.ExcludedForks = c.ExcludedForks + other.ExcludedForks
 	c.ExcludedArchived = c.ExcludedArchived + other.ExcludedArchived
+	c.MissingBlobs = c.MissingBlobs + other.MissingBlobs
 }
 
 // Zero returns true if stats is empty. IE calling Update will result in no
@@ -70,7 +75,8 @@ func (c *Stats) Zero() bool {
 		c.Status.Len() > 0 ||
 		c.ExcludedForks > 0 ||
 		c.ExcludedArchived > 0 ||
-		c.IsIndexUnavailable)
+		c.IsIndexUnavailable ||
+		c.MissingBlobs > 0)
 }
 
 func (c *Stats) String() string {
@@ -88,6 +94,7 @@ func (c *Stats) String() string {
 		{"repos", len(c.Repos)},
 		{"excludedForks", c.ExcludedForks},
 		{"excludedArchived", c.ExcludedArchived},
+		{"missingBlobs", c.MissingBlobs},
 	}
 	for _, p := range nums {
 		if p.n != 0 {
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -162,8 +163,20 @@ type SearcherParameters struct {
 	// repository if this field is true. Another example is we set this field
 	// to true if the user requests a specific timeout or maximum result size.
 	UseFullDeadline bool
+
+	// ResolveSubmodule resolves submodules to the repositories they refer
+	// to when PatternInfo.IncludeSubmodules is set.
+	ResolveSubmodule SubmoduleResolver
+
+	// MinusRepoFilters are the -repo: filters of the query. Submodules
+	// which refer to a repository they match are not searched.
+	MinusRepoFilters []string
 }
 
+// SubmoduleResolver returns the repository that the URL of a submodule of repo
+// refers to, or nil if there is no such repository.
+type SubmoduleResolver func(ctx context.Context, repo types.RepoName, submoduleURL string) (*types.RepoName, error)
+
 // TextParameters are the parameters passed to a search backend. It contains the Pattern
 // to search for, as well as the hydrated list of repository revisions to
 // search. It defines behavior for text search on repository names, file names, and file content.
@@ -210,8 +223,13 @@ type TextParametersForCommitParameters struct {
 // TextPatternInfo is the struct used by vscode pass on search queries. Keep it in
 // sync with pkg/searcher/protocol.PatternInfo.
 type TextPatternInfo struct {
-	Pattern         string
-	IsNegated       bool
+	Pattern   string
+	IsNegated bool
+
+	// PatternExpression, if non-nil, is a boolean expression of patterns
+	// that backends evaluate per file in place of Pattern and IsNegated.
+	PatternExpression *PatternNode
+
 	IsRegExp        bool
 	IsStructuralPat bool
 	CombyRule       string
@@ -235,10 +253,55 @@ type TextPatternInfo struct {
 	PatternMatchesPath    bool
 
 	Languages []string
+
+	// IncludeSubmodules is true if unindexed search should also search the
+	// submodules of repositories at their pinned commits.
+	IncludeSubmodules bool
+}
+
+type PatternNodeKind int
+
+const (
+	PatternNodePattern PatternNodeKind = iota
+	PatternNodeAnd
+	PatternNodeOr
+	PatternNodeNot
+)
+
+// PatternNode is a node of a boolean pattern expression. Leaves hold a
+// pattern that is a regular expression if IsRegExp is set on the enclosing
+// TextPatternInfo. Not nodes have exactly one operand.
+type PatternNode struct {
+	Kind     PatternNodeKind
+	Value    string
+	Operands []*PatternNode
+}
+
+func (n *PatternNode) String() string {
+	var op string
+	switch n.Kind {
+	case PatternNodePattern:
+		return fmt.Sprintf("%q", n.Value)
+	case PatternNodeAnd:
+		op = "and"
+	case PatternNodeOr:
+		op = "or"
+	case PatternNodeNot:
+		op = "not"
+	}
+
+	args := []string{op}
+	for _, operand := range n.Operands {
+		args = append(args, operand.String())
+	}
+	return "(" + strings.Join(args, " ") + ")"
 }
 
 func (p *TextPatternInfo) String() string {
 	args := []string{fmt.Sprintf("%q", p.Pattern)}
+	if p.PatternExpression != nil {
+		args = []string{p.PatternExpression.String()}
+	}
 	if p.IsRegExp {
 		args = append(args, "re")
 	}
@@ -301,7 +364,9 @@ type RepoOptions struct {
 	OnlyArchived      bool
 	CommitAfter       string
 	Visibility        query.RepoVisibility
-	Ranked            bool // Return results ordered by rank
+	HasTopics         []string                    // repo:has.topic() predicates
+	HasKVPs           []query.RepoHasKVPPredicate // repo:has() predicates
+	Ranked            bool                        // Return results ordered by rank
 	Limit             int
 	CacheLookup       bool
 	Query             query.Q
@@ -328,6 +393,16 @@ func (op *RepoOptions) String() string {
 	if op.CommitAfter != "" {
 		_, _ = fmt.Fprintf(&b, " CommitAfter=%q", op.CommitAfter)
 	}
+	if len(op.HasTopics) > 0 {
+		_, _ = fmt.Fprintf(&b, " HasTopics=%q", op.HasTopics)
+	}
+	for _, kvp := range op.HasKVPs {
+		if kvp.Value == nil {
+			_, _ = fmt.Fprintf(&b, " HasKVP=%q", kvp.Key)
+		} else {
+			_, _ = fmt.Fprintf(&b, " HasKVP=%q", kvp.Key+":"+*kvp.Value)
+		}
+	}
 
 	if op.NoForks {
 		b.WriteString(" NoForks")
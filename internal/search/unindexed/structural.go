@@ -110,9 +110,11 @@ func streamStructuralSearch(ctx context.Context, args *search.SearcherParameters
 	jobs := []*searchRepos{}
 	for _, repoSet := range repos {
 		searcherArgs := &search.SearcherParameters{
-			SearcherURLs:    args.SearcherURLs,
-			PatternInfo:     args.PatternInfo,
-			UseFullDeadline: args.UseFullDeadline,
+			SearcherURLs:     args.SearcherURLs,
+			PatternInfo:      args.PatternInfo,
+			UseFullDeadline:  args.UseFullDeadline,
+			ResolveSubmodule: args.ResolveSubmodule,
+			MinusRepoFilters: args.MinusRepoFilters,
 		}
 
 		jobs = append(jobs, &searchRepos{args: searcherArgs, stream: stream, repoSet: repoSet})
@@ -3,6 +3,7 @@ package unindexed
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -115,7 +116,87 @@ func searchFilesInRepo(ctx context.Context, searcherURLs *endpoint.Map, repo typ
 		})
 	}
 
-	return searcher.Search(ctx, searcherURLs, gitserverRepo, repo.ID, rev, commit, index, info, fetchTimeout, indexerEndpoints, onMatches)
+	limitHit, missingBlobs, err := searcher.Search(ctx, searcherURLs, gitserverRepo, repo.ID, rev, commit, index, info, fetchTimeout, indexerEndpoints, onMatches)
+	if missingBlobs > 0 {
+		stream.Send(streaming.SearchEvent{
+			Stats: streaming.Stats{MissingBlobs: missingBlobs},
+		})
+	}
+	return limitHit, err
+}
+
+// searchFilesInSubmodules searches the submodules of repo at rev, at their
+// pinned commits. The matches are reported in the repositories the submodules
+// refer to. Submodules of submodules are not searched.
+func searchFilesInSubmodules(ctx context.Context, args *search.SearcherParameters, repo types.RepoName, gitserverRepo api.RepoName, rev string, fetchTimeout time.Duration, stream streaming.Sender) (limitHit bool, err error) {
+	commit, err := git.ResolveRevision(ctx, gitserverRepo, rev, git.ResolveRevisionOptions{NoEnsureRevision: true})
+	if err != nil {
+		return false, err
+	}
+	submodules, err := git.Submodules(ctx, gitserverRepo, commit)
+	if err != nil {
+		return false, err
+	}
+
+	excluded := make([]*regexp.Regexp, 0, len(args.MinusRepoFilters))
+	for _, pat := range args.MinusRepoFilters {
+		re, err := regexp.Compile(`(?i)` + pat)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid regex for -repo filter %q", pat)
+		}
+		excluded = append(excluded, re)
+	}
+
+	searched := map[api.RepoName]map[api.CommitID]struct{}{}
+	for _, submodule := range submodules {
+		if submodule.URL == "" {
+			continue
+		}
+		submoduleRepo, err := args.ResolveSubmodule(ctx, repo, submodule.URL)
+		if err != nil {
+			return limitHit, err
+		}
+		if submoduleRepo == nil {
+			// The submodule is not a repository on this instance.
+			continue
+		}
+		if matchesAny(excluded, string(submoduleRepo.Name)) {
+			// The repository is excluded from the search by -repo:.
+			continue
+		}
+
+		// The same commit of a repository may be pinned by several
+		// submodules.
+		if _, ok := searched[submoduleRepo.Name][submodule.CommitID]; ok {
+			continue
+		}
+		if searched[submoduleRepo.Name] == nil {
+			searched[submoduleRepo.Name] = map[api.CommitID]struct{}{}
+		}
+		searched[submoduleRepo.Name][submodule.CommitID] = struct{}{}
+
+		submoduleLimitHit, err := searchFilesInRepo(ctx, args.SearcherURLs, *submoduleRepo, submoduleRepo.Name, string(submodule.CommitID), false, args.PatternInfo, fetchTimeout, stream)
+		if err != nil {
+			if ctx.Err() != nil {
+				return limitHit, ctx.Err()
+			}
+			// The pinned commit may not have been fetched yet, which should
+			// not fail the search of the superproject.
+			log15.Warn("searchFilesInSubmodules failed", "error", err, "repo", repo.Name, "submodule", submodule.Path)
+			continue
+		}
+		limitHit = limitHit || submoduleLimitHit
+	}
+	return limitHit, nil
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
 }
 
 // newToMatches returns a closure that converts []*protocol.FileMatch to []result.Match.
@@ -183,7 +264,7 @@ func repoHasFilesWithNamesMatching(ctx context.Context, searcherURLs *endpoint.M
 			}
 		}
 		p := search.TextPatternInfo{IsRegExp: true, FileMatchLimit: 1, IncludePatterns: []string{pattern}, PathPatternsAreCaseSensitive: false, PatternMatchesContent: true, PatternMatchesPath: true}
-		_, err := searcher.Search(ctx, searcherURLs, repo.Name, repo.ID, "", commit, false, &p, fetchTimeout, []string{}, onMatches)
+		_, _, err := searcher.Search(ctx, searcherURLs, repo.Name, repo.ID, "", commit, false, &p, fetchTimeout, []string{}, onMatches)
 		if err != nil {
 			return false, err
 		}
@@ -282,6 +363,11 @@ func callSearcherOverRepos(
 					defer done()
 
 					repoLimitHit, err := searchFilesInRepo(ctx, args.SearcherURLs, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], index, args.PatternInfo, fetchTimeout, stream)
+					if err == nil && args.PatternInfo.IncludeSubmodules && args.ResolveSubmodule != nil {
+						var submodulesLimitHit bool
+						submodulesLimitHit, err = searchFilesInSubmodules(ctx, args, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], fetchTimeout, stream)
+						repoLimitHit = repoLimitHit || submodulesLimitHit
+					}
 					if err != nil {
 						tr.LogFields(otlog.String("repo", string(repoRev.Repo.Name)), otlog.Error(err), otlog.Bool("timeout", errcode.IsTimeout(err)), otlog.Bool("temporary", errcode.IsTemporary(err)))
 						log15.Warn("searchFilesInRepo failed", "error", err, "repo", repoRev.Repo.Name)
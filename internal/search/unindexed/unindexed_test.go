@@ -325,17 +325,17 @@ func TestSearchFilesInRepos_multipleRevsPerRepo(t *testing.T) {
 }
 
 func TestRepoShouldBeSearched(t *testing.T) {
-	searcher.MockSearch = func(ctx context.Context, repo api.RepoName, repoID api.RepoID, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration, onMatches func([]*protocol.FileMatch)) (limitHit bool, err error) {
+	searcher.MockSearch = func(ctx context.Context, repo api.RepoName, repoID api.RepoID, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration, onMatches func([]*protocol.FileMatch)) (limitHit bool, missingBlobs int, err error) {
 		repoName := repo
 		switch repoName {
 		case "foo/one":
 			onMatches([]*protocol.FileMatch{{Path: "main.go"}})
-			return false, nil
+			return false, 0, nil
 		case "foo/no-filematch":
 			onMatches([]*protocol.FileMatch{})
-			return false, nil
+			return false, 0, nil
 		default:
-			return false, errors.New("Unexpected repo")
+			return false, 0, errors.New("Unexpected repo")
 		}
 	}
 	defer func() { searcher.MockSearch = nil }()
@@ -463,3 +463,89 @@ func TestFileMatch_Limit(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchFilesInRepos_submodules(t *testing.T) {
+	var searched []string
+	mockSearchFilesInRepo = func(ctx context.Context, repo types.RepoName, gitserverRepo api.RepoName, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration, stream streaming.Sender) (limitHit bool, err error) {
+		searched = append(searched, string(repo.Name)+"@"+rev)
+		stream.Send(streaming.SearchEvent{
+			Results: []result.Match{&result.FileMatch{
+				File: result.File{
+					Repo:     repo,
+					CommitID: api.CommitID(rev),
+					Path:     "main.go",
+				},
+			}},
+		})
+		return false, nil
+	}
+	defer func() { mockSearchFilesInRepo = nil }()
+
+	git.Mocks.ResolveRevision = func(spec string, opt git.ResolveRevisionOptions) (api.CommitID, error) {
+		return api.CommitID(spec), nil
+	}
+	git.Mocks.Submodules = func(repo api.RepoName, commit api.CommitID) ([]git.Submodule, error) {
+		if repo != "foo" {
+			t.Fatalf("unexpected submodules lookup for %s", repo)
+		}
+		return []git.Submodule{
+			{URL: "https://example.com/bar.git", Path: "lib", CommitID: "pinned"},
+			// The same commit pinned twice is searched once.
+			{URL: "https://example.com/bar.git", Path: "vendor/lib", CommitID: "pinned"},
+			{URL: "https://example.com/unknown.git", Path: "unknown", CommitID: "pinned"},
+			// Excluded by -repo:.
+			{URL: "https://example.com/baz.git", Path: "baz", CommitID: "pinned"},
+			{Path: "no-url", CommitID: "pinned"},
+		}, nil
+	}
+	defer git.ResetMocks()
+
+	resolveSubmodule := func(ctx context.Context, repo types.RepoName, submoduleURL string) (*types.RepoName, error) {
+		switch submoduleURL {
+		case "https://example.com/bar.git":
+			return &types.RepoName{ID: 2, Name: "bar"}, nil
+		case "https://example.com/baz.git":
+			return &types.RepoName{ID: 3, Name: "baz"}, nil
+		}
+		return nil, nil
+	}
+
+	for _, includeSubmodules := range []bool{false, true} {
+		searched = nil
+		args := &search.TextParameters{
+			PatternInfo: &search.TextPatternInfo{
+				FileMatchLimit:    search.DefaultMaxSearchResults,
+				Pattern:           "foo",
+				IncludeSubmodules: includeSubmodules,
+			},
+			Repos:        makeRepositoryRevisions("foo@master"),
+			Zoekt:        &searchbackend.FakeSearcher{},
+			SearcherURLs: endpoint.Static("test"),
+		}
+		zoektArgs, err := zoektutil.NewIndexedSearchRequest(context.Background(), args, search.TextRequest, func([]*search.RepositoryRevisions) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		searcherArgs := &search.SearcherParameters{
+			SearcherURLs:     args.SearcherURLs,
+			PatternInfo:      args.PatternInfo,
+			ResolveSubmodule: resolveSubmodule,
+			MinusRepoFilters: []string{"^BAZ$"},
+		}
+		matches, _, err := SearchFilesInReposBatch(context.Background(), zoektArgs, searcherArgs, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"foo@master"}
+		if includeSubmodules {
+			want = append(want, "bar@pinned")
+		}
+		if diff := cmp.Diff(want, searched); diff != "" {
+			t.Fatalf("unexpected searches (-want +have):\n%s", diff)
+		}
+		if len(matches) != len(want) {
+			t.Fatalf("have %d matches, want %d", len(matches), len(want))
+		}
+	}
+}
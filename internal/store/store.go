@@ -27,6 +27,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/diskcache"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
 	"github.com/sourcegraph/sourcegraph/internal/mutablelimiter"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
@@ -290,15 +291,29 @@ func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string,
 			continue
 		}
 
+		// The blobs of files missing from partial clones on gitserver were
+		// not fetched, so like large files we only search their names. They
+		// are marked so that searches can report them.
+		_, missingBlob := hdr.PAXRecords[protocol.MissingBlobPAXRecord]
+
 		// We are happy with the file, so we can write it to zw.
-		w, err := zw.CreateHeader(&zip.FileHeader{
+		fh := &zip.FileHeader{
 			Name:   hdr.Name,
 			Method: zip.Store,
-		})
+		}
+		if missingBlob {
+			fh.Comment = missingBlobComment
+		}
+		w, err := zw.CreateHeader(fh)
 		if err != nil {
 			return err
 		}
 
+		if missingBlob {
+			missingBlobs.Inc()
+			continue
+		}
+
 		n, err := tr.Read(buf)
 		switch err {
 		case io.EOF:
@@ -410,6 +425,10 @@ var (
 		Name: "searcher_store_fetch_failed",
 		Help: "The total number of archive fetches that failed.",
 	})
+	missingBlobs = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "searcher_store_missing_blobs",
+		Help: "The total number of files in fetched archives whose content was skipped since gitserver has not fetched it.",
+	})
 )
 
 // temporaryError wraps an error but adds the Temporary method. It does not
@@ -2,10 +2,12 @@ package store
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"context"
 	"io"
 	"os"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 )
 
 func TestPrepareZip(t *testing.T) {
@@ -117,6 +120,64 @@ func TestPrepareZip_errHeader(t *testing.T) {
 	}
 }
 
+func TestCopySearchable_missingBlob(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "main.go", Size: int64(len("package main\n"))},
+		{Name: "assets/level1.bin", PAXRecords: map[string]string{protocol.MissingBlobPAXRecord: "ba08ec99207abca2322333f97de2ebd0c9d27601"}},
+	} {
+		hdr.Typeflag = tar.TypeReg
+		hdr.Mode = 0644
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte("package main\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zipBuf := new(bytes.Buffer)
+	zw := zip.NewWriter(zipBuf)
+	err := copySearchable(tar.NewReader(buf), zw, nil, func(*tar.Header) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(zipBuf.Bytes()), int64(zipBuf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := map[string]uint64{}
+	for _, f := range zr.File {
+		have[f.Name] = f.UncompressedSize64
+	}
+	// The file of the missing blob is kept, so that its name is searched.
+	want := map[string]uint64{
+		"main.go":           uint64(len("package main\n")),
+		"assets/level1.bin": 0,
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("have files %v, want %v", have, want)
+	}
+
+	var zf ZipFile
+	if err := zf.PopulateFiles(zr); err != nil {
+		t.Fatal(err)
+	}
+	if zf.MissingBlobs != 1 {
+		t.Fatalf("have %d missing blobs, want 1", zf.MissingBlobs)
+	}
+}
+
 func TestIngoreSizeMax(t *testing.T) {
 	patterns := []string{
 		"foo",
@@ -99,8 +99,11 @@ type ZipFile struct {
 	Files  []SrcFile
 	MaxLen int
 	Data   []byte
-	f      *os.File
-	wg     sync.WaitGroup // ensures underlying file is not munmap'd or closed while in use
+	// MissingBlobs is the number of files whose content is missing from the
+	// archive since gitserver has not fetched it from a partial clone.
+	MissingBlobs int
+	f            *os.File
+	wg           sync.WaitGroup // ensures underlying file is not munmap'd or closed while in use
 }
 
 func readZipFile(path string) (*ZipFile, error) {
@@ -152,6 +155,9 @@ func (f *ZipFile) PopulateFiles(r *zip.Reader) error {
 			return errors.Errorf("file %s has size > 2gb: %v", file.Name, size)
 		}
 		f.Files[i] = SrcFile{Name: file.Name, Off: off, Len: int32(size)}
+		if file.Comment == missingBlobComment {
+			f.MissingBlobs++
+		}
 		if size > f.MaxLen {
 			f.MaxLen = size
 		}
@@ -174,6 +180,10 @@ func (f *ZipFile) Close() {
 }
 
 // A SrcFile is a single file inside a ZipFile.
+// missingBlobComment is the comment of the zip entries of files whose content
+// is missing since gitserver has not fetched it from a partial clone.
+const missingBlobComment = "missing-blob"
+
 type SrcFile struct {
 	// Take care with the size of this struct.
 	// There will be *lots* of these in memory.
@@ -59,6 +59,12 @@ func (e *ExternalService) RedactConfigSecrets() (string, error) {
 		newCfg, err = redactField(e.Config, []string{"token"})
 	case *schema.PerforceConnection:
 		newCfg, err = redactField(e.Config, []string{"p4.passwd"})
+	case *schema.SubversionConnection:
+		var fields [][]string
+		if cfg.Password != "" {
+			fields = append(fields, []string{"password"})
+		}
+		newCfg, err = redactField(e.Config, fields...)
 	case *schema.GitoliteConnection:
 		// Gitolite has no secret fields
 		newCfg, err = redactField(e.Config)
@@ -140,6 +146,12 @@ func (e *ExternalService) UnredactConfig(old *ExternalService) error {
 		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{[]string{"token"}, &cfg.Token})
 	case *schema.PerforceConnection:
 		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{[]string{"p4.passwd"}, &cfg.P4Passwd})
+	case *schema.SubversionConnection:
+		var fields []jsonStringField
+		if cfg.Password != "" {
+			fields = append(fields, jsonStringField{[]string{"password"}, &cfg.Password})
+		}
+		unredacted, err = unredactField(old.Config, e.Config, &cfg, fields...)
 	case *schema.GitoliteConnection:
 		// no secret fields?
 		unredacted, err = unredactField(old.Config, e.Config, &cfg)
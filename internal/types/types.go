@@ -460,7 +460,9 @@ type GitserverRepo struct {
 	LastFetched time.Time
 	// The last time a fetch updated the repository.
 	LastChanged time.Time
-	UpdatedAt   time.Time
+	// The on-disk size of the clone in bytes, or 0 if it hasn't been computed yet.
+	RepoSizeBytes int64
+	UpdatedAt     time.Time
 }
 
 // ExternalService is a connection to an external service.
@@ -1196,6 +1198,12 @@ type SearchContext struct {
 	NamespaceOrgID  int32 // if non-zero, the owner is this organization. NamespaceUserID/NamespaceOrgID are mutually exclusive.
 	UpdatedAt       time.Time
 
+	// Query, if non-empty, defines the repositories of the search context. The repositories are resolved
+	// when searching, so a query-defined search context has no static list of repository revisions. File
+	// filters of the query, such as lang:go, are added to the queries the search context is used in.
+	// Example: repo:^github\.com/ourorg/ lang:go -repo:archive archived:no
+	Query string
+
 	// We cache namespace names to avoid separate database lookups when constructing the search context spec
 
 	// NamespaceUserName is the name of the user if NamespaceUserID is present.
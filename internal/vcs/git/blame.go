@@ -1,16 +1,22 @@
 package git
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/golang/groupcache/lru"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git/gitapi"
 )
@@ -43,7 +49,280 @@ func BlameFile(ctx context.Context, repo api.RepoName, path string, opt *BlameOp
 	span.SetTag("path", path)
 	span.SetTag("opt", opt)
 	defer span.Finish()
-	return blameFileCmd(ctx, gitserverCmdFunc(repo), path, opt)
+
+	key, cacheable := blameCacheKey(repo, path, opt)
+	if cacheable {
+		if hunks, ok := getCachedBlame(key); ok {
+			span.SetTag("cached", true)
+			return hunks, nil
+		}
+	}
+
+	hunks, err := blameFileCmd(ctx, gitserverCmdFunc(repo), path, opt)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		addCachedBlame(key, hunks)
+	}
+	return hunks, nil
+}
+
+// blameCache caches the hunks of complete blames of a file at a commit. Blaming
+// a file with a long history can take a long time, and the same file tends to
+// be blamed repeatedly (for example, when several users view it).
+var (
+	blameCacheMu sync.Mutex
+	blameCache   = lru.New(100)
+)
+
+// blameCacheKey returns the cache key of a blame, and whether its result may
+// be cached. Only blames of a whole file at an absolute commit are cached,
+// because the result for a revision like "HEAD" changes over time.
+func blameCacheKey(repo api.RepoName, path string, opt *BlameOptions) (string, bool) {
+	if opt == nil || opt.OldestCommit != "" || opt.StartLine != 0 || opt.EndLine != 0 {
+		return "", false
+	}
+	if ensureAbsoluteCommit(opt.NewestCommit) != nil {
+		return "", false
+	}
+	return string(repo) + ":" + string(opt.NewestCommit) + ":" + filepath.ToSlash(path), true
+}
+
+// getCachedBlame returns copies of the cached hunks of a blame, ordered by
+// line, so callers may modify them.
+func getCachedBlame(key string) ([]*Hunk, bool) {
+	blameCacheMu.Lock()
+	v, ok := blameCache.Get(key)
+	blameCacheMu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	cached := v.([]Hunk)
+	hunks := make([]*Hunk, len(cached))
+	for i := range cached {
+		hunk := cached[i]
+		hunks[i] = &hunk
+	}
+	return hunks, true
+}
+
+// addCachedBlame caches copies of hunks, so that the cache is not affected by
+// callers modifying them.
+func addCachedBlame(key string, hunks []*Hunk) {
+	cached := make([]Hunk, len(hunks))
+	for i, hunk := range hunks {
+		cached[i] = *hunk
+	}
+	blameCacheMu.Lock()
+	blameCache.Add(key, cached)
+	blameCacheMu.Unlock()
+}
+
+// StreamBlameFile is like BlameFile, but calls onHunk with each hunk as soon
+// as git has computed it, instead of waiting for the whole file to be blamed.
+// Hunks are not reported in line order. If onHunk returns an error, the blame
+// is stopped and that error is returned. opt.NewestCommit must be an absolute
+// commit ID.
+//
+// The result of a blame of a whole file is cached, and is shared with
+// BlameFile. In that case the hunks are reported in line order.
+func StreamBlameFile(ctx context.Context, repo api.RepoName, path string, opt *BlameOptions, onHunk func(*Hunk) error) error {
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: StreamBlameFile")
+	span.SetTag("repo", repo)
+	span.SetTag("path", path)
+	span.SetTag("opt", opt)
+	defer span.Finish()
+
+	if opt == nil {
+		opt = &BlameOptions{}
+	}
+	if opt.OldestCommit != "" {
+		return errors.Errorf("OldestCommit not implemented")
+	}
+	if err := ensureAbsoluteCommit(opt.NewestCommit); err != nil {
+		return err
+	}
+
+	key, cacheable := blameCacheKey(repo, path, opt)
+	if cacheable {
+		if hunks, ok := getCachedBlame(key); ok {
+			span.SetTag("cached", true)
+			for _, hunk := range hunks {
+				if err := onHunk(hunk); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	// git blame --incremental does not output the content of lines, so we
+	// need to read the file to determine the byte offsets of hunks.
+	offsets, err := readLineOffsets(ctx, repo, opt.NewestCommit, path)
+	if err != nil {
+		return err
+	}
+	// Like BlameFile, byte offsets are relative to the first blamed line.
+	base := 0
+	if opt.StartLine > 1 && opt.StartLine <= len(offsets) {
+		base = offsets[opt.StartLine-1]
+	}
+
+	args := []string{"blame", "--incremental", "-w"}
+	if opt.StartLine != 0 || opt.EndLine != 0 {
+		args = append(args, fmt.Sprintf("-L%d,%d", opt.StartLine, opt.EndLine))
+	}
+	args = append(args, string(opt.NewestCommit), "--", filepath.ToSlash(path))
+
+	cmd := gitserver.DefaultClient.Command("git", args...)
+	cmd.Repo = repo
+	rc, err := gitserver.StdoutReader(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var hunks []*Hunk
+	err = parseIncrementalBlame(rc, func(hunk *Hunk) error {
+		if hunk.StartLine < 1 || hunk.EndLine > len(offsets) {
+			return errors.Errorf("blame hunk for lines %d-%d is outside of file with %d lines", hunk.StartLine, hunk.EndLine-1, len(offsets)-1)
+		}
+		hunk.StartByte = offsets[hunk.StartLine-1] - base
+		hunk.EndByte = offsets[hunk.EndLine-1] - base
+		if cacheable {
+			// Copy the hunk before onHunk can modify it.
+			cached := *hunk
+			hunks = append(hunks, &cached)
+		}
+		return onHunk(hunk)
+	})
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("git command %v failed", args))
+	}
+
+	if cacheable {
+		sort.Slice(hunks, func(i, j int) bool { return hunks[i].StartLine < hunks[j].StartLine })
+		addCachedBlame(key, hunks)
+	}
+	return nil
+}
+
+// readLineOffsets returns the byte offsets of the start of each line of the
+// named file at commit, followed by the size of the file. The offsets of line
+// n are offsets[n-1] (inclusive) to offsets[n] (exclusive).
+func readLineOffsets(ctx context.Context, repo api.RepoName, commit api.CommitID, name string) ([]int, error) {
+	rc, err := NewFileReader(ctx, repo, commit, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	offsets := []int{0}
+	size := 0
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := rc.Read(buf)
+		for i, b := range buf[:n] {
+			if b == '\n' {
+				offsets = append(offsets, size+i+1)
+			}
+		}
+		size += n
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if size > offsets[len(offsets)-1] {
+		// The last line has no trailing newline.
+		offsets = append(offsets, size)
+	}
+	return offsets, nil
+}
+
+// parseIncrementalBlame parses the output of git blame --incremental, calling
+// onHunk for each blame entry. Information about a commit is only output for
+// its first entry, and each entry ends with the name of the file in the commit.
+func parseIncrementalBlame(r io.Reader, onHunk func(*Hunk) error) error {
+	commits := make(map[api.CommitID]gitapi.Commit)
+	br := bufio.NewReader(r)
+
+	var hunk *Hunk
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if hunk == nil {
+			// Consume the header of an entry: <commit> <orig line> <final line> <lines>
+			header := strings.Split(line, " ")
+			if len(header) != 4 {
+				return errors.Errorf("Expected 4 parts to hunk header, but got: %q", line)
+			}
+			lineNo, err := strconv.Atoi(header[2])
+			if err != nil {
+				return errors.Errorf("Failed to parse hunk header %q", line)
+			}
+			nLines, err := strconv.Atoi(header[3])
+			if err != nil {
+				return errors.Errorf("Failed to parse hunk header %q", line)
+			}
+			hunk = &Hunk{
+				CommitID:  api.CommitID(header[0]),
+				StartLine: lineNo,
+				EndLine:   lineNo + nLines,
+			}
+			continue
+		}
+
+		var value string
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			line, value = line[:i], line[i+1:]
+		}
+		commit := commits[hunk.CommitID]
+		commit.ID = hunk.CommitID
+		switch line {
+		case "author":
+			commit.Author.Name = value
+		case "author-mail":
+			if len(value) >= 2 && value[0] == '<' && value[len(value)-1] == '>' {
+				value = value[1 : len(value)-1]
+			}
+			commit.Author.Email = value
+		case "author-time":
+			authorTime, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.Errorf("Failed to parse author-time %q", value)
+			}
+			commit.Author.Date = time.Unix(authorTime, 0).UTC()
+		case "summary":
+			commit.Message = gitapi.Message(value)
+		case "filename":
+			// The last line of an entry.
+			hunk.Author = commit.Author
+			hunk.Message = string(commit.Message)
+			hunk.Filename = value
+			if err := onHunk(hunk); err != nil {
+				return err
+			}
+			hunk = nil
+			continue
+		}
+		commits[commit.ID] = commit
+	}
+
+	if hunk != nil {
+		return errors.Errorf("Unexpected end of blame output in hunk for lines %d-%d", hunk.StartLine, hunk.EndLine-1)
+	}
+	return nil
 }
 
 func blameFileCmd(ctx context.Context, command cmdFunc, path string, opt *BlameOptions) ([]*Hunk, error) {
@@ -3,6 +3,8 @@ package git
 import (
 	"context"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -79,3 +81,137 @@ func TestRepository_BlameFile(t *testing.T) {
 		}
 	}
 }
+
+func TestRepository_StreamBlameFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	repo := MakeGitRepository(t,
+		"echo line1 > f",
+		"git add f",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m foo --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"echo line2 >> f",
+		"git add f",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m bar --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"git mv f f2",
+		"echo line3 >> f2",
+		"git add f2",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m baz --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	)
+	commitID, err := ResolveRevision(ctx, repo, "master", ResolveRevisionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamBlame := func(opt *BlameOptions) []*Hunk {
+		t.Helper()
+		var hunks []*Hunk
+		err := StreamBlameFile(ctx, repo, "f2", opt, func(hunk *Hunk) error {
+			hunks = append(hunks, hunk)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(hunks, func(i, j int) bool { return hunks[i].StartLine < hunks[j].StartLine })
+		return hunks
+	}
+
+	for _, opt := range []*BlameOptions{
+		{NewestCommit: commitID, StartLine: 2, EndLine: 3},
+		{NewestCommit: commitID},
+	} {
+		want, err := blameFileCmd(ctx, gitserverCmdFunc(repo), "f2", opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := streamBlame(opt); !reflect.DeepEqual(have, want) {
+			t.Errorf("%+v: hunks != wantHunks\n\nhunks ==========\n%s\n\nwantHunks ==========\n%s", opt, AsJSON(have), AsJSON(want))
+		}
+	}
+
+	// Only the blame of the whole file at an absolute commit is cached, and it
+	// is served to both StreamBlameFile and BlameFile.
+	key, _ := blameCacheKey(repo, "f2", &BlameOptions{NewestCommit: commitID})
+	cached, ok := getCachedBlame(key)
+	if !ok || len(cached) != 3 {
+		t.Fatalf("expected 3 cached hunks, have %d", len(cached))
+	}
+	if _, ok := blameCacheKey(repo, "f2", &BlameOptions{NewestCommit: "master"}); ok {
+		t.Error("expected blame of a symbolic revision not to be cacheable")
+	}
+	hunks, err := BlameFile(ctx, repo, "f2", &BlameOptions{NewestCommit: commitID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hunks, cached) {
+		t.Error("expected BlameFile to return the cached hunks")
+	}
+
+	// Modifying returned hunks does not affect the cache.
+	hunks[0].Message = "modified"
+	err = StreamBlameFile(ctx, repo, "f2", &BlameOptions{NewestCommit: commitID}, func(hunk *Hunk) error {
+		hunk.Filename = "modified"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, _ := getCachedBlame(key); !reflect.DeepEqual(have, cached) {
+		t.Errorf("expected cached hunks not to be modified\n\nhunks ==========\n%s\n\nwantHunks ==========\n%s", AsJSON(have), AsJSON(cached))
+	}
+}
+
+func TestParseIncrementalBlame(t *testing.T) {
+	// Entries are not ordered by line, and information about a commit is only
+	// output for its first entry.
+	out := `fad406f4fe02c358a09df0d03ec7a36c2c8a20f1 2 3 1
+author a
+author-mail <a@a.com>
+author-time 1136214245
+author-tz +0000
+committer a
+committer-mail <a@a.com>
+committer-time 1136214245
+committer-tz +0000
+summary bar
+previous e6093374dcf5725d8517db0dccbbf69df65dbde0 f
+filename f
+e6093374dcf5725d8517db0dccbbf69df65dbde0 1 1 1
+author b
+author-mail <b@b.com>
+author-time 1136214245
+author-tz +0000
+committer b
+committer-mail <b@b.com>
+committer-time 1136214245
+committer-tz +0000
+summary foo
+boundary
+filename f
+fad406f4fe02c358a09df0d03ec7a36c2c8a20f1 3 4 2
+filename f2
+`
+	date := MustParseTime(time.RFC3339, "2006-01-02T15:04:05Z")
+	want := []*Hunk{
+		{StartLine: 3, EndLine: 4, CommitID: "fad406f4fe02c358a09df0d03ec7a36c2c8a20f1", Message: "bar", Author: gitapi.Signature{Name: "a", Email: "a@a.com", Date: date}, Filename: "f"},
+		{StartLine: 1, EndLine: 2, CommitID: "e6093374dcf5725d8517db0dccbbf69df65dbde0", Message: "foo", Author: gitapi.Signature{Name: "b", Email: "b@b.com", Date: date}, Filename: "f"},
+		{StartLine: 4, EndLine: 6, CommitID: "fad406f4fe02c358a09df0d03ec7a36c2c8a20f1", Message: "bar", Author: gitapi.Signature{Name: "a", Email: "a@a.com", Date: date}, Filename: "f2"},
+	}
+
+	var have []*Hunk
+	err := parseIncrementalBlame(strings.NewReader(out), func(hunk *Hunk) error {
+		have = append(have, hunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("hunks != wantHunks\n\nhunks ==========\n%s\n\nwantHunks ==========\n%s", AsJSON(have), AsJSON(want))
+	}
+
+	if err := parseIncrementalBlame(strings.NewReader(out[:100]), func(*Hunk) error { return nil }); err == nil {
+		t.Error("expected error for truncated output")
+	}
+}
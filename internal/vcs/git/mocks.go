@@ -26,6 +26,7 @@ var Mocks, emptyMocks struct {
 	Commits          func(repo api.RepoName, opt CommitsOptions) ([]*gitapi.Commit, error)
 	MergeBase        func(repo api.RepoName, a, b api.CommitID) (api.CommitID, error)
 	GetDefaultBranch func(repo api.RepoName) (refName string, commit api.CommitID, err error)
+	Submodules       func(repo api.RepoName, commit api.CommitID) ([]Submodule, error)
 }
 
 // ResetMocks clears the mock functions set on Mocks (so that subsequent tests don't inadvertently
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/config"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// Submodules returns the submodules of the repository at commit, ordered by
+// path. Submodules which are not described in .gitmodules have an empty URL.
+func Submodules(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]Submodule, error) {
+	if Mocks.Submodules != nil {
+		return Mocks.Submodules(repo, commit)
+	}
+
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: Submodules")
+	span.SetTag("Commit", commit)
+	defer span.Finish()
+
+	entries, err := lsTree(ctx, repo, commit, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	var submodules []Submodule
+	for _, e := range entries {
+		if submodule, ok := e.Sys().(Submodule); ok {
+			submodules = append(submodules, submodule)
+		}
+	}
+	return submodules, nil
+}
+
+// readGitModules returns the URLs of the submodules described in the
+// .gitmodules file of the repository at commit, by path.
+func readGitModules(ctx context.Context, repo api.RepoName, commit api.CommitID) (map[string]string, error) {
+	cmd := gitserver.DefaultClient.Command("git", "show", fmt.Sprintf("%s:.gitmodules", commit))
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// The .gitmodules file is missing, so we don't know the URLs of
+		// the submodules.
+		return map[string]string{}, nil
+	}
+	return parseGitModules(out)
+}
+
+func parseGitModules(data []byte) (map[string]string, error) {
+	var cfg config.Config
+	if err := config.NewDecoder(bytes.NewBuffer(data)).Decode(&cfg); err != nil {
+		return nil, errors.Errorf("error parsing .gitmodules: %s", err)
+	}
+
+	// Submodules are named sections, and the name of a submodule is not
+	// necessarily its path.
+	urls := map[string]string{}
+	for _, s := range cfg.Section("submodule").Subsections {
+		if path := s.Option("path"); path != "" {
+			urls[path] = s.Option("url")
+		}
+	}
+	return urls, nil
+}
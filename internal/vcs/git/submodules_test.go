@@ -0,0 +1,73 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestParseGitModules(t *testing.T) {
+	data := []byte(`[submodule "lib"]
+	path = vendor/lib
+	url = https://github.com/foo/lib.git
+[submodule "docs"]
+	path = docs
+	url = ../docs.git
+[submodule "no-path"]
+	url = https://github.com/foo/bar.git
+`)
+	have, err := parseGitModules(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"vendor/lib": "https://github.com/foo/lib.git",
+		"docs":       "../docs.git",
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatalf("unexpected submodules (-want +have):\n%s", diff)
+	}
+
+	if _, err := parseGitModules([]byte("[submodule")); err == nil {
+		t.Fatal("expected error for invalid .gitmodules")
+	}
+}
+
+func TestSubmodules(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	submodDir := InitGitRepository(t,
+		"touch f",
+		"git add f",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m commit1 --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	)
+	const submodCommit = "94aa9078934ce2776ccbb589569eca5ef575f12e"
+
+	repo := MakeGitRepository(t,
+		"git -c protocol.file.allow=always submodule add "+filepath.ToSlash(submodDir)+" submod",
+		// The name of this submodule differs from its path.
+		"git -c protocol.file.allow=always submodule add --name other "+filepath.ToSlash(submodDir)+" dir/submod",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m 'add submodules' --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	)
+	commitID, err := ResolveRevision(ctx, repo, "master", ResolveRevisionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have, err := Submodules(ctx, repo, commitID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Submodule{
+		{URL: filepath.ToSlash(submodDir), Path: "dir/submod", CommitID: api.CommitID(submodCommit)},
+		{URL: filepath.ToSlash(submodDir), Path: "submod", CommitID: api.CommitID(submodCommit)},
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatalf("unexpected submodules (-want +have):\n%s", diff)
+	}
+}
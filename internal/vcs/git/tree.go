@@ -13,8 +13,6 @@ import (
 	"sync"
 	"time"
 
-	"gopkg.in/src-d/go-git.v4/plumbing/format/config"
-
 	"github.com/cockroachdb/errors"
 	"github.com/golang/groupcache/lru"
 
@@ -233,6 +231,8 @@ func lsTreeUncached(ctx context.Context, repo api.RepoName, commit api.CommitID,
 
 	trimPath := strings.TrimPrefix(path, "./")
 	lines := strings.Split(string(out), "\x00")
+	// gitModules maps the paths of the submodules to their URLs.
+	var gitModules map[string]string
 	fis := make([]fs.FileInfo, len(lines)-1)
 	for i, line := range lines {
 		if i == len(lines)-1 {
@@ -292,22 +292,19 @@ func lsTreeUncached(ctx context.Context, repo api.RepoName, commit api.CommitID,
 			}
 		case "commit":
 			mode = mode | ModeSubmodule
-			cmd := gitserver.DefaultClient.Command("git", "show", fmt.Sprintf("%s:.gitmodules", commit))
-			cmd.Repo = repo
-			var submodule Submodule
-			if out, err := cmd.Output(ctx); err == nil {
-
-				var cfg config.Config
-				err := config.NewDecoder(bytes.NewBuffer(out)).Decode(&cfg)
+			if gitModules == nil {
+				// We only read .gitmodules once, and only if the tree
+				// contains submodules.
+				gitModules, err = readGitModules(ctx, repo, commit)
 				if err != nil {
-					return nil, errors.Errorf("error parsing .gitmodules: %s", err)
+					return nil, err
 				}
-
-				submodule.Path = cfg.Section("submodule").Subsection(name).Option("path")
-				submodule.URL = cfg.Section("submodule").Subsection(name).Option("url")
 			}
-			submodule.CommitID = api.CommitID(oid.String())
-			sys = submodule
+			sys = Submodule{
+				URL:      gitModules[name],
+				Path:     name,
+				CommitID: api.CommitID(oid.String()),
+			}
 		case "tree":
 			mode = mode | os.ModeDir
 		}
@@ -0,0 +1,78 @@
+package inference
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func PythonPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		pathPattern(rawPattern("setup.py")),
+		pathPattern(rawPattern("pyproject.toml")),
+		pathPattern(rawPattern("requirements.txt")),
+	}
+}
+
+func CanIndexPythonRepo(gitclient GitClient, paths []string) bool {
+	for _, path := range paths {
+		if isPythonProjectPath(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const lsifPyImage = "sourcegraph/lsif-py:autoindex"
+
+func InferPythonIndexJobs(gitclient GitClient, paths []string) (indexes []config.IndexJob) {
+	for _, root := range pythonProjectRoots(paths) {
+		var localSteps []string
+		if contains(paths, filepath.Join(root, "requirements.txt")) {
+			localSteps = append(localSteps, "pip install -r requirements.txt")
+		}
+		if contains(paths, filepath.Join(root, "setup.py")) || contains(paths, filepath.Join(root, "pyproject.toml")) {
+			localSteps = append(localSteps, "pip install .")
+		}
+
+		indexes = append(indexes, config.IndexJob{
+			Steps:       nil,
+			LocalSteps:  localSteps,
+			Root:        root,
+			Indexer:     lsifPyImage,
+			IndexerArgs: []string{"lsif-py", ".", "--file", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		})
+	}
+
+	return indexes
+}
+
+// pythonProjectRoots returns the distinct directories containing a Python project file, in the
+// order in which they first occur in the given paths.
+func pythonProjectRoots(paths []string) (roots []string) {
+	for _, path := range paths {
+		if !isPythonProjectPath(path) {
+			continue
+		}
+
+		if root := dirWithoutDot(path); !contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+
+	return roots
+}
+
+var pythonSegmentBlockList = append([]string{"venv", ".venv", "site-packages", "node_modules"}, segmentBlockList...)
+
+func isPythonProjectPath(path string) bool {
+	switch filepath.Base(path) {
+	case "setup.py", "pyproject.toml", "requirements.txt":
+		return containsNoSegments(path, pythonSegmentBlockList...)
+	}
+
+	return false
+}
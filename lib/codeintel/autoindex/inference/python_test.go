@@ -0,0 +1,112 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func TestPythonPatterns(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"setup.py", true},
+		{"subdir/setup.py", true},
+		{"pyproject.toml", true},
+		{"subdir/pyproject.toml", true},
+		{"requirements.txt", true},
+		{"subdir/requirements.txt", true},
+		{"setup.py/subdir", false},
+		{"dev-requirements.txt", false},
+		{"main.py", false},
+	}
+
+	for _, testCase := range testCases {
+		match := false
+		for _, pattern := range PythonPatterns() {
+			if pattern.MatchString(testCase.path) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			if !testCase.expected {
+				t.Error(fmt.Sprintf("did not expect match: %s", testCase.path))
+			}
+
+		} else if testCase.expected {
+			t.Error(fmt.Sprintf("expected match: %s", testCase.path))
+		}
+	}
+}
+
+func TestCanIndexPythonRepo(t *testing.T) {
+	testCases := []struct {
+		paths    []string
+		expected bool
+	}{
+		{paths: []string{"setup.py"}, expected: true},
+		{paths: []string{"a/pyproject.toml"}, expected: true},
+		{paths: []string{"requirements.txt"}, expected: true},
+		{paths: []string{"venv/lib/foo/setup.py"}, expected: false},
+		{paths: []string{"tests/fixtures/requirements.txt"}, expected: false},
+		{paths: []string{"foo/bar-setup.py"}, expected: false},
+		{paths: []string{"package.json"}, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		name := strings.Join(testCase.paths, ", ")
+
+		t.Run(name, func(t *testing.T) {
+			if value := CanIndexPythonRepo(NewMockGitClient(), testCase.paths); value != testCase.expected {
+				t.Errorf("unexpected result from CanIndex. want=%v have=%v", testCase.expected, value)
+			}
+		})
+	}
+}
+
+func TestInferPythonIndexJobs(t *testing.T) {
+	paths := []string{
+		"requirements.txt",
+		"setup.py",
+		"a/pyproject.toml",
+		"b/requirements.txt",
+		".venv/lib/site-packages/c/setup.py",
+	}
+
+	expectedIndexJobs := []config.IndexJob{
+		{
+			Steps:       nil,
+			LocalSteps:  []string{"pip install -r requirements.txt", "pip install ."},
+			Root:        "",
+			Indexer:     lsifPyImage,
+			IndexerArgs: []string{"lsif-py", ".", "--file", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		},
+		{
+			Steps:       nil,
+			LocalSteps:  []string{"pip install ."},
+			Root:        "a",
+			Indexer:     lsifPyImage,
+			IndexerArgs: []string{"lsif-py", ".", "--file", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		},
+		{
+			Steps:       nil,
+			LocalSteps:  []string{"pip install -r requirements.txt"},
+			Root:        "b",
+			Indexer:     lsifPyImage,
+			IndexerArgs: []string{"lsif-py", ".", "--file", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		},
+	}
+	if diff := cmp.Diff(expectedIndexJobs, InferPythonIndexJobs(NewMockGitClient(), paths)); diff != "" {
+		t.Errorf("unexpected index jobs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,51 @@
+package inference
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func RubyPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		pathPattern(rawPattern("Gemfile")),
+	}
+}
+
+func CanIndexRubyRepo(gitclient GitClient, paths []string) bool {
+	for _, path := range paths {
+		if isGemfilePath(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const lsifRubyImage = "sourcegraph/lsif-ruby:autoindex"
+
+func InferRubyIndexJobs(gitclient GitClient, paths []string) (indexes []config.IndexJob) {
+	for _, path := range paths {
+		if !isGemfilePath(path) {
+			continue
+		}
+
+		indexes = append(indexes, config.IndexJob{
+			Steps:       nil,
+			LocalSteps:  []string{"bundle install"},
+			Root:        dirWithoutDot(path),
+			Indexer:     lsifRubyImage,
+			IndexerArgs: []string{"lsif-ruby", "--output", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		})
+	}
+
+	return indexes
+}
+
+var rubySegmentBlockList = append([]string{"vendor"}, segmentBlockList...)
+
+func isGemfilePath(path string) bool {
+	return filepath.Base(path) == "Gemfile" && containsNoSegments(path, rubySegmentBlockList...)
+}
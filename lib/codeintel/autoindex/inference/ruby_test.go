@@ -0,0 +1,97 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func TestRubyPatterns(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"Gemfile", true},
+		{"subdir/Gemfile", true},
+		{"Gemfile/subdir", false},
+		{"Gemfile.lock", false},
+		{"app.rb", false},
+	}
+
+	for _, testCase := range testCases {
+		match := false
+		for _, pattern := range RubyPatterns() {
+			if pattern.MatchString(testCase.path) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			if !testCase.expected {
+				t.Error(fmt.Sprintf("did not expect match: %s", testCase.path))
+			}
+
+		} else if testCase.expected {
+			t.Error(fmt.Sprintf("expected match: %s", testCase.path))
+		}
+	}
+}
+
+func TestCanIndexRubyRepo(t *testing.T) {
+	testCases := []struct {
+		paths    []string
+		expected bool
+	}{
+		{paths: []string{"Gemfile"}, expected: true},
+		{paths: []string{"a/Gemfile"}, expected: true},
+		{paths: []string{"vendor/bundle/foo/Gemfile"}, expected: false},
+		{paths: []string{"examples/Gemfile"}, expected: false},
+		{paths: []string{"foo/bar-Gemfile"}, expected: false},
+		{paths: []string{"package.json"}, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		name := strings.Join(testCase.paths, ", ")
+
+		t.Run(name, func(t *testing.T) {
+			if value := CanIndexRubyRepo(NewMockGitClient(), testCase.paths); value != testCase.expected {
+				t.Errorf("unexpected result from CanIndex. want=%v have=%v", testCase.expected, value)
+			}
+		})
+	}
+}
+
+func TestInferRubyIndexJobs(t *testing.T) {
+	paths := []string{
+		"Gemfile",
+		"engines/a/Gemfile",
+		"vendor/bundle/b/Gemfile",
+	}
+
+	expectedIndexJobs := []config.IndexJob{
+		{
+			Steps:       nil,
+			LocalSteps:  []string{"bundle install"},
+			Root:        "",
+			Indexer:     lsifRubyImage,
+			IndexerArgs: []string{"lsif-ruby", "--output", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		},
+		{
+			Steps:       nil,
+			LocalSteps:  []string{"bundle install"},
+			Root:        "engines/a",
+			Indexer:     lsifRubyImage,
+			IndexerArgs: []string{"lsif-ruby", "--output", "dump.lsif"},
+			Outfile:     "dump.lsif",
+		},
+	}
+	if diff := cmp.Diff(expectedIndexJobs, InferRubyIndexJobs(NewMockGitClient(), paths)); diff != "" {
+		t.Errorf("unexpected index jobs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,129 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func TestRustPatterns(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"Cargo.toml", true},
+		{"subdir/Cargo.toml", true},
+		{"Cargo.toml/subdir", false},
+		{"Cargo.lock", false},
+		{"main.rs", false},
+	}
+
+	for _, testCase := range testCases {
+		match := false
+		for _, pattern := range RustPatterns() {
+			if pattern.MatchString(testCase.path) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			if !testCase.expected {
+				t.Error(fmt.Sprintf("did not expect match: %s", testCase.path))
+			}
+
+		} else if testCase.expected {
+			t.Error(fmt.Sprintf("expected match: %s", testCase.path))
+		}
+	}
+}
+
+func TestCanIndexRustRepo(t *testing.T) {
+	testCases := []struct {
+		paths    []string
+		expected bool
+	}{
+		{paths: []string{"Cargo.toml"}, expected: true},
+		{paths: []string{"a/Cargo.toml"}, expected: true},
+		{paths: []string{"target/debug/build/foo/Cargo.toml"}, expected: false},
+		{paths: []string{"vendor/foo/Cargo.toml"}, expected: false},
+		{paths: []string{"foo/bar-Cargo.toml"}, expected: false},
+		{paths: []string{"go.mod"}, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		name := strings.Join(testCase.paths, ", ")
+
+		t.Run(name, func(t *testing.T) {
+			if value := CanIndexRustRepo(NewMockGitClient(), testCase.paths); value != testCase.expected {
+				t.Errorf("unexpected result from CanIndex. want=%v have=%v", testCase.expected, value)
+			}
+		})
+	}
+}
+
+func TestInferRustIndexJobs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		paths         []string
+		manifests     map[string]string
+		expectedRoots []string
+	}{
+		{
+			name:          "single crate",
+			paths:         []string{"Cargo.toml"},
+			expectedRoots: []string{""},
+		},
+		{
+			name:  "workspace",
+			paths: []string{"Cargo.toml", "crates/a/Cargo.toml", "crates/b/Cargo.toml"},
+			manifests: map[string]string{
+				"Cargo.toml": "[workspace]\nmembers = [\"crates/*\"]\n",
+			},
+			expectedRoots: []string{""},
+		},
+		{
+			name:  "nested workspace",
+			paths: []string{"a/Cargo.toml", "b/Cargo.toml", "b/c/Cargo.toml"},
+			manifests: map[string]string{
+				"b/Cargo.toml": "[package]\nname = \"b\"\n\n[workspace.dependencies]\n",
+			},
+			expectedRoots: []string{"a", "b"},
+		},
+		{
+			name:          "independent crates",
+			paths:         []string{"Cargo.toml", "a/Cargo.toml"},
+			manifests:     map[string]string{"Cargo.toml": "[package]\nname = \"root\"\n"},
+			expectedRoots: []string{"", "a"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockGit := NewMockGitClient()
+			mockGit.RawContentsFunc.SetDefaultHook(func(ctx context.Context, file string) ([]byte, error) {
+				return []byte(testCase.manifests[file]), nil
+			})
+
+			var expectedIndexJobs []config.IndexJob
+			for _, root := range testCase.expectedRoots {
+				expectedIndexJobs = append(expectedIndexJobs, config.IndexJob{
+					Steps:       nil,
+					LocalSteps:  []string{"cargo fetch"},
+					Root:        root,
+					Indexer:     lsifRustImage,
+					IndexerArgs: []string{"rust-analyzer", "lsif", ".", ">", "dump.lsif"},
+					Outfile:     "dump.lsif",
+				})
+			}
+			if diff := cmp.Diff(expectedIndexJobs, InferRustIndexJobs(mockGit, testCase.paths)); diff != "" {
+				t.Errorf("unexpected index jobs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -23,8 +23,59 @@ import (
 //
 // If getChildren == nil, no pruning of irrelevant data is performed.
 func Correlate(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc) (*precise.GroupedBundleDataChans, error) {
+	groupedBundleData, _, err := CorrelateWithOptions(ctx, r, root, getChildren, CorrelateOptions{})
+	return groupedBundleData, err
+}
+
+// CorrelateOptions control the resource usage of the correlation process.
+type CorrelateOptions struct {
+	// MemoryBudget is the maximum number of bytes of hover, diagnostic, documentation result, and
+	// documentation string payloads held in memory during correlation. Payloads exceeding this budget
+	// are written to a temporary file on disk. The graph structure of the index (ranges, result sets,
+	// monikers, and the edges between them) is always held in memory, so the budget bounds the
+	// memory used by payloads rather than the total memory used by correlation. A zero value holds
+	// all data in memory.
+	MemoryBudget int64
+
+	// TempDir is the directory in which the temporary file is created. The default temporary
+	// directory is used if empty.
+	TempDir string
+}
+
+// CorrelateWithOptions behaves like Correlate, but bounds the memory used during correlation as
+// configured by the given options. The returned close function must be called once the returned
+// channels have been drained. It releases the temporary file used by the correlation process and
+// returns any error that occurred while reading data back from disk, in which case the data sent
+// over the channels is incomplete and must be discarded.
+func CorrelateWithOptions(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc, opts CorrelateOptions) (*precise.GroupedBundleDataChans, func() error, error) {
+	var spillFile *datastructures.SpillFile
+	if opts.MemoryBudget > 0 {
+		spillFile = datastructures.NewSpillFile(opts.TempDir, opts.MemoryBudget)
+	}
+
+	groupedBundleData, err := correlateWithSpillFile(ctx, r, root, getChildren, spillFile)
+	if err != nil {
+		if spillFile != nil {
+			_ = spillFile.Close()
+		}
+
+		return nil, nil, err
+	}
+
+	closeFn := func() error {
+		if spillFile == nil {
+			return nil
+		}
+
+		return spillFile.Close()
+	}
+
+	return groupedBundleData, closeFn, nil
+}
+
+func correlateWithSpillFile(ctx context.Context, r io.Reader, root string, getChildren pathexistence.GetChildrenFunc, spillFile *datastructures.SpillFile) (*precise.GroupedBundleDataChans, error) {
 	// Read raw upload stream and return a correlation state
-	state, err := correlateFromReader(ctx, r, root)
+	state, err := correlateFromReader(ctx, r, root, spillFile)
 	if err != nil {
 		return nil, err
 	}
@@ -117,8 +168,9 @@ func gitRoot(path string) (string, error) {
 }
 
 // correlateFromReader reads the given upload stream and returns a correlation state object.
-// The data in the correlation state is neither canonicalized nor pruned.
-func correlateFromReader(ctx context.Context, r io.Reader, root string) (*State, error) {
+// The data in the correlation state is neither canonicalized nor pruned. Payloads are moved
+// to the given spill file (if non-nil) once its memory budget is exhausted.
+func correlateFromReader(ctx context.Context, r io.Reader, root string, spillFile *datastructures.SpillFile) (*State, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	ch := Read(ctx, r)
 	defer func() {
@@ -130,7 +182,7 @@ func correlateFromReader(ctx context.Context, r io.Reader, root string) (*State,
 		}
 	}()
 
-	wrappedState := newWrappedState(root)
+	wrappedState := newWrappedState(root, spillFile)
 
 	i := 0
 	for pair := range ch {
@@ -158,9 +210,9 @@ type wrappedState struct {
 	unsupportedVertices *datastructures.IDSet
 }
 
-func newWrappedState(dumpRoot string) *wrappedState {
+func newWrappedState(dumpRoot string, spillFile *datastructures.SpillFile) *wrappedState {
 	return &wrappedState{
-		State:               newState(),
+		State:               newState(spillFile),
 		dumpRoot:            dumpRoot,
 		unsupportedVertices: datastructures.NewIDSet(),
 	}
@@ -323,8 +375,7 @@ func correlateHoverResult(state *wrappedState, element Element) error {
 		return ErrUnexpectedPayload
 	}
 
-	state.HoverData[element.ID] = payload
-	return nil
+	return state.HoverData.Set(element.ID, payload)
 }
 
 func correlateMoniker(state *wrappedState, element Element) error {
@@ -353,8 +404,7 @@ func correlateDiagnosticResult(state *wrappedState, element Element) error {
 		return ErrUnexpectedPayload
 	}
 
-	state.DiagnosticResults[element.ID] = payload
-	return nil
+	return state.DiagnosticResults.Set(element.ID, payload)
 }
 
 func correlateContainsEdge(state *wrappedState, id int, edge Edge) error {
@@ -462,7 +512,7 @@ func correlateTextDocumentReferencesEdge(state *wrappedState, id int, edge Edge)
 }
 
 func correlateTextDocumentHoverEdge(state *wrappedState, id int, edge Edge) error {
-	if _, ok := state.HoverData[edge.InV]; !ok {
+	if !state.HoverData.Has(edge.InV) {
 		return malformedDump(id, edge.InV, "hoverResult")
 	}
 
@@ -531,7 +581,7 @@ func correlateDiagnosticEdge(state *wrappedState, id int, edge Edge) error {
 		return malformedDump(id, edge.OutV, "document")
 	}
 
-	if _, ok := state.DiagnosticResults[edge.InV]; !ok {
+	if !state.DiagnosticResults.Has(edge.InV) {
 		return malformedDump(id, edge.InV, "diagnosticResult")
 	}
 
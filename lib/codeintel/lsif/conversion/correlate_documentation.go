@@ -19,8 +19,7 @@ func correlateDocumentationResult(state *wrappedState, element Element) error {
 		// it gracefully.
 		payload.Tags = []protocol.Tag{}
 	}
-	state.DocumentationResultsData[element.ID] = payload
-	return nil
+	return state.DocumentationResultsData.Set(element.ID, payload)
 }
 
 func correlateDocumentationString(state *wrappedState, element Element) error {
@@ -29,7 +28,9 @@ func correlateDocumentationString(state *wrappedState, element Element) error {
 		return ErrUnexpectedPayload
 	}
 
-	state.DocumentationStringsData[element.ID] = payload
+	if err := state.DocumentationStringsData.Set(element.ID, payload); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -37,7 +38,7 @@ func correlateDocumentationResultEdge(state *wrappedState, id int, edge Edge) er
 	documentationResult := edge.InV
 	projectOrResultSet := edge.OutV
 
-	if _, ok := state.DocumentationResultsData[documentationResult]; !ok {
+	if !state.DocumentationResultsData.Has(documentationResult) {
 		return malformedDump(id, documentationResult, "documentationResult")
 	}
 
@@ -56,11 +57,11 @@ func correlateDocumentationChildrenEdge(state *wrappedState, id int, edge Edge)
 	parent := edge.OutV
 
 	for _, child := range children {
-		if _, ok := state.DocumentationResultsData[child]; !ok {
+		if !state.DocumentationResultsData.Has(child) {
 			return malformedDump(id, child, "documentationResult")
 		}
 	}
-	if _, ok := state.DocumentationResultsData[parent]; !ok {
+	if !state.DocumentationResultsData.Has(parent) {
 		return malformedDump(id, parent, "documentationResult")
 	}
 	state.DocumentationChildren[parent] = children
@@ -71,10 +72,10 @@ func correlateDocumentationStringEdge(state *wrappedState, id int, edge reader.D
 	documentationString := edge.InV
 	documentationResult := edge.OutV
 
-	if _, ok := state.DocumentationStringsData[documentationString]; !ok {
+	if !state.DocumentationStringsData.Has(documentationString) {
 		return malformedDump(id, documentationString, "documentationString")
 	}
-	if _, ok := state.DocumentationResultsData[documentationResult]; !ok {
+	if !state.DocumentationResultsData.Has(documentationResult) {
 		return malformedDump(id, documentationResult, "documentationResult")
 	}
 
@@ -3,6 +3,7 @@ package conversion
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion/datastructures"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/reader"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
 )
 
 func TestCorrelate(t *testing.T) {
@@ -19,7 +21,7 @@ func TestCorrelate(t *testing.T) {
 		t.Fatalf("unexpected error reading test file: %s", err)
 	}
 
-	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root")
+	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root", nil)
 	if err != nil {
 		t.Fatalf("unexpected error correlating input: %s", err)
 	}
@@ -104,10 +106,10 @@ func TestCorrelate(t *testing.T) {
 			14: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{2: datastructures.IDSetWith(4, 5)}),
 			15: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{}),
 		},
-		HoverData: map[int]string{
+		HoverData: datastructures.SpillableMapWith(hoverDataCodec, map[int]interface{}{
 			16: "```go\ntext A\n```",
 			17: "```go\ntext B\n```",
-		},
+		}),
 		MonikerData: map[int]Moniker{
 			18: {
 				Moniker: reader.Moniker{
@@ -146,8 +148,8 @@ func TestCorrelate(t *testing.T) {
 			22: {Name: "pkg A", Version: "v0.1.0"},
 			23: {Name: "pkg B", Version: "v1.2.3"},
 		},
-		DiagnosticResults: map[int][]Diagnostic{
-			49: {
+		DiagnosticResults: datastructures.SpillableMapWith(diagnosticResultsCodec, map[int]interface{}{
+			49: []Diagnostic{
 				{
 					Severity:       1,
 					Code:           "2322",
@@ -159,7 +161,7 @@ func TestCorrelate(t *testing.T) {
 					EndCharacter:   6,
 				},
 			},
-		},
+		}),
 		NextData: map[int]int{
 			9:  10,
 			10: 11,
@@ -183,8 +185,8 @@ func TestCorrelate(t *testing.T) {
 		}),
 
 		// TODO(slimsag): Documentation extension tests
-		DocumentationResultsData:  map[int]protocol.Documentation{},
-		DocumentationStringsData:  map[int]protocol.MarkupContent{},
+		DocumentationResultsData:  datastructures.SpillableMapWith(documentationResultsCodec, map[int]interface{}{}),
+		DocumentationStringsData:  datastructures.SpillableMapWith(documentationStringsCodec, map[int]interface{}{}),
 		DocumentationResultRoot:   -1,
 		DocumentationChildren:     map[int][]int{},
 		DocumentationStringLabel:  map[int]int{},
@@ -202,7 +204,7 @@ func TestCorrelateMetaDataRoot(t *testing.T) {
 		t.Fatalf("unexpected error reading test file: %s", err)
 	}
 
-	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root/")
+	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "root/", nil)
 	if err != nil {
 		t.Fatalf("unexpected error correlating input: %s", err)
 	}
@@ -217,10 +219,10 @@ func TestCorrelateMetaDataRoot(t *testing.T) {
 		ResultSetData:          map[int]ResultSet{},
 		DefinitionData:         map[int]*datastructures.DefaultIDSetMap{},
 		ReferenceData:          map[int]*datastructures.DefaultIDSetMap{},
-		HoverData:              map[int]string{},
+		HoverData:              datastructures.SpillableMapWith(hoverDataCodec, map[int]interface{}{}),
 		MonikerData:            map[int]Moniker{},
 		PackageInformationData: map[int]PackageInformation{},
-		DiagnosticResults:      map[int][]Diagnostic{},
+		DiagnosticResults:      datastructures.SpillableMapWith(diagnosticResultsCodec, map[int]interface{}{}),
 		NextData:               map[int]int{},
 		ImportedMonikers:       datastructures.NewIDSet(),
 		ExportedMonikers:       datastructures.NewIDSet(),
@@ -231,8 +233,8 @@ func TestCorrelateMetaDataRoot(t *testing.T) {
 		Diagnostics:            datastructures.NewDefaultIDSetMap(),
 
 		// TODO(slimsag): Documentation extension tests
-		DocumentationResultsData:  map[int]protocol.Documentation{},
-		DocumentationStringsData:  map[int]protocol.MarkupContent{},
+		DocumentationResultsData:  datastructures.SpillableMapWith(documentationResultsCodec, map[int]interface{}{}),
+		DocumentationStringsData:  datastructures.SpillableMapWith(documentationStringsCodec, map[int]interface{}{}),
 		DocumentationResultRoot:   -1,
 		DocumentationChildren:     map[int][]int{},
 		DocumentationStringLabel:  map[int]int{},
@@ -250,7 +252,7 @@ func TestCorrelateMetaDataRootX(t *testing.T) {
 		t.Fatalf("unexpected error reading test file: %s", err)
 	}
 
-	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "")
+	state, err := correlateFromReader(context.Background(), bytes.NewReader(input), "", nil)
 	if err != nil {
 		t.Fatalf("unexpected error correlating input: %s", err)
 	}
@@ -265,10 +267,10 @@ func TestCorrelateMetaDataRootX(t *testing.T) {
 		ResultSetData:          map[int]ResultSet{},
 		DefinitionData:         map[int]*datastructures.DefaultIDSetMap{},
 		ReferenceData:          map[int]*datastructures.DefaultIDSetMap{},
-		HoverData:              map[int]string{},
+		HoverData:              datastructures.SpillableMapWith(hoverDataCodec, map[int]interface{}{}),
 		MonikerData:            map[int]Moniker{},
 		PackageInformationData: map[int]PackageInformation{},
-		DiagnosticResults:      map[int][]Diagnostic{},
+		DiagnosticResults:      datastructures.SpillableMapWith(diagnosticResultsCodec, map[int]interface{}{}),
 		NextData:               map[int]int{},
 		ImportedMonikers:       datastructures.NewIDSet(),
 		ExportedMonikers:       datastructures.NewIDSet(),
@@ -279,8 +281,8 @@ func TestCorrelateMetaDataRootX(t *testing.T) {
 		Diagnostics:            datastructures.NewDefaultIDSetMap(),
 
 		// TODO(slimsag): Documentation extension tests
-		DocumentationResultsData:  map[int]protocol.Documentation{},
-		DocumentationStringsData:  map[int]protocol.MarkupContent{},
+		DocumentationResultsData:  datastructures.SpillableMapWith(documentationResultsCodec, map[int]interface{}{}),
+		DocumentationStringsData:  datastructures.SpillableMapWith(documentationStringsCodec, map[int]interface{}{}),
 		DocumentationResultRoot:   -1,
 		DocumentationChildren:     map[int][]int{},
 		DocumentationStringLabel:  map[int]int{},
@@ -291,3 +293,69 @@ func TestCorrelateMetaDataRootX(t *testing.T) {
 		t.Errorf("unexpected state (-want +got):\n%s", diff)
 	}
 }
+
+func TestCorrelateSpilled(t *testing.T) {
+	input, err := os.ReadFile("../testdata/dump1.lsif")
+	if err != nil {
+		t.Fatalf("unexpected error reading test file: %s", err)
+	}
+
+	correlate := func(spillFile *datastructures.SpillFile) *precise.GroupedBundleDataMaps {
+		groupedBundleData, err := correlateWithSpillFile(context.Background(), bytes.NewReader(input), "root", nil, spillFile)
+		if err != nil {
+			t.Fatalf("unexpected error correlating input: %s", err)
+		}
+
+		return precise.GroupedBundleDataChansToMaps(groupedBundleData)
+	}
+
+	expected := correlate(nil)
+
+	for _, budget := range []int64{0, 64} {
+		t.Run(fmt.Sprintf("budget=%d", budget), func(t *testing.T) {
+			spillFile := datastructures.NewSpillFile(t.TempDir(), budget)
+			actual := correlate(spillFile)
+
+			if err := spillFile.Close(); err != nil {
+				t.Fatalf("unexpected error closing spill file: %s", err)
+			}
+			if spillFile.Spilled() == 0 {
+				t.Errorf("expected data to be written to disk")
+			}
+			if diff := cmp.Diff(expected, actual); diff != "" {
+				t.Errorf("unexpected bundle data (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func BenchmarkCorrelate(b *testing.B) {
+	input, err := os.ReadFile("../testdata/dump1.lsif")
+	if err != nil {
+		b.Fatalf("unexpected error reading test file: %s", err)
+	}
+
+	for _, opts := range []CorrelateOptions{{}, {MemoryBudget: 1, TempDir: b.TempDir()}} {
+		name := "in-memory"
+		if opts.MemoryBudget > 0 {
+			name = "spilled"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				groupedBundleData, closeFn, err := CorrelateWithOptions(context.Background(), bytes.NewReader(input), "root", nil, opts)
+				if err != nil {
+					b.Fatalf("unexpected error correlating input: %s", err)
+				}
+
+				_ = precise.GroupedBundleDataChansToMaps(groupedBundleData)
+
+				if err := closeFn(); err != nil {
+					b.Fatalf("unexpected error closing correlator: %s", err)
+				}
+			}
+		})
+	}
+}
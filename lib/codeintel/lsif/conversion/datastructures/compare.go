@@ -5,6 +5,7 @@ import "github.com/google/go-cmp/cmp"
 var Comparers = []cmp.Option{
 	IDSetComparer,
 	DefaultIDSetMapComparer,
+	SpillableMapComparer,
 }
 
 // IDSetComparer is a github.com/google/go-cmp/cmp comparer that can be
@@ -16,3 +17,8 @@ var IDSetComparer = cmp.Comparer(compareIDSets)
 // be supplied to the cmp.Diff method to determine if two identifier sets contain
 // the same set of identifiers.
 var DefaultIDSetMapComparer = cmp.Comparer(compareDefaultIDSetMaps)
+
+// SpillableMapComparer is a github.com/google/go-cmp/cmp comparer that can be
+// supplied to the cmp.Diff method to determine if two spillable maps contain
+// the same keys and values.
+var SpillableMapComparer = cmp.Comparer(compareSpillableMaps)
@@ -0,0 +1,293 @@
+package datastructures
+
+import (
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SpillFile is an append-only temporary file shared by a set of spillable maps. The file
+// tracks the number of encoded value bytes held in memory by all of its maps. Once that
+// number would exceed the configured memory budget, new values are appended to the file
+// on disk and only their offsets are retained in memory.
+//
+// The correlation process holds every hover text, diagnostic, and documentation string of
+// an index until the entire upload has been read. For large indexes these payloads account
+// for the majority of the process's heap, so moving them to disk bounds memory usage by the
+// (much smaller) size of the graph structure itself.
+//
+// Values are written only while the upload is being read (from a single goroutine) but may
+// be read concurrently afterwards. Reads racing with Close fail instead of reading from a closed
+// file, and the error is returned by Close.
+type SpillFile struct {
+	dir      string
+	budget   int64
+	inMemory int64
+	size     int64
+
+	mu   sync.RWMutex // protects file
+	file *os.File
+
+	errMu sync.Mutex
+	err   error
+}
+
+// NewSpillFile creates a new spill file that holds at most budget bytes of values in memory.
+// The backing file is created lazily in the given directory (or the default temporary directory
+// if empty) once the budget is exceeded.
+func NewSpillFile(dir string, budget int64) *SpillFile {
+	return &SpillFile{dir: dir, budget: budget}
+}
+
+// Spilled returns the number of bytes written to disk.
+func (f *SpillFile) Spilled() int64 {
+	return f.size
+}
+
+// Close closes and removes the backing file. Any error that occurred while reading a value
+// from disk is returned, as maps cannot report errors to readers that cannot handle them.
+func (f *SpillFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := f.readErr()
+
+	if f.file != nil {
+		if closeErr := f.file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		f.file = nil
+	}
+
+	return err
+}
+
+// store retains the given encoded value either in memory or on disk, depending on the amount
+// of remaining memory budget. If the value is written to disk, its location is returned with a
+// nil slice.
+func (f *SpillFile) store(value []byte) ([]byte, spillSpan, error) {
+	if f.inMemory+int64(len(value)) <= f.budget {
+		f.inMemory += int64(len(value))
+		return value, spillSpan{}, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		file, err := os.CreateTemp(f.dir, "lsif-conversion-*")
+		if err != nil {
+			return nil, spillSpan{}, errors.Wrap(err, "creating spill file")
+		}
+
+		// Unlink the file immediately so that the disk space is reclaimed when the descriptor
+		// is closed, even if the process exits before the spill file is closed explicitly.
+		if err := os.Remove(file.Name()); err != nil {
+			_ = file.Close()
+			return nil, spillSpan{}, errors.Wrap(err, "removing spill file")
+		}
+
+		f.file = file
+	}
+
+	n, err := f.file.WriteAt(value, f.size)
+	if err != nil {
+		return nil, spillSpan{}, errors.Wrap(err, "writing to spill file")
+	}
+
+	span := spillSpan{offset: f.size, length: n}
+	f.size += int64(n)
+	return nil, span, nil
+}
+
+// load reads the value at the given location from disk.
+func (f *SpillFile) load(span spillSpan) ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.file == nil {
+		return nil, errSpillFileClosed
+	}
+
+	value := make([]byte, span.length)
+	if _, err := f.file.ReadAt(value, span.offset); err != nil {
+		return nil, errors.Wrap(err, "reading from spill file")
+	}
+
+	return value, nil
+}
+
+var errSpillFileClosed = errors.New("reading from closed spill file")
+
+func (f *SpillFile) setReadErr(err error) {
+	f.errMu.Lock()
+	defer f.errMu.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+func (f *SpillFile) readErr() error {
+	f.errMu.Lock()
+	defer f.errMu.Unlock()
+
+	return f.err
+}
+
+// spillSpan is the location of a value in a spill file.
+type spillSpan struct {
+	offset int64
+	length int
+}
+
+// Codec converts the values of a spillable map to and from bytes.
+type Codec struct {
+	Encode func(v interface{}) ([]byte, error)
+	Decode func(data []byte) (interface{}, error)
+}
+
+// SpillableMap is a map from integer keys to arbitrary values that can move its values to
+// disk once the memory budget of its spill file is exhausted. A map without a spill file
+// holds its values in memory without encoding them.
+type SpillableMap struct {
+	file   *SpillFile
+	codec  Codec
+	values map[int]interface{} // decoded (no spill file) or encoded (with spill file) values
+	spans  map[int]spillSpan   // locations of values written to disk
+}
+
+// NewSpillableMap creates a new empty spillable map. If file is nil, all values are held in
+// memory.
+func NewSpillableMap(file *SpillFile, codec Codec) *SpillableMap {
+	return &SpillableMap{
+		file:   file,
+		codec:  codec,
+		values: map[int]interface{}{},
+		spans:  map[int]spillSpan{},
+	}
+}
+
+// SpillableMapWith creates a memory-only spillable map with the given contents.
+func SpillableMapWith(codec Codec, m map[int]interface{}) *SpillableMap {
+	sm := NewSpillableMap(nil, codec)
+	for k, v := range m {
+		sm.values[k] = v
+	}
+
+	return sm
+}
+
+// Set inserts or replaces the value at the given key. Values should not be replaced once the
+// map has a spill file, as the space used by the previous value is not reclaimed.
+func (sm *SpillableMap) Set(key int, value interface{}) error {
+	if sm.file == nil {
+		sm.values[key] = value
+		return nil
+	}
+
+	encoded, err := sm.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+
+	encoded, span, err := sm.file.store(encoded)
+	if err != nil {
+		return err
+	}
+
+	if encoded != nil {
+		sm.values[key] = encoded
+		delete(sm.spans, key)
+	} else {
+		sm.spans[key] = span
+		delete(sm.values, key)
+	}
+
+	return nil
+}
+
+// Get returns the value at the given key. If the value cannot be read from disk or decoded, the
+// error is recorded in the spill file (and returned on close) and the value is reported as missing.
+func (sm *SpillableMap) Get(key int) (interface{}, bool) {
+	value, ok, err := sm.get(key)
+	if err != nil {
+		sm.file.setReadErr(err)
+		return nil, false
+	}
+
+	return value, ok
+}
+
+func (sm *SpillableMap) get(key int) (interface{}, bool, error) {
+	if value, ok := sm.values[key]; ok {
+		if sm.file == nil {
+			return value, true, nil
+		}
+
+		decoded, err := sm.codec.Decode(value.([]byte))
+		return decoded, err == nil, err
+	}
+
+	span, ok := sm.spans[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	encoded, err := sm.file.load(span)
+	if err != nil {
+		return nil, false, err
+	}
+
+	decoded, err := sm.codec.Decode(encoded)
+	return decoded, err == nil, err
+}
+
+// Has returns true if the map contains a value at the given key.
+func (sm *SpillableMap) Has(key int) bool {
+	if _, ok := sm.values[key]; ok {
+		return true
+	}
+
+	_, ok := sm.spans[key]
+	return ok
+}
+
+// Len returns the number of keys in the map.
+func (sm *SpillableMap) Len() int {
+	return len(sm.values) + len(sm.spans)
+}
+
+// Each invokes the given function with each key and value in the map.
+func (sm *SpillableMap) Each(f func(key int, value interface{})) {
+	for key := range sm.values {
+		if value, ok := sm.Get(key); ok {
+			f(key, value)
+		}
+	}
+	for key := range sm.spans {
+		if value, ok := sm.Get(key); ok {
+			f(key, value)
+		}
+	}
+}
+
+// compareSpillableMaps returns true if the given maps contain the same keys and (decoded) values.
+func compareSpillableMaps(x, y *SpillableMap) bool {
+	if x == nil && y == nil {
+		return true
+	}
+
+	if x == nil || y == nil || x.Len() != y.Len() {
+		return false
+	}
+
+	equal := true
+	x.Each(func(key int, value interface{}) {
+		other, ok := y.Get(key)
+		equal = equal && ok && reflect.DeepEqual(value, other)
+	})
+	return equal
+}
@@ -0,0 +1,121 @@
+package datastructures
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+var stringCodec = Codec{
+	Encode: func(v interface{}) ([]byte, error) { return []byte(v.(string)), nil },
+	Decode: func(data []byte) (interface{}, error) { return string(data), nil },
+}
+
+func TestSpillableMap(t *testing.T) {
+	for _, budget := range []int64{-1, 0, 64, 1 << 20} {
+		name := fmt.Sprintf("budget=%d", budget)
+
+		t.Run(name, func(t *testing.T) {
+			var file *SpillFile
+			if budget >= 0 {
+				file = NewSpillFile(t.TempDir(), budget)
+			}
+
+			m := NewSpillableMap(file, stringCodec)
+			for i := 1; i <= 100; i++ {
+				if err := m.Set(i, fmt.Sprintf("value-%d", i)); err != nil {
+					t.Fatalf("unexpected error setting value: %s", err)
+				}
+			}
+
+			if m.Len() != 100 {
+				t.Errorf("unexpected length. want=%d have=%d", 100, m.Len())
+			}
+			if m.Has(101) {
+				t.Errorf("unexpected has. want=%v have=%v", false, true)
+			}
+			if _, ok := m.Get(101); ok {
+				t.Errorf("unexpected value for missing key")
+			}
+
+			for i := 1; i <= 100; i++ {
+				if !m.Has(i) {
+					t.Errorf("unexpected has. want=%v have=%v", true, false)
+				}
+
+				value, ok := m.Get(i)
+				if expected := fmt.Sprintf("value-%d", i); !ok || value != expected {
+					t.Errorf("unexpected value. want=%q have=%q", expected, value)
+				}
+			}
+
+			seen := 0
+			m.Each(func(key int, value interface{}) { seen++ })
+			if seen != 100 {
+				t.Errorf("unexpected number of values. want=%d have=%d", 100, seen)
+			}
+
+			if file != nil {
+				if spilled := file.Spilled() > 0; spilled != (budget < 1<<20) {
+					t.Errorf("unexpected spill. want=%v have=%v", budget < 1<<20, spilled)
+				}
+
+				if err := file.Close(); err != nil {
+					t.Fatalf("unexpected error closing spill file: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSpillableMapReadError(t *testing.T) {
+	file := NewSpillFile(t.TempDir(), 0)
+	m := NewSpillableMap(file, stringCodec)
+	if err := m.Set(1, "value"); err != nil {
+		t.Fatalf("unexpected error setting value: %s", err)
+	}
+
+	// Simulate a failing disk
+	_ = file.file.Close()
+
+	if _, ok := m.Get(1); ok {
+		t.Errorf("unexpected value for unreadable key")
+	}
+	if err := file.Close(); err == nil {
+		t.Errorf("expected read error on close")
+	}
+}
+
+func TestSpillableMapConcurrentClose(t *testing.T) {
+	file := NewSpillFile(t.TempDir(), 0)
+	m := NewSpillableMap(file, stringCodec)
+	for i := 1; i <= 100; i++ {
+		if err := m.Set(i, fmt.Sprintf("value-%d", i)); err != nil {
+			t.Fatalf("unexpected error setting value: %s", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= 100; j++ {
+				// Values read after close are reported as missing
+				if value, ok := m.Get(j); ok && value != fmt.Sprintf("value-%d", j) {
+					t.Errorf("unexpected value. want=%q have=%q", fmt.Sprintf("value-%d", j), value)
+				}
+			}
+		}()
+	}
+
+	closeErr := file.Close()
+	wg.Wait()
+
+	if _, ok := m.Get(1); ok {
+		t.Errorf("unexpected value after close")
+	}
+	if closeErr != nil && closeErr != errSpillFileClosed {
+		t.Errorf("unexpected error closing spill file: %s", closeErr)
+	}
+}
@@ -126,13 +126,13 @@ func serializeDocument(state *State, documentID int) precise.DocumentData {
 		}
 
 		if rangeData.HoverResultID != 0 {
-			hoverData := state.HoverData[rangeData.HoverResultID]
+			hoverData := state.getHoverData(rangeData.HoverResultID)
 			document.HoverResults[toID(rangeData.HoverResultID)] = hoverData
 		}
 	})
 
 	state.Diagnostics.SetEach(documentID, func(diagnosticID int) {
-		for _, diagnostic := range state.DiagnosticResults[diagnosticID] {
+		for _, diagnostic := range state.getDiagnosticResults(diagnosticID) {
 			document.Diagnostics = append(document.Diagnostics, precise.DiagnosticData{
 				Severity:       diagnostic.Severity,
 				Code:           diagnostic.Code,
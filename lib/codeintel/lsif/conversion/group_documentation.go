@@ -243,11 +243,11 @@ func (p *pageCollector) collect(ctx context.Context, ch chan<- *precise.Document
 	walk = func(parent *precise.DocumentationNode, documentationResult int, pathID string) {
 		labelID := p.state.DocumentationStringLabel[documentationResult]
 		detailID := p.state.DocumentationStringDetail[documentationResult]
-		documentation := p.state.DocumentationResultsData[documentationResult]
+		documentation := p.state.getDocumentationResult(documentationResult)
 		this := &precise.DocumentationNode{
 			Documentation: documentation,
-			Label:         p.state.DocumentationStringsData[labelID],
-			Detail:        p.state.DocumentationStringsData[detailID],
+			Label:         p.state.getDocumentationString(labelID),
+			Detail:        p.state.getDocumentationString(detailID),
 		}
 		switch {
 		case pathID == "":
@@ -151,10 +151,10 @@ func TestGroupBundleData(t *testing.T) {
 				1003: datastructures.IDSetWith(2007, 2009),
 			}),
 		},
-		HoverData: map[int]string{
+		HoverData: datastructures.SpillableMapWith(hoverDataCodec, map[int]interface{}{
 			3008: "foo",
 			3009: "bar",
-		},
+		}),
 		MonikerData: map[int]Moniker{
 			4001: {
 				Moniker: reader.Moniker{
@@ -219,8 +219,8 @@ func TestGroupBundleData(t *testing.T) {
 				Version: "3.2.1",
 			},
 		},
-		DiagnosticResults: map[int][]Diagnostic{
-			1001: {
+		DiagnosticResults: datastructures.SpillableMapWith(diagnosticResultsCodec, map[int]interface{}{
+			1001: []Diagnostic{
 				{
 					Severity:       1,
 					Code:           "1234",
@@ -232,7 +232,7 @@ func TestGroupBundleData(t *testing.T) {
 					EndCharacter:   14,
 				},
 			},
-			1002: {
+			1002: []Diagnostic{
 				{
 					Severity:       2,
 					Code:           "2",
@@ -244,7 +244,7 @@ func TestGroupBundleData(t *testing.T) {
 					EndCharacter:   24,
 				},
 			},
-			1003: {
+			1003: []Diagnostic{
 				{
 					Severity:       3,
 					Code:           "3234",
@@ -266,7 +266,7 @@ func TestGroupBundleData(t *testing.T) {
 					EndCharacter:   44,
 				},
 			},
-		},
+		}),
 		ImportedMonikers: datastructures.IDSetWith(4001, 4006),
 		ExportedMonikers: datastructures.IDSetWith(4003, 4005),
 		Contains: datastructures.DefaultIDSetMapWith(map[int]*datastructures.IDSet{
@@ -282,6 +282,8 @@ func TestGroupBundleData(t *testing.T) {
 			1001: datastructures.IDSetWith(1001, 1002),
 			1002: datastructures.IDSetWith(1003),
 		}),
+		DocumentationResultsData: datastructures.SpillableMapWith(documentationResultsCodec, map[int]interface{}{}),
+		DocumentationStringsData: datastructures.SpillableMapWith(documentationStringsCodec, map[int]interface{}{}),
 	}
 
 	actualBundleData, err := groupBundleData(context.Background(), state)
@@ -1,6 +1,8 @@
 package conversion
 
 import (
+	"encoding/json"
+
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion/datastructures"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
 )
@@ -14,10 +16,10 @@ type State struct {
 	ResultSetData          map[int]ResultSet
 	DefinitionData         map[int]*datastructures.DefaultIDSetMap
 	ReferenceData          map[int]*datastructures.DefaultIDSetMap
-	HoverData              map[int]string
+	HoverData              *datastructures.SpillableMap // maps hoverResult vertices -> hover text (string)
 	MonikerData            map[int]Moniker
 	PackageInformationData map[int]PackageInformation
-	DiagnosticResults      map[int][]Diagnostic
+	DiagnosticResults      *datastructures.SpillableMap    // maps diagnosticResult vertices -> diagnostics ([]Diagnostic)
 	NextData               map[int]int                     // maps range/result sets related via next edges
 	ImportedMonikers       *datastructures.IDSet           // moniker ids that have kind "import"
 	ExportedMonikers       *datastructures.IDSet           // moniker ids that have kind "export"
@@ -28,26 +30,30 @@ type State struct {
 	Diagnostics            *datastructures.DefaultIDSetMap // maps diagnostics to their documents
 
 	// Sourcegraph extensions
-	DocumentationResultsData  map[int]protocol.Documentation // maps documentationResult vertices -> their data
-	DocumentationStringsData  map[int]protocol.MarkupContent // maps documentationString vertices -> their data
-	DocumentationResultRoot   int                            // the documentationResult vertex corresponding to the project root.
-	DocumentationChildren     map[int][]int                  // maps documentationResult vertex -> ordered list of children documentationResult vertices
-	DocumentationStringLabel  map[int]int                    // maps documentationResult vertex -> label documentationString vertex
-	DocumentationStringDetail map[int]int                    // maps documentationResult vertex -> detail documentationString vertex
+	DocumentationResultsData  *datastructures.SpillableMap // maps documentationResult vertices -> their data (protocol.Documentation)
+	DocumentationStringsData  *datastructures.SpillableMap // maps documentationString vertices -> their data (protocol.MarkupContent)
+	DocumentationResultRoot   int                          // the documentationResult vertex corresponding to the project root.
+	DocumentationChildren     map[int][]int                // maps documentationResult vertex -> ordered list of children documentationResult vertices
+	DocumentationStringLabel  map[int]int                  // maps documentationResult vertex -> label documentationString vertex
+	DocumentationStringDetail map[int]int                  // maps documentationResult vertex -> detail documentationString vertex
 }
 
-// newState create a new State with zero-valued map fields.
-func newState() *State {
+// newState create a new State with zero-valued map fields. Hover, diagnostic, documentation result,
+// and documentation string payloads are moved to the given spill file once its memory budget is
+// exhausted. The graph structure (ranges, result sets, monikers, and the edges between them) is
+// always held in memory, as it is accessed too frequently to be read back from disk. If the spill
+// file is nil, all data is held in memory.
+func newState(spillFile *datastructures.SpillFile) *State {
 	return &State{
 		DocumentData:           map[int]string{},
 		RangeData:              map[int]Range{},
 		ResultSetData:          map[int]ResultSet{},
 		DefinitionData:         map[int]*datastructures.DefaultIDSetMap{},
 		ReferenceData:          map[int]*datastructures.DefaultIDSetMap{},
-		HoverData:              map[int]string{},
+		HoverData:              datastructures.NewSpillableMap(spillFile, hoverDataCodec),
 		MonikerData:            map[int]Moniker{},
 		PackageInformationData: map[int]PackageInformation{},
-		DiagnosticResults:      map[int][]Diagnostic{},
+		DiagnosticResults:      datastructures.NewSpillableMap(spillFile, diagnosticResultsCodec),
 		NextData:               map[int]int{},
 		ImportedMonikers:       datastructures.NewIDSet(),
 		ExportedMonikers:       datastructures.NewIDSet(),
@@ -58,11 +64,79 @@ func newState() *State {
 		Diagnostics:            datastructures.NewDefaultIDSetMap(),
 
 		// Sourcegraph extensions
-		DocumentationResultsData:  map[int]protocol.Documentation{},
-		DocumentationStringsData:  map[int]protocol.MarkupContent{},
+		DocumentationResultsData:  datastructures.NewSpillableMap(spillFile, documentationResultsCodec),
+		DocumentationStringsData:  datastructures.NewSpillableMap(spillFile, documentationStringsCodec),
 		DocumentationResultRoot:   -1,
 		DocumentationChildren:     map[int][]int{},
 		DocumentationStringLabel:  map[int]int{},
 		DocumentationStringDetail: map[int]int{},
 	}
 }
+
+// getHoverData returns the hover text with the given identifier.
+func (s *State) getHoverData(id int) string {
+	if value, ok := s.HoverData.Get(id); ok {
+		return value.(string)
+	}
+
+	return ""
+}
+
+// getDiagnosticResults returns the diagnostics of the diagnostic result with the given identifier.
+func (s *State) getDiagnosticResults(id int) []Diagnostic {
+	if value, ok := s.DiagnosticResults.Get(id); ok {
+		return value.([]Diagnostic)
+	}
+
+	return nil
+}
+
+// getDocumentationResult returns the documentation result with the given identifier.
+func (s *State) getDocumentationResult(id int) protocol.Documentation {
+	if value, ok := s.DocumentationResultsData.Get(id); ok {
+		return value.(protocol.Documentation)
+	}
+
+	return protocol.Documentation{}
+}
+
+// getDocumentationString returns the documentation string with the given identifier.
+func (s *State) getDocumentationString(id int) protocol.MarkupContent {
+	if value, ok := s.DocumentationStringsData.Get(id); ok {
+		return value.(protocol.MarkupContent)
+	}
+
+	return protocol.MarkupContent{}
+}
+
+var hoverDataCodec = datastructures.Codec{
+	Encode: func(v interface{}) ([]byte, error) { return []byte(v.(string)), nil },
+	Decode: func(data []byte) (interface{}, error) { return string(data), nil },
+}
+
+var diagnosticResultsCodec = datastructures.Codec{
+	Encode: json.Marshal,
+	Decode: func(data []byte) (interface{}, error) {
+		var diagnostics []Diagnostic
+		err := json.Unmarshal(data, &diagnostics)
+		return diagnostics, err
+	},
+}
+
+var documentationResultsCodec = datastructures.Codec{
+	Encode: json.Marshal,
+	Decode: func(data []byte) (interface{}, error) {
+		var documentation protocol.Documentation
+		err := json.Unmarshal(data, &documentation)
+		return documentation, err
+	},
+}
+
+var documentationStringsCodec = datastructures.Codec{
+	Encode: json.Marshal,
+	Decode: func(data []byte) (interface{}, error) {
+		var content protocol.MarkupContent
+		err := json.Unmarshal(data, &content)
+		return content, err
+	},
+}
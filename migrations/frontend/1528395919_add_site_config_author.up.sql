@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE critical_and_site_config ADD COLUMN IF NOT EXISTS author_user_id integer;
+COMMENT ON COLUMN critical_and_site_config.author_user_id IS 'The user who created this revision of the configuration. Null for revisions created before authors were tracked or created by the system.';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS search_job_repos;
+DROP TABLE IF EXISTS search_jobs;
+
+COMMIT;
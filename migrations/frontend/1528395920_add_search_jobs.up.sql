@@ -0,0 +1,52 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS search_jobs (
+    id bigserial PRIMARY KEY,
+    initiator_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    query text NOT NULL,
+    state text DEFAULT 'queued',
+    failure_message text,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    process_after timestamp with time zone,
+    num_resets integer NOT NULL DEFAULT 0,
+    num_failures integer NOT NULL DEFAULT 0,
+    execution_logs json[],
+    worker_hostname text NOT NULL DEFAULT '',
+    last_heartbeat_at timestamp with time zone,
+    notified_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS search_jobs_initiator_id ON search_jobs(initiator_id);
+CREATE INDEX IF NOT EXISTS search_jobs_state ON search_jobs(state);
+
+COMMENT ON TABLE search_jobs IS 'Exhaustive searches run in the background. The job itself resolves the repositories to search; each repository is then searched by a row in search_job_repos.';
+COMMENT ON COLUMN search_jobs.notified_at IS 'When the initiator was notified that every repository of the job reached a terminal state.';
+
+CREATE TABLE IF NOT EXISTS search_job_repos (
+    id bigserial PRIMARY KEY,
+    search_job_id bigint NOT NULL REFERENCES search_jobs(id) ON DELETE CASCADE,
+    repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+    match_count integer,
+    state text DEFAULT 'queued',
+    failure_message text,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    process_after timestamp with time zone,
+    num_resets integer NOT NULL DEFAULT 0,
+    num_failures integer NOT NULL DEFAULT 0,
+    execution_logs json[],
+    worker_hostname text NOT NULL DEFAULT '',
+    last_heartbeat_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    UNIQUE (search_job_id, repo_id)
+);
+
+CREATE INDEX IF NOT EXISTS search_job_repos_search_job_id_state ON search_job_repos(search_job_id, state);
+CREATE INDEX IF NOT EXISTS search_job_repos_state ON search_job_repos(state);
+
+COMMENT ON COLUMN search_job_repos.match_count IS 'The number of matches written to the results of this repository. Null until the repository has been searched.';
+
+COMMIT;
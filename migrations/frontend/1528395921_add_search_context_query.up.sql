@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE search_contexts ADD COLUMN IF NOT EXISTS query text;
+COMMENT ON COLUMN search_contexts.query IS 'Search query that defines the repositories of a query-defined search context. Null for search contexts defined by a list of repositories in search_context_repos.';
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS repo_kvps (
+    repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+    key text NOT NULL,
+    value text,
+    PRIMARY KEY (repo_id, key)
+);
+
+COMMENT ON TABLE repo_kvps IS 'Key-value pairs of repository metadata defined by site admins.';
+COMMENT ON COLUMN repo_kvps.value IS 'The value of the pair. Null for pairs that act as tags without a value.';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE gitserver_repos ADD COLUMN IF NOT EXISTS repo_size_bytes bigint;
+
+COMMENT ON COLUMN gitserver_repos.repo_size_bytes IS 'The on-disk size of the clone of the repository in bytes, as last computed by gitserver. Null if it has not been computed yet.';
+
+COMMIT;
@@ -21,6 +21,10 @@ type AWSCodeCommitConnection struct {
 	// See the AWS CodeCommit documentation on Git credentials for CodeCommit: https://docs.aws.amazon.com/IAM/latest/UserGuide/id_credentials_ssh-keys.html#git-credentials-code-commit.
 	// For detailed instructions on how to create the credentials in IAM, see this page: https://docs.aws.amazon.com/codecommit/latest/userguide/setting-up-gc.html
 	GitCredentials AWSCodeCommitGitCredentials `json:"gitCredentials"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// InitialRepositoryEnablement description: Deprecated and ignored field which will be removed entirely in the next release. AWS CodeCommit repositories can no longer be enabled or disabled explicitly. Configure which repositories should not be mirrored via "exclude" instead.
 	InitialRepositoryEnablement bool `json:"initialRepositoryEnablement,omitempty"`
 	// Region description: The AWS region in which to access AWS CodeCommit. See the list of supported regions at https://docs.aws.amazon.com/codecommit/latest/userguide/regions.html#regions-git.
@@ -189,6 +193,10 @@ type BitbucketCloudConnection struct {
 	//
 	// Supports excluding by name ({"name": "myorg/myrepo"}) or by UUID ({"uuid": "{fceb73c7-cef6-4abe-956d-e471281126bd}"}).
 	Exclude []*ExcludedBitbucketCloudRepo `json:"exclude,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Bitbucket Cloud.
 	//
 	// If "http", Sourcegraph will access Bitbucket Cloud repositories using Git URLs of the form https://bitbucket.org/myteam/myproject.git.
@@ -241,6 +249,10 @@ type BitbucketServerConnection struct {
 	Exclude []*ExcludedBitbucketServerRepo `json:"exclude,omitempty"`
 	// ExcludePersonalRepositories description: Whether or not personal repositories should be excluded or not. When true, Sourcegraph will ignore personal repositories it may have access to. See https://docs.sourcegraph.com/integration/bitbucket_server#excluding-personal-repositories for more information.
 	ExcludePersonalRepositories bool `json:"excludePersonalRepositories,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Bitbucket Server instance.
 	//
 	// If "http", Sourcegraph will access Bitbucket Server repositories using Git URLs of the form http(s)://bitbucket.example.com/scm/myproject/myrepo.git (using https: if the Bitbucket Server instance uses HTTPS).
@@ -572,6 +584,16 @@ type ExperimentalFeatures struct {
 	EnablePostSignupFlow bool `json:"enablePostSignupFlow,omitempty"`
 	// EventLogging description: Enables user event logging inside of the Sourcegraph instance. This will allow admins to have greater visibility of user activity, such as frequently viewed pages, frequent searches, and more. These event logs (and any specific user actions) are only stored locally, and never leave this Sourcegraph instance.
 	EventLogging string `json:"eventLogging,omitempty"`
+	// GitLFS description: Resolve files tracked by Git LFS to their content in archives and file reads, instead of returning the pointer files stored in Git. This affects search, file views and the symbols service. Objects are fetched from the LFS server of the code host with the credentials of the code host connection, and cached on gitserver.
+	GitLFS *GitLFS `json:"gitLFS,omitempty"`
+	// GitPartialClone description: JSON array of configuration that maps from Git clone URL domain/path to a partial clone filter. Matching repositories are cloned without the blobs excluded by the filter, which are fetched on demand when they are read. Repositories already cloned must be recloned for a change to take effect.
+	GitPartialClone []*GitPartialCloneMapping `json:"gitPartialClone,omitempty"`
+	// GitServerCommandCache description: Cache the output of expensive read-only git commands on gitserver, such as commit counts, logs and recursive tree listings, if their arguments only reference immutable commit IDs. Repeated commands are served from the cache instead of being run again.
+	GitServerCommandCache *GitServerCommandCache `json:"gitServerCommandCache,omitempty"`
+	// GitServerDiskQuotas description: JSON array of limits on the disk space used on gitservers by the repositories of a code host connection. Once the cloned repositories of a code host connection use up its quota, its repositories that aren't cloned yet are not cloned anymore. Repositories that are already cloned continue to be updated. A repository synced by several code host connections counts towards the quota of each of them.
+	GitServerDiskQuotas []*GitServerDiskQuota `json:"gitServerDiskQuotas,omitempty"`
+	// GitServerReplicas description: Mirror hot repositories to additional gitservers, and spread read-only requests for them (such as file reads, archives and commit searches) across all their copies. A repository is hot if it is listed in `repos`, or if a service sends more than `hotRequestsPerMinute` read requests for it per minute. Replicas are cloned from the primary gitserver of a repository when they are first read, and fetched from it after each update of the repository until they have not been read for `idleDays`.
+	GitServerReplicas *GitServerReplicas `json:"gitServerReplicas,omitempty"`
 	// JvmPackages description: Allow adding JVM packages code host connections
 	JvmPackages string `json:"jvmPackages,omitempty"`
 	// Perforce description: Allow adding Perforce code host connections
@@ -586,6 +608,8 @@ type ExperimentalFeatures struct {
 	SearchMultipleRevisionsPerRepository *bool `json:"searchMultipleRevisionsPerRepository,omitempty"`
 	// StructuralSearch description: Enables structural search.
 	StructuralSearch string `json:"structuralSearch,omitempty"`
+	// Subversion description: Allow adding Subversion code host connections
+	Subversion string `json:"subversion,omitempty"`
 	// TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
 	TlsExternal *TlsExternal `json:"tls.external,omitempty"`
 	// VersionContexts description: DEPRECATED: Use search contexts instead.
@@ -691,6 +715,10 @@ type GitHubConnection struct {
 	//
 	// Note: ID is the GitHub GraphQL ID, not the GitHub database ID. eg: "curl https://api.github.com/repos/vuejs/vue | jq .node_id"
 	Exclude []*ExcludedGitHubRepo `json:"exclude,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this GitHub instance.
 	//
 	// If "http", Sourcegraph will access GitHub repositories using Git URLs of the form http(s)://github.com/myteam/myproject.git (using https: if the GitHub instance uses HTTPS).
@@ -750,6 +778,16 @@ type GitHubWebhook struct {
 	Secret string `json:"secret"`
 }
 
+// GitLFS description: Resolve files tracked by Git LFS to their content in archives and file reads, instead of returning the pointer files stored in Git. This affects search, file views and the symbols service. Objects are fetched from the LFS server of the code host with the credentials of the code host connection, and cached on gitserver.
+type GitLFS struct {
+	// CacheSizeMB description: The maximum size in megabytes of the LFS objects cached on each gitserver. The least recently used objects are evicted first.
+	CacheSizeMB int `json:"cacheSizeMB,omitempty"`
+	// Enabled description: Whether Git LFS pointer files are resolved to their content.
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxFileSizeKB description: The maximum size in kilobytes of an LFS object to resolve. Larger files are returned as pointer files.
+	MaxFileSizeKB int `json:"maxFileSizeKB,omitempty"`
+}
+
 // GitLabAuthProvider description: Configures the GitLab OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create a OAuth App on your GitLab instance: https://docs.gitlab.com/ee/integration/oauth_provider.html. The application should have `api` and `read_user` scopes and the callback URL set to the concatenation of your Sourcegraph instance URL and "/.auth/gitlab/callback".
 type GitLabAuthProvider struct {
 	// ApiScope description: The OAuth API scope that should be used
@@ -782,6 +820,10 @@ type GitLabConnection struct {
 	CloudGlobal bool `json:"cloudGlobal,omitempty"`
 	// Exclude description: A list of projects to never mirror from this GitLab instance. Takes precedence over "projects" and "projectQuery" configuration. Supports excluding by name ({"name": "group/name"}) or by ID ({"id": 42}).
 	Exclude []*ExcludedGitLabProject `json:"exclude,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this GitLab instance.
 	//
 	// If "http", Sourcegraph will access GitLab repositories using Git URLs of the form http(s)://gitlab.example.com/myteam/myproject.git (using https: if the GitLab instance uses HTTPS).
@@ -840,10 +882,52 @@ type GitLabWebhook struct {
 	Secret string `json:"secret"`
 }
 
+// GitPartialCloneMapping description: Mapping from Git clone URL domain/path to a partial clone filter. The `domainPath` field contains the Git clone URL domain/path part, or a prefix of it to apply the filter to all repositories under a code host or namespace. The `filter` field contains the filter passed to `git fetch --filter`.
+type GitPartialCloneMapping struct {
+	// DomainPath description: Git clone URL domain/path, or a prefix of it
+	DomainPath string `json:"domainPath"`
+	// Filter description: Partial clone filter. Use `blob:limit=<size>` to only omit large blobs, or `blob:none` to omit all blobs.
+	Filter string `json:"filter"`
+}
+
+// GitServerCommandCache description: Cache the output of expensive read-only git commands on gitserver, such as commit counts, logs and recursive tree listings, if their arguments only reference immutable commit IDs. Repeated commands are served from the cache instead of being run again.
+type GitServerCommandCache struct {
+	// CacheSizeMB description: The maximum size in megabytes of the command output cached on each gitserver. The least recently used output is evicted first.
+	CacheSizeMB int `json:"cacheSizeMB,omitempty"`
+	// Enabled description: Whether the output of commands is cached.
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxEntrySizeKB description: The maximum size in kilobytes of the output of a command to cache. Commands with larger output are not cached.
+	MaxEntrySizeKB int `json:"maxEntrySizeKB,omitempty"`
+}
+
+// GitServerDiskQuota description: Limit on the disk space used by the repositories of a code host connection.
+type GitServerDiskQuota struct {
+	// ExternalService description: The database ID of the code host connection.
+	ExternalService int `json:"externalService"`
+	// MaxBytes description: The maximum number of bytes the cloned repositories of the code host connection may use on disk, summed across all gitservers.
+	MaxBytes int `json:"maxBytes"`
+}
+
+// GitServerReplicas description: Mirror hot repositories to additional gitservers, and spread read-only requests for them (such as file reads, archives and commit searches) across all their copies. A repository is hot if it is listed in `repos`, or if a service sends more than `hotRequestsPerMinute` read requests for it per minute. Replicas are cloned from the primary gitserver of a repository when they are first read, and fetched from it after each update of the repository until they have not been read for `idleDays`.
+type GitServerReplicas struct {
+	// HotRequestsPerMinute description: The number of read requests per minute a single service must send for a repository for it to be replicated. 0 only replicates the repositories listed in `repos`.
+	HotRequestsPerMinute int `json:"hotRequestsPerMinute,omitempty"`
+	// IdleDays description: The number of days after which a replica of a repository which is not listed in `repos` is removed if it has not been read. Removed replicas are no longer fetched, and are cloned again when the repository becomes hot again.
+	IdleDays int `json:"idleDays,omitempty"`
+	// Replicas description: The number of gitservers in addition to its primary gitserver that hold a replica of a hot repository. 0 disables replicas.
+	Replicas int `json:"replicas,omitempty"`
+	// Repos description: The names of the repositories which are always replicated, such as "github.com/sourcegraph/sourcegraph". Their replicas are cloned eagerly.
+	Repos []string `json:"repos,omitempty"`
+}
+
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
 	// Exclude description: A list of repositories to never mirror from this Gitolite instance. Supports excluding by exact name ({"name": "foo"}).
 	Exclude []*ExcludedGitoliteRepo `json:"exclude,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// Host description: Gitolite host that stores the repositories (e.g., git@gitolite.example.com, ssh://git@gitolite.example.com:2222/).
 	Host string `json:"host"`
 	// Phabricator description: Phabricator instance that integrates with this Gitolite instance
@@ -1158,7 +1242,11 @@ type OpenIDConnectAuthProvider struct {
 
 // OtherExternalServiceConnection description: Configuration for a Connection to Git repositories for which an external service integration isn't yet available.
 type OtherExternalServiceConnection struct {
-	Repos []string `json:"repos"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int      `json:"gitMaxConcurrentClones,omitempty"`
+	Repos                  []string `json:"repos"`
 	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for the repositories. In the pattern, the variable "{base}" is replaced with the Git clone base URL host and path, and "{repo}" is replaced with the repository path taken from the `repos` field.
 	//
 	// For example, if your Git clone base URL is https://git.example.com/repos and `repos` contains the value "my/repo", then a repositoryPathPattern of "{base}/{repo}" would mean that a repository at https://git.example.com/repos/my/repo is available on Sourcegraph at https://sourcegraph.example.com/git.example.com/repos/my/repo.
@@ -1197,6 +1285,10 @@ type PerforceConnection struct {
 	Depots []string `json:"depots,omitempty"`
 	// FusionClient description: Configuration for the experimental p4-fusion client
 	FusionClient *FusionClient `json:"fusionClient,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// MaxChanges description: Only import at most n changes when possible (git p4 clone --max-changes).
 	MaxChanges float64 `json:"maxChanges,omitempty"`
 	// P4Client description: Client specified as an option for p4 CLI (P4CLIENT, also enables '--use-client-spec')
@@ -1327,6 +1419,20 @@ type SMTPServerConfig struct {
 	Username string `json:"username,omitempty"`
 }
 
+// SearchCostBudgets description: Budgets for the estimated cost of the searches each user and access token can run within a time window. The cost of a search grows with the number of repositories it searches and with expensive options such as "count:all", "type:diff" and structural search. Searches of site admins are not limited.
+type SearchCostBudgets struct {
+	// MaxQueueSeconds description: The maximum time an over budget search is queued for when overBudget is "queue". Searches which would wait longer are rejected. Defaults to 30 seconds.
+	MaxQueueSeconds int `json:"maxQueueSeconds,omitempty"`
+	// OverBudget description: What to do with searches over budget: "reject" them with an alert, or "queue" them until the next window if it starts within maxQueueSeconds.
+	OverBudget string `json:"overBudget,omitempty"`
+	// PerAccessToken description: The maximum total cost of the searches run with an access token within a window. Searches with an access token also count towards the budget of its user. Any value less than or equal to zero means unlimited.
+	PerAccessToken int `json:"perAccessToken,omitempty"`
+	// PerUser description: The maximum total cost of the searches of a user within a window. Any value less than or equal to zero means unlimited.
+	PerUser int `json:"perUser,omitempty"`
+	// WindowSeconds description: The length of the window over which search costs are counted, in seconds. Defaults to 1 hour.
+	WindowSeconds int `json:"windowSeconds,omitempty"`
+}
+
 // SearchLimits description: Limits that search applies for number of repositories searched and timeouts.
 type SearchLimits struct {
 	// CommitDiffMaxRepos description: The maximum number of repositories to search across when doing a "type:diff" or "type:commit". The user is prompted to narrow their query if the limit is exceeded. There is a separate limit (commitDiffWithTimeFilterMaxRepos) when "after:" or "before:" is specified because those queries are faster. Defaults to 50.
@@ -1578,9 +1684,9 @@ type SiteConfiguration struct {
 	GitCloneURLToRepositoryName []*CloneURLToRepositoryName `json:"git.cloneURLToRepositoryName,omitempty"`
 	// GitLongCommandTimeout description: Maximum number of seconds that a long Git command (e.g. clone or remote update) is allowed to execute. The default is 3600 seconds, or 1 hour.
 	GitLongCommandTimeout int `json:"gitLongCommandTimeout,omitempty"`
-	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote code host git operations (e.g. clone or ls-remote) to be run per second per gitserver. Default is -1, which is unlimited.
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote code host git operations (e.g. clone or ls-remote) to be run per second per gitserver, for each code host connection. Code host connections can override it with gitMaxCodehostRequestsPerSecond in their configuration. Default is -1, which is unlimited.
 	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
-	// GitMaxConcurrentClones description: Maximum number of git clone processes that will be run concurrently per gitserver to update repositories. Note: the global git update scheduler respects gitMaxConcurrentClones. However, we allow each gitserver to run upto gitMaxConcurrentClones to allow for urgent fetches. Urgent fetches are used when a user is browsing a PR and we do not have the commit yet.
+	// GitMaxConcurrentClones description: Maximum number of git clone processes that will be run concurrently per gitserver to update repositories. Note: the global git update scheduler respects gitMaxConcurrentClones. However, we allow each gitserver to run upto gitMaxConcurrentClones to allow for urgent fetches. Urgent fetches are used when a user is browsing a PR and we do not have the commit yet. Code host connections can further limit their clones with gitMaxConcurrentClones in their configuration.
 	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
 	// GitUpdateInterval description: JSON array of repo name patterns and update intervals. If a repo matches a pattern, the associated interval will be used. If it matches no patterns a default backoff heuristic will be used. Pattern matches are attempted in the order they are provided.
 	GitUpdateInterval []*UpdateIntervalRule `json:"gitUpdateInterval,omitempty"`
@@ -1638,6 +1744,8 @@ type SiteConfiguration struct {
 	RepoConcurrentExternalServiceSyncers int `json:"repoConcurrentExternalServiceSyncers,omitempty"`
 	// RepoListUpdateInterval description: Interval (in minutes) for checking code hosts (such as GitHub, Gitolite, etc.) for new repositories.
 	RepoListUpdateInterval int `json:"repoListUpdateInterval,omitempty"`
+	// SearchCostBudgets description: Budgets for the estimated cost of the searches each user and access token can run within a time window. The cost of a search grows with the number of repositories it searches and with expensive options such as "count:all", "type:diff" and structural search. Searches of site admins are not limited.
+	SearchCostBudgets *SearchCostBudgets `json:"search.costBudgets,omitempty"`
 	// SearchIndexEnabled description: Whether indexed search is enabled. If unset Sourcegraph detects the environment to decide if indexed search is enabled. Indexed search is RAM heavy, and is disabled by default in the single docker image. All other environments will have it enabled by default. The size of all your repository working copies is the amount of additional RAM required.
 	SearchIndexEnabled *bool `json:"search.index.enabled,omitempty"`
 	// SearchIndexSymbolsEnabled description: Whether indexed symbol search is enabled. This is contingent on the indexed search configuration, and is true by default for instances with indexed search enabled. Enabling this will cause every repository to re-index, which is a time consuming (several hours) operation. Additionally, it requires more storage and ram to accommodate the added symbols information in the search index.
@@ -1672,6 +1780,42 @@ type Step struct {
 	Run string `json:"run"`
 }
 
+// SubversionConnection description: Configuration for a connection to Subversion repositories.
+type SubversionConnection struct {
+	// Authors description: Maps Subversion usernames to the Git authors of their commits, in the form "Name <email>". Commits of unmapped users are authored by "username <username>".
+	Authors map[string]string `json:"authors,omitempty"`
+	// GitMaxCodehostRequestsPerSecond description: Maximum number of remote git operations (e.g. clone or ls-remote) against this code host connection to be run per second per gitserver. Overrides the site configuration gitMaxCodehostRequestsPerSecond. -1 means unlimited.
+	GitMaxCodehostRequestsPerSecond *int `json:"gitMaxCodehostRequestsPerSecond,omitempty"`
+	// GitMaxConcurrentClones description: Maximum number of git clones and fetches of the repositories of this code host connection that will be run concurrently per gitserver. The site configuration gitMaxConcurrentClones still limits the clones and fetches of all code hosts together. Default is no limit beyond gitMaxConcurrentClones.
+	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
+	// Layout description: The paths of the trunk, branches and tags of the repositories, relative to the repository path. Defaults to the standard Subversion layout.
+	Layout *SubversionLayout `json:"layout,omitempty"`
+	// Password description: The password to authenticate with.
+	Password string `json:"password,omitempty"`
+	// Repos description: The paths of the repositories to mirror, relative to url. If empty, every directory directly under url is mirrored as a repository.
+	Repos []string `json:"repos,omitempty"`
+	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for a Subversion repository. In the pattern, the variable "{host}" is replaced with the host of url, and "{path}" with the path of the repository relative to url.
+	//
+	// For example, if url is "https://svn.example.com/repos", the repository path is "hardware/firmware" and your Sourcegraph URL is https://src.example.com, then a repositoryPathPattern of "svn/{path}" would mean that the repository is available on Sourcegraph at https://src.example.com/svn/hardware/firmware.
+	//
+	// It is important that the Sourcegraph repository name generated with this pattern be unique to this Subversion root. If different Subversion roots generate repository names that collide, Sourcegraph's behavior is undefined.
+	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// Url description: URL of the Subversion root under which the repositories are. Supported schemes are svn, svn+ssh, http, https and file.
+	Url string `json:"url"`
+	// Username description: The username to authenticate with.
+	Username string `json:"username,omitempty"`
+}
+
+// SubversionLayout description: The paths of the trunk, branches and tags of the repositories, relative to the repository path. Defaults to the standard Subversion layout.
+type SubversionLayout struct {
+	// Branches description: The path of the directory of branches, each of which is mirrored as a branch.
+	Branches string `json:"branches,omitempty"`
+	// Tags description: The path of the directory of tags, each of which is mirrored as a tag.
+	Tags string `json:"tags,omitempty"`
+	// Trunk description: The path of the trunk, which is mirrored as the master branch.
+	Trunk string `json:"trunk,omitempty"`
+}
+
 // TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
 type TlsExternal struct {
 	// Certificates description: TLS certificates to accept. This is only necessary if you are using self-signed certificates or an internal CA. Can be an internal CA certificate or a self-signed certificate. To get the certificate of a webserver run `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM`. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.
@@ -57,3 +57,7 @@ var SettingsSchemaJSON string
 // SiteSchemaJSON is the content of the file "site.schema.json".
 //go:embed site.schema.json
 var SiteSchemaJSON string
+
+// SubversionSchemaJSON is the content of the file "subversion.schema.json".
+//go:embed subversion.schema.json
+var SubversionSchemaJSON string